@@ -2,6 +2,7 @@ package modules
 
 import (
 	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/requestctx"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -31,22 +32,14 @@ func (m *ModulesAction) ExecuteReplaceTextAction(action *database.Action, req *h
 	// Perform replacement based on target
 	switch config.Target {
 	case "request_body":
-		if req != nil && req.Body != nil {
-			// Read body
-			bodyBytes, err := io.ReadAll(req.Body)
-			if err != nil {
-				return err
-			}
-			req.Body.Close()
-
-			// Replace text
-			bodyStr := string(bodyBytes)
+		// Bodies over the request body cap are not buffered and pass through untouched
+		if req != nil && req.Body != nil && !requestctx.From(req).Oversized {
+			// Replace text in the shared, decoded body
+			bodyStr := string(requestctx.From(req).Body)
 			bodyStr = m.replaceText(bodyStr, config.Pattern, config.Replacement, config.UseRegex)
 
-			// Set new body
-			req.Body = io.NopCloser(bytes.NewBufferString(bodyStr))
-			req.ContentLength = int64(len(bodyStr))
-			req.Header.Set("Content-Length", strconv.Itoa(len(bodyStr)))
+			// Set new body so rules, templates and the proxy see the change
+			requestctx.SetBody(req, []byte(bodyStr))
 		}
 	case "response_body":
 		if resp != nil && resp.Body != nil {
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/requestctx"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -214,17 +215,9 @@ func convertHTTPRequestToJS(req *http.Request) (*JavascriptRequest, error) {
 		}
 	}
 
-	// Read body if present
+	// Use the shared, decoded body if present
 	if req.Body != nil {
-		bodyBytes, err := io.ReadAll(req.Body)
-		if err != nil {
-			return nil, err
-		}
-		req.Body.Close()
-		jsReq.Body = string(bodyBytes)
-
-		// Restore body for further use
-		req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		jsReq.Body = string(requestctx.From(req).Body)
 	}
 
 	return jsReq, nil
@@ -279,12 +272,9 @@ func applyJSRequestToHTTP(jsReq *JavascriptRequest, req *http.Request) error {
 		}
 	}
 
-	// Update body
+	// Update body so rules, templates and the proxy see the change
 	if jsReq.Body != "" {
-		bodyBytes := []byte(jsReq.Body)
-		req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-		req.ContentLength = int64(len(bodyBytes))
-		req.Header.Set("Content-Length", strconv.Itoa(len(bodyBytes)))
+		requestctx.SetBody(req, []byte(jsReq.Body))
 	}
 
 	return nil
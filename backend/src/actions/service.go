@@ -3,7 +3,10 @@ package actions
 import (
 	"beo-echo/backend/src/actions/modules"
 	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/requestctx"
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"regexp"
@@ -349,6 +352,7 @@ func (s *ActionService) matchFilters(filters []database.ActionFilter, req *http.
 }
 
 // matchFilter checks if a single filter matches
+// A body filter whose JSONPath key selects several values matches when any of them does.
 func (s *ActionService) matchFilter(filter database.ActionFilter, req *http.Request, resp *http.Response) bool {
 	var value string
 
@@ -370,11 +374,25 @@ func (s *ActionService) matchFilter(filter database.ActionFilter, req *http.Requ
 		if resp != nil {
 			value = strconv.Itoa(resp.StatusCode)
 		}
+	case "body":
+		if req == nil {
+			return false
+		}
+		for _, bodyValue := range requestBodyValues(req, filter.Key) {
+			if matchFilterValue(filter, bodyValue) {
+				return true
+			}
+		}
+		return false
 	default:
 		return false
 	}
 
-	// Compare based on operator
+	return matchFilterValue(filter, value)
+}
+
+// matchFilterValue compares a value with the filter value based on the operator
+func matchFilterValue(filter database.ActionFilter, value string) bool {
 	switch filter.Operator {
 	case "equals":
 		return value == filter.Value
@@ -394,3 +412,35 @@ func (s *ActionService) matchFilter(filter database.ActionFilter, req *http.Requ
 		return false
 	}
 }
+
+// requestBodyValues returns the request body values selected by a JSONPath key as strings
+// An empty key selects the whole body.
+func requestBodyValues(req *http.Request, key string) []string {
+	request := requestctx.From(req)
+	if key == "" {
+		return []string{string(request.Body)}
+	}
+
+	parsed, ok := request.JSON()
+	if !ok {
+		return nil
+	}
+	found, err := jsonpath.Find(parsed, key)
+	if err != nil {
+		return nil
+	}
+
+	values := make([]string, 0, len(found))
+	for _, v := range found {
+		switch typed := v.(type) {
+		case string:
+			values = append(values, typed)
+		case nil:
+			values = append(values, "")
+		default:
+			encoded, _ := json.Marshal(typed)
+			values = append(values, string(encoded))
+		}
+	}
+	return values
+}
@@ -0,0 +1,36 @@
+package actions
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestMatchFilter_Body(t *testing.T) {
+	service := &ActionService{}
+	body := `{"user":{"role":"admin"},"items":[{"sku":"A1"},{"sku":"B2"}]}`
+
+	tests := []struct {
+		name     string
+		filter   database.ActionFilter
+		expected bool
+	}{
+		{name: "nested key", filter: database.ActionFilter{Type: "body", Key: "user.role", Operator: "equals", Value: "admin"}, expected: true},
+		{name: "any array element", filter: database.ActionFilter{Type: "body", Key: "items[*].sku", Operator: "equals", Value: "B2"}, expected: true},
+		{name: "filter expression", filter: database.ActionFilter{Type: "body", Key: "$.items[?(@.sku=='A1')].sku", Operator: "starts_with", Value: "A"}, expected: true},
+		{name: "no element matches", filter: database.ActionFilter{Type: "body", Key: "items[*].sku", Operator: "equals", Value: "C3"}, expected: false},
+		{name: "whole body", filter: database.ActionFilter{Type: "body", Operator: "contains", Value: `"role":"admin"`}, expected: true},
+		{name: "invalid key", filter: database.ActionFilter{Type: "body", Key: "items[", Operator: "contains", Value: "A1"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://localhost/orders", strings.NewReader(body))
+			assert.Equal(t, tt.expected, service.matchFilter(tt.filter, req, nil))
+		})
+	}
+}
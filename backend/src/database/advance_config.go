@@ -1,18 +1,833 @@
 package database
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
 // AdvanceConfigProject defines advance configuration structure for projects
 type AdvanceConfigProject struct {
-	DelayMs int `json:"delayMs,omitempty"` // Response delay in milliseconds (0-120000)
+	DelayMs   int              `json:"delayMs,omitempty"`   // Response delay in milliseconds (0-120000)
+	Latency   *LatencyConfig   `json:"latency,omitempty"`   // Randomized delay; takes precedence over delayMs
+	Faults    []FaultRule      `json:"faults,omitempty"`    // Faults injected into responses (mock and proxied)
+	Scenarios []ScenarioConfig `json:"scenarios,omitempty"` // Named state machines used by responses
+	Record    *RecordConfig    `json:"record,omitempty"`    // Turns forwarded traffic into endpoints and responses
+	CORS      *CORSPolicy      `json:"cors,omitempty"`      // CORS policy of the mock endpoints, preflights included
+	Auth      *AuthConfig      `json:"auth,omitempty"`      // Credentials required before a request reaches the endpoints
+}
+
+// MaxCORSMaxAgeSeconds is the longest time browsers may cache a preflight response
+const MaxCORSMaxAgeSeconds = 86400
+
+// CORSPolicy is the CORS policy applied to the mock and proxied responses of a project
+// Preflight requests are answered from it without reaching the endpoints.
+type CORSPolicy struct {
+	Enabled          bool     `json:"enabled"`
+	AllowOrigins     []string `json:"allowOrigins,omitempty"`     // "*", "https://app.example.com" or "https://*.example.com"; any origin when empty
+	AllowMethods     []string `json:"allowMethods,omitempty"`     // Methods allowed by preflights; the usual methods when empty
+	AllowHeaders     []string `json:"allowHeaders,omitempty"`     // Request headers allowed by preflights; the requested headers when empty or "*"
+	ExposeHeaders    []string `json:"exposeHeaders,omitempty"`    // Response headers scripts can read
+	AllowCredentials bool     `json:"allowCredentials,omitempty"` // Allow cookies and Authorization; the origin is echoed instead of "*"
+	MaxAgeSeconds    int      `json:"maxAgeSeconds,omitempty"`    // Time browsers cache a preflight response (0-86400)
+}
+
+// Validate validates the CORS policy
+func (c *CORSPolicy) Validate() error {
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" || u.User != nil {
+			return fmt.Errorf("cors allowOrigins: %q must be \"*\" or an origin like https://app.example.com", origin)
+		}
+	}
+	for _, method := range c.AllowMethods {
+		if method != "*" && !validHeaderName(method) {
+			return fmt.Errorf("cors allowMethods: invalid method %q", method)
+		}
+	}
+	for _, name := range append(append([]string{}, c.AllowHeaders...), c.ExposeHeaders...) {
+		if name != "*" && !validHeaderName(name) {
+			return fmt.Errorf("cors: invalid header name %q", name)
+		}
+	}
+	if c.MaxAgeSeconds < 0 || c.MaxAgeSeconds > MaxCORSMaxAgeSeconds {
+		return fmt.Errorf("cors maxAgeSeconds must be between 0 and %d", MaxCORSMaxAgeSeconds)
+	}
+	return nil
+}
+
+// AllowsAnyOrigin reports whether every origin is allowed
+func (c *CORSPolicy) AllowsAnyOrigin() bool {
+	if len(c.AllowOrigins) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOrigin reports whether the policy allows requests from origin, e.g. "https://app.example.com"
+func (c *CORSPolicy) AllowsOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if c.AllowsAnyOrigin() {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.AllowOrigins {
+		allowed = strings.ToLower(allowed)
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			// "https://*.example.com" matches the subdomains of example.com, not example.com itself
+			if strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+domain) {
+				return true
+			}
+			continue
+		}
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordConfig turns requests forwarded in proxy and forwarder mode into mock endpoints and responses
+type RecordConfig struct {
+	Enabled       bool     `json:"enabled"`
+	GenerateRules bool     `json:"generateRules,omitempty"` // Keep a response per distinct query/body values, told apart by rules
+	IgnoreFields  []string `json:"ignoreFields,omitempty"`  // Request fields never used for rules, e.g. "query.ts" or "body.nonce"
+}
+
+// ScenarioConfig defines a project scenario and the states it can be in
+type ScenarioConfig struct {
+	Name         string   `json:"name"`
+	InitialState string   `json:"initialState,omitempty"` // State after a reset (default "Started")
+	States       []string `json:"states,omitempty"`       // Allowed states; any state is allowed when empty
+}
+
+// DefaultScenarioState is the initial state of scenarios that do not define one
+const DefaultScenarioState = "Started"
+
+// Initial returns the state the scenario starts in
+func (s ScenarioConfig) Initial() string {
+	if s.InitialState == "" {
+		return DefaultScenarioState
+	}
+	return s.InitialState
+}
+
+// HasState reports whether state is allowed for the scenario
+func (s ScenarioConfig) HasState(state string) bool {
+	if len(s.States) == 0 || state == s.Initial() {
+		return true
+	}
+	for _, allowed := range s.States {
+		if allowed == state {
+			return true
+		}
+	}
+	return false
+}
+
+// FindScenario returns the scenario with the given name
+func (a *AdvanceConfigProject) FindScenario(name string) (ScenarioConfig, bool) {
+	for _, scenario := range a.Scenarios {
+		if scenario.Name == name {
+			return scenario, true
+		}
+	}
+	return ScenarioConfig{}, false
+}
+
+// Simulated authentication types supported by AuthConfig
+const (
+	AuthNone   = "none"
+	AuthAPIKey = "api_key"
+	AuthBasic  = "basic"
+	AuthJWT    = "jwt"
+)
+
+// AuthConfig makes mock endpoints require credentials, like the API they stand in for
+// Requests without valid credentials get the unauthorized response, and JWTs that lack
+// the required scopes or claims get the forbidden response.
+type AuthConfig struct {
+	Type         string        `json:"type"` // none, api_key, basic or jwt
+	APIKey       *APIKeyAuth   `json:"apiKey,omitempty"`
+	Basic        *BasicAuth    `json:"basic,omitempty"`
+	JWT          *JWTAuth      `json:"jwt,omitempty"`
+	Unauthorized *AuthResponse `json:"unauthorized,omitempty"` // Replaces the default 401 response
+	Forbidden    *AuthResponse `json:"forbidden,omitempty"`    // Replaces the default 403 response
+}
+
+// APIKeyAuth accepts requests carrying one of the keys in a header or a query parameter
+type APIKeyAuth struct {
+	Header string   `json:"header,omitempty"` // e.g. "X-API-Key"
+	Query  string   `json:"query,omitempty"`  // e.g. "api_key"
+	Keys   []string `json:"keys"`
+}
+
+// BasicAuth accepts HTTP Basic credentials of the listed users
+type BasicAuth struct {
+	Users map[string]string `json:"users"`           // Username -> password
+	Realm string            `json:"realm,omitempty"` // Realm of the WWW-Authenticate challenge
+}
+
+// JWTAuth accepts bearer JWTs signed with an HMAC secret or a key of a JWKS document
+type JWTAuth struct {
+	Secret         string            `json:"secret,omitempty"`         // HMAC secret (HS256, HS384, HS512)
+	JWKS           json.RawMessage   `json:"jwks,omitempty"`           // Inline JWKS document with RSA, EC or Ed25519 keys
+	JWKSURL        string            `json:"jwksUrl,omitempty"`        // URL the JWKS document is fetched from
+	Issuer         string            `json:"issuer,omitempty"`         // Required iss claim
+	Audience       string            `json:"audience,omitempty"`       // Required aud value
+	LeewaySeconds  int               `json:"leewaySeconds,omitempty"`  // Clock skew allowed on exp, nbf and iat
+	RequiredScopes []string          `json:"requiredScopes,omitempty"` // Scopes the scope (or scp) claim must grant, else forbidden
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty"` // Claim -> value the token must hold, else forbidden
+}
+
+// AuthResponse is the response sent when a request is not authorized
+type AuthResponse struct {
+	Status  int               `json:"status,omitempty"` // 4xx status; 401 or 403 by default
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// MaxAuthLeewaySeconds is the largest clock skew allowed on JWT time claims
+const MaxAuthLeewaySeconds = 3600
+
+// Validate validates the authentication configuration
+func (a *AuthConfig) Validate() error {
+	switch a.Type {
+	case AuthNone:
+	case AuthAPIKey:
+		if a.APIKey == nil || (a.APIKey.Header == "" && a.APIKey.Query == "") {
+			return errors.New("auth apiKey needs a header or a query parameter")
+		}
+		if a.APIKey.Header != "" && !validHeaderName(a.APIKey.Header) {
+			return fmt.Errorf("auth apiKey: invalid header name %q", a.APIKey.Header)
+		}
+		if len(a.APIKey.Keys) == 0 {
+			return errors.New("auth apiKey needs at least one key")
+		}
+		for _, key := range a.APIKey.Keys {
+			if key == "" {
+				return errors.New("auth apiKey keys cannot be empty")
+			}
+		}
+	case AuthBasic:
+		if a.Basic == nil || len(a.Basic.Users) == 0 {
+			return errors.New("auth basic needs at least one user")
+		}
+		for user := range a.Basic.Users {
+			if user == "" || strings.Contains(user, ":") {
+				return fmt.Errorf("auth basic: invalid username %q", user)
+			}
+		}
+	case AuthJWT:
+		if err := a.JWT.validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("auth type must be %s, %s, %s or %s", AuthNone, AuthAPIKey, AuthBasic, AuthJWT)
+	}
+
+	for name, response := range map[string]*AuthResponse{"unauthorized": a.Unauthorized, "forbidden": a.Forbidden} {
+		if response == nil {
+			continue
+		}
+		if response.Status != 0 && (response.Status < 400 || response.Status > 499) {
+			return fmt.Errorf("auth %s status must be a 4xx status", name)
+		}
+		for header := range response.Headers {
+			if !validHeaderName(header) {
+				return fmt.Errorf("auth %s: invalid header name %q", name, header)
+			}
+		}
+	}
+	return nil
+}
+
+// validate validates the JWT settings
+func (j *JWTAuth) validate() error {
+	if j == nil {
+		return errors.New("auth jwt needs a secret, jwks or jwksUrl")
+	}
+	sources := 0
+	for _, set := range []bool{j.Secret != "", len(j.JWKS) > 0, j.JWKSURL != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return errors.New("auth jwt needs exactly one of secret, jwks or jwksUrl")
+	}
+	if len(j.JWKS) > 0 {
+		var document struct {
+			Keys []json.RawMessage `json:"keys"`
+		}
+		if err := json.Unmarshal(j.JWKS, &document); err != nil || len(document.Keys) == 0 {
+			return errors.New("auth jwt jwks must be a JWKS document with keys")
+		}
+	}
+	if j.JWKSURL != "" {
+		u, err := url.Parse(j.JWKSURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("auth jwt jwksUrl must be an absolute http or https URL")
+		}
+	}
+	if j.LeewaySeconds < 0 || j.LeewaySeconds > MaxAuthLeewaySeconds {
+		return fmt.Errorf("auth jwt leewaySeconds must be between 0 and %d", MaxAuthLeewaySeconds)
+	}
+	for claim := range j.RequiredClaims {
+		if claim == "" {
+			return errors.New("auth jwt requiredClaims names cannot be empty")
+		}
+	}
+	return nil
 }
 
 // AdvanceConfigEndpoint defines advance configuration structure for endpoints
 type AdvanceConfigEndpoint struct {
-	DelayMs int `json:"delayMs,omitempty"` // Response delay in milliseconds (0-120000)
+	DelayMs    int            `json:"delayMs,omitempty"`    // Response delay in milliseconds (0-120000)
+	Latency    *LatencyConfig `json:"latency,omitempty"`    // Randomized delay; takes precedence over delayMs
+	Faults     []FaultRule    `json:"faults,omitempty"`     // Faults injected into responses; replaces the project faults
+	Collection string         `json:"collection,omitempty"` // Collection name served when response_mode is "crud"
+	IDParam    string         `json:"idParam,omitempty"`    // Path param holding the collection item id (default "id")
+
+	ProxyRewrite *ProxyRewriteConfig `json:"proxyRewrite,omitempty"` // Rewrites applied when the endpoint proxies; replaces the target rewrites
+	Auth         *AuthConfig         `json:"auth,omitempty"`         // Replaces the project auth; type "none" makes the endpoint public
+}
+
+// Latency distributions supported by LatencyConfig
+const (
+	LatencyUniform    = "uniform"
+	LatencyNormal     = "normal"
+	LatencyLogNormal  = "lognormal"
+	LatencyPercentile = "percentile"
+)
+
+// MaxDelayMs is the upper bound for any configured or sampled delay
+const MaxDelayMs = 120000
+
+// LatencyConfig describes a delay drawn from a distribution on every request
+type LatencyConfig struct {
+	Distribution string  `json:"distribution"`       // uniform, normal, lognormal or percentile
+	MinMs        int     `json:"minMs,omitempty"`    // Lower bound (uniform range, clamp for the others)
+	MaxMs        int     `json:"maxMs,omitempty"`    // Upper bound (uniform range, clamp for the others)
+	MeanMs       float64 `json:"meanMs,omitempty"`   // Mean for normal and lognormal
+	StdDevMs     float64 `json:"stdDevMs,omitempty"` // Standard deviation for normal and lognormal
+	P50Ms        int     `json:"p50Ms,omitempty"`    // Percentile targets for the percentile distribution
+	P95Ms        int     `json:"p95Ms,omitempty"`
+	P99Ms        int     `json:"p99Ms,omitempty"`
+}
+
+// Validate validates the latency configuration
+func (l *LatencyConfig) Validate() error {
+	fields := []struct {
+		name  string
+		value float64
+	}{
+		{"minMs", float64(l.MinMs)}, {"maxMs", float64(l.MaxMs)}, {"meanMs", l.MeanMs}, {"stdDevMs", l.StdDevMs},
+		{"p50Ms", float64(l.P50Ms)}, {"p95Ms", float64(l.P95Ms)}, {"p99Ms", float64(l.P99Ms)},
+	}
+	for _, field := range fields {
+		if field.value < 0 {
+			return fmt.Errorf("latency %s cannot be negative", field.name)
+		}
+		if field.value > MaxDelayMs {
+			return fmt.Errorf("latency %s cannot exceed %dms", field.name, MaxDelayMs)
+		}
+	}
+	if l.MaxMs > 0 && l.MaxMs < l.MinMs {
+		return errors.New("latency maxMs cannot be lower than minMs")
+	}
+
+	switch l.Distribution {
+	case LatencyUniform:
+		if l.MaxMs == 0 {
+			return errors.New("uniform latency requires maxMs")
+		}
+	case LatencyNormal, LatencyLogNormal:
+		if l.MeanMs == 0 {
+			return fmt.Errorf("%s latency requires meanMs", l.Distribution)
+		}
+	case LatencyPercentile:
+		if l.P50Ms == 0 || l.P95Ms == 0 || l.P99Ms == 0 {
+			return errors.New("percentile latency requires p50Ms, p95Ms and p99Ms")
+		}
+		if l.P50Ms > l.P95Ms || l.P95Ms > l.P99Ms {
+			return errors.New("percentile latency requires p50Ms <= p95Ms <= p99Ms")
+		}
+		if l.MinMs > l.P50Ms || (l.MaxMs > 0 && l.MaxMs < l.P99Ms) {
+			return errors.New("percentile latency bounds must include p50Ms and p99Ms")
+		}
+	default:
+		return fmt.Errorf("unknown latency distribution: %s", l.Distribution)
+	}
+	return nil
+}
+
+// Fault types supported by FaultRule
+const (
+	FaultError         = "error"          // Reply with an error status instead of the response
+	FaultReset         = "reset"          // Reset the connection without replying
+	FaultEmpty         = "empty"          // Close the connection without replying
+	FaultTruncate      = "truncate"       // Close the connection part way through the body
+	FaultTrickle       = "trickle"        // Send the body slowly
+	FaultMalformedJSON = "malformed_json" // Corrupt the body so it no longer parses as JSON
+)
+
+// FaultRule describes a fault injected into a share of responses
+type FaultRule struct {
+	Type        string  `json:"type"`                  // One of the Fault* types
+	Probability float64 `json:"probability"`           // Chance (0-1) the fault hits a response
+	Status      int     `json:"status,omitempty"`      // Status for error faults (default 500)
+	Bytes       int     `json:"bytes,omitempty"`       // Body bytes sent before closing for truncate (default half the body)
+	BytesPerSec int     `json:"bytesPerSec,omitempty"` // Transfer rate for trickle
+}
+
+// ValidateFaults validates a list of fault rules
+func ValidateFaults(faults []FaultRule) error {
+	var total float64
+	for _, fault := range faults {
+		switch fault.Type {
+		case FaultError:
+			if fault.Status != 0 && (fault.Status < 400 || fault.Status > 599) {
+				return errors.New("error fault status must be between 400 and 599")
+			}
+		case FaultTrickle:
+			if fault.BytesPerSec <= 0 {
+				return errors.New("trickle fault requires bytesPerSec")
+			}
+		case FaultTruncate:
+			if fault.Bytes < 0 {
+				return errors.New("truncate fault bytes cannot be negative")
+			}
+		case FaultReset, FaultEmpty, FaultMalformedJSON:
+		default:
+			return fmt.Errorf("unknown fault type: %s", fault.Type)
+		}
+		if fault.Probability < 0 || fault.Probability > 1 {
+			return fmt.Errorf("%s fault probability must be between 0 and 1", fault.Type)
+		}
+		total += fault.Probability
+	}
+	if total > 1+1e-9 {
+		return errors.New("fault probabilities cannot add up to more than 1")
+	}
+	return nil
+}
+
+// ParseLatencyConfig parses a latency JSON string, returning nil when it is empty
+func ParseLatencyConfig(configJSON string) (*LatencyConfig, error) {
+	if configJSON == "" {
+		return nil, nil
+	}
+
+	var config LatencyConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, errors.New("invalid JSON format in latency")
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// MaxCallbacks is the maximum number of callbacks a response can send
+const MaxCallbacks = 10
+
+// Default and maximum retry settings of callbacks
+const (
+	MaxCallbackAttempts     = 10
+	DefaultCallbackBackoff  = 1000
+	DefaultCallbackMaxDelay = 30000
+)
+
+// CallbackConfig describes an HTTP request sent after a mock response is served,
+// like a payment provider calling a webhook. URL, header values and body are
+// templates rendered against the original request.
+type CallbackConfig struct {
+	URL     string            `json:"url"`               // Target URL, may be a template
+	Method  string            `json:"method,omitempty"`  // HTTP method (default POST)
+	Headers map[string]string `json:"headers,omitempty"` // Request headers, values may be templates
+	Body    string            `json:"body,omitempty"`    // Request body, may be a template
+	DelayMs int               `json:"delayMs,omitempty"` // Wait before the first attempt (0-120000)
+	Retry   *CallbackRetry    `json:"retry,omitempty"`   // Retry on network errors, 5xx and 429 responses
+}
+
+// CallbackRetry describes how a failed callback is retried with exponential backoff
+type CallbackRetry struct {
+	MaxAttempts  int `json:"maxAttempts"`            // Total attempts including the first (1-10)
+	BackoffMs    int `json:"backoffMs,omitempty"`    // Wait before the second attempt, doubled after each retry (default 1000)
+	MaxBackoffMs int `json:"maxBackoffMs,omitempty"` // Upper bound of the wait between attempts (default 30000)
+}
+
+// Validate validates the callback configuration
+func (c *CallbackConfig) Validate() error {
+	if strings.TrimSpace(c.URL) == "" {
+		return errors.New("callback url is required")
+	}
+	// Templated URLs are only known once rendered
+	if !strings.Contains(c.URL, "{{") {
+		target, err := url.Parse(c.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("callback url must be an absolute http or https URL: %s", c.URL)
+		}
+	}
+
+	switch strings.ToUpper(c.Method) {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions:
+	default:
+		return fmt.Errorf("unsupported callback method: %s", c.Method)
+	}
+
+	if c.DelayMs < 0 {
+		return errors.New("callback delayMs cannot be negative")
+	}
+	if c.DelayMs > MaxDelayMs {
+		return fmt.Errorf("callback delayMs cannot exceed %dms", MaxDelayMs)
+	}
+
+	if c.Retry != nil {
+		if c.Retry.MaxAttempts < 1 || c.Retry.MaxAttempts > MaxCallbackAttempts {
+			return fmt.Errorf("callback retry maxAttempts must be between 1 and %d", MaxCallbackAttempts)
+		}
+		if c.Retry.BackoffMs < 0 || c.Retry.MaxBackoffMs < 0 {
+			return errors.New("callback retry backoff cannot be negative")
+		}
+		if c.Retry.BackoffMs > MaxDelayMs || c.Retry.MaxBackoffMs > MaxDelayMs {
+			return fmt.Errorf("callback retry backoff cannot exceed %dms", MaxDelayMs)
+		}
+	}
+	return nil
+}
+
+// Backoff returns the wait before the given retry (1 for the second attempt)
+func (r *CallbackRetry) Backoff(retry int) int {
+	backoff := r.BackoffMs
+	if backoff == 0 {
+		backoff = DefaultCallbackBackoff
+	}
+	maxBackoff := r.MaxBackoffMs
+	if maxBackoff == 0 {
+		maxBackoff = DefaultCallbackMaxDelay
+	}
+	for i := 1; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// ParseCallbacks parses the callbacks JSON array of a response, returning nil when it is empty
+func ParseCallbacks(callbacksJSON string) ([]CallbackConfig, error) {
+	if strings.TrimSpace(callbacksJSON) == "" {
+		return nil, nil
+	}
+
+	var callbacks []CallbackConfig
+	if err := json.Unmarshal([]byte(callbacksJSON), &callbacks); err != nil {
+		return nil, errors.New("invalid JSON format in callbacks")
+	}
+	if len(callbacks) > MaxCallbacks {
+		return nil, fmt.Errorf("a response cannot have more than %d callbacks", MaxCallbacks)
+	}
+
+	for i := range callbacks {
+		if err := callbacks[i].Validate(); err != nil {
+			return nil, fmt.Errorf("callbacks[%d]: %w", i, err)
+		}
+	}
+	return callbacks, nil
+}
+
+// Proxy pool strategies
+const (
+	PoolRoundRobin = "round_robin" // Targets take turns
+	PoolWeighted   = "weighted"    // Targets are picked at random in proportion to their weight
+	PoolSticky     = "sticky"      // The value of a request header pins clients to a target
+	PoolFailover   = "failover"    // The first target serves, the next ones take over when it fails
+)
+
+// ProxyPoolConfig turns a proxy target into a pool that spreads requests over other targets
+type ProxyPoolConfig struct {
+	Strategy     string            `json:"strategy"`               // One of the Pool* strategies
+	Members      []ProxyPoolMember `json:"members"`                // Targets of the pool, in failover order
+	StickyHeader string            `json:"stickyHeader,omitempty"` // Header whose value picks the target (sticky)
+	Failover     bool              `json:"failover,omitempty"`     // Try the next target on connection errors and 5xx (always on for failover)
+}
+
+// ProxyPoolMember is a proxy target taking part in a pool
+type ProxyPoolMember struct {
+	TargetID string `json:"targetId"`
+	Weight   int    `json:"weight,omitempty"` // Relative share for weighted pools, 1 when unset
+}
+
+// Validate validates the pool configuration
+func (p *ProxyPoolConfig) Validate() error {
+	switch p.Strategy {
+	case PoolRoundRobin, PoolWeighted, PoolFailover:
+	case PoolSticky:
+		if strings.TrimSpace(p.StickyHeader) == "" {
+			return errors.New("sticky pool requires stickyHeader")
+		}
+	default:
+		return fmt.Errorf("unknown pool strategy: %s", p.Strategy)
+	}
+
+	if len(p.Members) == 0 {
+		return errors.New("pool requires at least one member")
+	}
+	seen := make(map[string]bool, len(p.Members))
+	for _, member := range p.Members {
+		if member.TargetID == "" {
+			return errors.New("pool member targetId is required")
+		}
+		if seen[member.TargetID] {
+			return fmt.Errorf("duplicate pool member: %s", member.TargetID)
+		}
+		seen[member.TargetID] = true
+		if member.Weight < 0 {
+			return errors.New("pool member weight cannot be negative")
+		}
+	}
+	return nil
+}
+
+// ParseProxyPool parses the pool JSON of a proxy target, returning nil when it is empty
+func ParseProxyPool(poolJSON string) (*ProxyPoolConfig, error) {
+	if strings.TrimSpace(poolJSON) == "" {
+		return nil, nil
+	}
+
+	var pool ProxyPoolConfig
+	if err := json.Unmarshal([]byte(poolJSON), &pool); err != nil {
+		return nil, errors.New("invalid JSON format in pool")
+	}
+
+	if err := pool.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &pool, nil
+}
+
+// Proxy transport defaults and limits
+const (
+	DefaultProxyConnectTimeoutMs = 10000  // Time allowed to connect and complete the TLS handshake
+	DefaultProxyReadTimeoutMs    = 30000  // Time allowed for the upstream to send response headers
+	MaxProxyTimeoutMs            = 600000 // Upper bound for proxy timeouts
+)
+
+// ProxyTransportConfig holds the connection settings used to reach a proxy target
+type ProxyTransportConfig struct {
+	VerifyTLS        bool   `json:"verifyTls,omitempty"`        // Verify the upstream certificate (off by default)
+	CABundle         string `json:"caBundle,omitempty"`         // PEM certificates trusted in addition to the system roots
+	ClientCert       string `json:"clientCert,omitempty"`       // PEM client certificate for mTLS upstreams
+	ClientKey        string `json:"clientKey,omitempty"`        // PEM private key of ClientCert
+	ServerName       string `json:"serverName,omitempty"`       // SNI and certificate name override
+	ConnectTimeoutMs int    `json:"connectTimeoutMs,omitempty"` // Defaults to DefaultProxyConnectTimeoutMs
+	ReadTimeoutMs    int    `json:"readTimeoutMs,omitempty"`    // Defaults to DefaultProxyReadTimeoutMs
+	HTTP2            bool   `json:"http2,omitempty"`            // Prefer HTTP/2 when the upstream supports it
+	ProxyURL         string `json:"proxyUrl,omitempty"`         // Outbound proxy, http, https or socks5
+}
+
+// Validate validates the transport configuration
+func (t *ProxyTransportConfig) Validate() error {
+	if t.CABundle != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(t.CABundle)) {
+			return errors.New("caBundle contains no valid PEM certificate")
+		}
+	}
+
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return errors.New("clientCert and clientKey must be set together")
+	}
+	if t.ClientCert != "" {
+		if _, err := tls.X509KeyPair([]byte(t.ClientCert), []byte(t.ClientKey)); err != nil {
+			return fmt.Errorf("invalid client certificate: %v", err)
+		}
+	}
+
+	for _, timeout := range []struct {
+		name  string
+		value int
+	}{{"connectTimeoutMs", t.ConnectTimeoutMs}, {"readTimeoutMs", t.ReadTimeoutMs}} {
+		if timeout.value < 0 {
+			return fmt.Errorf("%s cannot be negative", timeout.name)
+		}
+		if timeout.value > MaxProxyTimeoutMs {
+			return fmt.Errorf("%s cannot exceed %dms", timeout.name, MaxProxyTimeoutMs)
+		}
+	}
+
+	if t.ProxyURL != "" {
+		u, err := url.Parse(t.ProxyURL)
+		if err != nil || u.Host == "" {
+			return errors.New("proxyUrl must be an absolute URL")
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("unsupported proxyUrl scheme: %s", u.Scheme)
+		}
+	}
+	return nil
+}
+
+// ConnectTimeout returns the connect timeout, applying the default
+func (t *ProxyTransportConfig) ConnectTimeout() time.Duration {
+	if t.ConnectTimeoutMs == 0 {
+		return DefaultProxyConnectTimeoutMs * time.Millisecond
+	}
+	return time.Duration(t.ConnectTimeoutMs) * time.Millisecond
+}
+
+// ReadTimeout returns the response header timeout, applying the default
+func (t *ProxyTransportConfig) ReadTimeout() time.Duration {
+	if t.ReadTimeoutMs == 0 {
+		return DefaultProxyReadTimeoutMs * time.Millisecond
+	}
+	return time.Duration(t.ReadTimeoutMs) * time.Millisecond
+}
+
+// ParseProxyTransport parses the transport JSON of a proxy target.
+// An empty value yields the default configuration.
+func ParseProxyTransport(transportJSON string) (*ProxyTransportConfig, error) {
+	var transport ProxyTransportConfig
+	if strings.TrimSpace(transportJSON) == "" {
+		return &transport, nil
+	}
+
+	if err := json.Unmarshal([]byte(transportJSON), &transport); err != nil {
+		return nil, errors.New("invalid JSON format in transport")
+	}
+
+	if err := transport.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &transport, nil
+}
+
+// ProxyRewriteConfig rewrites requests forwarded to a proxy target and the responses it sends back
+type ProxyRewriteConfig struct {
+	Path           []PathRewrite     `json:"path,omitempty"`           // The first matching rule rewrites the forwarded path
+	RequestHeaders *HeaderRewrite    `json:"requestHeaders,omitempty"` // Changes to the headers sent upstream
+	PreserveHost   bool              `json:"preserveHost,omitempty"`   // Send the Host of the client request instead of the target host
+	Location       map[string]string `json:"location,omitempty"`       // Location URL prefix -> replacement; "" makes the URL relative
+	CookieDomain   map[string]string `json:"cookieDomain,omitempty"`   // Set-Cookie domain -> replacement; "" removes the domain, "*" matches any
+}
+
+// PathRewrite replaces a path prefix, or the matches of a regex, in the forwarded path
+type PathRewrite struct {
+	Prefix  string `json:"prefix,omitempty"`  // Path prefix to replace, e.g. "/api"
+	Regex   string `json:"regex,omitempty"`   // Regex matched against the path, e.g. "^/users/(\\d+)$"
+	Replace string `json:"replace,omitempty"` // Replacement; regex rules can refer to groups as $1 or ${name}
+}
+
+// HeaderRewrite changes the request headers sent upstream.
+// Headers are removed first, then set, then added.
+type HeaderRewrite struct {
+	Add    map[string]string `json:"add,omitempty"`    // Added next to the values sent by the client
+	Set    map[string]string `json:"set,omitempty"`    // Replace the values sent by the client
+	Remove []string          `json:"remove,omitempty"` // Never forwarded
+}
+
+// Validate validates the rewrite configuration
+func (r *ProxyRewriteConfig) Validate() error {
+	for i, rule := range r.Path {
+		switch {
+		case (rule.Prefix == "") == (rule.Regex == ""):
+			return fmt.Errorf("path[%d]: exactly one of prefix or regex is required", i)
+		case rule.Prefix != "" && !strings.HasPrefix(rule.Prefix, "/"):
+			return fmt.Errorf("path[%d]: prefix must start with /", i)
+		case rule.Regex != "":
+			if _, err := regexp.Compile(rule.Regex); err != nil {
+				return fmt.Errorf("path[%d]: invalid regex: %v", i, err)
+			}
+		}
+	}
+
+	if h := r.RequestHeaders; h != nil {
+		names := append([]string{}, h.Remove...)
+		for name := range h.Set {
+			names = append(names, name)
+		}
+		for name := range h.Add {
+			names = append(names, name)
+		}
+		for _, name := range names {
+			if !validHeaderName(name) {
+				return fmt.Errorf("invalid request header name: %q", name)
+			}
+			if strings.EqualFold(name, "Host") {
+				return errors.New("the Host header cannot be rewritten, use preserveHost")
+			}
+		}
+	}
+
+	for from := range r.Location {
+		u, err := url.Parse(from)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("location %q must be an absolute http or https URL", from)
+		}
+	}
+
+	for from := range r.CookieDomain {
+		if strings.TrimSpace(from) == "" {
+			return errors.New("cookieDomain keys cannot be empty")
+		}
+	}
+	return nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune("()<>@,;:\\\"/[]?={}", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseProxyRewrite parses the rewrite JSON of a proxy target, returning nil when it is empty
+func ParseProxyRewrite(rewriteJSON string) (*ProxyRewriteConfig, error) {
+	if strings.TrimSpace(rewriteJSON) == "" {
+		return nil, nil
+	}
+
+	var rewrite ProxyRewriteConfig
+	if err := json.Unmarshal([]byte(rewriteJSON), &rewrite); err != nil {
+		return nil, errors.New("invalid JSON format in rewrite")
+	}
+
+	if err := rewrite.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &rewrite, nil
 }
 
 // Validate validates the project advance configuration
@@ -20,9 +835,57 @@ func (a *AdvanceConfigProject) Validate() error {
 	if a.DelayMs < 0 {
 		return errors.New("delayMs cannot be negative")
 	}
-	if a.DelayMs > 120000 {
+	if a.DelayMs > MaxDelayMs {
 		return errors.New("delayMs cannot exceed 120000ms (2 minutes)")
 	}
+	if a.Latency != nil {
+		if err := a.Latency.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidateFaults(a.Faults); err != nil {
+		return err
+	}
+
+	if a.CORS != nil {
+		if err := a.CORS.Validate(); err != nil {
+			return err
+		}
+	}
+	if a.Auth != nil {
+		if err := a.Auth.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if a.Record != nil {
+		for _, field := range a.Record.IgnoreFields {
+			if !strings.HasPrefix(field, "query.") && !strings.HasPrefix(field, "body.") {
+				return fmt.Errorf("record ignoreFields must start with query. or body.: %s", field)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(a.Scenarios))
+	for _, scenario := range a.Scenarios {
+		if scenario.Name == "" {
+			return errors.New("scenario name is required")
+		}
+		if seen[scenario.Name] {
+			return fmt.Errorf("duplicate scenario name: %s", scenario.Name)
+		}
+		seen[scenario.Name] = true
+
+		if len(scenario.States) > 0 && scenario.InitialState != "" {
+			found := false
+			for _, state := range scenario.States {
+				found = found || state == scenario.InitialState
+			}
+			if !found {
+				return fmt.Errorf("scenario %s: initialState %s is not one of its states", scenario.Name, scenario.InitialState)
+			}
+		}
+	}
 	return nil
 }
 
@@ -31,9 +894,30 @@ func (a *AdvanceConfigEndpoint) Validate() error {
 	if a.DelayMs < 0 {
 		return errors.New("delayMs cannot be negative")
 	}
-	if a.DelayMs > 120000 {
+	if a.DelayMs > MaxDelayMs {
 		return errors.New("delayMs cannot exceed 120000ms (2 minutes)")
 	}
+	if a.Latency != nil {
+		if err := a.Latency.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := ValidateFaults(a.Faults); err != nil {
+		return err
+	}
+	if a.IDParam != "" && a.Collection == "" {
+		return errors.New("idParam requires a collection")
+	}
+	if a.ProxyRewrite != nil {
+		if err := a.ProxyRewrite.Validate(); err != nil {
+			return fmt.Errorf("proxyRewrite: %v", err)
+		}
+	}
+	if a.Auth != nil {
+		if err := a.Auth.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -75,7 +959,7 @@ func ParseEndpointAdvanceConfig(configJSON string) (*AdvanceConfigEndpoint, erro
 
 // ToJSON converts AdvanceConfigProject to JSON string
 func (a *AdvanceConfigProject) ToJSON() (string, error) {
-	if a.DelayMs == 0 {
+	if a.DelayMs == 0 && a.Latency == nil && len(a.Faults) == 0 && len(a.Scenarios) == 0 && a.Record == nil && a.CORS == nil && a.Auth == nil {
 		return "", nil
 	}
 
@@ -88,7 +972,7 @@ func (a *AdvanceConfigProject) ToJSON() (string, error) {
 
 // ToJSON converts AdvanceConfigEndpoint to JSON string
 func (a *AdvanceConfigEndpoint) ToJSON() (string, error) {
-	if a.DelayMs == 0 {
+	if a.DelayMs == 0 && a.Latency == nil && len(a.Faults) == 0 && a.Collection == "" && a.ProxyRewrite == nil && a.Auth == nil {
 		return "", nil
 	}
 
@@ -1,7 +1,10 @@
 package database
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -63,6 +66,149 @@ func TestAdvanceConfigProject_Validate(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "delayMs cannot exceed 120000ms")
 	})
+
+	t.Run("Valid scenarios", func(t *testing.T) {
+		config := &AdvanceConfigProject{
+			Scenarios: []ScenarioConfig{
+				{Name: "order", InitialState: "pending", States: []string{"pending", "shipped"}},
+				{Name: "login"},
+			},
+		}
+
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("Invalid scenario without name", func(t *testing.T) {
+		config := &AdvanceConfigProject{Scenarios: []ScenarioConfig{{}}}
+
+		err := config.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "scenario name is required")
+	})
+
+	t.Run("Invalid duplicate scenario names", func(t *testing.T) {
+		config := &AdvanceConfigProject{Scenarios: []ScenarioConfig{{Name: "order"}, {Name: "order"}}}
+
+		err := config.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate scenario name")
+	})
+
+	t.Run("Invalid scenario initial state", func(t *testing.T) {
+		config := &AdvanceConfigProject{
+			Scenarios: []ScenarioConfig{{Name: "order", InitialState: "lost", States: []string{"pending"}}},
+		}
+
+		err := config.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "initialState lost is not one of its states")
+	})
+
+	t.Run("Valid record config", func(t *testing.T) {
+		config := &AdvanceConfigProject{
+			Record: &RecordConfig{Enabled: true, GenerateRules: true, IgnoreFields: []string{"query.ts", "body.nonce"}},
+		}
+
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("Invalid record ignore field", func(t *testing.T) {
+		config := &AdvanceConfigProject{Record: &RecordConfig{Enabled: true, IgnoreFields: []string{"header.date"}}}
+
+		err := config.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must start with query. or body.")
+	})
+
+	t.Run("Valid cors config", func(t *testing.T) {
+		config := &AdvanceConfigProject{CORS: &CORSPolicy{
+			Enabled:          true,
+			AllowOrigins:     []string{"https://app.example.com", "http://localhost:5173", "https://*.example.com"},
+			AllowMethods:     []string{"GET", "POST"},
+			AllowHeaders:     []string{"Content-Type", "X-Api-Key"},
+			ExposeHeaders:    []string{"X-Request-Id"},
+			AllowCredentials: true,
+			MaxAgeSeconds:    600,
+		}}
+
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("Invalid cors config", func(t *testing.T) {
+		tests := []struct {
+			cors     CORSPolicy
+			expected string
+		}{
+			{CORSPolicy{AllowOrigins: []string{"app.example.com"}}, "must be \"*\" or an origin"},
+			{CORSPolicy{AllowOrigins: []string{"https://app.example.com/path"}}, "must be \"*\" or an origin"},
+			{CORSPolicy{AllowMethods: []string{"GET POST"}}, "invalid method"},
+			{CORSPolicy{AllowHeaders: []string{"X Api"}}, "invalid header name"},
+			{CORSPolicy{MaxAgeSeconds: 86401}, "maxAgeSeconds must be between 0 and 86400"},
+		}
+		for _, tt := range tests {
+			config := &AdvanceConfigProject{CORS: &tt.cors}
+
+			err := config.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		}
+	})
+
+	t.Run("Valid auth config", func(t *testing.T) {
+		configs := []AuthConfig{
+			{Type: AuthAPIKey, APIKey: &APIKeyAuth{Header: "X-API-Key", Query: "api_key", Keys: []string{"secret-key"}}},
+			{Type: AuthBasic, Basic: &BasicAuth{Users: map[string]string{"alice": "wonderland"}, Realm: "orders"}},
+			{Type: AuthJWT, JWT: &JWTAuth{Secret: "shh", Issuer: "https://auth.example.com", LeewaySeconds: 30, RequiredScopes: []string{"orders:read"}}},
+			{Type: AuthJWT, JWT: &JWTAuth{JWKS: json.RawMessage(`{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`)}},
+			{Type: AuthJWT, JWT: &JWTAuth{JWKSURL: "https://auth.example.com/.well-known/jwks.json"}, Forbidden: &AuthResponse{Status: 404, Body: `{"error":"not found"}`}},
+		}
+		for i := range configs {
+			config := &AdvanceConfigProject{Auth: &configs[i]}
+			assert.NoError(t, config.Validate(), configs[i].Type)
+		}
+
+		endpoint := &AdvanceConfigEndpoint{Auth: &AuthConfig{Type: AuthNone}}
+		assert.NoError(t, endpoint.Validate())
+	})
+
+	t.Run("Invalid auth config", func(t *testing.T) {
+		tests := []struct {
+			auth     AuthConfig
+			expected string
+		}{
+			{AuthConfig{Type: "oauth"}, "auth type must be"},
+			{AuthConfig{Type: AuthAPIKey, APIKey: &APIKeyAuth{Keys: []string{"k"}}}, "needs a header or a query parameter"},
+			{AuthConfig{Type: AuthAPIKey, APIKey: &APIKeyAuth{Header: "X-API-Key"}}, "needs at least one key"},
+			{AuthConfig{Type: AuthBasic}, "needs at least one user"},
+			{AuthConfig{Type: AuthBasic, Basic: &BasicAuth{Users: map[string]string{"a:b": "c"}}}, "invalid username"},
+			{AuthConfig{Type: AuthJWT}, "needs a secret, jwks or jwksUrl"},
+			{AuthConfig{Type: AuthJWT, JWT: &JWTAuth{Secret: "shh", JWKSURL: "https://auth.example.com/jwks"}}, "exactly one of secret, jwks or jwksUrl"},
+			{AuthConfig{Type: AuthJWT, JWT: &JWTAuth{JWKS: json.RawMessage(`{"keys":[]}`)}}, "must be a JWKS document with keys"},
+			{AuthConfig{Type: AuthJWT, JWT: &JWTAuth{JWKSURL: "ftp://auth.example.com/jwks"}}, "absolute http or https URL"},
+			{AuthConfig{Type: AuthJWT, JWT: &JWTAuth{Secret: "shh", LeewaySeconds: 3601}}, "leewaySeconds must be between 0 and 3600"},
+			{AuthConfig{Type: AuthBasic, Basic: &BasicAuth{Users: map[string]string{"a": "b"}}, Unauthorized: &AuthResponse{Status: 500}}, "must be a 4xx status"},
+		}
+		for _, tt := range tests {
+			config := &AdvanceConfigProject{Auth: &tt.auth}
+
+			err := config.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		}
+	})
+}
+
+func TestCORSPolicy_AllowsOrigin(t *testing.T) {
+	config := &CORSPolicy{AllowOrigins: []string{"https://app.example.com", "https://*.example.org"}}
+
+	assert.True(t, config.AllowsOrigin("https://app.example.com"))
+	assert.True(t, config.AllowsOrigin("HTTPS://APP.EXAMPLE.COM"))
+	assert.True(t, config.AllowsOrigin("https://a.b.example.org"))
+	assert.False(t, config.AllowsOrigin("https://example.org"))
+	assert.False(t, config.AllowsOrigin("http://app.example.com"))
+	assert.False(t, config.AllowsOrigin(""))
+
+	assert.True(t, (&CORSPolicy{}).AllowsOrigin("https://anything.test"))
 }
 
 func TestAdvanceConfigEndpoint_Validate(t *testing.T) {
@@ -271,3 +417,260 @@ func TestAdvanceConfigEndpoint_ToJSON(t *testing.T) {
 		assert.Equal(t, "", jsonStr)
 	})
 }
+
+func TestLatencyConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    LatencyConfig
+		expectErr string
+	}{
+		{name: "uniform", config: LatencyConfig{Distribution: LatencyUniform, MinMs: 10, MaxMs: 100}},
+		{name: "normal", config: LatencyConfig{Distribution: LatencyNormal, MeanMs: 100, StdDevMs: 20}},
+		{name: "lognormal", config: LatencyConfig{Distribution: LatencyLogNormal, MeanMs: 100, StdDevMs: 80}},
+		{name: "percentile", config: LatencyConfig{Distribution: LatencyPercentile, P50Ms: 100, P95Ms: 300, P99Ms: 800}},
+		{name: "unknown distribution", config: LatencyConfig{Distribution: "gamma"}, expectErr: "unknown latency distribution"},
+		{name: "uniform without max", config: LatencyConfig{Distribution: LatencyUniform, MinMs: 10}, expectErr: "requires maxMs"},
+		{name: "max below min", config: LatencyConfig{Distribution: LatencyUniform, MinMs: 100, MaxMs: 10}, expectErr: "maxMs cannot be lower than minMs"},
+		{name: "normal without mean", config: LatencyConfig{Distribution: LatencyNormal, StdDevMs: 10}, expectErr: "requires meanMs"},
+		{name: "negative stddev", config: LatencyConfig{Distribution: LatencyNormal, MeanMs: 100, StdDevMs: -1}, expectErr: "stdDevMs cannot be negative"},
+		{name: "above maximum", config: LatencyConfig{Distribution: LatencyUniform, MaxMs: 130000}, expectErr: "maxMs cannot exceed"},
+		{name: "missing percentile", config: LatencyConfig{Distribution: LatencyPercentile, P50Ms: 100}, expectErr: "requires p50Ms, p95Ms and p99Ms"},
+		{name: "unordered percentiles", config: LatencyConfig{Distribution: LatencyPercentile, P50Ms: 300, P95Ms: 200, P99Ms: 800}, expectErr: "p50Ms <= p95Ms <= p99Ms"},
+		{name: "max below p99", config: LatencyConfig{Distribution: LatencyPercentile, P50Ms: 100, P95Ms: 300, P99Ms: 800, MaxMs: 500}, expectErr: "bounds must include"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectErr)
+		})
+	}
+
+	t.Run("Invalid latency fails project and endpoint configs", func(t *testing.T) {
+		_, err := ParseProjectAdvanceConfig(`{"latency":{"distribution":"uniform"}}`)
+		assert.Error(t, err)
+		_, err = ParseEndpointAdvanceConfig(`{"latency":{"distribution":"normal"}}`)
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty latency string parses to nil", func(t *testing.T) {
+		config, err := ParseLatencyConfig("")
+		assert.NoError(t, err)
+		assert.Nil(t, config)
+	})
+}
+
+func TestValidateFaults(t *testing.T) {
+	tests := []struct {
+		name      string
+		faults    []FaultRule
+		expectErr string
+	}{
+		{name: "no faults"},
+		{name: "valid mix", faults: []FaultRule{
+			{Type: FaultError, Probability: 0.1, Status: 503},
+			{Type: FaultReset, Probability: 0.1},
+			{Type: FaultEmpty, Probability: 0.1},
+			{Type: FaultTruncate, Probability: 0.1, Bytes: 10},
+			{Type: FaultTrickle, Probability: 0.1, BytesPerSec: 100},
+			{Type: FaultMalformedJSON, Probability: 0.5},
+		}},
+		{name: "unknown type", faults: []FaultRule{{Type: "timeout", Probability: 0.1}}, expectErr: "unknown fault type"},
+		{name: "probability above one", faults: []FaultRule{{Type: FaultReset, Probability: 1.5}}, expectErr: "between 0 and 1"},
+		{name: "negative probability", faults: []FaultRule{{Type: FaultReset, Probability: -0.1}}, expectErr: "between 0 and 1"},
+		{name: "probabilities add up above one", faults: []FaultRule{{Type: FaultReset, Probability: 0.6}, {Type: FaultEmpty, Probability: 0.6}}, expectErr: "add up to more than 1"},
+		{name: "invalid error status", faults: []FaultRule{{Type: FaultError, Probability: 0.1, Status: 200}}, expectErr: "between 400 and 599"},
+		{name: "trickle without rate", faults: []FaultRule{{Type: FaultTrickle, Probability: 0.1}}, expectErr: "requires bytesPerSec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFaults(tt.faults)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectErr)
+		})
+	}
+
+	t.Run("Invalid faults fail project and endpoint configs", func(t *testing.T) {
+		_, err := ParseProjectAdvanceConfig(`{"faults":[{"type":"boom","probability":0.1}]}`)
+		assert.Error(t, err)
+		_, err = ParseEndpointAdvanceConfig(`{"faults":[{"type":"trickle","probability":0.1}]}`)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseCallbacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expectLen int
+		expectErr string
+	}{
+		{name: "empty"},
+		{name: "single", json: `[{"url":"https://example.com/hooks","body":"{}"}]`, expectLen: 1},
+		{name: "templated url", json: `[{"url":"{{.request.body.callback_url}}","method":"put"}]`, expectLen: 1},
+		{name: "with retry", json: `[{"url":"http://localhost:8080/hook","delayMs":500,"retry":{"maxAttempts":3,"backoffMs":200}}]`, expectLen: 1},
+		{name: "invalid json", json: `{"url":"https://example.com"}`, expectErr: "invalid JSON format in callbacks"},
+		{name: "missing url", json: `[{"method":"POST"}]`, expectErr: "callbacks[0]: callback url is required"},
+		{name: "relative url", json: `[{"url":"/hooks"}]`, expectErr: "absolute http or https URL"},
+		{name: "unsupported scheme", json: `[{"url":"ftp://example.com/hooks"}]`, expectErr: "absolute http or https URL"},
+		{name: "unsupported method", json: `[{"url":"https://example.com","method":"TRACE"}]`, expectErr: "unsupported callback method"},
+		{name: "negative delay", json: `[{"url":"https://example.com","delayMs":-1}]`, expectErr: "delayMs cannot be negative"},
+		{name: "delay above maximum", json: `[{"url":"https://example.com","delayMs":130000}]`, expectErr: "delayMs cannot exceed"},
+		{name: "zero attempts", json: `[{"url":"https://example.com","retry":{"maxAttempts":0}}]`, expectErr: "maxAttempts must be between 1 and 10"},
+		{name: "negative backoff", json: `[{"url":"https://example.com","retry":{"maxAttempts":2,"backoffMs":-5}}]`, expectErr: "backoff cannot be negative"},
+		{name: "too many", json: `[` + strings.Repeat(`{"url":"https://example.com"},`, MaxCallbacks) + `{"url":"https://example.com"}]`, expectErr: "more than 10 callbacks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callbacks, err := ParseCallbacks(tt.json)
+			if tt.expectErr == "" {
+				require.NoError(t, err)
+				assert.Len(t, callbacks, tt.expectLen)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectErr)
+		})
+	}
+}
+
+func TestCallbackRetry_Backoff(t *testing.T) {
+	retry := CallbackRetry{MaxAttempts: 5, BackoffMs: 100, MaxBackoffMs: 500}
+	assert.Equal(t, 100, retry.Backoff(1))
+	assert.Equal(t, 200, retry.Backoff(2))
+	assert.Equal(t, 400, retry.Backoff(3))
+	assert.Equal(t, 500, retry.Backoff(4))
+
+	defaults := CallbackRetry{MaxAttempts: 3}
+	assert.Equal(t, DefaultCallbackBackoff, defaults.Backoff(1))
+	assert.Equal(t, DefaultCallbackMaxDelay, defaults.Backoff(10))
+}
+
+func TestParseProxyPool(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expectNil bool
+		expectErr string
+	}{
+		{name: "empty", expectNil: true},
+		{name: "round robin", json: `{"strategy":"round_robin","members":[{"targetId":"a"},{"targetId":"b"}]}`},
+		{name: "weighted with failover", json: `{"strategy":"weighted","failover":true,"members":[{"targetId":"a","weight":3},{"targetId":"b"}]}`},
+		{name: "sticky", json: `{"strategy":"sticky","stickyHeader":"X-User-Id","members":[{"targetId":"a"}]}`},
+		{name: "failover", json: `{"strategy":"failover","members":[{"targetId":"a"},{"targetId":"b"}]}`},
+		{name: "invalid json", json: `[]`, expectErr: "invalid JSON format in pool"},
+		{name: "unknown strategy", json: `{"strategy":"random","members":[{"targetId":"a"}]}`, expectErr: "unknown pool strategy: random"},
+		{name: "sticky without header", json: `{"strategy":"sticky","members":[{"targetId":"a"}]}`, expectErr: "sticky pool requires stickyHeader"},
+		{name: "no members", json: `{"strategy":"round_robin","members":[]}`, expectErr: "at least one member"},
+		{name: "missing target", json: `{"strategy":"round_robin","members":[{"weight":1}]}`, expectErr: "targetId is required"},
+		{name: "duplicate member", json: `{"strategy":"round_robin","members":[{"targetId":"a"},{"targetId":"a"}]}`, expectErr: "duplicate pool member: a"},
+		{name: "negative weight", json: `{"strategy":"weighted","members":[{"targetId":"a","weight":-1}]}`, expectErr: "weight cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := ParseProxyPool(tt.json)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectNil, pool == nil)
+		})
+	}
+}
+
+func TestParseProxyTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expectErr string
+	}{
+		{name: "empty"},
+		{name: "timeouts and http2", json: `{"verifyTls":true,"serverName":"api.internal","connectTimeoutMs":2000,"readTimeoutMs":60000,"http2":true}`},
+		{name: "outbound proxy", json: `{"proxyUrl":"socks5://127.0.0.1:1080"}`},
+		{name: "invalid json", json: `[]`, expectErr: "invalid JSON format in transport"},
+		{name: "invalid ca bundle", json: `{"caBundle":"not a certificate"}`, expectErr: "caBundle contains no valid PEM certificate"},
+		{name: "cert without key", json: `{"clientCert":"-----BEGIN CERTIFICATE-----"}`, expectErr: "clientCert and clientKey must be set together"},
+		{name: "invalid key pair", json: `{"clientCert":"cert","clientKey":"key"}`, expectErr: "invalid client certificate"},
+		{name: "negative timeout", json: `{"connectTimeoutMs":-1}`, expectErr: "connectTimeoutMs cannot be negative"},
+		{name: "timeout above maximum", json: `{"readTimeoutMs":700000}`, expectErr: "readTimeoutMs cannot exceed"},
+		{name: "relative proxy url", json: `{"proxyUrl":"proxy:3128"}`, expectErr: "proxyUrl must be an absolute URL"},
+		{name: "unsupported proxy scheme", json: `{"proxyUrl":"ftp://proxy:21"}`, expectErr: "unsupported proxyUrl scheme: ftp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := ParseProxyTransport(tt.json)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, transport)
+		})
+	}
+}
+
+func TestProxyTransportConfig_Timeouts(t *testing.T) {
+	var defaults ProxyTransportConfig
+	assert.Equal(t, 10*time.Second, defaults.ConnectTimeout())
+	assert.Equal(t, 30*time.Second, defaults.ReadTimeout())
+
+	custom := ProxyTransportConfig{ConnectTimeoutMs: 500, ReadTimeoutMs: 90000}
+	assert.Equal(t, 500*time.Millisecond, custom.ConnectTimeout())
+	assert.Equal(t, 90*time.Second, custom.ReadTimeout())
+}
+
+func TestParseProxyRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expectNil bool
+		expectErr string
+	}{
+		{name: "empty", expectNil: true},
+		{name: "path rules", json: `{"path":[{"prefix":"/api","replace":"/v2"},{"regex":"^/users/(\\d+)$","replace":"/accounts/$1"}]}`},
+		{name: "headers and responses", json: `{"preserveHost":true,"requestHeaders":{"set":{"X-Env":"staging"},"add":{"X-Trace":"1"},"remove":["Cookie"]},"location":{"https://api.internal":""},"cookieDomain":{"*":""}}`},
+		{name: "invalid json", json: `[]`, expectErr: "invalid JSON format in rewrite"},
+		{name: "prefix and regex", json: `{"path":[{"prefix":"/api","regex":"^/api"}]}`, expectErr: "path[0]: exactly one of prefix or regex is required"},
+		{name: "empty path rule", json: `{"path":[{"replace":"/v2"}]}`, expectErr: "exactly one of prefix or regex"},
+		{name: "relative prefix", json: `{"path":[{"prefix":"api"}]}`, expectErr: "prefix must start with /"},
+		{name: "invalid regex", json: `{"path":[{"regex":"^/users/(\\d+"}]}`, expectErr: "path[0]: invalid regex"},
+		{name: "invalid header name", json: `{"requestHeaders":{"set":{"X Env":"staging"}}}`, expectErr: "invalid request header name"},
+		{name: "host header", json: `{"requestHeaders":{"remove":["host"]}}`, expectErr: "use preserveHost"},
+		{name: "relative location", json: `{"location":{"/api":"/"}}`, expectErr: "must be an absolute http or https URL"},
+		{name: "empty cookie domain", json: `{"cookieDomain":{"":"localhost"}}`, expectErr: "cookieDomain keys cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrite, err := ParseProxyRewrite(tt.json)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectNil, rewrite == nil)
+		})
+	}
+
+	t.Run("endpoint advance config", func(t *testing.T) {
+		_, err := ParseEndpointAdvanceConfig(`{"proxyRewrite":{"path":[{"prefix":"v1"}]}}`)
+		assert.ErrorContains(t, err, "proxyRewrite: path[0]: prefix must start with /")
+	})
+}
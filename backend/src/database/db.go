@@ -115,6 +115,9 @@ func CheckAndHandle() error {
 		&MockEndpoint{},
 		&MockResponse{},
 		&MockRule{},
+		&MockCollection{},
+		&MockFile{},
+		&ProtoFile{},
 		&RequestLog{},
 		&User{},
 		&UserIdentity{},
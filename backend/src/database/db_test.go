@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDatabaseConnection(t *testing.T) {
@@ -92,3 +95,33 @@ func TestDatabaseConnection(t *testing.T) {
 		os.Unsetenv("DATABASE_URL")
 	})
 }
+
+// TestResponseWeightMigration checks that responses saved before weights existed get a weight of 1
+func TestResponseWeightMigration(t *testing.T) {
+	SetupTestEnvironment(t)
+	db := GetDB()
+
+	// Deployments from before weighted responses have no weight column
+	require.NoError(t, db.Migrator().DropColumn(&MockResponse{}, "Weight"))
+	require.NoError(t, db.Exec("INSERT INTO mock_responses (id, endpoint_id, status_code) VALUES (?, ?, ?)", "old-response", "endpoint", 200).Error)
+
+	require.NoError(t, db.AutoMigrate(&MockResponse{}))
+
+	var old MockResponse
+	require.NoError(t, db.Where("id = ?", "old-response").First(&old).Error)
+	require.NotNil(t, old.Weight)
+	assert.Equal(t, 1, *old.Weight)
+
+	// New responses default to 1 unless a weight, 0 included, is given
+	zero := 0
+	created := &MockResponse{ID: "new-response", EndpointID: "endpoint"}
+	silent := &MockResponse{ID: "silent-response", EndpointID: "endpoint", Weight: &zero}
+	require.NoError(t, db.Create(created).Error)
+	require.NoError(t, db.Create(silent).Error)
+
+	var stored []MockResponse
+	require.NoError(t, db.Where("id IN ?", []string{"new-response", "silent-response"}).Order("id").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	assert.Equal(t, 1, *stored[0].Weight)
+	assert.Equal(t, 0, *stored[1].Weight)
+}
@@ -73,12 +73,20 @@ func (p *Project) TableName() string {
 type ProxyTarget struct {
 	ID        string    `gorm:"type:string;primaryKey" json:"id"`
 	ProjectID string    `gorm:"type:string" json:"project_id"`
-	Label     string    `json:"label"` // Example: "Staging", "Production"
-	URL       string    `json:"url"`   // Example: "https://staging.example.com"
+	Label     string    `json:"label"`                      // Example: "Staging", "Production"
+	URL       string    `json:"url"`                        // Example: "https://staging.example.com"
+	Pool      string    `gorm:"type:text" json:"pool"`      // Pool config as JSON; a pool forwards to its member targets instead of URL
+	Transport string    `gorm:"type:text" json:"transport"` // Transport config as JSON: TLS, timeouts, HTTP/2 and outbound proxy
+	Rewrite   string    `gorm:"type:text" json:"rewrite"`   // Rewrite rules as JSON: path, request headers, Host, Location and Set-Cookie
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// IsPool reports whether the target spreads requests over other targets
+func (pt *ProxyTarget) IsPool() bool {
+	return pt.Pool != ""
+}
+
 // BeforeCreate hook to generate UUID string
 func (pt *ProxyTarget) BeforeCreate(tx *gorm.DB) error {
 	if pt.ID == "" {
@@ -94,10 +102,13 @@ type MockEndpoint struct {
 	Method        string         `json:"method"`                                // GET, POST, PUT, DELETE, etc
 	Path          string         `json:"path"`                                  // Example: "/users/:id"
 	Enabled       bool           `json:"enabled" gorm:"default:true"`           // Whether endpoint is active or not
-	ResponseMode  string         `json:"response_mode" gorm:"default:'random'"` // "static", "random", "round_robin"
+	ResponseMode  string         `json:"response_mode" gorm:"default:'random'"` // "static", "random", "round_robin", "weighted", "crud"
 	Documentation string         `gorm:"type:text" json:"documentation"`        // Documentation URL or text
 	AdvanceConfig string         `gorm:"type:text" json:"advance_config"`       // Advanced configuration (e.g. timeout) as JSON string
 	Responses     []MockResponse `gorm:"foreignKey:EndpointID;constraint:OnDelete:CASCADE;" json:"responses"`
+	// GraphQL endpoints match responses on the operation and can validate it against a schema
+	Type          string `gorm:"type:string;default:'http'" json:"type"` // "http", "graphql", "websocket" or "grpc"
+	GraphQLSchema string `gorm:"type:text" json:"graphql_schema"`        // Optional SDL schema for graphql endpoints
 	// Proxy configuration for endpoint-level proxying
 	UseProxy      bool         `json:"use_proxy" gorm:"default:false"`               // Whether to use proxy for this endpoint
 	ProxyTargetID *string      `gorm:"type:string" json:"proxy_target_id"`           // ID of the proxy target to use
@@ -114,23 +125,65 @@ func (me *MockEndpoint) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Endpoint types
+const (
+	EndpointTypeHTTP      = "http"      // Plain HTTP endpoint (default)
+	EndpointTypeGraphQL   = "graphql"   // GraphQL endpoint, requests are parsed as GraphQL operations
+	EndpointTypeWebSocket = "websocket" // WebSocket endpoint, responses script the messages of the connection
+	EndpointTypeGRPC      = "grpc"      // gRPC method, the path is "/package.Service/Method" of a project proto file
+)
+
+// IsGraphQL reports whether the endpoint serves GraphQL operations
+func (me *MockEndpoint) IsGraphQL() bool {
+	return me.Type == EndpointTypeGraphQL
+}
+
+// IsWebSocket reports whether the endpoint accepts WebSocket connections
+func (me *MockEndpoint) IsWebSocket() bool {
+	return me.Type == EndpointTypeWebSocket
+}
+
+// IsGRPC reports whether the endpoint serves a gRPC method
+func (me *MockEndpoint) IsGRPC() bool {
+	return me.Type == EndpointTypeGRPC
+}
+
 // MockResponse represents possible responses from an endpoint
 type MockResponse struct {
 	ID         string     `gorm:"type:string;primaryKey" json:"id"`
 	EndpointID string     `gorm:"type:string" json:"endpoint_id"`
 	StatusCode int        `json:"status_code"`                                 // HTTP status code
 	Body       string     `gorm:"type:text" json:"body"`                       // Response body, stored as JSON
+	BodyType   string     `gorm:"type:string;default:'text'" json:"body_type"` // "text", "base64" (Body holds base64 data) or "file" (served from FileID)
+	FileID     string     `gorm:"type:string" json:"file_id"`                  // Uploaded MockFile served when BodyType = file
 	Headers    string     `gorm:"type:text" json:"headers"`                    // Headers stored as JSON
 	Priority   int        `json:"priority"`                                    // Priority if ResponseMode = static
+	Weight     *int       `gorm:"default:1" json:"weight"`                     // Relative chance if ResponseMode = weighted, 1 when not set, 0 never picks the response
 	DelayMS    int        `json:"delay_ms"`                                    // Delay before response (milliseconds)
+	Latency    string     `gorm:"type:text" json:"latency"`                    // Latency distribution as JSON, overrides DelayMS
 	Stream     bool       `json:"stream"`                                      // True if response is stream (e.g. SSE, chunked)
+	Templated  bool       `json:"templated"`                                   // Render Body and Headers as templates against the request
 	Note       string     `gorm:"type:text" json:"note"`                       // Optional note for the response
 	Enabled    bool       `json:"enabled" gorm:"default:true"`                 // Whether enabled or not
 	IsFallback bool       `json:"is_fallback" gorm:"default:false"`            // Whether this is a fallback response
 	RulesLogic string     `gorm:"type:string;default:'or'" json:"rules_logic"` // "and" or "or" for multiple rules
 	Rules      []MockRule `gorm:"foreignKey:ResponseID;constraint:OnDelete:CASCADE" json:"rules"`
-	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Scenario state machine (scenarios are defined in the project advance config)
+	Scenario      string `gorm:"type:string" json:"scenario"`       // Scenario this response takes part in
+	RequiredState string `gorm:"type:string" json:"required_state"` // Only served while the scenario is in this state
+	NewState      string `gorm:"type:string" json:"new_state"`      // State the scenario moves to after this response is served
+
+	GraphQLErrors string `gorm:"type:text" json:"graphql_errors"` // JSON array of GraphQL errors added to the body "errors" (graphql endpoints)
+	Callbacks     string `gorm:"type:text" json:"callbacks"`      // JSON array of callbacks sent after the response is served
+	GRPCStatus    string `gorm:"type:text" json:"grpc_status"`    // Status code, message and trailers ending a gRPC call as JSON (grpc endpoints)
+
+	// Record mode, set on responses created from forwarded traffic
+	RecordedRequest string `gorm:"type:text" json:"recorded_request"` // Query and body fields of the recorded request as JSON
+	RecordedHash    string `gorm:"type:string" json:"recorded_hash"`  // Digest of the response as recorded, differs once it is edited by hand
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 // BeforeCreate hook to generate UUID string
@@ -141,14 +194,92 @@ func (mr *MockResponse) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// MockCollection is a named, stateful data set served by endpoints in "crud" response mode
+// Seed is restored whenever the collection is reset; the live items are kept in memory.
+type MockCollection struct {
+	ID        string    `gorm:"type:string;primaryKey" json:"id"`
+	ProjectID string    `gorm:"type:string;uniqueIndex:idx_collection_project_name" json:"project_id"`
+	Name      string    `gorm:"type:string;uniqueIndex:idx_collection_project_name" json:"name"` // Referenced by endpoint advance_config "collection"
+	IDField   string    `gorm:"type:string;default:'id'" json:"id_field"`                        // Item field used as identifier
+	Seed      string    `gorm:"type:text" json:"seed"`                                           // Initial items as a JSON array of objects
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Association to the Project
+	Project Project `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID string
+func (mc *MockCollection) BeforeCreate(tx *gorm.DB) error {
+	if mc.ID == "" {
+		mc.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// MockFile is an uploaded file that responses can serve as their body
+// The content is stored under lib.UPLOAD_DIR, named after the file ID.
+type MockFile struct {
+	ID          string    `gorm:"type:string;primaryKey" json:"id"`
+	ProjectID   string    `gorm:"type:string;index" json:"project_id"`
+	Name        string    `gorm:"type:string" json:"name"`         // Original file name
+	ContentType string    `gorm:"type:string" json:"content_type"` // Served as Content-Type unless the response sets one
+	Size        int64     `json:"size"`                            // Size in bytes
+	SHA256      string    `gorm:"type:string" json:"sha256"`       // Hex digest of the content
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Association to the Project
+	Project Project `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID string
+func (mf *MockFile) BeforeCreate(tx *gorm.DB) error {
+	if mf.ID == "" {
+		mf.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Proto file kinds
+const (
+	ProtoFileSource        = "proto"          // .proto source file
+	ProtoFileDescriptorSet = "descriptor_set" // Serialized FileDescriptorSet, e.g. from protoc --descriptor_set_out
+)
+
+// ProtoFile is an uploaded protobuf definition describing the gRPC services of a project
+// All the files of a project are compiled together, so they can import each other by name.
+type ProtoFile struct {
+	ID        string    `gorm:"type:string;primaryKey" json:"id"`
+	ProjectID string    `gorm:"type:string;uniqueIndex:idx_proto_project_name" json:"project_id"`
+	Name      string    `gorm:"type:string;uniqueIndex:idx_proto_project_name" json:"name"` // Import path of a source, e.g. "shop/catalog.proto"
+	Kind      string    `gorm:"type:string" json:"kind"`                                    // "proto" or "descriptor_set"
+	Content   []byte    `json:"-"`                                                          // Source text or serialized descriptor set
+	Size      int64     `json:"size"`                                                       // Size in bytes
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Association to the Project
+	Project Project `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID string
+func (pf *ProtoFile) BeforeCreate(tx *gorm.DB) error {
+	if pf.ID == "" {
+		pf.ID = uuid.New().String()
+	}
+	return nil
+}
+
 // MockRule represents filter rules for selecting responses
 type MockRule struct {
 	ID         string `gorm:"type:string;primaryKey" json:"id"`
 	ResponseID string `gorm:"type:string" json:"response_id"`
-	Type       string `json:"type"`     // "header", "body", "query", "path"
+	Type       string `json:"type"`     // "header", "body", "query", "path", "graphql", "jwt"
 	Key        string `json:"key"`      // Example: "X-Auth", "q", "user.id"
-	Operator   string `json:"operator"` // "equals", "contains", "regex"
+	Operator   string `json:"operator"` // "equals", "not_equals", "contains", "regex", "gt", "between", "in", "exists", ... ("_ci" suffix for case-insensitive)
 	Value      string `json:"value"`
+	Match      string `json:"match"` // When a body key selects several values: "any" (default) or "all" must match
 }
 
 // BeforeCreate hook to generate UUID string
@@ -163,28 +294,40 @@ func (mr *MockRule) BeforeCreate(tx *gorm.DB) error {
 type SourceRequest string
 
 const (
-	RequestSourceUnknown SourceRequest = ""
-	RequestSourceEcho    SourceRequest = "echo"
-	RequestSourceReplay  SourceRequest = "replay"
+	RequestSourceUnknown  SourceRequest = ""
+	RequestSourceEcho     SourceRequest = "echo"
+	RequestSourceReplay   SourceRequest = "replay"
+	RequestSourceCallback SourceRequest = "callback" // Request sent by a mock response callback
 )
 
 // RequestLog stores detailed information about each incoming HTTP request.
 // It captures how the request was handled (mock, proxy, forwarder), whether it matched a mock endpoint,
 // and includes raw request/response data for auditing or debugging.
 type RequestLog struct {
-	ID              string `gorm:"type:string;primaryKey" json:"id"`    // Unique identifier (UUID)
-	ProjectID       string `gorm:"type:string;index" json:"project_id"` // Foreign key to the associated project
-	Method          string `json:"method"`                              // HTTP method (GET, POST, etc.)
-	Path            string `json:"path"`                                // Request path (e.g. "/api/users")
-	QueryParams     string `gorm:"type:text" json:"query_params"`       // Query parameters (stored as JSON string)
-	RequestHeaders  string `gorm:"type:text" json:"request_headers"`    // Request headers as array of key-value pairs
-	RequestBody     string `gorm:"type:text" json:"request_body"`       // Raw request body
-	ResponseStatus  int    `json:"response_status"`                     // HTTP status code returned
-	ResponseBody    string `gorm:"type:text" json:"response_body"`      // Raw response body
-	ResponseHeaders string `gorm:"type:text" json:"response_headers"`   // Response headers as array of key-value pairs
-	LatencyMS       int    `json:"latency_ms"`                          // Time taken to respond or delay applied (in milliseconds)
-	Bookmark        bool   `gorm:"type:bool" json:"bookmark"`           // Optional bookmark for easy reference
-	LogsHash        string `gorm:"type:string" json:"logs_hash"`        // Hash of the response body for integrity checks + jwt signature
+	ID                string `gorm:"type:string;primaryKey" json:"id"`     // Unique identifier (UUID)
+	ProjectID         string `gorm:"type:string;index" json:"project_id"`  // Foreign key to the associated project
+	Method            string `json:"method"`                               // HTTP method (GET, POST, etc.)
+	Path              string `json:"path"`                                 // Request path (e.g. "/api/users")
+	QueryParams       string `gorm:"type:text" json:"query_params"`        // Query parameters (stored as JSON string)
+	RequestHeaders    string `gorm:"type:text" json:"request_headers"`     // Request headers as array of key-value pairs
+	RequestBody       string `gorm:"type:text" json:"request_body"`        // Raw request body
+	RequestBodyParsed string `gorm:"type:text" json:"request_body_parsed"` // Structured view of XML, form and multipart bodies (stored as JSON string)
+	ResponseStatus    int    `json:"response_status"`                      // HTTP status code returned
+	ResponseBody      string `gorm:"type:text" json:"response_body"`       // Raw response body
+	ResponseHeaders   string `gorm:"type:text" json:"response_headers"`    // Response headers as array of key-value pairs
+	LatencyMS         int    `json:"latency_ms"`                           // Time taken to respond or delay applied (in milliseconds)
+	Bookmark          bool   `gorm:"type:bool" json:"bookmark"`            // Optional bookmark for easy reference
+	LogsHash          string `gorm:"type:string" json:"logs_hash"`         // Hash of the response body for integrity checks + jwt signature
+	PathParams        string `gorm:"type:text" json:"path_params"`         // Values captured from the matched endpoint path (stored as JSON string)
+	ScenarioStates    string `gorm:"type:text" json:"scenario_states"`     // Scenario states seen by the request and their transitions (stored as JSON string)
+	Fault             string `gorm:"type:string" json:"fault"`             // Fault injected into the response (e.g. "reset", "trickle"), empty when none
+	GraphQLOperation  string `gorm:"type:string" json:"graphql_operation"` // GraphQL operation of the request (e.g. "query GetUser"), empty for other requests
+	ProxyTarget       string `gorm:"type:string" json:"proxy_target"`      // Label of the proxy target that served the request, empty for mocks
+	ProxyTargetURL    string `gorm:"type:string" json:"proxy_target_url"`  // URL of the proxy target that served the request
+	WebSocketFrames   string `gorm:"type:text" json:"websocket_frames"`    // Frames of a WebSocket session in both directions (stored as JSON string)
+	GRPCMethod        string `gorm:"type:string" json:"grpc_method"`       // gRPC method of the call (e.g. "/shop.Catalog/GetItem"), empty for other requests
+	GRPCStatus        string `gorm:"type:string" json:"grpc_status"`       // gRPC status the call ended with (e.g. "NOT_FOUND")
+	Auth              string `gorm:"type:text" json:"auth"`                // Simulated authentication decision: type, result, subject and reason (stored as JSON string)
 
 	Source SourceRequest `gorm:"size:50;not null default:''" json:"source"` // Source of the request: "replay", "echo", etc.
 
@@ -462,8 +605,8 @@ func (a *Action) BeforeCreate(tx *gorm.DB) error {
 type ActionFilter struct {
 	ID        string    `gorm:"type:string;primaryKey" json:"id"`
 	ActionID  string    `gorm:"type:string;index;not null" json:"action_id"` // Foreign key to the associated action
-	Type      string    `gorm:"type:string;not null" json:"type"`            // Filter type: "method", "path", "header", "query", "status_code"
-	Key       string    `gorm:"type:string" json:"key"`                      // Key for header/query filters (e.g., "Content-Type", "user_id")
+	Type      string    `gorm:"type:string;not null" json:"type"`            // Filter type: "method", "path", "header", "query", "status_code", "body"
+	Key       string    `gorm:"type:string" json:"key"`                      // Key for header/query filters (e.g., "Content-Type", "user_id"), JSONPath for body filters
 	Operator  string    `gorm:"type:string;not null" json:"operator"`        // Comparison operator: "equals", "contains", "regex", "starts_with", "ends_with"
 	Value     string    `gorm:"type:string" json:"value"`                    // Value to compare against
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
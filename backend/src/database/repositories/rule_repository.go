@@ -41,6 +41,7 @@ func (r *ruleRepository) FindRuleByID(ruleID string) (*database.MockRule, error)
 }
 
 // CreateRule creates a new rule
+// Rule data is validated by the rule service (services.ValidateRule) before it is saved.
 func (r *ruleRepository) CreateRule(rule *database.MockRule) error {
 	// Check if response exists
 	var response database.MockResponse
@@ -48,35 +49,12 @@ func (r *ruleRepository) CreateRule(rule *database.MockRule) error {
 		return fmt.Errorf("response not found: %w", result.Error)
 	}
 
-	// Validate rule type
-	switch rule.Type {
-	case "header", "query", "body":
-		// Valid types
-	default:
-		return fmt.Errorf("invalid rule type: %s, must be header, query, or body", rule.Type)
-	}
-
-	// Validate operator
-	switch rule.Operator {
-	case "equals", "contains", "regex", "has_property", "matches_type", "matches_schema":
-		// Valid operators
-	default:
-		return fmt.Errorf("invalid operator: %s, must be equals, contains, regex, has_property, matches_type, or matches_schema", rule.Operator)
-	}
-
 	// Create rule
 	return r.db.Create(rule).Error
 }
 
 // UpdateRule updates an existing rule
 func (r *ruleRepository) UpdateRule(rule *database.MockRule) error {
-	// Validate operator
-	switch rule.Operator {
-	case "equals", "contains", "regex", "has_property", "matches_type", "matches_schema":
-		// Valid operators
-	default:
-		return fmt.Errorf("invalid operator: %s, must be equals, contains, regex, has_property, matches_type, or matches_schema", rule.Operator)
-	}
 	return r.db.Save(rule).Error
 }
 
@@ -0,0 +1,225 @@
+// Package graphql parses GraphQL requests and schemas for mock endpoints.
+//
+// It covers what a mock server needs: reading the operation a client sent (its type,
+// name, root fields and variables), parsing an SDL schema, validating operations against
+// the schema and checking that a mocked result fits the operation's selection set.
+// Nothing is executed.
+package graphql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Operation types
+const (
+	OperationQuery        = "query"
+	OperationMutation     = "mutation"
+	OperationSubscription = "subscription"
+)
+
+// Location is a line and column in a GraphQL source, both 1-based
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a GraphQL error as returned in the "errors" array of a response
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Document is a parsed executable document: operations and fragments
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// Operation is a query, mutation or subscription in a document
+type Operation struct {
+	Type         string // query, mutation or subscription
+	Name         string // Empty for anonymous operations
+	Variables    []*VariableDefinition
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+// VariableDefinition declares an operation variable
+type VariableDefinition struct {
+	Name       string
+	Type       *TypeRef
+	HasDefault bool
+	Loc        Location
+}
+
+// Fragment is a named fragment definition
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+// Selection is a *Field, *FragmentSpread or *InlineFragment
+type Selection interface {
+	location() Location
+}
+
+// Field selects a field, optionally under an alias
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Loc          Location
+}
+
+// ResponseKey is the key the field has in the result: its alias, or its name
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread includes a named fragment
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Loc        Location
+}
+
+// InlineFragment selects fields for a type condition, or for the parent type when it has none
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+func (f *Field) location() Location          { return f.Loc }
+func (f *FragmentSpread) location() Location { return f.Loc }
+func (f *InlineFragment) location() Location { return f.Loc }
+
+// Argument is a named argument of a field or directive
+type Argument struct {
+	Name  string
+	Value Value
+	Loc   Location
+}
+
+// Directive is a directive such as @include(if: $flag)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+// Value is an argument value: a Variable, an EnumValue, a string, int64, float64, bool or nil
+// literal, a []Value list or a map[string]Value input object.
+type Value interface{}
+
+// Variable references an operation variable in a value
+type Variable struct {
+	Name string
+}
+
+// EnumValue is an unquoted enum literal
+type EnumValue string
+
+// TypeRef is a type reference such as String, [ID!] or User!
+type TypeRef struct {
+	Name    string   // Named type, empty for lists
+	Elem    *TypeRef // Item type of a list
+	NonNull bool
+}
+
+// NamedType returns the named type at the core of the reference
+func (t *TypeRef) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+func (t *TypeRef) String() string {
+	var s string
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	} else {
+		s = t.Name
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Operation returns the operation to execute: the one named name, or the only operation
+// of the document when name is empty
+func (d *Document) Operation(name string) (*Operation, error) {
+	if name == "" {
+		if len(d.Operations) != 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return d.Operations[0], nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
+
+// RootFields returns the names of the fields the operation selects at its root,
+// including those selected through fragments, without duplicates
+func (d *Document) RootFields(op *Operation) []string {
+	var names []string
+	seen := map[string]bool{}
+	visited := map[string]bool{}
+	var collect func(selections []Selection)
+	collect = func(selections []Selection) {
+		for _, selection := range selections {
+			switch s := selection.(type) {
+			case *Field:
+				if !seen[s.Name] {
+					seen[s.Name] = true
+					names = append(names, s.Name)
+				}
+			case *InlineFragment:
+				collect(s.SelectionSet)
+			case *FragmentSpread:
+				if fragment, ok := d.Fragments[s.Name]; ok && !visited[s.Name] {
+					visited[s.Name] = true
+					collect(fragment.SelectionSet)
+				}
+			}
+		}
+	}
+	collect(op.SelectionSet)
+	return names
+}
+
+// Describe returns a short label for an operation such as "query GetUser",
+// or "query { user }" for anonymous operations
+func (d *Document) Describe(op *Operation) string {
+	if op.Name != "" {
+		return op.Type + " " + op.Name
+	}
+	fields := d.RootFields(op)
+	if len(fields) == 0 {
+		return op.Type
+	}
+	return op.Type + " { " + strings.Join(fields, " ") + " }"
+}
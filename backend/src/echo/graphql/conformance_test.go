@@ -0,0 +1,306 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Conformance cases follow the grammar and examples of the GraphQL specification
+// (October 2021): the lexical rules of section 2.1, executable documents, values and
+// block strings, and the type system definitions of section 3.
+
+// argumentValue parses a query selecting a single field and returns the value of its "v" argument
+func argumentValue(t *testing.T, literal string) Value {
+	t.Helper()
+	doc, err := Parse(`{ f(v: ` + literal + `) }`)
+	require.NoError(t, err, literal)
+	return doc.Operations[0].SelectionSet[0].(*Field).Arguments[0].Value
+}
+
+func TestConformance_Values(t *testing.T) {
+	tests := []struct {
+		literal  string
+		expected Value
+	}{
+		// Int and Float (2.9.1, 2.9.2)
+		{literal: `0`, expected: int64(0)},
+		{literal: `-0`, expected: int64(0)},
+		{literal: `123`, expected: int64(123)},
+		{literal: `-42`, expected: int64(-42)},
+		{literal: `1.5`, expected: 1.5},
+		{literal: `-0.25`, expected: -0.25},
+		{literal: `1e3`, expected: 1000.0},
+		{literal: `6.0221413e23`, expected: 6.0221413e23},
+		{literal: `1.5E-2`, expected: 0.015},
+		{literal: `2e+2`, expected: 200.0},
+
+		// Boolean, null and enum (2.9.3, 2.9.5, 2.9.6)
+		{literal: `true`, expected: true},
+		{literal: `false`, expected: false},
+		{literal: `null`, expected: nil},
+		{literal: `MOBILE_WEB`, expected: EnumValue("MOBILE_WEB")},
+
+		// Strings and escapes (2.9.4)
+		{literal: `""`, expected: ""},
+		{literal: `"Hello, world"`, expected: "Hello, world"},
+		{literal: `"quote \" backslash \\ slash \/"`, expected: `quote " backslash \ slash /`},
+		{literal: `"\b\f\n\r\t"`, expected: "\b\f\n\r\t"},
+		{literal: `"Aé中"`, expected: "Aé中"},
+		{literal: `"unicode 😀 kept"`, expected: "unicode 😀 kept"},
+
+		// Lists and input objects (2.9.7, 2.9.8)
+		{literal: `[]`, expected: []Value{}},
+		{literal: `[1, "two", THREE, [4]]`, expected: []Value{int64(1), "two", EnumValue("THREE"), []Value{int64(4)}}},
+		{literal: `{}`, expected: map[string]Value{}},
+		{literal: `{lon: 12.43, lat: -53.211}`, expected: map[string]Value{"lon": 12.43, "lat": -53.211}},
+		{literal: `{nested: {list: [$var]}}`, expected: map[string]Value{"nested": map[string]Value{"list": []Value{Variable{Name: "var"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			assert.Equal(t, tt.expected, argumentValue(t, tt.literal))
+		})
+	}
+}
+
+func TestConformance_BlockStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		literal  string
+		expected string
+	}{
+		{
+			name: "common indentation and blank lines are removed",
+			literal: `"""
+
+    Hello,
+      World!
+
+    Yours,
+      GraphQL.
+  """`,
+			expected: "Hello,\n  World!\n\nYours,\n  GraphQL.",
+		},
+		{name: "first line keeps its indentation", literal: `"""  first
+    second"""`, expected: "  first\nsecond"},
+		{name: "escaped triple quote", literal: `"""contains \""" inside"""`, expected: `contains """ inside`},
+		{name: "escapes are not interpreted", literal: `"""raw \n A"""`, expected: `raw \n A`},
+		{name: "carriage return line breaks", literal: "\"\"\"\r\n    a\r\n    b\r\n\"\"\"", expected: "a\nb"},
+		{name: "empty", literal: `""""""`, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, argumentValue(t, tt.literal))
+		})
+	}
+}
+
+func TestConformance_Documents(t *testing.T) {
+	t.Run("Ignored tokens", func(t *testing.T) {
+		// Unicode BOM, commas, tabs, CRLF line terminators and comments are insignificant
+		doc, err := Parse("\ufeff# comment\r\n{\tuser(id: 4,,) , { id, name } # trailing\n}")
+		require.NoError(t, err)
+		field := doc.Operations[0].SelectionSet[0].(*Field)
+		assert.Equal(t, "user", field.Name)
+		assert.Len(t, field.SelectionSet, 2)
+		assert.Equal(t, Location{Line: 2, Column: 3}, field.Loc)
+	})
+
+	t.Run("Keywords are valid names", func(t *testing.T) {
+		doc, err := Parse(`query query { fragment: on { query mutation subscription type true null } }`)
+		require.NoError(t, err)
+		op := doc.Operations[0]
+		assert.Equal(t, "query", op.Name)
+		field := op.SelectionSet[0].(*Field)
+		assert.Equal(t, "fragment", field.Alias)
+		assert.Equal(t, "on", field.Name)
+		assert.Len(t, field.SelectionSet, 6)
+	})
+
+	t.Run("Operations, variables and directives", func(t *testing.T) {
+		doc, err := Parse(`
+			query Feed($first: Int = 10, $ids: [ID!]! = ["a"], $filter: Filter = {tags: [], open: true}, $flag: Boolean @deprecated) @live {
+				feed(first: $first) @include(if: $flag) { id }
+			}
+			mutation Like { like(storyID: 12345) { story { likeCount } } }
+			subscription OnLike { liked { id } }
+		`)
+		require.NoError(t, err)
+		require.Len(t, doc.Operations, 3)
+
+		feed := doc.Operations[0]
+		assert.Equal(t, OperationQuery, feed.Type)
+		require.Len(t, feed.Variables, 4)
+		assert.Equal(t, "[ID!]!", feed.Variables[1].Type.String())
+		assert.Equal(t, "ID", feed.Variables[1].Type.NamedType())
+		for _, variable := range feed.Variables[:3] {
+			assert.True(t, variable.HasDefault, variable.Name)
+		}
+		assert.False(t, feed.Variables[3].HasDefault)
+		assert.Equal(t, "live", feed.Directives[0].Name)
+		assert.Equal(t, "include", feed.SelectionSet[0].(*Field).Directives[0].Name)
+
+		assert.Equal(t, OperationMutation, doc.Operations[1].Type)
+		assert.Equal(t, "Like", doc.Operations[1].Name)
+		assert.Equal(t, OperationSubscription, doc.Operations[2].Type)
+	})
+
+	t.Run("Lone anonymous operation", func(t *testing.T) {
+		_, err := Parse(`{ a } mutation { b }`)
+		assert.Error(t, err)
+	})
+
+	t.Run("Fragments", func(t *testing.T) {
+		doc, err := Parse(`
+			query withNestedFragments { user(id: 4) { friends(first: 10) { ...friendFields } } }
+			fragment friendFields on User @cached { id ...standardProfilePic }
+			fragment standardProfilePic on User { profilePic(size: 50) }
+			query inlineFragmentNoType($expandedInfo: Boolean) {
+				user(handle: "zuck") {
+					... @include(if: $expandedInfo) { firstName }
+					... on Page { likers { count } }
+				}
+			}
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, "User", doc.Fragments["friendFields"].TypeCondition)
+		assert.Equal(t, "cached", doc.Fragments["friendFields"].Directives[0].Name)
+
+		op, err := doc.Operation("inlineFragmentNoType")
+		require.NoError(t, err)
+		selections := op.SelectionSet[0].(*Field).SelectionSet
+		untyped := selections[0].(*InlineFragment)
+		assert.Empty(t, untyped.TypeCondition)
+		assert.Equal(t, "include", untyped.Directives[0].Name)
+		assert.Equal(t, "Page", selections[1].(*InlineFragment).TypeCondition)
+	})
+}
+
+func TestConformance_SyntaxErrors(t *testing.T) {
+	for _, query := range []string{
+		`{ f(v: 00) }`,
+		`{ f(v: 01) }`,
+		`{ f(v: -) }`,
+		`{ f(v: 1.) }`,
+		`{ f(v: .5) }`,
+		`{ f(v: 1e) }`,
+		`{ f(v: 1x) }`,
+		`{ f(v: 0x1F) }`,
+		`{ f(v: "\x") }`,
+		`{ f(v: "\u00G1") }`,
+		"{ f(v: \"line\nbreak\") }",
+		`{ f(v: """unterminated) }`,
+		`{ f(v: $) }`,
+		`{ f(v: {a}) }`,
+		`{ f(v: [1, 2) }`,
+		`{ f(: 1) }`,
+		`{ alias: }`,
+		`{ ...on }`,
+		`fragment on on User { id } { a }`,
+		`query Q($a Int) { a }`,
+		`query Q($a: [Int) { a }`,
+		`query Q($a: Int = $b) { a }`,
+		`{ a @ }`,
+		`{ a } garbage`,
+		`query`,
+		`{}`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := Parse(query)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConformance_TypeSystem(t *testing.T) {
+	schema, err := ParseSchema(`
+		"""
+		Schema description
+		"""
+		schema @link(url: "https://example.com") { query: Root mutation: Mutations }
+
+		"A scalar with a spec"
+		scalar DateTime @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+		interface Node { id: ID! }
+		interface Resource implements Node { id: ID! url: String }
+		type Image implements & Resource & Node @key(fields: "id") {
+			id: ID!
+			url: String
+			"Width in pixels"
+			width(
+				"Scale factor"
+				scale: Float = 1.0
+			): Int
+			taken: DateTime
+		}
+		type Root { image(id: ID!): Image search(in: Filter = {kinds: [PHOTO], size: {min: 1}}): [Media] }
+		type Mutations { upload(images: [ImageInput!]!): [Image!]! }
+		union Media = | Image | Video
+		type Video { id: ID! }
+		type Audio { id: ID! }
+		enum Kind { PHOTO "Moving pictures" VIDEO @deprecated }
+		input Filter { kinds: [Kind!] = [] size: Range }
+		input Range { min: Int max: Int }
+		input ImageInput { url: String! }
+		directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+		directive @link(url: String) on | SCHEMA
+
+		extend schema @link(url: "https://example.com/v2")
+		extend scalar DateTime @link(url: "x")
+		extend type Image { tags: [String!] }
+		extend interface Node @key(fields: "id")
+		extend union Media = Audio
+		extend type Later { b: Int }
+		type Later { a: Int }
+		extend enum Kind { AUDIO }
+		extend input Filter { q: String }
+	`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Root", schema.RootType(OperationQuery).Name)
+	assert.Equal(t, "Mutations", schema.RootType(OperationMutation).Name)
+	assert.Nil(t, schema.RootType(OperationSubscription))
+
+	image := schema.Types["Image"]
+	assert.ElementsMatch(t, []string{"Resource", "Node"}, image.Interfaces)
+	assert.True(t, image.Fields["width"].Args["scale"].HasDefault)
+	assert.NotNil(t, image.Fields["tags"], "type extensions add fields")
+	assert.Equal(t, []string{"Node"}, schema.Types["Resource"].Interfaces)
+	assert.True(t, schema.IsPossibleType(schema.Types["Node"], "Image"))
+
+	assert.Equal(t, KindScalar, schema.Types["DateTime"].Kind)
+	assert.Equal(t, []string{"Image", "Video", "Audio"}, schema.Types["Media"].PossibleTypes)
+	assert.Len(t, schema.Types["Later"].Fields, 2, "extensions may precede their type")
+	assert.Equal(t, map[string]bool{"PHOTO": true, "VIDEO": true, "AUDIO": true}, schema.Types["Kind"].EnumValues)
+	assert.NotNil(t, schema.Types["Filter"].InputFields["q"], "input extensions add fields")
+	assert.Equal(t, "[Media]", schema.Types["Root"].Fields["search"].Type.String())
+
+	for _, sdl := range []string{
+		`type Query { a: Int`,
+		`type Query { a(b: Int = ): Int }`,
+		`type Query implements { a: Int }`,
+		`union U = `,
+		`enum E { true }`,
+		`enum E { null }`,
+		`input I { a: Int b }`,
+		`directive @d on UNKNOWN_LOCATION type Query { a: Int }`,
+		`directive d on FIELD type Query { a: Int }`,
+		`extend type Missing { a: Int } type Query { a: Int }`,
+		`type Query { a: Int } type Query { b: Int }`,
+		`type Query { a: Int } extend input Query { b: Int }`,
+		`extend type T { a: Int } input T { b: Int } type Query { a: Int }`,
+		`type A { a: Int } union U = A | A type Query { a: Int }`,
+		`type A { a: Int } union U = A extend union U = A type Query { a: Int }`,
+		`schema { query: Missing }`,
+		`type Query { a: Int } garbage`,
+	} {
+		t.Run(sdl, func(t *testing.T) {
+			_, err := ParseSchema(sdl)
+			assert.Error(t, err)
+		})
+	}
+}
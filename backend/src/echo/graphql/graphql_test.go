@@ -0,0 +1,363 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+"""The root query"""
+type Query {
+	user(id: ID!): User
+	users(first: Int = 10, role: Role): [User!]!
+	search(text: String!): [SearchResult!]!
+	node(id: ID!): Node
+}
+
+type Mutation {
+	createUser(input: CreateUserInput!): User!
+}
+
+interface Node { id: ID! }
+
+type User implements Node {
+	id: ID!
+	name: String!
+	age: Int
+	role: Role
+	friends: [User!]
+}
+
+type Post implements Node {
+	id: ID!
+	title: String @deprecated(reason: "use heading")
+}
+
+union SearchResult = User | Post
+
+enum Role { ADMIN MEMBER }
+
+input CreateUserInput {
+	name: String!
+	role: Role = MEMBER
+}
+
+directive @auth(requires: Role = ADMIN) on FIELD_DEFINITION | OBJECT
+
+extend type Query {
+	me: User
+}
+`
+
+func mustSchema(t *testing.T) *Schema {
+	t.Helper()
+	schema, err := ParseSchema(testSchema)
+	require.NoError(t, err)
+	return schema
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# Fetch a user and their friends
+		query GetUser($id: ID!, $withFriends: Boolean = false) @cached {
+			account: user(id: $id) {
+				...UserFields
+				friends @include(if: $withFriends) { name }
+				... on User { age }
+			}
+		}
+		mutation CreateUser { createUser(input: {name: "Jane", role: ADMIN, tags: ["a", "b"]}) { id } }
+		fragment UserFields on User { id name }
+	`)
+	require.NoError(t, err)
+	require.Len(t, doc.Operations, 2)
+
+	op, err := doc.Operation("GetUser")
+	require.NoError(t, err)
+	assert.Equal(t, OperationQuery, op.Type)
+	require.Len(t, op.Variables, 2)
+	assert.Equal(t, "ID!", op.Variables[0].Type.String())
+	assert.True(t, op.Variables[1].HasDefault)
+	assert.Equal(t, []string{"user"}, doc.RootFields(op))
+	assert.Equal(t, "query GetUser", doc.Describe(op))
+
+	field := op.SelectionSet[0].(*Field)
+	assert.Equal(t, "account", field.ResponseKey())
+	assert.Equal(t, Variable{Name: "id"}, field.Arguments[0].Value)
+	assert.Equal(t, Location{Line: 4, Column: 4}, field.Loc)
+
+	mutation, err := doc.Operation("CreateUser")
+	require.NoError(t, err)
+	input := mutation.SelectionSet[0].(*Field).Arguments[0].Value.(map[string]Value)
+	assert.Equal(t, "Jane", input["name"])
+	assert.Equal(t, EnumValue("ADMIN"), input["role"])
+	assert.Equal(t, []Value{"a", "b"}, input["tags"])
+
+	_, err = doc.Operation("")
+	assert.EqualError(t, err, "Must provide operation name if query contains multiple operations.")
+	_, err = doc.Operation("Missing")
+	assert.Error(t, err)
+
+	anonymous, err := Parse(`{ me { id } users { id } }`)
+	require.NoError(t, err)
+	assert.Equal(t, "query { me users }", anonymous.Describe(anonymous.Operations[0]))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: `{ user(id: 1) { id }`, expected: "Syntax Error: Expected Name, found <EOF>."},
+		{query: `query { }`, expected: `Syntax Error: Expected Name, found "}".`},
+		{query: `query Q($id: ) { a }`, expected: `Syntax Error: Expected Name, found ")".`},
+		{query: `{ a(x: "unterminated) }`, expected: "Syntax Error: Unterminated string."},
+		{query: `{ a } { b }`, expected: "This anonymous operation must be the only defined operation."},
+		{query: `query A { a } query A { b }`, expected: `There can be only one operation named "A".`},
+		{query: `fragment F on User { id }`, expected: "Syntax Error: The document does not contain an operation."},
+		{query: `{ a(x: 1.) }`, expected: `Syntax Error: Invalid number, expected digit after ".".`},
+		{query: `{ a ? }`, expected: `Syntax Error: Unexpected character '?'.`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			require.Error(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+			var gqlErr *Error
+			assert.ErrorAs(t, err, &gqlErr)
+		})
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	schema := mustSchema(t)
+
+	assert.Equal(t, KindObject, schema.Types["User"].Kind)
+	assert.Equal(t, "[User!]", schema.Types["User"].Fields["friends"].Type.String())
+	assert.True(t, schema.Types["Query"].Fields["users"].Args["first"].HasDefault)
+	assert.NotNil(t, schema.Types["Query"].Fields["me"], "extensions are merged")
+	assert.Equal(t, []string{"User", "Post"}, schema.Types["SearchResult"].PossibleTypes)
+	assert.True(t, schema.Types["Role"].EnumValues["ADMIN"])
+	assert.NotNil(t, schema.RootType(OperationMutation))
+	assert.Nil(t, schema.RootType(OperationSubscription))
+	assert.True(t, schema.IsPossibleType(schema.Types["Node"], "Post"))
+	assert.False(t, schema.IsPossibleType(schema.Types["SearchResult"], "Query"))
+
+	custom, err := ParseSchema(`schema { query: Root } type Root { ok: Boolean }`)
+	require.NoError(t, err)
+	assert.Equal(t, "Root", custom.RootType(OperationQuery).Name)
+
+	for _, sdl := range []string{
+		`type Query { user: Missing }`,
+		`type User { id: ID }`,
+		`type Query { a: Int } type Query { b: Int }`,
+		`type Query { a: Int } extend enum Query { B }`,
+		`type Query { a(: Int) }`,
+	} {
+		_, err := ParseSchema(sdl)
+		assert.Error(t, err, sdl)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	t.Run("POST JSON", func(t *testing.T) {
+		body := `{"query":"query A { me { id } } query B { users { id } }","operationName":"B","variables":{"first":2}}`
+		request, err := ParseRequest(http.MethodPost, url.Values{}, "application/json", []byte(body))
+		require.NoError(t, err)
+		assert.Equal(t, "B", request.Name())
+		assert.Equal(t, OperationQuery, request.Type())
+		assert.Equal(t, []string{"users"}, request.RootFields())
+		assert.Equal(t, float64(2), request.Variables["first"])
+	})
+
+	t.Run("POST application/graphql", func(t *testing.T) {
+		request, err := ParseRequest(http.MethodPost, url.Values{}, "application/graphql", []byte(`mutation M { createUser(input: {name: "x"}) { id } }`))
+		require.NoError(t, err)
+		assert.Equal(t, "mutation M", request.Describe())
+		assert.NotNil(t, request.Variables)
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		query := url.Values{"query": {`query Me { me { id } }`}, "variables": {`{"a":1}`}}
+		request, err := ParseRequest(http.MethodGet, query, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Me", request.Name())
+		assert.Equal(t, float64(1), request.Variables["a"])
+
+		_, err = ParseRequest(http.MethodGet, url.Values{"query": {`mutation { createUser { id } }`}}, "", nil)
+		assert.EqualError(t, err, "Can only perform a mutation operation from a POST request.")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			body     string
+			expected string
+		}{
+			{name: "empty", body: ``, expected: "Must provide query string."},
+			{name: "invalid JSON", body: `{"query":`, expected: "POST body sent invalid JSON."},
+			{name: "batch", body: `[{"query":"{ me { id } }"}]`, expected: "Batched GraphQL requests are not supported."},
+			{name: "persisted query", body: `{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"}}}`, expected: "PersistedQueryNotFound"},
+			{name: "syntax", body: `{"query":"{ me { id }"}`, expected: "Syntax Error: Expected Name, found <EOF>."},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ParseRequest(http.MethodPost, url.Values{}, "application/json", []byte(tt.body))
+				assert.EqualError(t, err, tt.expected)
+			})
+		}
+	})
+}
+
+func errorMessages(errs []*Error) []string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+	return messages
+}
+
+func TestValidate(t *testing.T) {
+	schema := mustSchema(t)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		expected  []string
+	}{
+		{
+			name:      "valid",
+			query:     `query Q($id: ID!) { user(id: $id) { ...F friends { name } } search(text: "x") { __typename ... on Post { title } } } fragment F on User { id name }`,
+			variables: map[string]interface{}{"id": "1"},
+		},
+		{name: "introspection", query: `{ __schema { types { name } } __typename }`},
+		{name: "unknown field", query: `{ me { email } }`, expected: []string{`Cannot query field "email" on type "User".`}},
+		{name: "unknown argument", query: `{ me { friends(first: 1) { id } } }`, expected: []string{`Unknown argument "first" on field "User.friends".`}},
+		{name: "missing argument", query: `{ user { id } }`, expected: []string{`Field "user" argument "id" of type "ID!" is required, but it was not provided.`}},
+		{name: "leaf with selection", query: `{ me { name { x } } }`, expected: []string{`Field "name" must not have a selection since type "String!" has no subfields.`}},
+		{name: "object without selection", query: `{ me }`, expected: []string{`Field "me" of type "User" must have a selection of subfields.`}},
+		{name: "unknown fragment", query: `{ me { ...Missing } }`, expected: []string{`Unknown fragment "Missing".`}},
+		{name: "unknown type condition", query: `{ me { ... on Admin { id } } }`, expected: []string{`Unknown type "Admin".`}},
+		{name: "fragment cycle", query: `{ me { ...A } } fragment A on User { ...B } fragment B on User { ...A }`, expected: []string{`Cannot spread fragment "A" within itself.`}},
+		{name: "undefined variable", query: `query Q { user(id: $id) { id } }`, expected: []string{`Variable "$id" is not defined by operation "Q".`}},
+		{name: "missing variable", query: `query Q($id: ID!) { user(id: $id) { id } }`, expected: []string{`Variable "$id" of required type "ID!" was not provided.`}},
+		{name: "non-input variable", query: `query Q($u: User) { me { id } }`, expected: []string{`Variable "$u" cannot be non-input type "User".`}},
+		{name: "no subscriptions", query: `subscription { me { id } }`, expected: []string{`Schema is not configured to execute subscription operation.`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.query)
+			require.NoError(t, err)
+			errs := schema.Validate(doc, doc.Operations[0], tt.variables)
+			if tt.expected == nil {
+				assert.Empty(t, errs)
+				return
+			}
+			assert.Equal(t, tt.expected, errorMessages(errs))
+		})
+	}
+}
+
+func TestValidateResult(t *testing.T) {
+	schema := mustSchema(t)
+	doc, err := Parse(`query Q($id: ID!, $full: Boolean!) {
+		account: user(id: $id) { id name age role friends { id } bio: name @include(if: $full) }
+		search(text: "x") { __typename ... on User { name } ... on Post { title } }
+		node(id: $id) { id ... on Post { title } }
+	}`)
+	require.NoError(t, err)
+	op := doc.Operations[0]
+
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+		path     []interface{}
+	}{
+		{
+			name: "valid",
+			data: `{"account":{"id":"1","name":"Jane","age":30,"role":"ADMIN","friends":[{"id":2}]},
+				"search":[{"__typename":"User","name":"Jane"},{"__typename":"Post","title":null}],
+				"node":{"id":"3"}}`,
+		},
+		{name: "null data", data: `null`},
+		{name: "nullable root", data: `{"account":null,"search":[],"node":null}`},
+		{
+			name:     "wrong scalar",
+			data:     `{"account":{"id":"1","name":"Jane","age":"thirty","role":null,"friends":null},"search":[],"node":null}`,
+			expected: []string{`Int cannot represent "thirty".`},
+			path:     []interface{}{"account", "age"},
+		},
+		{
+			name:     "non-null",
+			data:     `{"account":{"id":"1","name":null,"age":null,"role":null,"friends":null},"search":[],"node":null}`,
+			expected: []string{`Cannot return null for non-nullable field of type "String!".`},
+			path:     []interface{}{"account", "name"},
+		},
+		{
+			name:     "unknown enum",
+			data:     `{"account":{"id":"1","name":"x","age":null,"role":"OWNER","friends":null},"search":[],"node":null}`,
+			expected: []string{`Enum "Role" cannot represent "OWNER".`},
+		},
+		{
+			name:     "list item",
+			data:     `{"account":{"id":"1","name":"x","age":null,"role":null,"friends":[{"id":true}]},"search":[],"node":null}`,
+			expected: []string{`ID cannot represent true.`},
+			path:     []interface{}{"account", "friends", 0, "id"},
+		},
+		{
+			name:     "not selected",
+			data:     `{"account":{"id":"1","name":"x","age":null,"role":null,"friends":null,"email":"x"},"search":[],"node":null}`,
+			expected: []string{`Field "email" is not selected by the operation on type "User".`},
+		},
+		{
+			name:     "missing field",
+			data:     `{"account":{"id":"1","name":"x","role":null,"friends":null},"search":[],"node":null}`,
+			expected: []string{`Field "age" of type "User" is missing.`},
+		},
+		{
+			name:     "fragment of another type",
+			data:     `{"account":null,"search":[{"__typename":"User","title":"x","name":"y"}],"node":null}`,
+			expected: []string{`Field "title" is not selected by the operation on type "User".`},
+		},
+		{
+			name:     "impossible typename",
+			data:     `{"account":null,"search":[{"__typename":"Query"}],"node":null}`,
+			expected: []string{`"Query" is not a possible type of "SearchResult".`},
+		},
+		{
+			name:     "object expected",
+			data:     `{"account":"Jane","search":[],"node":null}`,
+			expected: []string{`Expected an object of type "User", found "Jane".`},
+		},
+		{
+			name:     "list expected",
+			data:     `{"account":null,"search":{},"node":null}`,
+			expected: []string{`Expected a list of type "[SearchResult!]!".`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.data), &data))
+			errs := schema.ValidateResult(doc, op, data)
+			if tt.expected == nil {
+				assert.Empty(t, errorMessages(errs))
+				return
+			}
+			assert.Equal(t, tt.expected, errorMessages(errs))
+			if tt.path != nil {
+				assert.Equal(t, tt.path, errs[0].Path)
+			}
+		})
+	}
+}
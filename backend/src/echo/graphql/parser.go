@@ -0,0 +1,451 @@
+package graphql
+
+import (
+	"strconv"
+)
+
+// parser is a recursive descent parser with one token of lookahead
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return p.lex.errorf(p.tok.loc, "Unexpected %s.", p.tok.describe())
+}
+
+// peek reports whether the current token is the punctuator punct
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+// peekKeyword reports whether the current token is the name keyword
+func (p *parser) peekKeyword(keyword string) bool {
+	return p.tok.kind == tokenName && p.tok.value == keyword
+}
+
+// skip consumes the punctuator punct when it is the current token
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.peek(punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.lex.errorf(p.tok.loc, "Expected %q, found %s.", punct, p.tok.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		return p.lex.errorf(p.tok.loc, "Expected %q, found %s.", keyword, p.tok.describe())
+	}
+	return p.advance()
+}
+
+func (p *parser) parseName() (string, Location, error) {
+	if p.tok.kind != tokenName {
+		return "", p.tok.loc, p.lex.errorf(p.tok.loc, "Expected Name, found %s.", p.tok.describe())
+	}
+	name, loc := p.tok.value, p.tok.loc
+	return name, loc, p.advance()
+}
+
+// Parse parses an executable document (operations and fragments)
+func Parse(query string) (*Document, error) {
+	p, err := newParser(query)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			op := &Operation{Type: OperationQuery, Loc: p.tok.loc}
+			if op.SelectionSet, err = p.parseSelectionSet(); err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peekKeyword(OperationQuery), p.peekKeyword(OperationMutation), p.peekKeyword(OperationSubscription):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.peekKeyword("fragment"):
+			fragment, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := doc.Fragments[fragment.Name]; exists {
+				return nil, &Error{Message: "There can be only one fragment named \"" + fragment.Name + "\".", Locations: []Location{fragment.Loc}}
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, &Error{Message: "Syntax Error: The document does not contain an operation."}
+	}
+	names := map[string]bool{}
+	for _, op := range doc.Operations {
+		if op.Name == "" && len(doc.Operations) > 1 {
+			return nil, &Error{Message: "This anonymous operation must be the only defined operation.", Locations: []Location{op.Loc}}
+		}
+		if names[op.Name] {
+			return nil, &Error{Message: "There can be only one operation named \"" + op.Name + "\".", Locations: []Location{op.Loc}}
+		}
+		names[op.Name] = true
+	}
+	return doc, nil
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	op := &Operation{Type: p.tok.value, Loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.tok.kind == tokenName {
+		if op.Name, _, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if op.Variables, err = p.parseVariableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if op.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if op.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var definitions []*VariableDefinition
+	for !p.peek(")") {
+		definition := &VariableDefinition{Loc: p.tok.loc}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		var err error
+		if definition.Name, _, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if definition.Type, err = p.parseTypeRef(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if _, err := p.parseValue(true); err != nil {
+				return nil, err
+			}
+			definition.HasDefault = true
+		}
+		if _, err := p.parseDirectives(true); err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, p.advance()
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	fragment := &Fragment{Loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if fragment.Name, _, err = p.parseName(); err != nil {
+		return nil, err
+	}
+	if fragment.Name == "on" {
+		return nil, p.lex.errorf(fragment.Loc, "Unexpected Name \"on\".")
+	}
+	if err := p.expectKeyword("on"); err != nil {
+		return nil, err
+	}
+	if fragment.TypeCondition, _, err = p.parseName(); err != nil {
+		return nil, err
+	}
+	if fragment.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if fragment.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var selections []Selection
+	for !p.peek("}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.lex.errorf(p.tok.loc, "Expected Name, found <EOF>.")
+		}
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, p.lex.errorf(p.tok.loc, "Expected Name, found \"}\".")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	if !p.peek("...") {
+		return p.parseField()
+	}
+
+	loc := p.tok.loc
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value, Loc: loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if spread.Directives, err = p.parseDirectives(false); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+
+	inline := &InlineFragment{Loc: loc}
+	if p.peekKeyword("on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if inline.TypeCondition, _, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	if inline.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if inline.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) parseField() (*Field, error) {
+	field := &Field{}
+	var err error
+	if field.Name, field.Loc, err = p.parseName(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		field.Alias = field.Name
+		if field.Name, _, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	if field.Arguments, err = p.parseArguments(false); err != nil {
+		return nil, err
+	}
+	if field.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if field.SelectionSet, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) parseArguments(constant bool) ([]*Argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var arguments []*Argument
+	for !p.peek(")") {
+		argument := &Argument{}
+		var err error
+		if argument.Name, argument.Loc, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if argument.Value, err = p.parseValue(constant); err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+	}
+	if len(arguments) == 0 {
+		return nil, p.lex.errorf(p.tok.loc, "Expected Name, found \")\".")
+	}
+	return arguments, p.advance()
+}
+
+func (p *parser) parseDirectives(constant bool) ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		directive := &Directive{Loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if directive.Name, _, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		if directive.Arguments, err = p.parseArguments(constant); err != nil {
+			return nil, err
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// parseValue parses a value literal; variables are not allowed in constant values
+func (p *parser) parseValue(constant bool) (Value, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, _, err := p.parseName()
+			return Variable{Name: name}, err
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := []Value{}
+			for !p.peek("]") {
+				item, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			return list, p.advance()
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			object := map[string]Value{}
+			for !p.peek("}") {
+				name, _, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.parseValue(constant); err != nil {
+					return nil, err
+				}
+			}
+			return object, p.advance()
+		}
+	case tokenInt:
+		value, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, p.lex.errorf(tok.loc, "Invalid Int %s.", tok.value)
+		}
+		return value, p.advance()
+	case tokenFloat:
+		value, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, p.lex.errorf(tok.loc, "Invalid Float %s.", tok.value)
+		}
+		return value, p.advance()
+	case tokenString:
+		return tok.value, p.advance()
+	case tokenName:
+		var value Value
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = EnumValue(tok.value)
+		}
+		return value, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseTypeRef() (*TypeRef, error) {
+	var ref *TypeRef
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		elem, err := p.parseTypeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Elem: elem}
+	} else {
+		name, _, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Name: name}
+	}
+
+	nonNull, err := p.skip("!")
+	ref.NonNull = nonNull
+	return ref, err
+}
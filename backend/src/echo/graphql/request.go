@@ -0,0 +1,122 @@
+package graphql
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Request is a GraphQL request received over HTTP
+type Request struct {
+	Query         string
+	OperationName string // As sent by the client, may be empty
+	Variables     map[string]interface{}
+	Extensions    map[string]interface{}
+
+	Document  *Document
+	Operation *Operation // Operation selected by OperationName
+}
+
+// httpRequest is the JSON body of a GraphQL POST request and the query parameters of a GET request
+type httpRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+// ParseRequest reads a GraphQL request from its HTTP parts and parses its document
+//
+// GET requests carry query, operationName, variables and extensions as query parameters.
+// POST requests carry them as a JSON object, or send the document itself with
+// Content-Type application/graphql. The returned error is an *Error.
+func ParseRequest(method string, query url.Values, contentType string, body []byte) (*Request, error) {
+	var raw httpRequest
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case method == http.MethodGet:
+		raw.Query = query.Get("query")
+		raw.OperationName = query.Get("operationName")
+		if value := query.Get("variables"); value != "" {
+			if err := json.Unmarshal([]byte(value), &raw.Variables); err != nil {
+				return nil, &Error{Message: "Variables are invalid JSON."}
+			}
+		}
+		if value := query.Get("extensions"); value != "" {
+			if err := json.Unmarshal([]byte(value), &raw.Extensions); err != nil {
+				return nil, &Error{Message: "Extensions are invalid JSON."}
+			}
+		}
+	case mediaType == "application/graphql":
+		raw.Query = string(body)
+		raw.OperationName = query.Get("operationName")
+	default:
+		trimmed := strings.TrimSpace(string(body))
+		if strings.HasPrefix(trimmed, "[") {
+			return nil, &Error{Message: "Batched GraphQL requests are not supported."}
+		}
+		if trimmed == "" {
+			return nil, &Error{Message: "Must provide query string."}
+		}
+		if err := json.Unmarshal([]byte(trimmed), &raw); err != nil {
+			return nil, &Error{Message: "POST body sent invalid JSON."}
+		}
+	}
+
+	request := &Request{
+		Query:         raw.Query,
+		OperationName: raw.OperationName,
+		Variables:     raw.Variables,
+		Extensions:    raw.Extensions,
+	}
+	if request.Variables == nil {
+		request.Variables = map[string]interface{}{}
+	}
+
+	if strings.TrimSpace(request.Query) == "" {
+		// Automatic persisted queries send only a hash first and retry with the document on this error
+		if _, ok := request.Extensions["persistedQuery"]; ok {
+			return nil, &Error{Message: "PersistedQueryNotFound", Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"}}
+		}
+		return nil, &Error{Message: "Must provide query string."}
+	}
+
+	doc, err := Parse(request.Query)
+	if err != nil {
+		return nil, err
+	}
+	op, err := doc.Operation(request.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet && op.Type != OperationQuery {
+		return nil, &Error{Message: "Can only perform a " + op.Type + " operation from a POST request."}
+	}
+
+	request.Document = doc
+	request.Operation = op
+	return request, nil
+}
+
+// Type returns the operation type: query, mutation or subscription
+func (r *Request) Type() string {
+	return r.Operation.Type
+}
+
+// Name returns the name of the executed operation, empty when it is anonymous
+func (r *Request) Name() string {
+	return r.Operation.Name
+}
+
+// RootFields returns the fields the operation selects at its root
+func (r *Request) RootFields() []string {
+	return r.Document.RootFields(r.Operation)
+}
+
+// Describe returns a short label for the operation such as "mutation CreateUser"
+func (r *Request) Describe() string {
+	return r.Document.Describe(r.Operation)
+}
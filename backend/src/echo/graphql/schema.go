@@ -0,0 +1,505 @@
+package graphql
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Type kinds
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+)
+
+// Schema is a parsed SDL schema
+type Schema struct {
+	Types            map[string]*TypeDef
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+}
+
+// TypeDef is a named type of the schema
+type TypeDef struct {
+	Kind          string
+	Name          string
+	Fields        map[string]*FieldDef      // Object and interface fields
+	Interfaces    []string                  // Interfaces an object or interface implements
+	PossibleTypes []string                  // Members of a union
+	EnumValues    map[string]bool           // Values of an enum
+	InputFields   map[string]*InputValueDef // Fields of an input object
+}
+
+// FieldDef is a field of an object or interface type
+type FieldDef struct {
+	Name string
+	Type *TypeRef
+	Args map[string]*InputValueDef
+}
+
+// InputValueDef is an argument or input object field
+type InputValueDef struct {
+	Name       string
+	Type       *TypeRef
+	HasDefault bool
+}
+
+// builtinScalars are available in every schema
+var builtinScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+// IsLeaf reports whether values of the type are scalars or enums, which have no subfields
+func (t *TypeDef) IsLeaf() bool {
+	return t.Kind == KindScalar || t.Kind == KindEnum
+}
+
+// IsAbstract reports whether the type is an interface or a union
+func (t *TypeDef) IsAbstract() bool {
+	return t.Kind == KindInterface || t.Kind == KindUnion
+}
+
+// RootType returns the root type for an operation type, or nil when the schema does not support it
+func (s *Schema) RootType(operation string) *TypeDef {
+	switch operation {
+	case OperationQuery:
+		return s.Types[s.QueryType]
+	case OperationMutation:
+		return s.Types[s.MutationType]
+	case OperationSubscription:
+		return s.Types[s.SubscriptionType]
+	}
+	return nil
+}
+
+// IsPossibleType reports whether an object type can be returned where abstract is expected
+func (s *Schema) IsPossibleType(abstract *TypeDef, object string) bool {
+	if abstract.Name == object {
+		return true
+	}
+	switch abstract.Kind {
+	case KindUnion:
+		for _, member := range abstract.PossibleTypes {
+			if member == object {
+				return true
+			}
+		}
+	case KindInterface:
+		if def, ok := s.Types[object]; ok {
+			for _, implemented := range def.Interfaces {
+				if implemented == abstract.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ParseSchema parses a schema written in the GraphQL schema definition language
+// Type extensions are merged into their types. Directive definitions and directives are
+// accepted and ignored. Root types default to Query, Mutation and Subscription.
+func ParseSchema(sdl string) (*Schema, error) {
+	p, err := newParser(sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{Types: map[string]*TypeDef{}}
+	for _, name := range builtinScalars {
+		schema.Types[name] = &TypeDef{Kind: KindScalar, Name: name}
+	}
+
+	// Extensions may come before the definition of their type in the document
+	extended := map[string]Location{}
+	for p.tok.kind != tokenEOF {
+		if err := p.parseDefinition(schema, extended); err != nil {
+			return nil, err
+		}
+	}
+	if len(extended) > 0 {
+		names := make([]string, 0, len(extended))
+		for name := range extended {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, &Error{Message: fmt.Sprintf("Cannot extend type %q because it is not defined.", names[0]), Locations: []Location{extended[names[0]]}}
+	}
+
+	if schema.QueryType == "" {
+		schema.QueryType = "Query"
+	}
+	if schema.MutationType == "" {
+		schema.MutationType = "Mutation"
+	}
+	if schema.SubscriptionType == "" {
+		schema.SubscriptionType = "Subscription"
+	}
+	if err := schema.check(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// definitionKinds maps the keywords of type definitions to the kind they define
+var definitionKinds = map[string]string{
+	"scalar": KindScalar, "type": KindObject, "interface": KindInterface,
+	"union": KindUnion, "enum": KindEnum, "input": KindInputObject,
+}
+
+func (p *parser) parseDefinition(schema *Schema, extended map[string]Location) error {
+	if p.tok.kind == tokenString {
+		if err := p.advance(); err != nil { // Description
+			return err
+		}
+	}
+
+	extend := p.peekKeyword("extend")
+	if extend {
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if p.tok.kind != tokenName {
+		return p.unexpected()
+	}
+
+	keyword := p.tok.value
+	loc := p.tok.loc
+	if err := p.advance(); err != nil {
+		return err
+	}
+	if keyword == "schema" {
+		return p.parseSchemaDefinition(schema)
+	}
+	if keyword == "directive" {
+		return p.parseDirectiveDefinition()
+	}
+
+	kind, ok := definitionKinds[keyword]
+	if !ok {
+		return p.lex.errorf(loc, "Unexpected Name %q.", keyword)
+	}
+
+	name, nameLoc, err := p.parseName()
+	if err != nil {
+		return err
+	}
+	def, exists := schema.Types[name]
+	_, onlyExtended := extended[name]
+	switch {
+	case exists && def.Kind != kind && extend:
+		return &Error{Message: fmt.Sprintf("Cannot extend non-%s type %q.", kind, name), Locations: []Location{nameLoc}}
+	case exists && (def.Kind != kind || !onlyExtended) && !extend:
+		return &Error{Message: fmt.Sprintf("There can be only one type named %q.", name), Locations: []Location{nameLoc}}
+	case !exists:
+		def = &TypeDef{Kind: kind, Name: name}
+		schema.Types[name] = def
+		if extend {
+			extended[name] = nameLoc
+		}
+	}
+	if !extend {
+		delete(extended, name)
+	}
+
+	switch kind {
+	case KindObject, KindInterface:
+		if p.peekKeyword("implements") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if _, err := p.skip("&"); err != nil {
+				return err
+			}
+			for {
+				iface, _, err := p.parseName()
+				if err != nil {
+					return err
+				}
+				def.Interfaces = append(def.Interfaces, iface)
+				if ok, err := p.skip("&"); err != nil {
+					return err
+				} else if !ok {
+					break
+				}
+			}
+		}
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+		if p.peek("{") {
+			return p.parseFieldDefinitions(def)
+		}
+	case KindUnion:
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+		if ok, err := p.skip("="); err != nil || !ok {
+			return err
+		}
+		if _, err := p.skip("|"); err != nil {
+			return err
+		}
+		for {
+			member, memberLoc, err := p.parseName()
+			if err != nil {
+				return err
+			}
+			for _, existing := range def.PossibleTypes {
+				if existing == member {
+					return &Error{Message: fmt.Sprintf("Union type %q can only include type %q once.", name, member), Locations: []Location{memberLoc}}
+				}
+			}
+			def.PossibleTypes = append(def.PossibleTypes, member)
+			if ok, err := p.skip("|"); err != nil || !ok {
+				return err
+			}
+		}
+	case KindEnum:
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+		if def.EnumValues == nil {
+			def.EnumValues = map[string]bool{}
+		}
+		if ok, err := p.skip("{"); err != nil || !ok {
+			return err
+		}
+		for !p.peek("}") {
+			if p.tok.kind == tokenString {
+				if err := p.advance(); err != nil {
+					return err
+				}
+			}
+			value, _, err := p.parseName()
+			if err != nil {
+				return err
+			}
+			def.EnumValues[value] = true
+			if _, err := p.parseDirectives(true); err != nil {
+				return err
+			}
+		}
+		return p.advance()
+	case KindInputObject:
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+		if def.InputFields == nil {
+			def.InputFields = map[string]*InputValueDef{}
+		}
+		if p.peek("{") {
+			return p.parseInputValueDefinitions("{", "}", def.InputFields)
+		}
+	case KindScalar:
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseSchemaDefinition(schema *Schema) error {
+	if _, err := p.parseDirectives(true); err != nil {
+		return err
+	}
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return err
+	}
+	for !p.peek("}") {
+		operation, loc, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		typeName, _, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		switch operation {
+		case OperationQuery:
+			schema.QueryType = typeName
+		case OperationMutation:
+			schema.MutationType = typeName
+		case OperationSubscription:
+			schema.SubscriptionType = typeName
+		default:
+			return p.lex.errorf(loc, "Unexpected Name %q.", operation)
+		}
+	}
+	return p.advance()
+}
+
+// directiveLocations are the locations a directive definition may name
+var directiveLocations = map[string]bool{
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true, "FRAGMENT_DEFINITION": true,
+	"FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true, "VARIABLE_DEFINITION": true,
+	"SCHEMA": true, "SCALAR": true, "OBJECT": true, "FIELD_DEFINITION": true, "ARGUMENT_DEFINITION": true,
+	"INTERFACE": true, "UNION": true, "ENUM": true, "ENUM_VALUE": true, "INPUT_OBJECT": true, "INPUT_FIELD_DEFINITION": true,
+}
+
+// parseDirectiveDefinition skips a directive definition, directives are not validated
+func (p *parser) parseDirectiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, _, err := p.parseName(); err != nil {
+		return err
+	}
+	if p.peek("(") {
+		if err := p.parseInputValueDefinitions("(", ")", map[string]*InputValueDef{}); err != nil {
+			return err
+		}
+	}
+	if p.peekKeyword("repeatable") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+	if err := p.expectKeyword("on"); err != nil {
+		return err
+	}
+	if _, err := p.skip("|"); err != nil {
+		return err
+	}
+	for {
+		location, loc, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		if !directiveLocations[location] {
+			return p.lex.errorf(loc, "Unexpected Name %q.", location)
+		}
+		if ok, err := p.skip("|"); err != nil || !ok {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseFieldDefinitions(def *TypeDef) error {
+	if def.Fields == nil {
+		def.Fields = map[string]*FieldDef{}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.peek("}") {
+		if p.tok.kind == tokenString {
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+		field := &FieldDef{Args: map[string]*InputValueDef{}}
+		var err error
+		if field.Name, _, err = p.parseName(); err != nil {
+			return err
+		}
+		if p.peek("(") {
+			if err := p.parseInputValueDefinitions("(", ")", field.Args); err != nil {
+				return err
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if field.Type, err = p.parseTypeRef(); err != nil {
+			return err
+		}
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+		def.Fields[field.Name] = field
+	}
+	return p.advance()
+}
+
+func (p *parser) parseInputValueDefinitions(open, close string, into map[string]*InputValueDef) error {
+	if err := p.expect(open); err != nil {
+		return err
+	}
+	for !p.peek(close) {
+		if p.tok.kind == tokenString {
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+		value := &InputValueDef{}
+		var err error
+		if value.Name, _, err = p.parseName(); err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if value.Type, err = p.parseTypeRef(); err != nil {
+			return err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return err
+		} else if ok {
+			if _, err := p.parseValue(true); err != nil {
+				return err
+			}
+			value.HasDefault = true
+		}
+		if _, err := p.parseDirectives(true); err != nil {
+			return err
+		}
+		into[value.Name] = value
+	}
+	return p.advance()
+}
+
+// check verifies that every referenced type is defined and that a query root exists
+func (s *Schema) check() error {
+	if _, ok := s.Types[s.QueryType]; !ok {
+		return &Error{Message: fmt.Sprintf("Query root type %q is not defined.", s.QueryType)}
+	}
+
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	unknown := func(name, usedBy string) error {
+		if _, ok := s.Types[name]; ok {
+			return nil
+		}
+		return &Error{Message: fmt.Sprintf("Unknown type %q referenced by %s.", name, usedBy)}
+	}
+	for _, name := range names {
+		def := s.Types[name]
+		for _, field := range def.Fields {
+			if err := unknown(field.Type.NamedType(), name+"."+field.Name); err != nil {
+				return err
+			}
+			for _, arg := range field.Args {
+				if err := unknown(arg.Type.NamedType(), name+"."+field.Name+"("+arg.Name+")"); err != nil {
+					return err
+				}
+			}
+		}
+		for _, field := range def.InputFields {
+			if err := unknown(field.Type.NamedType(), name+"."+field.Name); err != nil {
+				return err
+			}
+		}
+		for _, iface := range def.Interfaces {
+			if err := unknown(iface, name); err != nil {
+				return err
+			}
+		}
+		for _, member := range def.PossibleTypes {
+			if err := unknown(member, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,430 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxErrors caps the errors reported for one operation or result
+const maxErrors = 20
+
+// errorList collects errors without duplicates, up to maxErrors
+type errorList struct {
+	errors []*Error
+	seen   map[string]bool
+}
+
+func (l *errorList) add(err *Error) {
+	key := fmt.Sprint(err.Message, err.Locations, err.Path)
+	if l.seen == nil {
+		l.seen = map[string]bool{}
+	}
+	if l.seen[key] || len(l.errors) >= maxErrors {
+		return
+	}
+	l.seen[key] = true
+	l.errors = append(l.errors, err)
+}
+
+func (l *errorList) addf(loc Location, format string, args ...interface{}) {
+	l.add(&Error{Message: fmt.Sprintf(format, args...), Locations: []Location{loc}})
+}
+
+// Validate checks an operation against the schema and the variables sent with it
+// It reports unknown fields, arguments, fragments and types, missing required arguments,
+// leaf fields with subselections and object fields without them, undefined variables and
+// required variables that were not provided.
+func (s *Schema) Validate(doc *Document, op *Operation, variables map[string]interface{}) []*Error {
+	v := &operationValidator{schema: s, doc: doc, used: map[string]Location{}}
+
+	root := s.RootType(op.Type)
+	if root == nil {
+		v.errs.addf(op.Loc, "Schema is not configured to execute %s operation.", op.Type)
+		return v.errs.errors
+	}
+	v.selectionSet(root, op.SelectionSet, map[string]bool{})
+	v.directives(op.Directives)
+
+	defined := map[string]bool{}
+	for _, definition := range op.Variables {
+		defined[definition.Name] = true
+		def, ok := s.Types[definition.Type.NamedType()]
+		switch {
+		case !ok:
+			v.errs.addf(definition.Loc, "Unknown type %q.", definition.Type.NamedType())
+		case def.Kind != KindScalar && def.Kind != KindEnum && def.Kind != KindInputObject:
+			v.errs.addf(definition.Loc, "Variable \"$%s\" cannot be non-input type %q.", definition.Name, definition.Type.String())
+		case definition.Type.NonNull && !definition.HasDefault && variables[definition.Name] == nil:
+			v.errs.addf(definition.Loc, "Variable \"$%s\" of required type %q was not provided.", definition.Name, definition.Type.String())
+		}
+	}
+
+	names := make([]string, 0, len(v.used))
+	for name := range v.used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !defined[name] {
+			if op.Name != "" {
+				v.errs.addf(v.used[name], "Variable \"$%s\" is not defined by operation %q.", name, op.Name)
+			} else {
+				v.errs.addf(v.used[name], "Variable \"$%s\" is not defined.", name)
+			}
+		}
+	}
+	return v.errs.errors
+}
+
+type operationValidator struct {
+	schema *Schema
+	doc    *Document
+	errs   errorList
+	used   map[string]Location // Variables referenced by arguments
+}
+
+func (v *operationValidator) selectionSet(parent *TypeDef, selections []Selection, spreading map[string]bool) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *Field:
+			v.field(parent, s, spreading)
+		case *InlineFragment:
+			v.directives(s.Directives)
+			target := parent
+			if s.TypeCondition != "" {
+				def, ok := v.schema.Types[s.TypeCondition]
+				if !ok {
+					v.errs.addf(s.Loc, "Unknown type %q.", s.TypeCondition)
+					continue
+				}
+				target = def
+			}
+			v.selectionSet(target, s.SelectionSet, spreading)
+		case *FragmentSpread:
+			v.directives(s.Directives)
+			fragment, ok := v.doc.Fragments[s.Name]
+			if !ok {
+				v.errs.addf(s.Loc, "Unknown fragment %q.", s.Name)
+				continue
+			}
+			if spreading[s.Name] {
+				v.errs.addf(s.Loc, "Cannot spread fragment %q within itself.", s.Name)
+				continue
+			}
+			def, ok := v.schema.Types[fragment.TypeCondition]
+			if !ok {
+				v.errs.addf(fragment.Loc, "Unknown type %q.", fragment.TypeCondition)
+				continue
+			}
+			spreading[s.Name] = true
+			v.selectionSet(def, fragment.SelectionSet, spreading)
+			delete(spreading, s.Name)
+		}
+	}
+}
+
+func (v *operationValidator) field(parent *TypeDef, field *Field, spreading map[string]bool) {
+	v.directives(field.Directives)
+	for _, argument := range field.Arguments {
+		v.variables(argument.Value, argument.Loc)
+	}
+
+	if field.Name == "__typename" {
+		if len(field.SelectionSet) > 0 {
+			v.errs.addf(field.Loc, "Field \"__typename\" must not have a selection since type \"String\" has no subfields.")
+		}
+		return
+	}
+	if (field.Name == "__schema" || field.Name == "__type") && parent.Name == v.schema.QueryType {
+		return // Introspection is answered by the mock, not checked
+	}
+
+	def := parent.Fields[field.Name]
+	if def == nil {
+		v.errs.addf(field.Loc, "Cannot query field %q on type %q.", field.Name, parent.Name)
+		return
+	}
+
+	provided := map[string]bool{}
+	for _, argument := range field.Arguments {
+		provided[argument.Name] = true
+		if def.Args[argument.Name] == nil {
+			v.errs.addf(argument.Loc, "Unknown argument %q on field \"%s.%s\".", argument.Name, parent.Name, field.Name)
+		}
+	}
+	argNames := make([]string, 0, len(def.Args))
+	for name := range def.Args {
+		argNames = append(argNames, name)
+	}
+	sort.Strings(argNames)
+	for _, name := range argNames {
+		arg := def.Args[name]
+		if arg.Type.NonNull && !arg.HasDefault && !provided[name] {
+			v.errs.addf(field.Loc, "Field %q argument %q of type %q is required, but it was not provided.", field.Name, name, arg.Type.String())
+		}
+	}
+
+	fieldType := v.schema.Types[def.Type.NamedType()]
+	switch {
+	case fieldType.IsLeaf() && len(field.SelectionSet) > 0:
+		v.errs.addf(field.Loc, "Field %q must not have a selection since type %q has no subfields.", field.Name, def.Type.String())
+	case !fieldType.IsLeaf() && len(field.SelectionSet) == 0:
+		v.errs.addf(field.Loc, "Field %q of type %q must have a selection of subfields.", field.Name, def.Type.String())
+	case len(field.SelectionSet) > 0:
+		v.selectionSet(fieldType, field.SelectionSet, spreading)
+	}
+}
+
+func (v *operationValidator) directives(directives []*Directive) {
+	for _, directive := range directives {
+		for _, argument := range directive.Arguments {
+			v.variables(argument.Value, argument.Loc)
+		}
+	}
+}
+
+// variables records the variables referenced in a value
+func (v *operationValidator) variables(value Value, loc Location) {
+	switch val := value.(type) {
+	case Variable:
+		if _, seen := v.used[val.Name]; !seen {
+			v.used[val.Name] = loc
+		}
+	case []Value:
+		for _, item := range val {
+			v.variables(item, loc)
+		}
+	case map[string]Value:
+		for _, item := range val {
+			v.variables(item, loc)
+		}
+	}
+}
+
+// ValidateResult checks that data, the "data" member of a response, fits the operation
+// Every key must be selected by the operation, values must have the type of their field
+// and non-null fields must not be null. Selected fields are required unless they depend on
+// @include/@skip or on a fragment whose type cannot be told without "__typename".
+// Errors carry the path of the offending value.
+func (s *Schema) ValidateResult(doc *Document, op *Operation, data interface{}) []*Error {
+	root := s.RootType(op.Type)
+	if root == nil || data == nil {
+		return nil
+	}
+	v := &resultValidator{schema: s, doc: doc}
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		v.errorf(nil, "Expected data to be an object of type %q.", root.Name)
+		return v.errs.errors
+	}
+	v.object(root, op.SelectionSet, object, nil)
+	return v.errs.errors
+}
+
+type resultValidator struct {
+	schema *Schema
+	doc    *Document
+	errs   errorList
+}
+
+func (v *resultValidator) errorf(path []interface{}, format string, args ...interface{}) {
+	v.errs.add(&Error{Message: fmt.Sprintf(format, args...), Path: path})
+}
+
+// childPath returns a new path extending path with a key or index
+func childPath(path []interface{}, element interface{}) []interface{} {
+	return append(append([]interface{}(nil), path...), element)
+}
+
+// selectedField is a response key of an object with the field it selects
+type selectedField struct {
+	name       string
+	def        *FieldDef // nil for __typename and introspection fields
+	selections []Selection
+	required   bool
+}
+
+func (v *resultValidator) object(declared *TypeDef, selections []Selection, object map[string]interface{}, path []interface{}) {
+	typeDef, certain := declared, !declared.IsAbstract()
+	if typename, ok := object["__typename"].(string); ok {
+		if def, exists := v.schema.Types[typename]; exists && def.Kind == KindObject && v.schema.IsPossibleType(declared, typename) {
+			typeDef, certain = def, true
+		} else {
+			v.errorf(childPath(path, "__typename"), "%q is not a possible type of %q.", typename, declared.Name)
+		}
+	}
+
+	fields := map[string]*selectedField{}
+	var keys []string
+	v.collect(typeDef, certain, selections, true, fields, &keys, map[string]bool{})
+
+	objectKeys := make([]string, 0, len(object))
+	for key := range object {
+		objectKeys = append(objectKeys, key)
+	}
+	sort.Strings(objectKeys)
+	for _, key := range objectKeys {
+		selected, ok := fields[key]
+		fieldPath := childPath(path, key)
+		if !ok {
+			v.errorf(fieldPath, "Field %q is not selected by the operation on type %q.", key, typeDef.Name)
+			continue
+		}
+		switch {
+		case selected.name == "__typename":
+			if _, ok := object[key].(string); !ok {
+				v.errorf(fieldPath, "Expected __typename to be a String.")
+			}
+		case selected.def != nil:
+			v.value(selected.def.Type, selected.selections, object[key], fieldPath)
+		}
+	}
+	for _, key := range keys {
+		if _, ok := object[key]; !ok && fields[key].required {
+			v.errorf(childPath(path, key), "Field %q of type %q is missing.", key, typeDef.Name)
+		}
+	}
+}
+
+// collect gathers the fields selected on an object of type typeDef by response key
+// When certain is false typeDef is abstract and every fragment may apply, so their fields
+// are allowed but not required.
+func (v *resultValidator) collect(typeDef *TypeDef, certain bool, selections []Selection, required bool, fields map[string]*selectedField, keys *[]string, spreading map[string]bool) {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *Field:
+			key := s.ResponseKey()
+			selected, exists := fields[key]
+			if !exists {
+				selected = &selectedField{name: s.Name}
+				if s.Name != "__typename" {
+					selected.def = typeDef.Fields[s.Name]
+				}
+				fields[key] = selected
+				*keys = append(*keys, key)
+			}
+			selected.selections = append(selected.selections, s.SelectionSet...)
+			selected.required = selected.required || (required && !isConditional(s.Directives))
+		case *InlineFragment:
+			v.collectFragment(typeDef, certain, s.TypeCondition, s.SelectionSet, required && !isConditional(s.Directives), fields, keys, spreading)
+		case *FragmentSpread:
+			fragment, ok := v.doc.Fragments[s.Name]
+			if !ok || spreading[s.Name] {
+				continue
+			}
+			spreading[s.Name] = true
+			v.collectFragment(typeDef, certain, fragment.TypeCondition, fragment.SelectionSet, required && !isConditional(s.Directives), fields, keys, spreading)
+			delete(spreading, s.Name)
+		}
+	}
+}
+
+func (v *resultValidator) collectFragment(typeDef *TypeDef, certain bool, condition string, selections []Selection, required bool, fields map[string]*selectedField, keys *[]string, spreading map[string]bool) {
+	if condition == "" || condition == typeDef.Name {
+		v.collect(typeDef, certain, selections, required, fields, keys, spreading)
+		return
+	}
+	conditionType, ok := v.schema.Types[condition]
+	if !ok {
+		return
+	}
+	if certain {
+		if v.schema.IsPossibleType(conditionType, typeDef.Name) {
+			v.collect(typeDef, true, selections, required, fields, keys, spreading)
+		}
+		return
+	}
+	// The concrete type is unknown: the fragment may or may not apply
+	v.collect(conditionType, !conditionType.IsAbstract(), selections, false, fields, keys, spreading)
+}
+
+// isConditional reports whether @include or @skip may leave the selection out
+func isConditional(directives []*Directive) bool {
+	for _, directive := range directives {
+		if directive.Name == "include" || directive.Name == "skip" {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *resultValidator) value(ref *TypeRef, selections []Selection, value interface{}, path []interface{}) {
+	if value == nil {
+		if ref.NonNull {
+			v.errorf(path, "Cannot return null for non-nullable field of type %q.", ref.String())
+		}
+		return
+	}
+
+	if ref.Elem != nil {
+		list, ok := value.([]interface{})
+		if !ok {
+			v.errorf(path, "Expected a list of type %q.", ref.String())
+			return
+		}
+		for i, item := range list {
+			v.value(ref.Elem, selections, item, childPath(path, i))
+		}
+		return
+	}
+
+	def := v.schema.Types[ref.Name]
+	switch def.Kind {
+	case KindScalar:
+		if !scalarAccepts(def.Name, value) {
+			v.errorf(path, "%s cannot represent %s.", def.Name, describeValue(value))
+		}
+	case KindEnum:
+		if name, ok := value.(string); !ok || !def.EnumValues[name] {
+			v.errorf(path, "Enum %q cannot represent %s.", def.Name, describeValue(value))
+		}
+	default:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.errorf(path, "Expected an object of type %q, found %s.", def.Name, describeValue(value))
+			return
+		}
+		v.object(def, selections, object, path)
+	}
+}
+
+// scalarAccepts reports whether a decoded JSON value is valid for a built-in scalar
+// Custom scalars accept any value.
+func scalarAccepts(scalar string, value interface{}) bool {
+	switch scalar {
+	case "Int":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number) && number >= math.MinInt32 && number <= math.MaxInt32
+	case "Float":
+		_, ok := value.(float64)
+		return ok
+	case "String":
+		_, ok := value.(string)
+		return ok
+	case "Boolean":
+		_, ok := value.(bool)
+		return ok
+	case "ID":
+		switch id := value.(type) {
+		case string:
+			return true
+		case float64:
+			return id == math.Trunc(id)
+		}
+		return false
+	}
+	return true
+}
+
+func describeValue(value interface{}) string {
+	switch val := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", val)
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,50 @@
+package collection
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+)
+
+// collectionDetail is a collection together with its live state
+type collectionDetail struct {
+	database.MockCollection
+	ItemCount int                      `json:"item_count"`
+	Items     []map[string]interface{} `json:"items,omitempty"`
+}
+
+// findCollection loads the collection from the route params, writing an error response when it is missing
+func findCollection(c *gin.Context) (*database.MockCollection, bool) {
+	projectId := c.Param("projectId")
+	collectionId := c.Param("collectionId")
+	if projectId == "" || collectionId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID and collection ID are required",
+		})
+		return nil, false
+	}
+
+	var collection database.MockCollection
+	result := database.GetDB().Where("id = ? AND project_id = ?", collectionId, projectId).First(&collection)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Collection not found",
+		})
+		return nil, false
+	}
+
+	return &collection, true
+}
+
+// collectionNameTaken reports whether another collection in the project already uses name
+func collectionNameTaken(projectID, name, excludeID string) bool {
+	var count int64
+	database.GetDB().Model(&database.MockCollection{}).
+		Where("project_id = ? AND name = ? AND id != ?", projectID, name, excludeID).
+		Count(&count)
+	return count > 0
+}
@@ -0,0 +1,105 @@
+package collection
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// CreateCollectionHandler creates a stateful collection for endpoints in "crud" response mode
+//
+// Sample curl:
+//
+//	curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/collections" \
+//	  -H "Content-Type: application/json" \
+//	  -H "Authorization: Bearer {token}" \
+//	  -d '{
+//	    "name": "users",
+//	    "id_field": "id",
+//	    "seed": "[{\"id\":1,\"name\":\"Jane\"}]"
+//	  }'
+func CreateCollectionHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	projectId := c.Param("projectId")
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID is required",
+		})
+		return
+	}
+
+	// Find project first
+	var project database.Project
+	result := database.GetDB().Where("id = ?", projectId).First(&project)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Project not found",
+		})
+		return
+	}
+
+	var collection database.MockCollection
+	if err := c.ShouldBindJSON(&collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	collection.Name = strings.TrimSpace(collection.Name)
+	if collection.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Collection name is required",
+		})
+		return
+	}
+
+	if collection.IDField == "" {
+		collection.IDField = "id"
+	}
+
+	if _, err := services.ParseCollectionSeed(collection.Seed); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid collection seed: " + err.Error(),
+		})
+		return
+	}
+
+	if collectionNameTaken(project.ID, collection.Name, "") {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   true,
+			"message": "A collection with this name already exists",
+		})
+		return
+	}
+
+	// Assign to project
+	collection.ID = ""
+	collection.ProjectID = project.ID
+
+	result = database.GetDB().Create(&collection)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to create collection: " + result.Error.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Collection created successfully",
+		"data":    collection,
+	})
+}
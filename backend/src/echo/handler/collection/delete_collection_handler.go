@@ -0,0 +1,40 @@
+package collection
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// DeleteCollectionHandler deletes a collection and its live items
+//
+// Sample curl:
+// curl -X DELETE "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/collections/{collectionId}" -H "Authorization: Bearer {token}"
+func DeleteCollectionHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	collection, ok := findCollection(c)
+	if !ok {
+		return
+	}
+
+	result := database.GetDB().Delete(collection)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to delete collection: " + result.Error.Error(),
+		})
+		return
+	}
+
+	services.ForgetCollection(collection.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Collection deleted successfully",
+	})
+}
@@ -0,0 +1,41 @@
+package collection
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// GetCollectionHandler returns a collection and its current items
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/collections/{collectionId}" -H "Authorization: Bearer {token}"
+func GetCollectionHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	collection, ok := findCollection(c)
+	if !ok {
+		return
+	}
+
+	items, err := services.CollectionItems(collection)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to load collection items: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": collectionDetail{
+			MockCollection: *collection,
+			ItemCount:      len(items),
+			Items:          items,
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package collection
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// ListCollectionsHandler lists the collections of a project with their current item counts
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/collections" -H "Authorization: Bearer {token}"
+func ListCollectionsHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	projectId := c.Param("projectId")
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID is required",
+		})
+		return
+	}
+
+	var collections []database.MockCollection
+	result := database.GetDB().
+		Where("project_id = ?", projectId).
+		Order("name").
+		Find(&collections)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to retrieve collections: " + result.Error.Error(),
+		})
+		return
+	}
+
+	details := make([]collectionDetail, 0, len(collections))
+	for i := range collections {
+		detail := collectionDetail{MockCollection: collections[i]}
+		if items, err := services.CollectionItems(&collections[i]); err == nil {
+			detail.ItemCount = len(items)
+		}
+		details = append(details, detail)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    details,
+	})
+}
@@ -0,0 +1,42 @@
+package collection
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// ResetCollectionHandler discards all changes made through the mock and restores the seed
+//
+// Sample curl:
+// curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/collections/{collectionId}/reset" -H "Authorization: Bearer {token}"
+func ResetCollectionHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	collection, ok := findCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := services.ResetCollection(collection); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to reset collection: " + err.Error(),
+		})
+		return
+	}
+
+	items, _ := services.CollectionItems(collection)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Collection reset successfully",
+		"data": collectionDetail{
+			MockCollection: *collection,
+			ItemCount:      len(items),
+			Items:          items,
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package collection
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// UpdateCollectionHandler updates a collection definition
+// Changing the seed or id field resets the live items to the new seed.
+//
+// Sample curl:
+//
+//	curl -X PUT "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/collections/{collectionId}" \
+//	  -H "Content-Type: application/json" \
+//	  -H "Authorization: Bearer {token}" \
+//	  -d '{
+//	    "seed": "[{\"id\":1,\"name\":\"Jane\"},{\"id\":2,\"name\":\"John\"}]"
+//	  }'
+func UpdateCollectionHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	collection, ok := findCollection(c)
+	if !ok {
+		return
+	}
+
+	var updateData struct {
+		Name    *string `json:"name"`
+		IDField *string `json:"id_field"`
+		Seed    *string `json:"seed"`
+	}
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if updateData.Name != nil {
+		name := strings.TrimSpace(*updateData.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Collection name is required",
+			})
+			return
+		}
+		if collectionNameTaken(collection.ProjectID, name, collection.ID) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   true,
+				"message": "A collection with this name already exists",
+			})
+			return
+		}
+		collection.Name = name
+	}
+
+	reset := false
+	if updateData.IDField != nil && *updateData.IDField != "" && *updateData.IDField != collection.IDField {
+		collection.IDField = *updateData.IDField
+		reset = true
+	}
+
+	if updateData.Seed != nil {
+		if _, err := services.ParseCollectionSeed(*updateData.Seed); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Invalid collection seed: " + err.Error(),
+			})
+			return
+		}
+		collection.Seed = *updateData.Seed
+		reset = true
+	}
+
+	result := database.GetDB().Save(collection)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to update collection: " + result.Error.Error(),
+		})
+		return
+	}
+
+	if reset {
+		services.ResetCollection(collection)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Collection updated successfully",
+		"data":    collection,
+	})
+}
@@ -17,6 +17,7 @@ const (
 	KeyExecutionMode = "executionMode"
 	KeyMatched       = "matched"
 	KeyPath          = "path"
+	KeyRequestMeta   = "requestMeta"
 )
 
 var mockService *services.MockService
@@ -0,0 +1,68 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/services"
+)
+
+// validateEndpointType checks the endpoint type, the method of websocket and grpc endpoints, the path
+// of grpc endpoints and the GraphQL schema, writing an error response when they are invalid
+func validateEndpointType(c *gin.Context, endpoint *database.MockEndpoint) bool {
+	switch endpoint.Type {
+	case database.EndpointTypeHTTP, database.EndpointTypeGraphQL, database.EndpointTypeWebSocket, database.EndpointTypeGRPC:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid endpoint type: must be \"http\", \"graphql\", \"websocket\" or \"grpc\"",
+		})
+		return false
+	}
+
+	// WebSocket connections always open with a GET request
+	if endpoint.IsWebSocket() && endpoint.Method != http.MethodGet {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "websocket endpoints must use the GET method",
+		})
+		return false
+	}
+
+	// gRPC calls are POST requests to "/package.Service/Method"
+	if endpoint.IsGRPC() {
+		if endpoint.Method != http.MethodPost {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "grpc endpoints must use the POST method",
+			})
+			return false
+		}
+		if err := services.ValidateGRPCMethodPath(endpoint.Path); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": err.Error(),
+			})
+			return false
+		}
+	}
+
+	if endpoint.GraphQLSchema != "" && !endpoint.IsGraphQL() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "graphql_schema is only supported on graphql endpoints",
+		})
+		return false
+	}
+
+	if err := services.ValidateGraphQLSchema(endpoint.GraphQLSchema); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": err.Error(),
+		})
+		return false
+	}
+	return true
+}
@@ -9,6 +9,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 /*
@@ -90,6 +91,14 @@ func CreateEndpointHandler(c *gin.Context) {
 	if endpoint.ResponseMode == "" {
 		endpoint.ResponseMode = "random"
 	}
+	if endpoint.Type == "" {
+		endpoint.Type = database.EndpointTypeHTTP
+	}
+
+	// GraphQL endpoints may carry a schema to validate operations against
+	if !validateEndpointType(c, &endpoint) {
+		return
+	}
 
 	// Validate JSON format in AdvanceConfig if provided
 	if endpoint.AdvanceConfig != "" {
@@ -101,6 +110,14 @@ func CreateEndpointHandler(c *gin.Context) {
 			})
 			return
 		}
+		// Validate delay and latency settings
+		if _, err := database.ParseEndpointAdvanceConfig(endpoint.AdvanceConfig); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Invalid advance_config: " + err.Error(),
+			})
+			return
+		}
 	}
 
 	// Validate proxy target if proxy is enabled
@@ -155,6 +172,8 @@ func CreateEndpointHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Endpoint created successfully",
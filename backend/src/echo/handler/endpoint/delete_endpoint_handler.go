@@ -7,6 +7,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // DeleteEndpointHandler removes an endpoint
@@ -56,6 +57,8 @@ func DeleteEndpointHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Endpoint deleted successfully",
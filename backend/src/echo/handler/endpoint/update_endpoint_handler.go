@@ -9,6 +9,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 /*
@@ -68,6 +69,8 @@ func UpdateEndpointHandler(c *gin.Context) {
 		AdvanceConfig *string `json:"advance_config"`  // Changed to pointer to detect if field is provided
 		UseProxy      *bool   `json:"use_proxy"`       // Whether to use proxy for this endpoint
 		ProxyTargetID *string `json:"proxy_target_id"` // ID of the proxy target to use
+		Type          *string `json:"type"`            // "http" or "graphql"
+		GraphQLSchema *string `json:"graphql_schema"`  // SDL schema of a graphql endpoint, empty to remove it
 	}
 
 	if err := c.ShouldBindJSON(&updateData); err != nil {
@@ -104,6 +107,23 @@ func UpdateEndpointHandler(c *gin.Context) {
 		existingEndpoint.Documentation = updateData.Documentation
 	}
 
+	if updateData.Type != nil {
+		existingEndpoint.Type = *updateData.Type
+	}
+	if updateData.GraphQLSchema != nil {
+		existingEndpoint.GraphQLSchema = *updateData.GraphQLSchema
+	}
+	// Switching back to http drops the schema that only applies to graphql endpoints
+	if existingEndpoint.Type == database.EndpointTypeHTTP && updateData.GraphQLSchema == nil {
+		existingEndpoint.GraphQLSchema = ""
+	}
+	if existingEndpoint.Type == "" {
+		existingEndpoint.Type = database.EndpointTypeHTTP
+	}
+	if !validateEndpointType(c, &existingEndpoint) {
+		return
+	}
+
 	// Handle advance_config: allow empty string to clear the config
 	// Check if advance_config field is provided (not nil)
 	if updateData.AdvanceConfig != nil {
@@ -120,6 +140,14 @@ func UpdateEndpointHandler(c *gin.Context) {
 				})
 				return
 			}
+			// Validate delay and latency settings
+			if _, err := database.ParseEndpointAdvanceConfig(advanceConfig); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   true,
+					"message": "Invalid advance_config: " + err.Error(),
+				})
+				return
+			}
 		}
 		// Update with the new value (could be empty string)
 		existingEndpoint.AdvanceConfig = advanceConfig
@@ -161,6 +189,8 @@ func UpdateEndpointHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Endpoint updated successfully",
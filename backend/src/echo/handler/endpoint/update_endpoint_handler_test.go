@@ -270,3 +270,90 @@ func TestUpdateEndpointHandler_EndpointNotFound(t *testing.T) {
 	assert.True(t, response["error"].(bool))
 	assert.Contains(t, response["message"], "Endpoint not found")
 }
+
+func TestUpdateEndpointHandler_GraphQL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup, err := database.InitTestWorkspaceWithProject(
+		"test@example.com",
+		"Test User",
+		"Test Workspace",
+		"Test Project",
+		"test-project-123",
+	)
+	if err != nil {
+		t.Fatalf("Failed to initialize test workspace: %v", err)
+	}
+	defer setup.Cleanup()
+
+	testProject := setup.Project
+	testEndpoint, err := database.CreateTestEndpointWithConfig(testProject.ID, "POST", "/graphql", "")
+	if err != nil {
+		t.Fatalf("Failed to create test endpoint: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		updateData     map[string]interface{}
+		expectedStatus int
+		expectedType   string
+		expectedSchema string
+	}{
+		{
+			name:           "Schema on http endpoint",
+			updateData:     map[string]interface{}{"graphql_schema": "type Query { me: String }"},
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   database.EndpointTypeHTTP,
+		},
+		{
+			name:           "Unknown type",
+			updateData:     map[string]interface{}{"type": "soap"},
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   database.EndpointTypeHTTP,
+		},
+		{
+			name:           "Invalid schema",
+			updateData:     map[string]interface{}{"type": "graphql", "graphql_schema": "type Query { me: Missing }"},
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   database.EndpointTypeHTTP,
+		},
+		{
+			name:           "GraphQL endpoint with schema",
+			updateData:     map[string]interface{}{"type": "graphql", "graphql_schema": "type Query { me: String }"},
+			expectedStatus: http.StatusOK,
+			expectedType:   database.EndpointTypeGraphQL,
+			expectedSchema: "type Query { me: String }",
+		},
+		{
+			name:           "Back to http drops the schema",
+			updateData:     map[string]interface{}{"type": "http"},
+			expectedStatus: http.StatusOK,
+			expectedType:   database.EndpointTypeHTTP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonData, _ := json.Marshal(tt.updateData)
+			req, _ := http.NewRequest("PUT", "/api/projects/"+testProject.ID+"/endpoints/"+testEndpoint.ID, bytes.NewBuffer(jsonData))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = req
+			c.Params = []gin.Param{
+				{Key: "projectId", Value: testProject.ID},
+				{Key: "id", Value: testEndpoint.ID},
+			}
+
+			UpdateEndpointHandler(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+
+			var updatedEndpoint database.MockEndpoint
+			database.DB.First(&updatedEndpoint, "id = ?", testEndpoint.ID)
+			assert.Equal(t, tt.expectedType, updatedEndpoint.Type)
+			assert.Equal(t, tt.expectedSchema, updatedEndpoint.GraphQLSchema)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+)
+
+// findFile loads the file from the route params, writing an error response when it is missing
+func findFile(c *gin.Context) (*database.MockFile, bool) {
+	projectId := c.Param("projectId")
+	fileId := c.Param("fileId")
+	if projectId == "" || fileId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID and file ID are required",
+		})
+		return nil, false
+	}
+
+	var file database.MockFile
+	result := database.GetDB().Where("id = ? AND project_id = ?", fileId, projectId).First(&file)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "File not found",
+		})
+		return nil, false
+	}
+
+	return &file, true
+}
@@ -0,0 +1,55 @@
+package file
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// DeleteFileHandler deletes an uploaded file that no response serves anymore
+//
+// Sample curl:
+// curl -X DELETE "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/files/{fileId}" -H "Authorization: Bearer {token}"
+func DeleteFileHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	file, ok := findFile(c)
+	if !ok {
+		return
+	}
+
+	var inUse int64
+	database.GetDB().Model(&database.MockResponse{}).
+		Where("file_id = ? AND body_type = ?", file.ID, services.BodyTypeFile).
+		Count(&inUse)
+	if inUse > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   true,
+			"message": "File is still used by one or more responses",
+		})
+		return
+	}
+
+	result := database.GetDB().Delete(file)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to delete file: " + result.Error.Error(),
+		})
+		return
+	}
+
+	if err := services.RemoveMockFile(file.ID); err != nil {
+		log.Error().Err(err).Str("file_id", file.ID).Msg("Failed to remove file content")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "File deleted successfully",
+	})
+}
@@ -0,0 +1,24 @@
+package file
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// DownloadFileHandler sends the content of an uploaded file
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/files/{fileId}/content" -H "Authorization: Bearer {token}" -o file.bin
+func DownloadFileHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	file, ok := findFile(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", file.ContentType)
+	c.FileAttachment(services.MockFilePath(file.ID), file.Name)
+}
@@ -0,0 +1,27 @@
+package file
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+)
+
+// GetFileHandler returns the metadata of an uploaded file
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/files/{fileId}" -H "Authorization: Bearer {token}"
+func GetFileHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	file, ok := findFile(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    file,
+	})
+}
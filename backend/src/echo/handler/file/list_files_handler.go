@@ -0,0 +1,45 @@
+package file
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+)
+
+// ListFilesHandler lists the files uploaded to a project
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/files" -H "Authorization: Bearer {token}"
+func ListFilesHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	projectId := c.Param("projectId")
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID is required",
+		})
+		return
+	}
+
+	var files []database.MockFile
+	result := database.GetDB().
+		Where("project_id = ?", projectId).
+		Order("name").
+		Find(&files)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to retrieve files: " + result.Error.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    files,
+	})
+}
@@ -0,0 +1,123 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+	systemConfig "beo-echo/backend/src/systemConfigs"
+)
+
+// defaultMaxUploadBytes caps uploads when MAX_UPLOAD_FILE_BYTES cannot be read
+const defaultMaxUploadBytes = 50 << 20
+
+// UploadFileHandler uploads a file that responses can serve as their body (body_type "file")
+// The content type is taken from the content_type form field, then the file name, then the content.
+// Requests larger than the MAX_UPLOAD_FILE_BYTES system config are rejected with 413.
+//
+// Sample curl:
+//
+//	curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/files" \
+//	  -H "Authorization: Bearer {token}" \
+//	  -F "file=@avatar.png" \
+//	  -F "content_type=image/png"
+func UploadFileHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	projectId := c.Param("projectId")
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID is required",
+		})
+		return
+	}
+
+	// Find project first
+	var project database.Project
+	result := database.GetDB().Where("id = ?", projectId).First(&project)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Project not found",
+		})
+		return
+	}
+
+	// Uploads are read to disk, so their size is capped before the form is parsed
+	maxBytes := int64(defaultMaxUploadBytes)
+	if limit, err := systemConfig.GetSystemConfigWithType[int](systemConfig.MAX_UPLOAD_FILE_BYTES); err == nil && limit > 0 {
+		maxBytes = int64(limit)
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error":   true,
+				"message": fmt.Sprintf("Upload exceeds the maximum size of %d bytes", maxBytes),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "A file is required in the \"file\" form field: " + err.Error(),
+		})
+		return
+	}
+
+	content, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Failed to read uploaded file: " + err.Error(),
+		})
+		return
+	}
+	defer content.Close()
+
+	contentType := c.PostForm("content_type")
+	if contentType == "" {
+		contentType = header.Header.Get("Content-Type")
+	}
+
+	file := database.MockFile{
+		ID:          uuid.New().String(),
+		ProjectID:   project.ID,
+		Name:        filepath.Base(header.Filename),
+		ContentType: contentType,
+	}
+
+	if err := services.StoreMockFile(&file, content); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to store file: " + err.Error(),
+		})
+		return
+	}
+
+	result = database.GetDB().Create(&file)
+	if result.Error != nil {
+		services.RemoveMockFile(file.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to save file: " + result.Error.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "File uploaded successfully",
+		"data":    file,
+	})
+}
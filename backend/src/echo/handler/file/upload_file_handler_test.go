@@ -0,0 +1,85 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/handler/project"
+	"beo-echo/backend/src/echo/services"
+	"beo-echo/backend/src/lib"
+	systemConfig "beo-echo/backend/src/systemConfigs"
+)
+
+func TestUploadFileHandler_MaxSize(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	handler.EnsureMockService()
+
+	originalDir := lib.UPLOAD_DIR
+	lib.UPLOAD_DIR = t.TempDir()
+	defer func() { lib.UPLOAD_DIR = originalDir }()
+
+	require.NoError(t, systemConfig.SetSystemConfig(systemConfig.MAX_UPLOAD_FILE_BYTES, "2048"))
+	t.Cleanup(func() {
+		database.GetDB().Where("key = ?", systemConfig.MAX_UPLOAD_FILE_BYTES).Delete(&database.SystemConfig{})
+	})
+
+	proj := &database.Project{ID: uuid.New().String(), Name: "Files", Alias: "files-" + uuid.New().String()[:8]}
+	require.NoError(t, database.GetDB().Create(proj).Error)
+
+	router := gin.New()
+	router.POST("/api/workspaces/:workspaceID/projects/:projectId/files", UploadFileHandler)
+	router.DELETE("/api/workspaces/:workspaceID/projects/:projectId", project.DeleteProjectHandler)
+
+	upload := func(content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "data.txt")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		req, _ := http.NewRequest("POST", "/api/workspaces/test-workspace/projects/"+proj.ID+"/files", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Uploads over the limit are rejected
+	w := upload(strings.Repeat("x", 4096))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	var count int64
+	database.GetDB().Model(&database.MockFile{}).Where("project_id = ?", proj.ID).Count(&count)
+	assert.Zero(t, count)
+
+	// Smaller uploads are stored
+	w = upload("hello")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Data database.MockFile `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.FileExists(t, services.MockFilePath(created.Data.ID))
+
+	// Deleting the project removes the stored content
+	req, _ := http.NewRequest("DELETE", "/api/workspaces/test-workspace/projects/"+proj.ID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	_, err := os.Stat(services.MockFilePath(created.Data.ID))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/services"
+)
+
+// writeFaultResponse writes resp to the client while applying a connection level fault
+// It returns false when the fault does not act on the connection, in which case the
+// response is written as usual.
+func writeFaultResponse(c *gin.Context, resp *http.Response, fault *database.FaultRule) bool {
+	switch fault.Type {
+	case database.FaultReset:
+		closeResponseBody(resp)
+		closeConnection(c, true)
+	case database.FaultEmpty:
+		closeResponseBody(resp)
+		closeConnection(c, false)
+	case database.FaultTruncate:
+		writeTruncatedResponse(c, resp, fault.Bytes)
+	case database.FaultTrickle:
+		writeTrickledResponse(c, resp, fault.BytesPerSec)
+	default:
+		return false
+	}
+	return true
+}
+
+// closeConnection drops the client connection without completing the response
+// A reset sends a TCP RST instead of a normal close.
+func closeConnection(c *gin.Context, reset bool) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		// HTTP/2 connections cannot be hijacked; the client gets an empty response instead
+		log.Debug().Err(err).Msg("Failed to hijack connection for fault injection")
+		return
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok && reset {
+		tcpConn.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// writeTruncatedResponse announces the full body but closes the connection after limit bytes
+// A zero limit sends half the body, or the first chunk of a stream.
+func writeTruncatedResponse(c *gin.Context, resp *http.Response, limit int) {
+	defer closeResponseBody(resp)
+
+	var body []byte
+	if resp.Body != nil {
+		if services.IsStreamingResponse(resp) {
+			if limit <= 0 {
+				buf := make([]byte, 32*1024)
+				n, _ := resp.Body.Read(buf)
+				body = buf[:n]
+			} else {
+				body, _ = io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+			}
+		} else {
+			full, _ := io.ReadAll(resp.Body)
+			if limit <= 0 || limit >= len(full) {
+				limit = len(full) / 2
+			}
+			body = full[:limit]
+			c.Header("Content-Length", strconv.Itoa(len(full)))
+		}
+	}
+
+	copyResponseHeaders(c, resp)
+	c.Status(resp.StatusCode)
+	c.Writer.Write(body)
+	c.Writer.Flush()
+	closeConnection(c, false)
+}
+
+// writeTrickledResponse sends the body at roughly bytesPerSec, in ten writes per second
+func writeTrickledResponse(c *gin.Context, resp *http.Response, bytesPerSec int) {
+	defer closeResponseBody(resp)
+
+	copyResponseHeaders(c, resp)
+	c.Status(resp.StatusCode)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+	if resp.Body == nil || bytesPerSec <= 0 {
+		return
+	}
+
+	chunkSize := bytesPerSec / 10
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	ticker := time.NewTicker(time.Duration(chunkSize) * time.Second / time.Duration(bytesPerSec))
+	defer ticker.Stop()
+
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(resp.Body, buf)
+		if n > 0 {
+			if _, writeErr := c.Writer.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		if err != nil {
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func closeResponseBody(resp *http.Response) {
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+// newFaultServer serves body through writeFaultResponse with the given fault
+func newFaultServer(t *testing.T, body string, fault database.FaultRule) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		require.True(t, writeFaultResponse(c, resp, &fault))
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWriteFaultResponse(t *testing.T) {
+	body := `{"items":[1,2,3,4,5,6,7,8,9,10]}`
+
+	t.Run("Reset drops the connection", func(t *testing.T) {
+		server := newFaultServer(t, body, database.FaultRule{Type: database.FaultReset})
+		_, err := http.Get(server.URL)
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty closes without a reply", func(t *testing.T) {
+		server := newFaultServer(t, body, database.FaultRule{Type: database.FaultEmpty})
+		_, err := http.Get(server.URL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "EOF")
+	})
+
+	t.Run("Truncate sends part of the body", func(t *testing.T) {
+		server := newFaultServer(t, body, database.FaultRule{Type: database.FaultTruncate, Bytes: 5})
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, int64(len(body)), resp.ContentLength)
+		received, err := io.ReadAll(resp.Body)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, body[:5], string(received))
+	})
+
+	t.Run("Trickle sends the whole body slowly", func(t *testing.T) {
+		server := newFaultServer(t, body, database.FaultRule{Type: database.FaultTrickle, BytesPerSec: 100})
+		start := time.Now()
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		received, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(received))
+		// 33 bytes at 100 bytes/sec in 10 byte chunks
+		assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+	})
+
+	t.Run("Other faults are written normally", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		assert.False(t, writeFaultResponse(c, &http.Response{}, &database.FaultRule{Type: database.FaultError}))
+	})
+}
@@ -6,6 +6,10 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/echo/requestctx"
+	"beo-echo/backend/src/echo/services"
 )
 
 // MockRequestHandler is a catch-all handler for mock API endpoints
@@ -44,9 +48,28 @@ func MockRequestHandler(c *gin.Context) {
 	if path == "" {
 		path = "/"
 	}
+	reqPath := path
+
+	// gRPC clients call "/package.Service/Method" on the host, without the alias in the path
+	if services.IsGRPCRequest(c.Request) {
+		if alias := grpcProjectAlias(c.Request); alias != "" {
+			projectAlias = alias
+			path = c.Request.URL.Path
+			// Keep the alias prefix HandleRequest trims from the path off the package name
+			reqPath = "/" + alias + path
+		}
+	}
+
+	// Share one parsed view of the request, the logger usually attached it already
+	c.Request, _ = requestctx.Attach(c.Request)
+
+	// Attach request meta so the service can report captured details to the logger
+	meta := &services.RequestMeta{}
+	c.Request = c.Request.WithContext(services.ContextWithRequestMeta(c.Request.Context(), meta))
+	c.Set(KeyRequestMeta, meta)
 
 	// Process the request with context
-	resp, err, projectID, mode, matched := mockService.HandleRequest(c.Request.Context(), projectAlias, c.Request.Method, path, c.Request)
+	resp, err, projectID, mode, matched := mockService.HandleRequest(c.Request.Context(), projectAlias, c.Request.Method, reqPath, c.Request)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   true,
@@ -61,24 +84,97 @@ func MockRequestHandler(c *gin.Context) {
 	c.Set(KeyMatched, matched)
 	c.Set(KeyPath, path)
 
-	// Copy response headers
-	for key, values := range resp.Header {
-		for _, value := range values {
-			c.Header(key, value)
+	// WebSocket sessions take over the connection once upgraded, and run until it closes
+	if meta.WebSocket != nil {
+		if resp.StatusCode == http.StatusSwitchingProtocols {
+			if err := meta.WebSocket.Serve(c.Writer, c.Request); err != nil {
+				log.Debug().Err(err).Str("path", path).Msg("WebSocket upgrade failed")
+			}
+			return
 		}
+		// A fault replaced the upgrade response
+		meta.WebSocket.Close()
 	}
 
+	// Connection level faults take over writing the response
+	if meta.Fault != nil && writeFaultResponse(c, resp, meta.Fault) {
+		return
+	}
+
+	// Copy response headers
+	copyResponseHeaders(c, resp)
+
 	// Send response with proper status code
 	c.Status(resp.StatusCode)
 
 	// Copy response body
 	if resp.Body != nil {
 		defer resp.Body.Close()
-		// Copy body to response writer
-		if body, err := io.ReadAll(resp.Body); err == nil {
-			c.Writer.Write(body)
+
+		// Streaming and proxied responses are flushed as each chunk arrives, so clients
+		// receive upstream bytes (downloads, SSE, long polling) as the upstream sends them
+		if services.IsStreamingResponse(resp) || services.IsUpstreamResponse(resp) {
+			streamResponseBody(c, resp.Body)
+		} else {
+			// Copy body to response writer without buffering large file bodies
+			io.Copy(c.Writer, resp.Body)
+		}
+	}
+
+	// Trailers, such as the gRPC status, are known once the body is read
+	copyResponseTrailers(c, resp)
+}
+
+// streamResponseBody copies body to the client, flushing after every read
+func streamResponseBody(c *gin.Context, body io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, writeErr := c.Writer.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			c.Writer.Flush()
 		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// copyResponseHeaders copies the headers of resp to the client response
+func copyResponseHeaders(c *gin.Context, resp *http.Response) {
+	for key, values := range resp.Header {
+		for _, value := range values {
+			c.Header(key, value)
+		}
+	}
+}
+
+// copyResponseTrailers sends the trailers of resp after the body
+func copyResponseTrailers(c *gin.Context, resp *http.Response) {
+	for key, values := range resp.Trailer {
+		for _, value := range values {
+			c.Writer.Header().Add(http.TrailerPrefix+key, value)
+		}
+	}
+}
+
+// grpcProjectAlias returns the project of a gRPC call whose path has no alias prefix
+// The project comes from the x-beo-project metadata, or else the subdomain.
+func grpcProjectAlias(req *http.Request) string {
+	if alias := req.Header.Get("X-Beo-Project"); alias != "" {
+		return alias
+	}
+
+	// "/alias/package.Service/Method" already names the project
+	if strings.Count(req.URL.Path, "/") != 2 {
+		return ""
+	}
+	if parts := strings.Split(req.Host, "."); len(parts) > 2 {
+		return parts[0]
 	}
+	return ""
 }
 
 // extractProjectAlias extracts project alias from request (subdomain or path)
@@ -8,6 +8,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 /*
@@ -148,6 +149,8 @@ func UpdateProjectAdvanceConfigHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Project advance config updated successfully",
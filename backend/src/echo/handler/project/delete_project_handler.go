@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // DeleteProjectHandler removes a project
@@ -36,6 +38,11 @@ func DeleteProjectHandler(c *gin.Context) {
 		return
 	}
 
+	// Collections and files are removed by the cascade below; their live items and stored content must go too
+	var collectionIDs, fileIDs []string
+	database.GetDB().Model(&database.MockCollection{}).Where("project_id = ?", project.ID).Pluck("id", &collectionIDs)
+	database.GetDB().Model(&database.MockFile{}).Where("project_id = ?", project.ID).Pluck("id", &fileIDs)
+
 	// Delete the project (GORM will cascade delete related records due to constraints)
 	result = database.GetDB().Delete(&project)
 	if result.Error != nil {
@@ -46,6 +53,16 @@ func DeleteProjectHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(project.ID)
+	for _, collectionID := range collectionIDs {
+		services.ForgetCollection(collectionID)
+	}
+	for _, fileID := range fileIDs {
+		if err := services.RemoveMockFile(fileID); err != nil {
+			log.Error().Err(err).Str("file_id", fileID).Msg("Failed to remove file content")
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Project deleted successfully",
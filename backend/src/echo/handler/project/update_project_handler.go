@@ -7,6 +7,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 /*
@@ -149,6 +150,8 @@ func UpdateProjectHandler(c *gin.Context) {
 		Where("id = ?", existingProject.ID).
 		First(&existingProject)
 
+	services.InvalidateProjectRoutes(existingProject.ID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Project updated successfully",
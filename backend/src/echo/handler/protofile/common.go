@@ -0,0 +1,75 @@
+package protofile
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/services"
+)
+
+// projectProtoFiles loads the proto files of the project in the route params,
+// writing an error response when the project is missing
+func projectProtoFiles(c *gin.Context) (*database.Project, []database.ProtoFile, bool) {
+	projectId := c.Param("projectId")
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID is required",
+		})
+		return nil, nil, false
+	}
+
+	var project database.Project
+	if err := database.GetDB().Where("id = ?", projectId).First(&project).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Project not found",
+		})
+		return nil, nil, false
+	}
+
+	var files []database.ProtoFile
+	if err := database.GetDB().Where("project_id = ?", projectId).Order("name").Find(&files).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to retrieve proto files: " + err.Error(),
+		})
+		return nil, nil, false
+	}
+	return &project, files, true
+}
+
+// createGRPCEndpoints creates a grpc endpoint for every method that has none yet
+// Client and bidirectional streaming methods cannot be mocked and are skipped.
+func createGRPCEndpoints(projectID string, descriptors *services.GRPCDescriptors) ([]database.MockEndpoint, error) {
+	created := []database.MockEndpoint{}
+	for _, method := range descriptors.Methods() {
+		if method.ClientStreaming {
+			continue
+		}
+
+		var count int64
+		database.GetDB().Model(&database.MockEndpoint{}).
+			Where("project_id = ? AND method = ? AND path = ?", projectID, http.MethodPost, method.Path).
+			Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		endpoint := database.MockEndpoint{
+			ProjectID:    projectID,
+			Method:       http.MethodPost,
+			Path:         method.Path,
+			Enabled:      true,
+			ResponseMode: "static",
+			Type:         database.EndpointTypeGRPC,
+		}
+		if err := database.GetDB().Create(&endpoint).Error; err != nil {
+			return created, err
+		}
+		created = append(created, endpoint)
+	}
+	return created, nil
+}
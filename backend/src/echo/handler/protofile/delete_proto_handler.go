@@ -0,0 +1,65 @@
+package protofile
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// DeleteProtoFileHandler deletes a proto file that no other file of the project imports
+// The grpc endpoints of its methods are kept, and answer UNIMPLEMENTED until the methods are defined again.
+//
+// Sample curl:
+// curl -X DELETE "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/protos/{protoId}" -H "Authorization: Bearer {token}"
+func DeleteProtoFileHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	project, files, ok := projectProtoFiles(c)
+	if !ok {
+		return
+	}
+
+	protoId := c.Param("protoId")
+	var file *database.ProtoFile
+	remaining := []database.ProtoFile{}
+	for i := range files {
+		if files[i].ID == protoId {
+			file = &files[i]
+			continue
+		}
+		remaining = append(remaining, files[i])
+	}
+	if file == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Proto file not found",
+		})
+		return
+	}
+
+	if _, err := services.CompileProtoFiles(remaining); err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   true,
+			"message": "The other proto files do not compile without this one: " + err.Error(),
+		})
+		return
+	}
+
+	if err := database.GetDB().Delete(file).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to delete proto file: " + err.Error(),
+		})
+		return
+	}
+	services.InvalidateProjectProtos(project.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Proto file deleted successfully",
+	})
+}
@@ -0,0 +1,40 @@
+package protofile
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// ListProtoFilesHandler lists the proto files of a project together with the gRPC methods they define
+// compile_error is set, and methods is empty, when the files do not compile.
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/protos" -H "Authorization: Bearer {token}"
+func ListProtoFilesHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	_, files, ok := projectProtoFiles(c)
+	if !ok {
+		return
+	}
+
+	data := gin.H{
+		"files":   files,
+		"methods": []services.GRPCMethodInfo{},
+	}
+	descriptors, err := services.CompileProtoFiles(files)
+	if err != nil {
+		data["compile_error"] = err.Error()
+	} else {
+		data["methods"] = descriptors.Methods()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
@@ -0,0 +1,155 @@
+package protofile
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// maxProtoFileBytes limits the size of an uploaded proto file or descriptor set
+const maxProtoFileBytes = 10 << 20
+
+// UploadProtoFileHandler uploads a .proto source or a FileDescriptorSet describing the gRPC services of a project
+// The file is compiled with the other files of the project and rejected when that fails. Uploading a file
+// with the name of an existing one replaces it. Sources are imported by their name, which defaults to the
+// uploaded file name; set the name form field to the import path, e.g. "shop/catalog.proto".
+// With create_endpoints=true, a grpc endpoint is created for every method that has none yet.
+//
+// Sample curl:
+//
+//	curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/protos" \
+//	  -H "Authorization: Bearer {token}" \
+//	  -F "file=@catalog.proto" \
+//	  -F "name=shop/catalog.proto" \
+//	  -F "create_endpoints=true"
+func UploadProtoFileHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	project, files, ok := projectProtoFiles(c)
+	if !ok {
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "A file is required in the \"file\" form field: " + err.Error(),
+		})
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = filepath.Base(header.Filename)
+	}
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if strings.HasPrefix(name, "/") || name == "." || strings.HasPrefix(name, "../") || name == ".." {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid proto file name: " + name,
+		})
+		return
+	}
+
+	if header.Size > maxProtoFileBytes {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Proto files are limited to 10 MB",
+		})
+		return
+	}
+
+	reader, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Failed to read uploaded file: " + err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxProtoFileBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Failed to read uploaded file: " + err.Error(),
+		})
+		return
+	}
+
+	file := database.ProtoFile{
+		ProjectID: project.ID,
+		Name:      name,
+		Kind:      services.ProtoFileKind(name),
+		Content:   content,
+		Size:      int64(len(content)),
+	}
+
+	// Compile the project files with the upload, replacing the file of the same name
+	compiled := []database.ProtoFile{file}
+	for _, existing := range files {
+		if existing.Name == name {
+			file.ID = existing.ID
+			file.CreatedAt = existing.CreatedAt
+			continue
+		}
+		compiled = append(compiled, existing)
+	}
+	descriptors, err := services.CompileProtoFiles(compiled)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid proto file: " + err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusCreated
+	if file.ID != "" {
+		status = http.StatusOK
+		err = database.GetDB().Save(&file).Error
+	} else {
+		err = database.GetDB().Create(&file).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to save proto file: " + err.Error(),
+		})
+		return
+	}
+	services.InvalidateProjectProtos(project.ID)
+
+	endpoints := []database.MockEndpoint{}
+	if c.PostForm("create_endpoints") == "true" {
+		endpoints, err = createGRPCEndpoints(project.ID, descriptors)
+		services.InvalidateProjectRoutes(project.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   true,
+				"message": "Proto file saved, but failed to create endpoints: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(status, gin.H{
+		"success": true,
+		"message": "Proto file uploaded successfully",
+		"data": gin.H{
+			"file":      file,
+			"methods":   descriptors.Methods(),
+			"endpoints": endpoints,
+		},
+	})
+}
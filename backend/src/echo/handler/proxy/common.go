@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+)
+
+// validateProxyTarget checks the URL or pool of a proxy target, its transport settings
+// and its rewrite rules, writing an error response when they are invalid
+func validateProxyTarget(c *gin.Context, target *database.ProxyTarget) bool {
+	if _, err := database.ParseProxyTransport(target.Transport); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid proxy transport: " + err.Error(),
+		})
+		return false
+	}
+
+	if _, err := database.ParseProxyRewrite(target.Rewrite); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid proxy rewrite: " + err.Error(),
+		})
+		return false
+	}
+
+	if !target.IsPool() {
+		if target.URL == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Proxy target URL is required",
+			})
+			return false
+		}
+		return true
+	}
+
+	if err := validateProxyPool(target); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid proxy pool: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// validateProxyPool checks that every pool member is a plain proxy target of the same
+// project, and that the pool is not itself a member of another pool
+func validateProxyPool(target *database.ProxyTarget) error {
+	pool, err := database.ParseProxyPool(target.Pool)
+	if err != nil {
+		return err
+	}
+
+	var targets []database.ProxyTarget
+	if err := database.GetDB().Where("project_id = ?", target.ProjectID).Find(&targets).Error; err != nil {
+		return err
+	}
+	byID := make(map[string]database.ProxyTarget, len(targets))
+	for _, t := range targets {
+		byID[t.ID] = t
+	}
+
+	for _, member := range pool.Members {
+		if target.ID != "" && member.TargetID == target.ID {
+			return fmt.Errorf("pool cannot contain itself")
+		}
+		memberTarget, ok := byID[member.TargetID]
+		if !ok {
+			return fmt.Errorf("proxy target %s not found in project", member.TargetID)
+		}
+		if memberTarget.IsPool() {
+			return fmt.Errorf("proxy target %s is a pool, pools cannot be nested", memberTarget.Label)
+		}
+	}
+
+	if target.ID != "" {
+		if pools := poolsContaining(targets, target.ID); len(pools) > 0 {
+			return fmt.Errorf("proxy target is a member of pool %s, pools cannot be nested", pools[0])
+		}
+	}
+	return nil
+}
+
+// poolsContaining returns the labels of the pools among targets that have targetID as a member
+func poolsContaining(targets []database.ProxyTarget, targetID string) []string {
+	var labels []string
+	for _, target := range targets {
+		pool, err := database.ParseProxyPool(target.Pool)
+		if err != nil || pool == nil {
+			continue
+		}
+		for _, member := range pool.Members {
+			if member.TargetID == targetID {
+				labels = append(labels, target.Label)
+				break
+			}
+		}
+	}
+	return labels
+}
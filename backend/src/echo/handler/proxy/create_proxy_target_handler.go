@@ -7,9 +7,13 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // CreateProxyTargetHandler creates a new proxy target for a project
+// A target with a pool spreads requests over other proxy targets of the project instead of using its URL.
+// The optional transport holds its TLS, timeout, HTTP/2 and outbound proxy settings,
+// and the optional rewrite its path, header, Host, Location and Set-Cookie rewrite rules.
 //
 // Sample curl:
 //
@@ -18,7 +22,17 @@ import (
 //	  -H "Authorization: Bearer {token}" \
 //	  -d '{
 //	    "label": "Production",
-//	    "url": "https://api.example.com"
+//	    "url": "https://api.example.com",
+//	    "transport": "{\"verifyTls\":true,\"connectTimeoutMs\":5000,\"proxyUrl\":\"http://proxy.internal:3128\"}",
+//	    "rewrite": "{\"preserveHost\":true,\"location\":{\"https://api.example.com\":\"\"},\"cookieDomain\":{\"api.example.com\":\"\"}}"
+//	  }'
+//
+//	curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/proxies" \
+//	  -H "Content-Type: application/json" \
+//	  -H "Authorization: Bearer {token}" \
+//	  -d '{
+//	    "label": "Backends",
+//	    "pool": "{\"strategy\":\"weighted\",\"failover\":true,\"members\":[{\"targetId\":\"{proxyId1}\",\"weight\":3},{\"targetId\":\"{proxyId2}\"}]}"
 //	  }'
 func CreateProxyTargetHandler(c *gin.Context) {
 	handler.EnsureMockService()
@@ -62,17 +76,14 @@ func CreateProxyTargetHandler(c *gin.Context) {
 		return
 	}
 
-	if proxyTarget.URL == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   true,
-			"message": "Proxy target URL is required",
-		})
-		return
-	}
-
 	// Assign to project
 	proxyTarget.ProjectID = project.ID
 
+	// A pool needs no URL, it forwards to its members
+	if !validateProxyTarget(c, &proxyTarget) {
+		return
+	}
+
 	// Create proxy target
 	result = database.GetDB().Create(&proxyTarget)
 	if result.Error != nil {
@@ -83,6 +94,8 @@ func CreateProxyTargetHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Proxy target created successfully",
@@ -7,6 +7,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // DeleteProxyTargetHandler deletes a proxy target
@@ -66,6 +67,17 @@ func DeleteProxyTargetHandler(c *gin.Context) {
 		return
 	}
 
+	// Pools forwarding to this target would lose a member
+	var targets []database.ProxyTarget
+	database.GetDB().Where("project_id = ?", project.ID).Find(&targets)
+	if pools := poolsContaining(targets, proxyID); len(pools) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Cannot delete proxy target used by pool " + pools[0] + ". Remove it from the pool first.",
+		})
+		return
+	}
+
 	// Delete the proxy target
 	result = database.GetDB().Delete(&proxyTarget)
 	if result.Error != nil {
@@ -76,6 +88,8 @@ func DeleteProxyTargetHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Proxy target deleted successfully",
@@ -7,6 +7,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // UpdateProxyTargetHandler updates a proxy target
@@ -18,7 +19,10 @@ import (
 //	  -H "Authorization: Bearer {token}" \
 //	  -d '{
 //	    "label": "Staging",
-//	    "url": "https://staging.example.com"
+//	    "url": "https://staging.example.com",
+//	    "transport": "{\"verifyTls\":true,\"serverName\":\"staging.internal\",\"readTimeoutMs\":60000,\"http2\":true}",
+//	    "rewrite": "{\"path\":[{\"prefix\":\"/api\",\"replace\":\"/v2\"}],\"requestHeaders\":{\"set\":{\"X-Env\":\"staging\"},\"remove\":[\"Cookie\"]}}",
+//	    "pool": "{\"strategy\":\"sticky\",\"stickyHeader\":\"X-User-Id\",\"members\":[{\"targetId\":\"{proxyId1}\"},{\"targetId\":\"{proxyId2}\"}]}"
 //	  }'
 func UpdateProxyTargetHandler(c *gin.Context) {
 	handler.EnsureMockService()
@@ -66,8 +70,11 @@ func UpdateProxyTargetHandler(c *gin.Context) {
 
 	// Parse update data
 	var updateData struct {
-		Label string `json:"label"`
-		URL   string `json:"url"`
+		Label     string  `json:"label"`
+		URL       string  `json:"url"`
+		Pool      *string `json:"pool"`      // An empty string turns a pool back into a single target
+		Transport *string `json:"transport"` // An empty string restores the default transport
+		Rewrite   *string `json:"rewrite"`   // An empty string removes the rewrite rules
 	}
 
 	if err := c.ShouldBindJSON(&updateData); err != nil {
@@ -87,6 +94,22 @@ func UpdateProxyTargetHandler(c *gin.Context) {
 		existingProxy.URL = updateData.URL
 	}
 
+	if updateData.Pool != nil {
+		existingProxy.Pool = *updateData.Pool
+	}
+
+	if updateData.Transport != nil {
+		existingProxy.Transport = *updateData.Transport
+	}
+
+	if updateData.Rewrite != nil {
+		existingProxy.Rewrite = *updateData.Rewrite
+	}
+
+	if !validateProxyTarget(c, &existingProxy) {
+		return
+	}
+
 	// Save updates
 	result = database.GetDB().Save(&existingProxy)
 	if result.Error != nil {
@@ -97,6 +120,8 @@ func UpdateProxyTargetHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Proxy target updated successfully",
@@ -0,0 +1,125 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/services"
+)
+
+// validateResponseBody checks the body type of a response and that a referenced file belongs
+// to the project, writing an error response when it is invalid
+func validateResponseBody(c *gin.Context, projectID string, response *database.MockResponse) bool {
+	if err := services.ValidateResponseBody(response); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response body: " + err.Error(),
+		})
+		return false
+	}
+
+	if response.BodyType != services.BodyTypeFile {
+		return true
+	}
+
+	var count int64
+	database.GetDB().Model(&database.MockFile{}).
+		Where("id = ? AND project_id = ?", response.FileID, projectID).
+		Count(&count)
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response body: file not found in this project",
+		})
+		return false
+	}
+	return true
+}
+
+// validateGraphQLResponse checks the GraphQL parts of a response, writing an error response when
+// they are invalid. graphql_errors is only used by graphql endpoints, whose static JSON bodies
+// must be GraphQL responses.
+func validateGraphQLResponse(c *gin.Context, endpoint *database.MockEndpoint, response *database.MockResponse) bool {
+	if response.GraphQLErrors != "" && !endpoint.IsGraphQL() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "graphql_errors is only supported on graphql endpoints",
+		})
+		return false
+	}
+
+	if err := services.ValidateGraphQLErrors(response.GraphQLErrors); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid graphql_errors: " + err.Error(),
+		})
+		return false
+	}
+
+	if endpoint.IsGraphQL() && !response.Stream && (response.BodyType == "" || response.BodyType == services.BodyTypeText) {
+		if err := services.ValidateGraphQLResponseBody(response.Body, response.Templated); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Invalid response body: " + err.Error(),
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// validateWebSocketResponse checks the script of a response of a websocket endpoint,
+// writing an error response when it is invalid
+func validateWebSocketResponse(c *gin.Context, endpoint *database.MockEndpoint, response *database.MockResponse) bool {
+	if !endpoint.IsWebSocket() {
+		return true
+	}
+
+	if err := services.ValidateWebSocketResponse(*response); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid WebSocket script: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// validateGRPCResponse checks a response of a grpc endpoint against the proto files of the project,
+// writing an error response when it is invalid. grpc_status is only used by grpc endpoints.
+func validateGRPCResponse(c *gin.Context, endpoint *database.MockEndpoint, response *database.MockResponse) bool {
+	if !endpoint.IsGRPC() {
+		if response.GRPCStatus != "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "grpc_status is only supported on grpc endpoints",
+			})
+			return false
+		}
+		return true
+	}
+
+	var files []database.ProtoFile
+	if err := database.GetDB().Where("project_id = ?", endpoint.ProjectID).Find(&files).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to retrieve proto files: " + err.Error(),
+		})
+		return false
+	}
+
+	descriptors, err := services.CompileProtoFiles(files)
+	if err == nil {
+		err = services.ValidateGRPCResponse(descriptors, endpoint, *response)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid gRPC response: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
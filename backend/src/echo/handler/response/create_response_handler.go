@@ -7,6 +7,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 /*
@@ -19,11 +20,19 @@ Sample curl:
 	  -d '{
 	    "statusCode": 200,
 	    "body": "{\"message\":\"Hello World\"}",
+	    "body_type": "text",
 	    "headers": "{\"Content-Type\":\"application/json\"}",
 	    "priority": 1,
+	    "weight": 95,
 	    "delayMS": 0,
+	    "latency": "{\"distribution\":\"normal\",\"meanMs\":200,\"stdDevMs\":50}",
 	    "stream": false,
-	    "active": true
+	    "templated": true,
+	    "active": true,
+	    "scenario": "order",
+	    "required_state": "Started",
+	    "new_state": "shipped",
+	    "callbacks": "[{\"url\":\"https://example.com/webhooks\",\"delayMs\":2000,\"retry\":{\"maxAttempts\":3}}]"
 	  }'
 */
 func CreateResponseHandler(c *gin.Context) {
@@ -39,9 +48,10 @@ func CreateResponseHandler(c *gin.Context) {
 	}
 
 	// Check if project exists
+	var project database.Project
 	if err := database.GetDB().
 		Where("id = ?", projectId).
-		First(&database.Project{}).Error; err != nil {
+		First(&project).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   true,
 			"message": "Project not found: " + err.Error(),
@@ -77,6 +87,81 @@ func CreateResponseHandler(c *gin.Context) {
 		response.StatusCode = 200 // Default to 200 OK
 	}
 
+	if response.Weight != nil && *response.Weight < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Weight cannot be negative",
+		})
+		return
+	}
+
+	// Reject invalid body/header templates before saving
+	if response.Templated {
+		if err := services.ValidateResponseTemplate(response.Body, response.Headers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Invalid response template: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	// Streaming responses must define their events in the body
+	if err := services.ValidateStreamResponse(response.Stream, response.Templated, response.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid stream response: " + err.Error(),
+		})
+		return
+	}
+
+	// Binary bodies must decode and files must belong to the project
+	if !validateResponseBody(c, projectId, &response) {
+		return
+	}
+
+	// GraphQL endpoints answer with GraphQL responses
+	if !validateGraphQLResponse(c, &endpoint, &response) {
+		return
+	}
+
+	// WebSocket endpoints play the script held in the body of the responses accepting the connection
+	if !validateWebSocketResponse(c, &endpoint, &response) {
+		return
+	}
+
+	// gRPC endpoints answer with output messages of their method
+	if !validateGRPCResponse(c, &endpoint, &response) {
+		return
+	}
+
+	// Latency distributions are stored as JSON and validated up front
+	if _, err := database.ParseLatencyConfig(response.Latency); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response latency: " + err.Error(),
+		})
+		return
+	}
+
+	// Callbacks are stored as JSON and their templates checked before saving
+	if err := services.ValidateCallbacks(response.Callbacks); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response callbacks: " + err.Error(),
+		})
+		return
+	}
+
+	// Scenario states must be defined in the project advance config
+	if err := services.ValidateResponseScenario(&project, &response); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response scenario: " + err.Error(),
+		})
+		return
+	}
+
 	// Assign to endpoint
 	response.EndpointID = endpointIDStr
 
@@ -90,6 +175,8 @@ func CreateResponseHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Response created successfully",
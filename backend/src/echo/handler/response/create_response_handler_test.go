@@ -0,0 +1,73 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/handler"
+)
+
+func TestCreateResponseHandler_Weight(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	handler.EnsureMockService()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "Weights", Alias: "weights-" + uuid.New().String()[:8]}
+	require.NoError(t, database.GetDB().Create(project).Error)
+	endpoint := &database.MockEndpoint{
+		ID:           uuid.New().String(),
+		ProjectID:    project.ID,
+		Path:         "/flaky",
+		Method:       "GET",
+		ResponseMode: "weighted",
+	}
+	require.NoError(t, database.GetDB().Create(endpoint).Error)
+
+	router := gin.New()
+	router.POST("/api/workspaces/:workspaceID/projects/:projectId/endpoints/:id/responses", CreateResponseHandler)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedWeight int
+	}{
+		{name: "absent weight defaults to 1", body: `{"status_code": 200}`, expectedStatus: http.StatusCreated, expectedWeight: 1},
+		{name: "zero weight is kept", body: `{"status_code": 503, "weight": 0}`, expectedStatus: http.StatusCreated, expectedWeight: 0},
+		{name: "explicit weight", body: `{"status_code": 200, "weight": 95}`, expectedStatus: http.StatusCreated, expectedWeight: 95},
+		{name: "negative weight", body: `{"status_code": 200, "weight": -1}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/workspaces/test-workspace/projects/"+project.ID+"/endpoints/"+endpoint.ID+"/responses", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var created struct {
+				Data database.MockResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+			var stored database.MockResponse
+			require.NoError(t, database.GetDB().Where("id = ?", created.Data.ID).First(&stored).Error)
+			require.NotNil(t, stored.Weight)
+			assert.Equal(t, tt.expectedWeight, *stored.Weight)
+			require.NotNil(t, created.Data.Weight, "the created response reports its weight")
+			assert.Equal(t, tt.expectedWeight, *created.Data.Weight)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // DeleteResponseHandler removes a response
@@ -76,6 +77,8 @@ func DeleteResponseHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Response deleted successfully",
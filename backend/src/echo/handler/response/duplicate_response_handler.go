@@ -8,6 +8,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // DuplicateResponseHandler duplicates an existing response with all its rules
@@ -91,12 +92,24 @@ func DuplicateResponseHandler(c *gin.Context) {
 		EndpointID: originalResponse.EndpointID,
 		StatusCode: originalResponse.StatusCode,
 		Body:       originalResponse.Body,
+		BodyType:   originalResponse.BodyType,
+		FileID:     originalResponse.FileID,
 		Headers:    originalResponse.Headers,
 		Priority:   originalResponse.Priority,
+		Weight:     originalResponse.Weight,
 		DelayMS:    originalResponse.DelayMS,
+		Latency:    originalResponse.Latency,
 		Stream:     originalResponse.Stream,
+		Templated:  originalResponse.Templated,
 		Note:       originalResponse.Note + " (Copy)", // Add "(Copy)" to distinguish
 		Enabled:    originalResponse.Enabled,
+
+		Scenario:      originalResponse.Scenario,
+		RequiredState: originalResponse.RequiredState,
+		NewState:      originalResponse.NewState,
+		GraphQLErrors: originalResponse.GraphQLErrors,
+		Callbacks:     originalResponse.Callbacks,
+		GRPCStatus:    originalResponse.GRPCStatus,
 		// Don't copy Rules here - we'll handle them separately
 	}
 
@@ -129,6 +142,7 @@ func DuplicateResponseHandler(c *gin.Context) {
 			Key:        originalRule.Key,
 			Operator:   originalRule.Operator,
 			Value:      originalRule.Value,
+			Match:      originalRule.Match,
 		}
 
 		if err := tx.Create(&duplicatedRule).Error; err != nil {
@@ -157,6 +171,8 @@ func DuplicateResponseHandler(c *gin.Context) {
 		Where("id = ?", duplicatedResponse.ID).
 		First(&responseWithRules)
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Response duplicated successfully",
@@ -38,6 +38,7 @@ func TestDuplicateResponseHandler(t *testing.T) {
 	require.NoError(t, database.GetDB().Create(endpoint).Error)
 
 	// Create original response with rules
+	weight := 3
 	originalResponse := &database.MockResponse{
 		ID:         uuid.New().String(),
 		EndpointID: endpoint.ID,
@@ -45,6 +46,7 @@ func TestDuplicateResponseHandler(t *testing.T) {
 		Body:       `{"message": "Hello World"}`,
 		Headers:    `{"Content-Type": "application/json"}`,
 		Priority:   1,
+		Weight:     &weight,
 		DelayMS:    100,
 		Stream:     false,
 		Note:       "Original response",
@@ -103,6 +105,7 @@ func TestDuplicateResponseHandler(t *testing.T) {
 		assert.Equal(t, originalResponse.Body, responseData["body"])
 		assert.Equal(t, originalResponse.Headers, responseData["headers"])
 		assert.Equal(t, float64(originalResponse.Priority), responseData["priority"])
+		assert.Equal(t, float64(*originalResponse.Weight), responseData["weight"])
 		assert.Equal(t, float64(originalResponse.DelayMS), responseData["delay_ms"])
 		assert.Equal(t, originalResponse.Stream, responseData["stream"])
 		assert.Equal(t, "Original response (Copy)", responseData["note"]) // Should have "(Copy)" appended
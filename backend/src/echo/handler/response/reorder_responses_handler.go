@@ -9,6 +9,7 @@ import (
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/database/repositories"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 // ReorderResponsesRequest represents the request body for reordering responses
@@ -120,8 +121,10 @@ func ReorderResponsesHandler(c *gin.Context) {
 			"body":        response.Body,
 			"headers":     response.Headers,
 			"priority":    response.Priority,
+			"weight":      response.Weight,
 			"delay_ms":    response.DelayMS,
 			"stream":      response.Stream,
+			"templated":   response.Templated,
 			"enabled":     response.Enabled,
 			"note":        response.Note,
 			"created_at":  response.CreatedAt,
@@ -141,6 +144,8 @@ func ReorderResponsesHandler(c *gin.Context) {
 		jsonResponses = append(jsonResponses, responseMap)
 	}
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"error":   false,
 		"message": "Responses reordered successfully",
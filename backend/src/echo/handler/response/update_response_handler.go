@@ -9,6 +9,7 @@ import (
 
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
 )
 
 /*
@@ -23,6 +24,7 @@ Sample curl:
 	    "body": "{\"message\":\"Resource created\"}",
 	    "headers": "{\"Content-Type\":\"application/json\",\"Location\":\"/api/resources/123\"}",
 	    "priority": 2,
+	    "weight": 5,
 	    "delayMS": 50,
 	    "active": true
 	  }'
@@ -84,14 +86,27 @@ func UpdateResponseHandler(c *gin.Context) {
 	var updateData struct {
 		StatusCode *int    `json:"status_code"`
 		Body       *string `json:"body"`
+		BodyType   *string `json:"body_type"`
+		FileID     *string `json:"file_id"`
 		Headers    *string `json:"headers"` // Allow headers to be null
 		Priority   *int    `json:"priority"`
+		Weight     *int    `json:"weight"`
 		DelayMS    *int    `json:"delay_ms"`
+		Latency    *string `json:"latency"`
 		Stream     *bool   `json:"stream"`
+		Templated  *bool   `json:"templated"`
 		Enabled    *bool   `json:"enabled"`
 		Note       *string `json:"note"`
 		IsFallback *bool   `json:"is_fallback"`
 		RulesLogic *string `json:"rules_logic"`
+
+		Scenario      *string `json:"scenario"`
+		RequiredState *string `json:"required_state"`
+		NewState      *string `json:"new_state"`
+
+		GraphQLErrors *string `json:"graphql_errors"`
+		Callbacks     *string `json:"callbacks"`
+		GRPCStatus    *string `json:"grpc_status"`
 	}
 
 	if err := c.ShouldBindJSON(&updateData); err != nil {
@@ -111,6 +126,14 @@ func UpdateResponseHandler(c *gin.Context) {
 		existingResponse.Body = *updateData.Body
 	}
 
+	if updateData.BodyType != nil {
+		existingResponse.BodyType = *updateData.BodyType
+	}
+
+	if updateData.FileID != nil {
+		existingResponse.FileID = *updateData.FileID
+	}
+
 	if updateData.Headers != nil {
 		// Check if headers are empty
 		var headers map[string]string
@@ -133,14 +156,33 @@ func UpdateResponseHandler(c *gin.Context) {
 		existingResponse.Priority = *updateData.Priority
 	}
 
+	if updateData.Weight != nil {
+		if *updateData.Weight < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Weight cannot be negative",
+			})
+			return
+		}
+		existingResponse.Weight = updateData.Weight
+	}
+
 	if updateData.DelayMS != nil {
 		existingResponse.DelayMS = *updateData.DelayMS
 	}
 
+	if updateData.Latency != nil {
+		existingResponse.Latency = *updateData.Latency
+	}
+
 	if updateData.Stream != nil {
 		existingResponse.Stream = *updateData.Stream
 	}
 
+	if updateData.Templated != nil {
+		existingResponse.Templated = *updateData.Templated
+	}
+
 	if updateData.Enabled != nil {
 		existingResponse.Enabled = *updateData.Enabled
 	}
@@ -157,6 +199,105 @@ func UpdateResponseHandler(c *gin.Context) {
 		existingResponse.RulesLogic = *updateData.RulesLogic
 	}
 
+	if updateData.Scenario != nil {
+		existingResponse.Scenario = *updateData.Scenario
+	}
+
+	if updateData.RequiredState != nil {
+		existingResponse.RequiredState = *updateData.RequiredState
+	}
+
+	if updateData.NewState != nil {
+		existingResponse.NewState = *updateData.NewState
+	}
+
+	if updateData.GraphQLErrors != nil {
+		existingResponse.GraphQLErrors = *updateData.GraphQLErrors
+	}
+
+	if updateData.Callbacks != nil {
+		existingResponse.Callbacks = *updateData.Callbacks
+	}
+
+	if updateData.GRPCStatus != nil {
+		existingResponse.GRPCStatus = *updateData.GRPCStatus
+	}
+
+	// Reject invalid body/header templates before saving
+	if existingResponse.Templated {
+		if err := services.ValidateResponseTemplate(existingResponse.Body, existingResponse.Headers); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"message": "Invalid response template: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	// Streaming responses must define their events in the body
+	if err := services.ValidateStreamResponse(existingResponse.Stream, existingResponse.Templated, existingResponse.Body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid stream response: " + err.Error(),
+		})
+		return
+	}
+
+	// Binary bodies must decode and files must belong to the project
+	if !validateResponseBody(c, projectId, &existingResponse) {
+		return
+	}
+
+	// GraphQL endpoints answer with GraphQL responses
+	if !validateGraphQLResponse(c, &endpoint, &existingResponse) {
+		return
+	}
+
+	// WebSocket endpoints play the script held in the body of the responses accepting the connection
+	if !validateWebSocketResponse(c, &endpoint, &existingResponse) {
+		return
+	}
+
+	// gRPC endpoints answer with output messages of their method
+	if !validateGRPCResponse(c, &endpoint, &existingResponse) {
+		return
+	}
+
+	// Latency distributions are stored as JSON and validated up front
+	if _, err := database.ParseLatencyConfig(existingResponse.Latency); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response latency: " + err.Error(),
+		})
+		return
+	}
+
+	// Callbacks are stored as JSON and their templates checked before saving
+	if err := services.ValidateCallbacks(existingResponse.Callbacks); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response callbacks: " + err.Error(),
+		})
+		return
+	}
+
+	// Scenario states must be defined in the project advance config
+	var project database.Project
+	if err := database.GetDB().Where("id = ?", projectId).First(&project).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Project not found",
+		})
+		return
+	}
+	if err := services.ValidateResponseScenario(&project, &existingResponse); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid response scenario: " + err.Error(),
+		})
+		return
+	}
+
 	// Save updates
 	result = database.GetDB().Save(&existingResponse)
 	if result.Error != nil {
@@ -189,6 +330,8 @@ func UpdateResponseHandler(c *gin.Context) {
 		Where("id = ?", existingResponse.ID).
 		First(&existingResponse)
 
+	services.InvalidateProjectRoutes(projectId)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Response updated successfully",
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -237,13 +238,19 @@ func (h *RuleHandler) CreateRuleHandler(c *gin.Context) {
 	// Create rule through service layer
 	createdRule, err := h.service.CreateRule(&rule)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidRule) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error":   true,
 			"message": "Failed to create rule: " + err.Error(),
 		})
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectID)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Rule created successfully",
@@ -332,13 +339,19 @@ func (h *RuleHandler) UpdateRuleHandler(c *gin.Context) {
 	// Update rule through service layer
 	updatedRule, err := h.service.UpdateRule(ruleID, &updateData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidRule) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error":   true,
 			"message": "Failed to update rule: " + err.Error(),
 		})
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Rule updated successfully",
@@ -416,6 +429,8 @@ func (h *RuleHandler) DeleteRuleHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Rule deleted successfully",
@@ -482,6 +497,8 @@ func (h *RuleHandler) DeleteAllRulesHandler(c *gin.Context) {
 		return
 	}
 
+	services.InvalidateProjectRoutes(projectID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "All rules deleted successfully",
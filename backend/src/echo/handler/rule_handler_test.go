@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+	dbRepositories "beo-echo/backend/src/database/repositories"
+	"beo-echo/backend/src/echo/services"
+)
+
+// ruleTestServer serves the rule API wired like server.go, with a response to add rules to
+// The endpoint has a second response without rules that is served when the rules do not match.
+type ruleTestServer struct {
+	router   *gin.Engine
+	rulesURL string
+	project  *database.Project
+}
+
+func newRuleTestServer(t *testing.T) *ruleTestServer {
+	database.SetupTestEnvironment(t)
+	InitMockService() // Serve mock requests from the test database
+
+	project := &database.Project{ID: uuid.New().String(), Name: "Rules", Alias: "rules-" + uuid.New().String()[:8], Mode: database.ModeMock}
+	require.NoError(t, database.GetDB().Create(project).Error)
+	t.Cleanup(func() { services.InvalidateProjectRoutes(project.ID) })
+	endpoint := &database.MockEndpoint{ID: uuid.New().String(), ProjectID: project.ID, Path: "/orders", Method: "POST", Enabled: true, ResponseMode: "static"}
+	require.NoError(t, database.GetDB().Create(endpoint).Error)
+	response := &database.MockResponse{ID: uuid.New().String(), EndpointID: endpoint.ID, StatusCode: 200, Body: "matched", Priority: 2, Enabled: true}
+	require.NoError(t, database.GetDB().Create(response).Error)
+	fallback := &database.MockResponse{ID: uuid.New().String(), EndpointID: endpoint.ID, StatusCode: 200, Body: "default", Priority: 1, Enabled: true}
+	require.NoError(t, database.GetDB().Create(fallback).Error)
+
+	ruleService := services.NewRuleService(dbRepositories.NewRuleRepository(database.GetDB()), dbRepositories.NewResponseRepository(database.GetDB()))
+	ruleHandler := NewRuleHandler(ruleService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	base := "/api/workspaces/:workspaceID/projects/:projectId/endpoints/:id/responses/:responseId/rules"
+	router.POST(base, ruleHandler.CreateRuleHandler)
+	router.PUT(base+"/:ruleId", ruleHandler.UpdateRuleHandler)
+
+	return &ruleTestServer{
+		router:   router,
+		rulesURL: "/api/workspaces/test-workspace/projects/" + project.ID + "/endpoints/" + endpoint.ID + "/responses/" + response.ID + "/rules",
+		project:  project,
+	}
+}
+
+// mock sends a request to POST /orders of the mock project and returns the body it serves
+// headers are name, value pairs.
+func (s *ruleTestServer) mock(t *testing.T, body string, headers ...string) string {
+	req, _ := http.NewRequest("POST", "http://localhost/"+s.project.Alias+"/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err, _, _, _ := mockService.HandleRequest(context.Background(), s.project.Alias, "POST", "/"+s.project.Alias+"/orders", req)
+	require.NoError(t, err)
+	served, _ := io.ReadAll(resp.Body)
+	return string(served)
+}
+
+// do sends a JSON request and returns the status and the rule in the response data
+func (s *ruleTestServer) do(t *testing.T, method, url, body string) (int, database.MockRule) {
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var result struct {
+		Data database.MockRule `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result), w.Body.String())
+	return w.Code, result.Data
+}
+
+func TestRuleHandler_Operators(t *testing.T) {
+	server := newRuleTestServer(t)
+
+	t.Run("gt", func(t *testing.T) {
+		status, rule := server.do(t, "POST", server.rulesURL, `{"type": "body", "key": "total", "operator": "gt", "value": "100"}`)
+		require.Equal(t, http.StatusCreated, status)
+
+		status, rule = server.do(t, "PUT", server.rulesURL+"/"+rule.ID, `{"operator": "gte", "value": "250"}`)
+		require.Equal(t, http.StatusOK, status)
+
+		var stored database.MockRule
+		require.NoError(t, database.GetDB().Where("id = ?", rule.ID).First(&stored).Error)
+		assert.Equal(t, "gte", stored.Operator)
+		assert.Equal(t, "250", stored.Value)
+	})
+
+	t.Run("contains_ci", func(t *testing.T) {
+		status, rule := server.do(t, "POST", server.rulesURL, `{"type": "header", "key": "Authorization", "operator": "contains_ci", "value": "bearer"}`)
+		require.Equal(t, http.StatusCreated, status)
+
+		status, rule = server.do(t, "PUT", server.rulesURL+"/"+rule.ID, `{"operator": "starts_with_ci", "value": "BEARER "}`)
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "starts_with_ci", rule.Operator)
+	})
+
+	t.Run("invalid rules are rejected with 400", func(t *testing.T) {
+		status, _ := server.do(t, "POST", server.rulesURL, `{"type": "body", "key": "total", "operator": "gt", "value": "lots"}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		status, _ = server.do(t, "POST", server.rulesURL, `{"type": "header", "key": "Accept", "operator": "unknown", "value": "x"}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestRuleHandler_GraphQLRule(t *testing.T) {
+	server := newRuleTestServer(t)
+
+	status, _ := server.do(t, "POST", server.rulesURL, `{"type": "graphql", "key": "operationName", "operator": "equals", "value": "GetOrder"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	assert.Equal(t, "matched", server.mock(t, `{"query": "query GetOrder { order(id: 1) { id } }", "operationName": "GetOrder"}`))
+	assert.Equal(t, "default", server.mock(t, `{"query": "query ListOrders { orders { id } }"}`))
+
+	status, _ = server.do(t, "POST", server.rulesURL, `{"type": "graphql", "key": "operation", "operator": "equals", "value": "x"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestRuleHandler_JWTRule(t *testing.T) {
+	server := newRuleTestServer(t)
+
+	status, _ := server.do(t, "POST", server.rulesURL, `{"type": "jwt", "key": "scope", "operator": "contains", "value": "orders:write"}`)
+	require.Equal(t, http.StatusCreated, status)
+
+	bearer := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+	assert.Equal(t, "matched", server.mock(t, `{}`, "Authorization", bearer(jwt.MapClaims{"sub": "user-1", "scope": "orders:read orders:write"})))
+	assert.Equal(t, "default", server.mock(t, `{}`, "Authorization", bearer(jwt.MapClaims{"sub": "user-1", "scope": "orders:read"})))
+	assert.Equal(t, "default", server.mock(t, `{}`))
+
+	status, _ = server.do(t, "POST", server.rulesURL, `{"type": "jwt", "key": "", "operator": "exists"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,33 @@
+package scenario
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/database"
+)
+
+// findProject loads the project from the route params, writing an error response when it is missing
+func findProject(c *gin.Context) (*database.Project, bool) {
+	projectId := c.Param("projectId")
+	if projectId == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Project ID is required",
+		})
+		return nil, false
+	}
+
+	var project database.Project
+	result := database.GetDB().Where("id = ?", projectId).First(&project)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": "Project not found",
+		})
+		return nil, false
+	}
+
+	return &project, true
+}
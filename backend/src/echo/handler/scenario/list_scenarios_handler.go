@@ -0,0 +1,37 @@
+package scenario
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// ListScenariosHandler lists the scenarios of a project with their current state
+//
+// Sample curl:
+// curl -X GET "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/scenarios" -H "Authorization: Bearer {token}"
+func ListScenariosHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	project, ok := findProject(c)
+	if !ok {
+		return
+	}
+
+	scenarios, err := services.ListScenarios(project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to read scenarios: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    scenarios,
+	})
+}
@@ -0,0 +1,66 @@
+package scenario
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// ResetScenarioHandler returns a scenario to its initial state
+//
+// Sample curl:
+// curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/scenarios/{name}/reset" -H "Authorization: Bearer {token}"
+func ResetScenarioHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	project, ok := findProject(c)
+	if !ok {
+		return
+	}
+
+	status, err := services.ResetScenario(project, c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   true,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scenario reset successfully",
+		"data":    status,
+	})
+}
+
+// ResetAllScenariosHandler returns every scenario of a project to its initial state
+//
+// Sample curl:
+// curl -X POST "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/scenarios/reset" -H "Authorization: Bearer {token}"
+func ResetAllScenariosHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	project, ok := findProject(c)
+	if !ok {
+		return
+	}
+
+	scenarios, err := services.ResetAllScenarios(project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"message": "Failed to reset scenarios: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scenarios reset successfully",
+		"data":    scenarios,
+	})
+}
@@ -0,0 +1,53 @@
+package scenario
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/services"
+)
+
+// SetScenarioStateHandler moves a scenario to a given state
+//
+// Sample curl:
+//
+//	curl -X PUT "http://localhost:8000/api/workspaces/{workspaceID}/projects/{projectId}/scenarios/{name}/state" \
+//	  -H "Content-Type: application/json" \
+//	  -H "Authorization: Bearer {token}" \
+//	  -d '{"state": "shipped"}'
+func SetScenarioStateHandler(c *gin.Context) {
+	handler.EnsureMockService()
+
+	project, ok := findProject(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		State string `json:"state"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	status, err := services.SetScenarioState(project, c.Param("name"), body.State)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Scenario state updated successfully",
+		"data":    status,
+	})
+}
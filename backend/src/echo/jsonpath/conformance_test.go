@@ -0,0 +1,170 @@
+package jsonpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Conformance cases taken from the examples of RFC 9535 and of Goessner's JSONPath
+// article, checking every syntax the package documents. The package differs from the RFC
+// where it follows the older syntax: filters use the parenthesized [?(...)] form, =~ searches
+// instead of matching the whole string like match(), and a slice step of 0 is rejected.
+
+const bookstoreJSON = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	}
+}`
+
+type conformanceCase struct {
+	expr     string
+	expected []interface{}
+	anyOrder bool // The RFC leaves the order of the results open
+}
+
+func runConformance(t *testing.T, document string, tests []conformanceCase) {
+	t.Helper()
+	data := decode(t, document)
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			values, err := Find(data, tt.expr)
+			require.NoError(t, err)
+			if tt.anyOrder {
+				assert.ElementsMatch(t, tt.expected, values)
+			} else {
+				assert.Equal(t, tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestConformance_Bookstore(t *testing.T) {
+	store := decode(t, bookstoreJSON).(map[string]interface{})["store"].(map[string]interface{})
+	books := store["book"].([]interface{})
+	bicycle := store["bicycle"]
+
+	runConformance(t, bookstoreJSON, []conformanceCase{
+		{expr: "$.store.book[*].author", expected: []interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{expr: "$..author", expected: []interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"}},
+		{expr: "$.store.*", expected: []interface{}{books, bicycle}, anyOrder: true},
+		{expr: "$.store..price", expected: []interface{}{399.0, 8.95, 12.99, 8.99, 22.99}, anyOrder: true},
+		{expr: "$..book[2]", expected: []interface{}{books[2]}},
+		{expr: "$..book[2].author", expected: []interface{}{"Herman Melville"}},
+		{expr: "$..book[2].publisher", expected: nil},
+		{expr: "$..book[-1]", expected: []interface{}{books[3]}},
+		{expr: "$..book[0,1]", expected: []interface{}{books[0], books[1]}},
+		{expr: "$..book[:2]", expected: []interface{}{books[0], books[1]}},
+		{expr: "$..book[?(@.isbn)]", expected: []interface{}{books[2], books[3]}},
+		{expr: "$..book[?(@.price<10)]", expected: []interface{}{books[0], books[2]}},
+		{expr: "$..book[?(@.price < $.store.bicycle.price && @.category == 'reference')].title", expected: []interface{}{"Sayings of the Century"}},
+	})
+
+	// $..* selects every member value and array element below the root
+	values, err := Find(decode(t, bookstoreJSON), "$..*")
+	require.NoError(t, err)
+	assert.Len(t, values, 27)
+}
+
+func TestConformance_NameSelectors(t *testing.T) {
+	runConformance(t, `{"o": {"j j": {"k.k": 3}}, "'": {"@": 2}}`, []conformanceCase{
+		{expr: "$.o['j j']", expected: []interface{}{map[string]interface{}{"k.k": 3.0}}},
+		{expr: "$.o['j j']['k.k']", expected: []interface{}{3.0}},
+		{expr: `$.o["j j"]["k.k"]`, expected: []interface{}{3.0}},
+		{expr: `$["'"]["@"]`, expected: []interface{}{2.0}},
+		{expr: `$['\'']['@']`, expected: []interface{}{2.0}},
+		{expr: "$['o','missing']", expected: []interface{}{map[string]interface{}{"j j": map[string]interface{}{"k.k": 3.0}}}},
+	})
+}
+
+func TestConformance_IndexAndSlices(t *testing.T) {
+	runConformance(t, `["a", "b", "c", "d", "e", "f", "g"]`, []conformanceCase{
+		{expr: "$[1]", expected: []interface{}{"b"}},
+		{expr: "$[-2]", expected: []interface{}{"f"}},
+		{expr: "$[7]", expected: nil},
+		{expr: "$[-8]", expected: nil},
+		{expr: "$[0,3]", expected: []interface{}{"a", "d"}},
+		{expr: "$[0,0]", expected: []interface{}{"a", "a"}},
+		{expr: "$[1:3]", expected: []interface{}{"b", "c"}},
+		{expr: "$[5:]", expected: []interface{}{"f", "g"}},
+		{expr: "$[:2]", expected: []interface{}{"a", "b"}},
+		{expr: "$[-2:]", expected: []interface{}{"f", "g"}},
+		{expr: "$[1:5:2]", expected: []interface{}{"b", "d"}},
+		{expr: "$[5:1:-2]", expected: []interface{}{"f", "d"}},
+		{expr: "$[::-1]", expected: []interface{}{"g", "f", "e", "d", "c", "b", "a"}},
+		{expr: "$[::3]", expected: []interface{}{"a", "d", "g"}},
+		{expr: "$[10:]", expected: nil},
+		{expr: "$[3:1]", expected: nil},
+		{expr: "$[*]", expected: []interface{}{"a", "b", "c", "d", "e", "f", "g"}},
+	})
+}
+
+func TestConformance_Filters(t *testing.T) {
+	document := `{
+		"a": [3, 5, 1, 2, 4, 6, {"b": "j"}, {"b": "k"}, {"b": {}}, {"b": "kilo"}],
+		"o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}},
+		"e": "f"
+	}`
+	kilo := map[string]interface{}{"b": "kilo"}
+
+	runConformance(t, document, []conformanceCase{
+		{expr: "$.a[?(@.b == 'kilo')]", expected: []interface{}{kilo}},
+		{expr: "$.a[?(@ > 3.5)]", expected: []interface{}{5.0, 4.0, 6.0}},
+		{expr: "$.a[?(@.b)]", expected: []interface{}{
+			map[string]interface{}{"b": "j"}, map[string]interface{}{"b": "k"},
+			map[string]interface{}{"b": map[string]interface{}{}}, kilo,
+		}},
+		{expr: "$.a[?(@ < 2 || @.b == 'k')]", expected: []interface{}{1.0, map[string]interface{}{"b": "k"}}},
+		{expr: "$.a[?(@.b =~ '[jk]')]", expected: []interface{}{map[string]interface{}{"b": "j"}, map[string]interface{}{"b": "k"}, kilo}},
+		{expr: "$.a[?(@.b =~ /^K/i)]", expected: []interface{}{map[string]interface{}{"b": "k"}, kilo}},
+		{expr: "$.a[?(@ == $.a[0])]", expected: []interface{}{3.0}},
+		{expr: "$.a[?(!(@ > 2))]", expected: []interface{}{1.0, 2.0, map[string]interface{}{"b": "j"}, map[string]interface{}{"b": "k"}, map[string]interface{}{"b": map[string]interface{}{}}, kilo}},
+		{expr: "$.o[?(@ > 1 && @ < 4)]", expected: []interface{}{2.0, 3.0}, anyOrder: true},
+		{expr: "$.o[?(@.u || @.x)]", expected: []interface{}{map[string]interface{}{"u": 6.0}}},
+		// Two missing values compare equal
+		{expr: "$.a[?(@.b == $.x)]", expected: []interface{}{3.0, 5.0, 1.0, 2.0, 4.0, 6.0}},
+		{expr: "$[?(@ == 'f')]", expected: []interface{}{"f"}},
+		{expr: "$.a[?(@ != null && @ >= 5)]", expected: []interface{}{5.0, 6.0}},
+	})
+}
+
+func TestConformance_Descendants(t *testing.T) {
+	document := `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`
+
+	runConformance(t, document, []conformanceCase{
+		{expr: "$..j", expected: []interface{}{1.0, 4.0}, anyOrder: true},
+		{expr: "$..[0]", expected: []interface{}{5.0, map[string]interface{}{"j": 4.0}}, anyOrder: true},
+		{expr: "$.o..*", expected: []interface{}{1.0, 2.0}, anyOrder: true},
+		{expr: "$.a..*", expected: []interface{}{
+			5.0, 3.0,
+			[]interface{}{map[string]interface{}{"j": 4.0}, map[string]interface{}{"k": 6.0}},
+			map[string]interface{}{"j": 4.0}, map[string]interface{}{"k": 6.0}, 4.0, 6.0,
+		}, anyOrder: true},
+		{expr: "$..[?(@.j)]", expected: []interface{}{map[string]interface{}{"j": 4.0}, map[string]interface{}{"j": 1.0, "k": 2.0}}, anyOrder: true},
+	})
+}
+
+func TestConformance_InvalidSyntax(t *testing.T) {
+	for _, expr := range []string{
+		"$.a[1:3",
+		"$[1:2:0]",
+		"$['a'",
+		"$[?(@.a ==)]",
+		"$[?(@.a && )]",
+		"$[?(@.a =~ 'a(')]",
+		"$..",
+		"$.a[?@.b == 1",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Compile(expr)
+			assert.Error(t, err)
+		})
+	}
+}
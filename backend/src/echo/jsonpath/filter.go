@@ -0,0 +1,165 @@
+package jsonpath
+
+import (
+	"reflect"
+	"regexp"
+)
+
+// filterExpr is a boolean expression inside [?( )]
+type filterExpr interface {
+	test(current, root interface{}) bool
+}
+
+type orExpr struct{ left, right filterExpr }
+
+func (e orExpr) test(current, root interface{}) bool {
+	return e.left.test(current, root) || e.right.test(current, root)
+}
+
+type andExpr struct{ left, right filterExpr }
+
+func (e andExpr) test(current, root interface{}) bool {
+	return e.left.test(current, root) && e.right.test(current, root)
+}
+
+type notExpr struct{ expr filterExpr }
+
+func (e notExpr) test(current, root interface{}) bool {
+	return !e.expr.test(current, root)
+}
+
+// existsExpr is a path operand used on its own, true when the path selects something
+type existsExpr struct{ operand pathOperand }
+
+func (e existsExpr) test(current, root interface{}) bool {
+	return len(e.operand.values(current, root)) > 0
+}
+
+// operand is a value in a comparison
+type operand interface {
+	// value returns the operand value and false when a path selects nothing
+	value(current, root interface{}) (interface{}, bool)
+}
+
+type literalOperand struct{ literal interface{} }
+
+func (o literalOperand) value(current, root interface{}) (interface{}, bool) {
+	return o.literal, true
+}
+
+// pathOperand is a path relative to the current element (@) or the root ($)
+type pathOperand struct {
+	fromRoot bool
+	segments []segment
+}
+
+func (o pathOperand) values(current, root interface{}) []interface{} {
+	start := current
+	if o.fromRoot {
+		start = root
+	}
+	return evaluate(o.segments, start, root)
+}
+
+func (o pathOperand) value(current, root interface{}) (interface{}, bool) {
+	values := o.values(current, root)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+type compareExpr struct {
+	left, right operand
+	op          string
+	regex       *regexp.Regexp // Set for =~ with a literal pattern
+}
+
+func (e compareExpr) test(current, root interface{}) bool {
+	left, leftOK := e.left.value(current, root)
+	right, rightOK := e.right.value(current, root)
+
+	switch e.op {
+	case "==":
+		return leftOK == rightOK && (!leftOK || equal(left, right))
+	case "!=":
+		return leftOK != rightOK || (leftOK && !equal(left, right))
+	case "=~":
+		text, ok := left.(string)
+		if !leftOK || !ok || e.regex == nil {
+			return false
+		}
+		return e.regex.MatchString(text)
+	}
+
+	if !leftOK || !rightOK {
+		return false
+	}
+	cmp, ok := compare(left, right)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// equal compares JSON values, numbers by value whatever their Go type
+func equal(left, right interface{}) bool {
+	if l, ok := toNumber(left); ok {
+		r, ok := toNumber(right)
+		return ok && l == r
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// compare orders two numbers or two strings
+func compare(left, right interface{}) (int, bool) {
+	if l, ok := toNumber(left); ok {
+		r, ok := toNumber(right)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		}
+		return 0, true
+	}
+	l, lok := left.(string)
+	r, rok := right.(string)
+	if !lok || !rok {
+		return 0, false
+	}
+	switch {
+	case l < r:
+		return -1, true
+	case l > r:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
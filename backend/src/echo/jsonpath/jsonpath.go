@@ -0,0 +1,281 @@
+// Package jsonpath evaluates JSONPath expressions against decoded JSON values.
+//
+// Supported syntax:
+//
+//	$                     the root value ($ may be omitted: "user.id" is "$.user.id")
+//	.name  ['name']       object member (on arrays a numeric name is an index: "items.0")
+//	[0]  [-1]             array index, negative counts from the end
+//	[0,2]  ['a','b']      unions
+//	[1:3]  [::2]          array slices
+//	*  [*]                every member or element
+//	..name  ..*           recursive descent
+//	[?(@.qty > 1)]        filters with == != < <= > >= =~ && || ! and parentheses
+//
+// Filter operands are @ (the current element) or $ (the root) paths, strings in single or
+// double quotes, numbers, true, false and null. =~ matches a /regex/ (optionally /regex/i)
+// or a quoted pattern. A path operand on its own tests for existence.
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"beo-echo/backend/src/echo/lrucache"
+)
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	expr     string
+	segments []segment
+}
+
+// segment selects children of each node, or of each node and its descendants when descend is set
+type segment struct {
+	descend   bool
+	selectors []selector
+}
+
+// selector appends the values it selects from node to out
+type selector interface {
+	apply(node, root interface{}, out []interface{}) []interface{}
+	definite() bool
+}
+
+// cacheSize bounds the compiled paths kept in memory; expressions also come from
+// request data, such as collection query filters, so the cache must not grow without limit
+const cacheSize = 1024
+
+var cache = lrucache.New[string, *Path](cacheSize)
+
+// Compile parses a JSONPath expression
+// Compiled paths are immutable and the most recently used ones are cached, so compiling
+// the same expression again is cheap.
+func Compile(expr string) (*Path, error) {
+	if cached, ok := cache.Get(expr); ok {
+		return cached, nil
+	}
+
+	p := &parser{src: expr}
+	segments, err := p.parsePath(false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+	}
+	path := &Path{expr: expr, segments: segments}
+	cache.Add(expr, path)
+	return path, nil
+}
+
+// Find returns every value expr selects in data
+func Find(data interface{}, expr string) ([]interface{}, error) {
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.Find(data), nil
+}
+
+// Get returns the first value expr selects in data and whether there was one
+// Invalid expressions select nothing.
+func Get(data interface{}, expr string) (interface{}, bool) {
+	path, err := Compile(expr)
+	if err != nil {
+		return nil, false
+	}
+	values := path.Find(data)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// String returns the source expression
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Definite reports whether the path selects at most one value, i.e. it has no wildcards,
+// unions, slices, filters or recursive descent
+func (p *Path) Definite() bool {
+	for _, seg := range p.segments {
+		if seg.descend || len(seg.selectors) != 1 || !seg.selectors[0].definite() {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns every value the path selects in data, in document order
+// Object members are visited in key order so results are stable.
+func (p *Path) Find(data interface{}) []interface{} {
+	return evaluate(p.segments, data, data)
+}
+
+// evaluate applies segments in turn starting from node
+func evaluate(segments []segment, node, root interface{}) []interface{} {
+	nodes := []interface{}{node}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, n := range nodes {
+			if seg.descend {
+				walk(n, func(v interface{}) {
+					for _, sel := range seg.selectors {
+						next = sel.apply(v, root, next)
+					}
+				})
+				continue
+			}
+			for _, sel := range seg.selectors {
+				next = sel.apply(n, root, next)
+			}
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// walk calls visit for node and every value nested in it
+func walk(node interface{}, visit func(interface{})) {
+	visit(node)
+	for _, child := range children(node) {
+		walk(child, visit)
+	}
+}
+
+// children returns the members of an object in key order or the elements of an array
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			values[i] = v[key]
+		}
+		return values
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+type nameSelector struct{ name string }
+
+func (s nameSelector) apply(node, root interface{}, out []interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if value, ok := v[s.name]; ok {
+			out = append(out, value)
+		}
+	case []interface{}:
+		if index, err := strconv.Atoi(s.name); err == nil {
+			return indexSelector{index}.apply(node, root, out)
+		}
+	}
+	return out
+}
+
+func (nameSelector) definite() bool { return true }
+
+type indexSelector struct{ index int }
+
+func (s indexSelector) apply(node, root interface{}, out []interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok {
+		return out
+	}
+	index := s.index
+	if index < 0 {
+		index += len(arr)
+	}
+	if index >= 0 && index < len(arr) {
+		out = append(out, arr[index])
+	}
+	return out
+}
+
+func (indexSelector) definite() bool { return true }
+
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(node, root interface{}, out []interface{}) []interface{} {
+	return append(out, children(node)...)
+}
+
+func (wildcardSelector) definite() bool { return false }
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(node, root interface{}, out []interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok || s.step == 0 {
+		return out
+	}
+	n := len(arr)
+	normalize := func(i int) int {
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+
+	if s.step > 0 {
+		start, end := 0, n
+		if s.start != nil {
+			start = clamp(normalize(*s.start), 0, n)
+		}
+		if s.end != nil {
+			end = clamp(normalize(*s.end), 0, n)
+		}
+		for i := start; i < end; i += s.step {
+			out = append(out, arr[i])
+		}
+		return out
+	}
+
+	start, end := n-1, -1
+	if s.start != nil {
+		start = clamp(normalize(*s.start), -1, n-1)
+	}
+	if s.end != nil {
+		end = clamp(normalize(*s.end), -1, n-1)
+	}
+	for i := start; i > end; i += s.step {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+func (sliceSelector) definite() bool { return false }
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+type filterSelector struct{ expr filterExpr }
+
+func (s filterSelector) apply(node, root interface{}, out []interface{}) []interface{} {
+	for _, child := range children(node) {
+		if s.expr.test(child, root) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+func (filterSelector) definite() bool { return false }
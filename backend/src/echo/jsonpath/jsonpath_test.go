@@ -0,0 +1,143 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderJSON = `{
+	"id": "ord-1",
+	"customer": {"name": "Jane", "tier": "gold", "address": {"city": "Berlin"}},
+	"items": [
+		{"sku": "A1", "type": "book", "qty": 1, "price": 12.5},
+		{"sku": "B2", "type": "toy", "qty": 3, "price": 4},
+		{"sku": "C3", "type": "book", "qty": 2, "price": 30, "gift": true}
+	],
+	"tags": ["new", "priority"],
+	"user-name": "jdoe"
+}`
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &value))
+	return value
+}
+
+func TestFind(t *testing.T) {
+	order := decode(t, orderJSON)
+
+	tests := []struct {
+		expr     string
+		expected []interface{}
+	}{
+		{expr: "", expected: []interface{}{order}},
+		{expr: "$", expected: []interface{}{order}},
+		{expr: "id", expected: []interface{}{"ord-1"}},
+		{expr: "customer.address.city", expected: []interface{}{"Berlin"}},
+		{expr: "$.customer.name", expected: []interface{}{"Jane"}},
+		{expr: "$['customer']['tier']", expected: []interface{}{"gold"}},
+		{expr: "user-name", expected: []interface{}{"jdoe"}},
+		{expr: "items[0].sku", expected: []interface{}{"A1"}},
+		{expr: "items.1.sku", expected: []interface{}{"B2"}},
+		{expr: "items[-1].sku", expected: []interface{}{"C3"}},
+		{expr: "items[*].qty", expected: []interface{}{1.0, 3.0, 2.0}},
+		{expr: "items.*.sku", expected: []interface{}{"A1", "B2", "C3"}},
+		{expr: "items[0,2].sku", expected: []interface{}{"A1", "C3"}},
+		{expr: "items[1:].sku", expected: []interface{}{"B2", "C3"}},
+		{expr: "items[::-1].sku", expected: []interface{}{"C3", "B2", "A1"}},
+		{expr: "$..city", expected: []interface{}{"Berlin"}},
+		{expr: "$..sku", expected: []interface{}{"A1", "B2", "C3"}},
+		{expr: "customer.*", expected: []interface{}{map[string]interface{}{"city": "Berlin"}, "Jane", "gold"}},
+		{expr: "$.items[?(@.type=='book')].sku", expected: []interface{}{"A1", "C3"}},
+		{expr: `$.items[?(@.type == "toy")].sku`, expected: []interface{}{"B2"}},
+		{expr: "items[?(@.qty > 1 && @.price < 10)].sku", expected: []interface{}{"B2"}},
+		{expr: "items[?(@.qty >= 3 || @.gift)].sku", expected: []interface{}{"B2", "C3"}},
+		{expr: "items[?(!@.gift)].sku", expected: []interface{}{"A1", "B2"}},
+		{expr: "items[?(@.sku =~ /^[ab]/i)].sku", expected: []interface{}{"A1", "B2"}},
+		{expr: "items[?(@.type != 'book')].sku", expected: []interface{}{"B2"}},
+		{expr: "items[?(@.type == $.items[0].type)].sku", expected: []interface{}{"A1", "C3"}},
+		{expr: "tags[?(@ == 'new')]", expected: []interface{}{"new"}},
+		{expr: "missing", expected: nil},
+		{expr: "items[5]", expected: nil},
+		{expr: "id.sub", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			values, err := Find(order, tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		"items[",
+		"items[0",
+		"items[?(@.qty >)]",
+		"items[?(@.sku =~ /[/)]",
+		"items[?(@.a == 'x)]",
+		"items[::0]",
+		"a..",
+		"$x[0]]",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Compile(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompileCacheIsBounded(t *testing.T) {
+	first, err := Compile("customer.name")
+	require.NoError(t, err)
+	again, err := Compile("customer.name")
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+
+	// Keys sent by clients must not grow the cache without limit
+	for i := 0; i < cacheSize*2; i++ {
+		_, err := Compile(fmt.Sprintf("filter%d", i))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, cacheSize, cache.Len())
+}
+
+func TestDefinite(t *testing.T) {
+	for expr, expected := range map[string]bool{
+		"user.id":               true,
+		"items[0].sku":          true,
+		"$['a']":                true,
+		"items[*].sku":          false,
+		"items[0,1]":            false,
+		"items[1:]":             false,
+		"$..sku":                false,
+		"items[?(@.qty > 1)].a": false,
+	} {
+		path, err := Compile(expr)
+		require.NoError(t, err)
+		assert.Equal(t, expected, path.Definite(), expr)
+	}
+}
+
+func TestGet(t *testing.T) {
+	order := decode(t, orderJSON)
+
+	value, ok := Get(order, "items[*].sku")
+	assert.True(t, ok)
+	assert.Equal(t, "A1", value)
+
+	// Explicit null is found
+	value, ok = Get(map[string]interface{}{"a": nil}, "a")
+	assert.True(t, ok)
+	assert.Nil(t, value)
+
+	_, ok = Get(order, "items[")
+	assert.False(t, ok)
+}
@@ -0,0 +1,440 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parser is a recursive descent parser over a JSONPath expression
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// parsePath parses segments until the end of the expression
+// Inside a filter it stops at the first character that cannot continue the path.
+func (p *parser) parsePath(inFilter bool) ([]segment, error) {
+	var segments []segment
+
+	if !inFilter {
+		p.consume("$")
+		// A leading name without "$." is relative to the root: "user.id", "items[0]"
+		if c := p.peek(); !p.eof() && c != '.' && c != '[' {
+			seg, err := p.parseName(inFilter, false)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		}
+	}
+
+	for !p.eof() {
+		switch p.peek() {
+		case '.':
+			p.pos++
+			descend := p.consume(".")
+			if descend && p.peek() == '[' {
+				seg, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				seg.descend = true
+				segments = append(segments, seg)
+				continue
+			}
+			seg, err := p.parseName(inFilter, descend)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		case '[':
+			seg, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		default:
+			if inFilter {
+				return segments, nil
+			}
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+	}
+	return segments, nil
+}
+
+// parseName parses a dot-notation member name or *
+func (p *parser) parseName(inFilter, descend bool) (segment, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if c == '.' || c == '[' {
+			break
+		}
+		if inFilter && strings.IndexByte(" \t)=!<>&|,]", c) >= 0 {
+			break
+		}
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	if name == "" {
+		return segment{}, p.errorf("expected a member name")
+	}
+	if name == "*" {
+		return segment{descend: descend, selectors: []selector{wildcardSelector{}}}, nil
+	}
+	return segment{descend: descend, selectors: []selector{nameSelector{name}}}, nil
+}
+
+// parseBracket parses a [...] segment
+func (p *parser) parseBracket() (segment, error) {
+	p.pos++ // [
+	p.skipSpaces()
+
+	var selectors []selector
+	switch p.peek() {
+	case '?':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return segment{}, err
+		}
+		selectors = append(selectors, filterSelector{expr})
+	case '*':
+		p.pos++
+		selectors = append(selectors, wildcardSelector{})
+	default:
+		for {
+			p.skipSpaces()
+			sel, err := p.parseBracketSelector()
+			if err != nil {
+				return segment{}, err
+			}
+			selectors = append(selectors, sel)
+			p.skipSpaces()
+			if !p.consume(",") {
+				break
+			}
+		}
+	}
+
+	p.skipSpaces()
+	if !p.consume("]") {
+		return segment{}, p.errorf("expected ]")
+	}
+	return segment{selectors: selectors}, nil
+}
+
+// parseBracketSelector parses a quoted name, an index or a slice
+func (p *parser) parseBracketSelector() (selector, error) {
+	if c := p.peek(); c == '\'' || c == '"' {
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector{name}, nil
+	}
+
+	start, hasStart, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.consume(":") {
+		if !hasStart {
+			return nil, p.errorf("expected an index, slice or quoted name")
+		}
+		return indexSelector{start}, nil
+	}
+
+	slice := sliceSelector{step: 1}
+	if hasStart {
+		slice.start = &start
+	}
+	p.skipSpaces()
+	end, hasEnd, err := p.parseOptionalInt()
+	if err != nil {
+		return nil, err
+	}
+	if hasEnd {
+		slice.end = &end
+	}
+	p.skipSpaces()
+	if p.consume(":") {
+		p.skipSpaces()
+		step, hasStep, err := p.parseOptionalInt()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			if step == 0 {
+				return nil, p.errorf("slice step cannot be 0")
+			}
+			slice.step = step
+		}
+	}
+	return slice, nil
+}
+
+func (p *parser) parseOptionalInt() (int, bool, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	value, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid number %q", p.src[start:p.pos])
+	}
+	return value, true, nil
+}
+
+// parseString parses a single or double quoted string, a backslash escapes the next character
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		p.pos++
+		switch c {
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			b.WriteByte(p.peek())
+			p.pos++
+		case quote:
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consume("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+}
+
+func (p *parser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consume("&&") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (filterExpr, error) {
+	p.skipSpaces()
+	if p.peek() == '!' && !strings.HasPrefix(p.src[p.pos:], "!=") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *parser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	op := ""
+	for _, candidate := range comparisonOperators {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		path, ok := left.(pathOperand)
+		if !ok {
+			return nil, p.errorf("expected a comparison operator")
+		}
+		return existsExpr{path}, nil
+	}
+
+	p.skipSpaces()
+	if op == "=~" {
+		regex, err := p.parseRegex()
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr{left: left, right: literalOperand{}, op: op, regex: regex}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return compareExpr{left: left, right: right, op: op}, nil
+}
+
+// parseRegex parses /pattern/flags or a quoted pattern
+func (p *parser) parseRegex() (*regexp.Regexp, error) {
+	var pattern string
+	switch p.peek() {
+	case '/':
+		p.pos++
+		var b strings.Builder
+		closed := false
+		for !p.eof() && !closed {
+			c := p.peek()
+			p.pos++
+			switch {
+			case c == '\\' && p.peek() == '/':
+				b.WriteByte('/')
+				p.pos++
+			case c == '/':
+				closed = true
+			default:
+				b.WriteByte(c)
+			}
+		}
+		if !closed {
+			return nil, p.errorf("unterminated regex")
+		}
+		pattern = b.String()
+		for !p.eof() && p.peek() >= 'a' && p.peek() <= 'z' {
+			if p.peek() != 'i' {
+				return nil, p.errorf("unsupported regex flag %q", p.peek())
+			}
+			pattern = "(?i)" + pattern
+			p.pos++
+		}
+	case '\'', '"':
+		var err error
+		if pattern, err = p.parseString(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.errorf("expected a regex after =~")
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf("invalid regex: %v", err)
+	}
+	return regex, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	p.skipSpaces()
+	c := p.peek()
+	switch {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parsePath(true)
+		if err != nil {
+			return nil, err
+		}
+		return pathOperand{fromRoot: c == '$', segments: segments}, nil
+	case c == '\'' || c == '"':
+		value, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{value}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789.eE+-", p.peek()) >= 0 {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return literalOperand{value}, nil
+	case p.consume("true"):
+		return literalOperand{true}, nil
+	case p.consume("false"):
+		return literalOperand{false}, nil
+	case p.consume("null"):
+		return literalOperand{nil}, nil
+	}
+	if p.eof() {
+		return nil, errors.New("unexpected end of filter")
+	}
+	return nil, p.errorf("unexpected %q in filter", c)
+}
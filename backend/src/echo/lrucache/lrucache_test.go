@@ -0,0 +1,51 @@
+package lrucache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	cache := New[string, int](2)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	// Reading a marks it as recently used, so adding c evicts b
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	cache.Add("c", 3)
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	value, _ = cache.Get("c")
+	assert.Equal(t, 3, value)
+	assert.Equal(t, 2, cache.Len())
+
+	// Adding an existing key replaces its value without growing the cache
+	cache.Add("a", 10)
+	value, _ = cache.Get("a")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	cache := New[string, int](16)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := fmt.Sprintf("%d-%d", worker, i%32)
+				cache.Add(key, i)
+				cache.Get(key)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	assert.Equal(t, 16, cache.Len())
+}
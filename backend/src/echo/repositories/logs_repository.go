@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"beo-echo/backend/src/database"
+	"encoding/json"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -18,8 +20,13 @@ func NewLogRepository(db *gorm.DB) *LogRepository {
 	}
 }
 
+// LogFilter narrows the logs returned by GetLogs
+type LogFilter struct {
+	PathParams map[string]string // Captured path params that must all match exactly
+}
+
 // GetLogs retrieves logs with pagination
-func (r *LogRepository) GetLogs(page, pageSize int, projectID string) ([]database.RequestLog, int64, error) {
+func (r *LogRepository) GetLogs(page, pageSize int, projectID string, filter LogFilter) ([]database.RequestLog, int64, error) {
 	var logs []database.RequestLog
 	var total int64
 
@@ -30,6 +37,16 @@ func (r *LogRepository) GetLogs(page, pageSize int, projectID string) ([]databas
 		query = query.Where("project_id = ?", projectID)
 	}
 
+	// Path params are stored as a JSON object, so match on the encoded "key":"value" pair
+	for key, value := range filter.PathParams {
+		pair, err := json.Marshal(map[string]string{key: value})
+		if err != nil {
+			return nil, 0, err
+		}
+		pattern := strings.TrimSuffix(strings.TrimPrefix(string(pair), "{"), "}")
+		query = query.Where(`path_params LIKE ? ESCAPE '\'`, "%"+escapeLike(pattern)+"%")
+	}
+
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -44,6 +61,14 @@ func (r *LogRepository) GetLogs(page, pageSize int, projectID string) ([]databas
 	return logs, total, nil
 }
 
+// likeEscaper escapes the LIKE wildcards of a value, for patterns using ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes a value match itself literally in a LIKE pattern
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
 // GetLatestLogs retrieves the most recent logs up to a limit
 func (r *LogRepository) GetLatestLogs(limit int, projectID string) ([]database.RequestLog, error) {
 	var logs []database.RequestLog
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestGetLogs_PathParamsFilter(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	db := database.GetDB()
+	repo := NewLogRepository(db)
+
+	projectID := uuid.New().String()
+	for _, params := range []string{
+		`{"id":"a_b"}`,
+		`{"id":"axb"}`,
+		`{"id":"50%"}`,
+		`{"id":"500"}`,
+		`{"id":"a\\b"}`,
+		`{"file":"x","id":"42"}`,
+		`{"uid":"42"}`,
+	} {
+		require.NoError(t, db.Create(&database.RequestLog{ID: uuid.New().String(), ProjectID: projectID, PathParams: params}).Error)
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		expected []string
+	}{
+		{name: "underscore is literal", params: map[string]string{"id": "a_b"}, expected: []string{`{"id":"a_b"}`}},
+		{name: "percent is literal", params: map[string]string{"id": "50%"}, expected: []string{`{"id":"50%"}`}},
+		{name: "backslash is literal", params: map[string]string{"id": `a\b`}, expected: []string{`{"id":"a\\b"}`}},
+		{name: "key is not a suffix of another key", params: map[string]string{"id": "42"}, expected: []string{`{"file":"x","id":"42"}`}},
+		{name: "every param must match", params: map[string]string{"id": "42", "file": "y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, total, err := repo.GetLogs(1, 50, projectID, LogFilter{PathParams: tt.params})
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.expected)), total)
+			var found []string
+			for _, log := range logs {
+				found = append(found, log.PathParams)
+			}
+			assert.ElementsMatch(t, tt.expected, found)
+		})
+	}
+}
@@ -26,40 +26,36 @@ func NewMockRepository(db *gorm.DB) *MockRepository {
 // FindProjectByAlias finds a project by its alias (slug/subdomain)
 func (r *MockRepository) FindProjectByAlias(alias string) (*database.Project, error) {
 	var project database.Project
-	result := r.DB.Preload("ActiveProxy").Where("alias = ?", alias).First(&project)
+	result := r.DB.Preload("ActiveProxy").Preload("ProxyTargets").Where("alias = ?", alias).First(&project)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &project, nil
 }
 
-// FindMatchingEndpoint finds an endpoint that matches the given method and path
-func (r *MockRepository) FindMatchingEndpoint(projectID string, method, path string) (*database.MockEndpoint, error) {
-	var endpoints []database.MockEndpoint
+// EndpointMatch is the result of matching a request path against the project's endpoints
+// It carries the matched endpoint together with the values captured from the path.
+type EndpointMatch struct {
+	*database.MockEndpoint
+	PathParams  map[string]string // Named captures from ":name" segments and "(?P<name>...)" regex groups
+	Wildcards   []string          // Values matched by "*" segments, in order
+	RegexGroups []string          // All regex capture groups (named and unnamed), in order
+}
 
-	// Preload ProxyTarget for endpoints that use proxy
-	result := r.DB.Preload("ProxyTarget").Where("project_id = ? AND method = ? AND enabled = ?", projectID, strings.ToUpper(method), true).Find(&endpoints)
-	if result.Error != nil {
-		return nil, result.Error
+// Params returns every captured value keyed for use in rules, templates and logs
+// Named params keep their name, wildcards are keyed "*0", "*1"... and regex groups "$1", "$2"...
+func (m *EndpointMatch) Params() map[string]string {
+	params := make(map[string]string, len(m.PathParams)+len(m.Wildcards)+len(m.RegexGroups))
+	for i, value := range m.Wildcards {
+		params[fmt.Sprintf("*%d", i)] = value
 	}
-
-	// Find best matching path (handle path params like /users/:id)
-	bestMatch := findBestPathMatch(endpoints, path)
-	if bestMatch == nil {
-		return nil, fmt.Errorf("no matching endpoint found")
+	for i, value := range m.RegexGroups {
+		params[fmt.Sprintf("$%d", i+1)] = value
 	}
-
-	return bestMatch, nil
-}
-
-// FindResponsesByEndpointID gets all responses for an endpoint
-func (r *MockRepository) FindResponsesByEndpointID(endpointID string) ([]database.MockResponse, error) {
-	var responses []database.MockResponse
-	result := r.DB.Preload("Rules").Where("endpoint_id = ? AND enabled = ?", endpointID, true).Find(&responses)
-	if result.Error != nil {
-		return nil, result.Error
+	for name, value := range m.PathParams {
+		params[name] = value
 	}
-	return responses, nil
+	return params
 }
 
 // FindProxyTarget gets a proxy target by ID
@@ -72,63 +68,38 @@ func (r *MockRepository) GetProxyTarget(proxyTargetID string) (*database.ProxyTa
 	return &proxyTarget, nil
 }
 
-// Helper functions
-
-// findBestPathMatch finds the best matching endpoint from a list of endpoints
-// Supporting various path patterns:
-// 1. Exact match: /users/123
-// 2. Path parameters: /users/:id
-// 3. Wildcard: /api/v2/customer_rooms/*/broadcast_history
-// 4. Regex: /api/v\d+/users/\d+
-func findBestPathMatch(endpoints []database.MockEndpoint, requestPath string) *database.MockEndpoint {
-	var bestMatch *database.MockEndpoint
-	bestScore := -1
-
-	for i := range endpoints {
-		endpoint := &endpoints[i]
-		
-		if score := calculatePathMatchScore(endpoint.Path, requestPath); score > bestScore {
-			bestScore = score
-			bestMatch = endpoint
-		}
+// FindCollectionByName gets a project collection by its name
+func (r *MockRepository) FindCollectionByName(projectID, name string) (*database.MockCollection, error) {
+	var collection database.MockCollection
+	result := r.DB.Where("project_id = ? AND name = ?", projectID, name).First(&collection)
+	if result.Error != nil {
+		return nil, result.Error
 	}
+	return &collection, nil
+}
 
-	// If we have a match
-	if bestScore >= 0 {
-		return bestMatch
+// FindFileByID gets an uploaded mock file by ID
+func (r *MockRepository) FindFileByID(fileID string) (*database.MockFile, error) {
+	var file database.MockFile
+	result := r.DB.Where("id = ?", fileID).First(&file)
+	if result.Error != nil {
+		return nil, result.Error
 	}
-
-	return nil
+	return &file, nil
 }
 
-// calculatePathMatchScore calculates how well an endpoint path matches a request path
-// Higher score means better match. Supports different pattern types:
-// - Exact match (highest score: 100)
-// - Path parameters with : (score: 80)
-// - Wildcard patterns with * (score: 60)
-// - Regex patterns (score: 40)
-func calculatePathMatchScore(endpointPath, requestPath string) int {
-	// Clean paths
-	endpointPath = strings.Trim(endpointPath, "/")
-	requestPath = strings.Trim(requestPath, "/")
-	
-	// Exact match gets highest priority
-	if endpointPath == requestPath {
-		return 100
-	}
-	
-	// Check for regex pattern (contains regex metacharacters)
-	if isRegexPattern(endpointPath) {
-		if matchesRegex(endpointPath, requestPath) {
-			return 40
-		}
-		return -1
+// FindProtoFiles gets the proto files uploaded to a project
+func (r *MockRepository) FindProtoFiles(projectID string) ([]database.ProtoFile, error) {
+	var files []database.ProtoFile
+	result := r.DB.Where("project_id = ?", projectID).Order("name").Find(&files)
+	if result.Error != nil {
+		return nil, result.Error
 	}
-	
-	// Check for wildcard or path parameter patterns
-	return calculateSegmentMatchScore(endpointPath, requestPath)
+	return files, nil
 }
 
+// Helper functions
+
 // isRegexPattern checks if a path contains regex metacharacters
 func isRegexPattern(path string) bool {
 	// Common regex metacharacters that indicate it's a regex pattern
@@ -141,65 +112,73 @@ func isRegexPattern(path string) bool {
 	return false
 }
 
-// matchesRegex tests if request path matches the regex pattern
-func matchesRegex(pattern, requestPath string) bool {
-	// Compile and test regex
-	regex, err := regexp.Compile("^" + pattern + "$")
-	if err != nil {
+// captureCompiledRegex matches the request path against a compiled pattern and records its capture groups
+func captureCompiledRegex(regex *regexp.Regexp, requestPath string, match *EndpointMatch) bool {
+	groups := regex.FindStringSubmatch(requestPath)
+	if groups == nil {
 		return false
 	}
-	return regex.MatchString(requestPath)
+
+	names := regex.SubexpNames()
+	for i := 1; i < len(groups); i++ {
+		match.RegexGroups = append(match.RegexGroups, groups[i])
+		if names[i] != "" {
+			match.PathParams[names[i]] = groups[i]
+		}
+	}
+
+	return true
 }
 
-// calculateSegmentMatchScore handles path parameters and wildcard matching
-func calculateSegmentMatchScore(endpointPath, requestPath string) int {
-	endpointParts := strings.Split(endpointPath, "/")
-	requestParts := strings.Split(requestPath, "/")
-	
+// scoreSegments handles path parameters and wildcard matching on split paths
+// Captured parameter and wildcard values are recorded on match.
+func scoreSegments(endpointParts, requestParts []string, match *EndpointMatch) int {
 	// If lengths don't match, this can't be a match
 	if len(endpointParts) != len(requestParts) {
 		return -1
 	}
-	
+
 	score := 0
 	wildcardCount := 0
 	paramCount := 0
-	
+
 	for i := 0; i < len(endpointParts); i++ {
 		endpointPart := endpointParts[i]
 		requestPart := requestParts[i]
-		
+
 		// Exact match of path part (highest score)
 		if endpointPart == requestPart {
 			score += 10
 			continue
 		}
-		
+
 		// Path parameter (starts with :)
 		if strings.HasPrefix(endpointPart, ":") {
 			score += 8
 			paramCount++
+			match.PathParams[strings.TrimPrefix(endpointPart, ":")] = requestPart
 			continue
 		}
-		
+
 		// Wildcard match (*)
 		if endpointPart == "*" {
 			score += 6
 			wildcardCount++
+			match.Wildcards = append(match.Wildcards, requestPart)
 			continue
 		}
-		
+
 		// Not a match
 		return -1
 	}
-	
+
 	// Calculate final score based on match type
 	if wildcardCount > 0 {
 		return 60 + score // Wildcard patterns
 	} else if paramCount > 0 {
 		return 80 + score // Path parameter patterns
 	}
-	
+
 	return score // Should not reach here for valid matches
 }
 
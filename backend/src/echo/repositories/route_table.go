@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"beo-echo/backend/src/database"
+)
+
+// RouteTable is a compiled, read-only snapshot of everything needed to route a project's requests
+// Endpoint paths are indexed in a segment trie per method and regex paths are compiled once,
+// so matching a request needs no database query and no regex compilation.
+type RouteTable struct {
+	Project   *database.Project
+	methods   map[string]*methodRoutes
+	responses map[string][]database.MockResponse
+}
+
+// methodRoutes holds the compiled endpoints of one HTTP method
+type methodRoutes struct {
+	root  *routeNode
+	regex []*compiledRoute
+}
+
+// routeNode is a trie node keyed by endpoint path segments
+type routeNode struct {
+	children map[string]*routeNode // Every segment by its literal value, parameters included
+	params   []*routeNode          // Children whose segment is a ":name" parameter
+	wildcard *routeNode            // Child for a "*" segment
+	routes   []*compiledRoute      // Endpoints whose path ends at this node
+	segment  string
+}
+
+// compiledRoute is an endpoint path prepared for matching
+type compiledRoute struct {
+	endpoint *database.MockEndpoint
+	order    int // Position in the endpoint list, earlier endpoints win ties
+	path     string
+	parts    []string
+	isRegex  bool
+	regex    *regexp.Regexp // nil when the regex path does not compile, such paths only match exactly
+}
+
+// LoadRouteTable loads a project by alias with its enabled endpoints and responses and compiles them
+func (r *MockRepository) LoadRouteTable(alias string) (*RouteTable, error) {
+	project, err := r.FindProjectByAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []database.MockEndpoint
+	result := r.DB.Preload("ProxyTarget").Where("project_id = ? AND enabled = ?", project.ID, true).Find(&endpoints)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	endpointIDs := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpointIDs = append(endpointIDs, endpoint.ID)
+	}
+
+	var responses []database.MockResponse
+	if len(endpointIDs) > 0 {
+		result = r.DB.Preload("Rules").Where("endpoint_id IN ? AND enabled = ?", endpointIDs, true).Find(&responses)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+	}
+
+	return NewRouteTable(project, endpoints, responses), nil
+}
+
+// NewRouteTable compiles the given endpoints and responses of a project
+func NewRouteTable(project *database.Project, endpoints []database.MockEndpoint, responses []database.MockResponse) *RouteTable {
+	table := &RouteTable{
+		Project:   project,
+		methods:   map[string]*methodRoutes{},
+		responses: map[string][]database.MockResponse{},
+	}
+
+	for i := range endpoints {
+		method := strings.ToUpper(endpoints[i].Method)
+		routes, ok := table.methods[method]
+		if !ok {
+			routes = &methodRoutes{root: newRouteNode("")}
+			table.methods[method] = routes
+		}
+		routes.add(compileRoute(&endpoints[i], i))
+	}
+
+	for _, response := range responses {
+		table.responses[response.EndpointID] = append(table.responses[response.EndpointID], response)
+	}
+
+	return table
+}
+
+// Match finds the best endpoint for a method and path
+// Endpoint paths may be exact (/users/123), have parameters (/users/:id), wildcards
+// (/api/v2/customer_rooms/*/broadcast_history) or be regular expressions (/api/v\d+/users/\d+).
+// The highest scoring path wins, see compiledRoute.match. The returned endpoint is a copy,
+// so callers may keep it beyond the life of the table.
+func (t *RouteTable) Match(method, path string) (*EndpointMatch, error) {
+	routes, ok := t.methods[strings.ToUpper(method)]
+	if !ok {
+		return nil, fmt.Errorf("no matching endpoint found")
+	}
+
+	requestPath := strings.Trim(path, "/")
+	requestParts := strings.Split(requestPath, "/")
+
+	var best *EndpointMatch
+	var bestRoute *compiledRoute
+	bestScore := -1
+	consider := func(route *compiledRoute) {
+		score, match := route.match(requestPath, requestParts)
+		if score < 0 {
+			return
+		}
+		if score > bestScore || (score == bestScore && route.order < bestRoute.order) {
+			best, bestRoute, bestScore = match, route, score
+		}
+	}
+
+	routes.root.collect(requestParts, 0, consider)
+	for _, route := range routes.regex {
+		consider(route)
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no matching endpoint found")
+	}
+
+	endpoint := *bestRoute.endpoint
+	best.MockEndpoint = &endpoint
+	return best, nil
+}
+
+// Responses returns the enabled responses of an endpoint with their rules
+// The slice is a copy, callers may reorder it freely.
+func (t *RouteTable) Responses(endpointID string) []database.MockResponse {
+	responses := t.responses[endpointID]
+	return append(make([]database.MockResponse, 0, len(responses)), responses...)
+}
+
+func newRouteNode(segment string) *routeNode {
+	return &routeNode{children: map[string]*routeNode{}, segment: segment}
+}
+
+// add indexes a compiled route, regex paths are kept aside and tried one by one
+func (m *methodRoutes) add(route *compiledRoute) {
+	if route.isRegex {
+		m.regex = append(m.regex, route)
+		return
+	}
+
+	node := m.root
+	for _, part := range route.parts {
+		child, ok := node.children[part]
+		if !ok {
+			child = newRouteNode(part)
+			node.children[part] = child
+			switch {
+			case part == "*":
+				node.wildcard = child
+			case strings.HasPrefix(part, ":"):
+				node.params = append(node.params, child)
+			}
+		}
+		node = child
+	}
+	node.routes = append(node.routes, route)
+}
+
+// collect visits every route whose segments can match the request parts
+// A literal segment is tried first, then parameters and the wildcard, in the order scoreSegments scores them.
+func (n *routeNode) collect(parts []string, depth int, visit func(*compiledRoute)) {
+	if depth == len(parts) {
+		for _, route := range n.routes {
+			visit(route)
+		}
+		return
+	}
+
+	part := parts[depth]
+	if child, ok := n.children[part]; ok {
+		child.collect(parts, depth+1, visit)
+	}
+	for _, child := range n.params {
+		if child.segment != part {
+			child.collect(parts, depth+1, visit)
+		}
+	}
+	if n.wildcard != nil && part != "*" {
+		n.wildcard.collect(parts, depth+1, visit)
+	}
+}
+
+// compileRoute prepares an endpoint path for matching, compiling regex paths once
+func compileRoute(endpoint *database.MockEndpoint, order int) *compiledRoute {
+	route := &compiledRoute{endpoint: endpoint, order: order}
+	if endpoint != nil {
+		route.setPath(endpoint.Path)
+	}
+	return route
+}
+
+func (r *compiledRoute) setPath(endpointPath string) {
+	r.path = strings.Trim(endpointPath, "/")
+	r.parts = strings.Split(r.path, "/")
+	r.isRegex = isRegexPattern(r.path)
+	if r.isRegex {
+		r.regex, _ = regexp.Compile("^" + r.path + "$")
+	}
+}
+
+// match scores the route against a trimmed request path and captures the matched values
+// Higher scores are better matches: exact paths score 100, paths with parameters 80 and
+// wildcard paths 60, plus 10 per literal segment, 8 per parameter and 6 per wildcard, and
+// regex paths 40. A negative score means no match; the returned match has no endpoint set.
+func (r *compiledRoute) match(requestPath string, requestParts []string) (int, *EndpointMatch) {
+	match := &EndpointMatch{PathParams: map[string]string{}}
+
+	// Exact match gets highest priority
+	if r.path == requestPath {
+		return 100, match
+	}
+
+	if r.isRegex {
+		if r.regex != nil && captureCompiledRegex(r.regex, requestPath, match) {
+			return 40, match
+		}
+		return -1, match
+	}
+
+	// Check for wildcard or path parameter patterns
+	return scoreSegments(r.parts, requestParts, match), match
+}
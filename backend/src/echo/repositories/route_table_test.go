@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+// routeTestEndpoints mixes every kind of path pattern, including ones that compete for the same request
+var routeTestEndpoints = []database.MockEndpoint{
+	{ID: "users", Method: "GET", Path: "/users"},
+	{ID: "user", Method: "GET", Path: "/users/:id"},
+	{ID: "user-alt", Method: "GET", Path: "/users/:userId"},
+	{ID: "me", Method: "GET", Path: "/users/me"},
+	{ID: "user-posts", Method: "GET", Path: "/users/:id/posts/:postId"},
+	{ID: "broadcast", Method: "GET", Path: "/api/v2/customer_rooms/*/broadcast_history"},
+	{ID: "any-room", Method: "GET", Path: "/api/v2/customer_rooms/*/*"},
+	{ID: "regex", Method: "GET", Path: `/api/v\d+/orders/(?P<orderId>\d+)`},
+	{ID: "bad-regex", Method: "GET", Path: "/broken/(unclosed"},
+	{ID: "root", Method: "GET", Path: "/"},
+	{ID: "literal-param", Method: "GET", Path: "/files/:name"},
+	{ID: "create-user", Method: "POST", Path: "/users"},
+}
+
+func TestRouteTableMatchPatterns(t *testing.T) {
+	table := NewRouteTable(&database.Project{ID: "p"}, routeTestEndpoints, nil)
+
+	tests := []struct {
+		path       string
+		expectedID string // Empty when no endpoint matches
+		params     map[string]string
+	}{
+		{path: "/users", expectedID: "users", params: map[string]string{}},
+		{path: "/users/", expectedID: "users", params: map[string]string{}},
+		{path: "/users/42", expectedID: "user", params: map[string]string{"id": "42"}},
+		{path: "/users/me", expectedID: "me", params: map[string]string{}},
+		{path: "/users/42/posts/7", expectedID: "user-posts", params: map[string]string{"id": "42", "postId": "7"}},
+		{path: "/users/42/posts"},
+		{path: "/api/v2/customer_rooms/9/broadcast_history", expectedID: "broadcast", params: map[string]string{"*0": "9"}},
+		{path: "/api/v2/customer_rooms/9/members", expectedID: "any-room", params: map[string]string{"*0": "9", "*1": "members"}},
+		{path: "/api/v3/orders/15", expectedID: "regex", params: map[string]string{"$1": "15", "orderId": "15"}},
+		{path: "/api/vx/orders/15"},
+		{path: "/broken/(unclosed", expectedID: "bad-regex", params: map[string]string{}},
+		{path: "/", expectedID: "root", params: map[string]string{}},
+		{path: "", expectedID: "root", params: map[string]string{}},
+		{path: "/files/:name", expectedID: "literal-param", params: map[string]string{}},
+		{path: "/files/report.pdf", expectedID: "literal-param", params: map[string]string{"name": "report.pdf"}},
+		{path: "/unknown"},
+		{path: "/users//posts/1", expectedID: "user-posts", params: map[string]string{"id": "", "postId": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			match, err := table.Match("get", tt.path)
+			if tt.expectedID == "" {
+				assert.Error(t, err)
+				assert.Nil(t, match)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, match.ID)
+			assert.Equal(t, tt.params, match.Params())
+		})
+	}
+}
+
+// routeScore scores a single endpoint path against a request path
+func routeScore(endpointPath, requestPath string) int {
+	route := compileRoute(&database.MockEndpoint{Path: endpointPath}, 0)
+	requestPath = strings.Trim(requestPath, "/")
+	score, _ := route.match(requestPath, strings.Split(requestPath, "/"))
+	return score
+}
+
+func TestCompiledRouteScore(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpointPath  string
+		requestPath   string
+		expectedScore int
+	}{
+		// Exact matches
+		{name: "exact match simple", endpointPath: "/users", requestPath: "/users", expectedScore: 100},
+		{name: "exact match complex", endpointPath: "/api/v2/customer_rooms/broadcast_history", requestPath: "/api/v2/customer_rooms/broadcast_history", expectedScore: 100},
+
+		// Wildcard patterns: 60 plus 10 per literal segment and 6 per wildcard
+		{name: "wildcard single segment", endpointPath: "/api/v2/customer_rooms/*/broadcast_history", requestPath: "/api/v2/customer_rooms/331931307/broadcast_history", expectedScore: 106},
+		{name: "wildcard string value", endpointPath: "/api/v2/customer_rooms/*/broadcast_history", requestPath: "/api/v2/customer_rooms/salam/broadcast_history", expectedScore: 106},
+		{name: "multiple wildcards", endpointPath: "/api/*/users/*/profile", requestPath: "/api/v2/users/123/profile", expectedScore: 102},
+
+		// Path parameters: 80 plus 10 per literal segment and 8 per parameter
+		{name: "path parameter", endpointPath: "/users/:id", requestPath: "/users/123", expectedScore: 98},
+		{name: "mixed exact and parameters", endpointPath: "/api/v2/users/:id/settings", requestPath: "/api/v2/users/123/settings", expectedScore: 128},
+
+		// Regex patterns
+		{name: "regex numeric pattern", endpointPath: `/api/v\d+/users/\d+`, requestPath: "/api/v2/users/123", expectedScore: 40},
+		{name: "regex complex pattern", endpointPath: `/api/v[12]/customer_rooms/\w+/broadcast_history`, requestPath: "/api/v2/customer_rooms/salam/broadcast_history", expectedScore: 40},
+		{name: "regex word pattern", endpointPath: `/users/\w+/profile`, requestPath: "/users/john123/profile", expectedScore: 40},
+		{name: "regex character class", endpointPath: "/api/v[123]/users", requestPath: "/api/v2/users", expectedScore: 40},
+
+		// No matches
+		{name: "no match different paths", endpointPath: "/users", requestPath: "/posts", expectedScore: -1},
+		{name: "no match different lengths", endpointPath: "/users/123", requestPath: "/users", expectedScore: -1},
+		{name: "regex no match", endpointPath: `/api/v\d+/users`, requestPath: "/api/vX/users", expectedScore: -1},
+		{name: "regex character class no match", endpointPath: "/api/v[123]/users", requestPath: "/api/v4/users", expectedScore: -1},
+		{name: "invalid regex does not match", endpointPath: "/api/v[/users", requestPath: "/api/v2/users", expectedScore: -1},
+
+		// Edge cases
+		{name: "empty paths", endpointPath: "", requestPath: "", expectedScore: 100},
+		{name: "root path", endpointPath: "/", requestPath: "/", expectedScore: 100},
+		{name: "trailing slashes ignored", endpointPath: "/users/", requestPath: "/users", expectedScore: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedScore, routeScore(tt.endpointPath, tt.requestPath))
+		})
+	}
+}
+
+func TestRouteTableMatchPriority(t *testing.T) {
+	table := NewRouteTable(&database.Project{ID: "p"}, []database.MockEndpoint{
+		{ID: "1", Method: "GET", Path: "/users"},
+		{ID: "2", Method: "GET", Path: "/users/:id"},
+		{ID: "3", Method: "GET", Path: "/api/v2/customer_rooms/*/broadcast_history"},
+		{ID: "4", Method: "GET", Path: `/api/v\d+/users/\d+`},
+		{ID: "5", Method: "GET", Path: "/api/*/users/*"},
+		{ID: "6", Method: "GET", Path: `/api/v\d+/specific/\d+`},
+	}, nil)
+
+	tests := []struct {
+		name        string
+		requestPath string
+		expectedID  string
+	}{
+		{name: "exact match wins over parameters", requestPath: "/users", expectedID: "1"},
+		{name: "parameter match when no exact", requestPath: "/users/123", expectedID: "2"},
+		{name: "wildcard customer rooms", requestPath: "/api/v2/customer_rooms/331931307/broadcast_history", expectedID: "3"},
+		{name: "wildcard customer rooms string", requestPath: "/api/v2/customer_rooms/salam/broadcast_history", expectedID: "3"},
+		{name: "wildcard wins over regex", requestPath: "/api/v3/users/456", expectedID: "5"},
+		{name: "regex only match", requestPath: "/api/v2/specific/456", expectedID: "6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := table.Match("GET", tt.requestPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedID, match.ID)
+		})
+	}
+}
+
+func TestRouteTableMatchCapturesParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpointPath string
+		requestPath  string
+		expected     map[string]string
+	}{
+		{name: "named params", endpointPath: "/users/:userId/orders/:orderId", requestPath: "/users/42/orders/7", expected: map[string]string{"userId": "42", "orderId": "7"}},
+		{name: "wildcards are indexed", endpointPath: "/files/*/versions/*", requestPath: "/files/report/versions/3", expected: map[string]string{"*0": "report", "*1": "3"}},
+		{name: "regex groups are numbered", endpointPath: `/api/v(\d+)/items/(\w+)`, requestPath: "/api/v2/items/abc", expected: map[string]string{"$1": "2", "$2": "abc"}},
+		{name: "named regex groups", endpointPath: `/products/(?P<sku>[A-Z]+-\d+)`, requestPath: "/products/AB-12", expected: map[string]string{"$1": "AB-12", "sku": "AB-12"}},
+		{name: "exact match has no params", endpointPath: "/health", requestPath: "/health", expected: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewRouteTable(&database.Project{ID: "p"}, []database.MockEndpoint{{ID: "e", Method: "GET", Path: tt.endpointPath}}, nil)
+			match, err := table.Match("GET", tt.requestPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, match.Params())
+		})
+	}
+}
+
+func TestRouteTableMatch(t *testing.T) {
+	table := NewRouteTable(&database.Project{ID: "p"}, routeTestEndpoints, nil)
+
+	t.Run("Earlier endpoint wins a tie", func(t *testing.T) {
+		match, err := table.Match("GET", "/users/42")
+		require.NoError(t, err)
+		assert.Equal(t, "user", match.ID)
+	})
+
+	t.Run("Methods are routed separately", func(t *testing.T) {
+		match, err := table.Match("POST", "/users")
+		require.NoError(t, err)
+		assert.Equal(t, "create-user", match.ID)
+
+		_, err = table.Match("DELETE", "/users")
+		assert.Error(t, err)
+	})
+
+	t.Run("Regex captures are precompiled", func(t *testing.T) {
+		match, err := table.Match("GET", "/api/v1/orders/77")
+		require.NoError(t, err)
+		assert.Equal(t, "regex", match.ID)
+		assert.Equal(t, "77", match.PathParams["orderId"])
+	})
+
+	t.Run("Matched endpoint is a copy", func(t *testing.T) {
+		match, err := table.Match("GET", "/users")
+		require.NoError(t, err)
+		match.MockEndpoint.Path = "/changed"
+
+		again, err := table.Match("GET", "/users")
+		require.NoError(t, err)
+		assert.Equal(t, "/users", again.Path)
+	})
+}
+
+func TestRouteTableResponses(t *testing.T) {
+	table := NewRouteTable(&database.Project{ID: "p"}, routeTestEndpoints, []database.MockResponse{
+		{ID: "a", EndpointID: "users", Priority: 1},
+		{ID: "b", EndpointID: "users", Priority: 2},
+		{ID: "c", EndpointID: "me"},
+	})
+
+	responses := table.Responses("users")
+	require.Len(t, responses, 2)
+	assert.Equal(t, "a", responses[0].ID)
+
+	// Reordering the returned slice leaves the table untouched
+	responses[0], responses[1] = responses[1], responses[0]
+	assert.Equal(t, "a", table.Responses("users")[0].ID)
+
+	assert.Empty(t, table.Responses("unknown"))
+}
+
+// benchmarkEndpoints builds a project of n endpoints with a realistic mix of path patterns
+func benchmarkEndpoints(n int) []database.MockEndpoint {
+	endpoints := make([]database.MockEndpoint, 0, n)
+	for i := 0; len(endpoints) < n; i++ {
+		endpoints = append(endpoints,
+			database.MockEndpoint{ID: fmt.Sprintf("static-%d", i), Method: "GET", Path: fmt.Sprintf("/api/resource%d/list", i)},
+			database.MockEndpoint{ID: fmt.Sprintf("param-%d", i), Method: "GET", Path: fmt.Sprintf("/api/resource%d/:id", i)},
+			database.MockEndpoint{ID: fmt.Sprintf("wildcard-%d", i), Method: "GET", Path: fmt.Sprintf("/api/resource%d/*/history", i)},
+			database.MockEndpoint{ID: fmt.Sprintf("regex-%d", i), Method: "GET", Path: fmt.Sprintf(`/api/v\d+/resource%d/\d+`, i)},
+		)
+	}
+	return endpoints[:n]
+}
+
+var benchmarkPaths = []string{"/api/resource7/list", "/api/resource12/99", "/api/resource3/x/history", "/api/v2/resource5/10", "/missing"}
+
+func BenchmarkRouteTableMatch(b *testing.B) {
+	table := NewRouteTable(&database.Project{ID: "p"}, benchmarkEndpoints(200), nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		table.Match("GET", benchmarkPaths[i%len(benchmarkPaths)])
+	}
+}
@@ -0,0 +1,158 @@
+package requestctx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+)
+
+// Body formats reported by Request.Format
+const (
+	FormatJSON      = "json"
+	FormatXML       = "xml"
+	FormatForm      = "form"
+	FormatMultipart = "multipart"
+	FormatText      = "text"
+	FormatNone      = ""
+)
+
+// MultipartForm is a parsed multipart/form-data body
+type MultipartForm struct {
+	Fields url.Values            `json:"fields"`
+	Files  map[string][]FilePart `json:"files"`
+}
+
+// FilePart describes an uploaded file; the content itself is not kept
+type FilePart struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// Format returns how the body is parsed for rules, templates and the request log
+// Multipart bodies are recognised by their Content-Type. A body holding a JSON object or
+// array is JSON whatever its Content-Type, since clients often send JSON as form data or
+// plain text. XML is recognised by an XML Content-Type or by its content, forms by their
+// Content-Type. Anything else is text.
+func (r *Request) Format() string {
+	if len(r.Body) == 0 {
+		return FormatNone
+	}
+
+	mediaType := r.mediaType()
+	if mediaType == "multipart/form-data" {
+		return FormatMultipart
+	}
+	if parsed, ok := r.JSON(); ok {
+		switch parsed.(type) {
+		case map[string]interface{}, []interface{}:
+			return FormatJSON
+		}
+	}
+	if isXMLMediaType(mediaType) || mediaType == "" || mediaType == "text/plain" {
+		if _, ok := r.XML(); ok {
+			return FormatXML
+		}
+	}
+	if mediaType == "application/x-www-form-urlencoded" {
+		return FormatForm
+	}
+	return FormatText
+}
+
+// Multipart returns the fields and file descriptions of a multipart/form-data body
+// and whether the body could be parsed as one
+func (r *Request) Multipart() (*MultipartForm, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.multipartParsed {
+		r.multipartParsed = true
+		r.multipart, _ = parseMultipart(r.Header.Get("Content-Type"), r.Body)
+	}
+	return r.multipart, r.multipart != nil
+}
+
+// ParsedBody returns a structured view of XML, form and multipart bodies for display,
+// or nil for other formats
+func (r *Request) ParsedBody() interface{} {
+	switch r.Format() {
+	case FormatXML:
+		root, _ := r.XML()
+		return root
+	case FormatForm:
+		return r.Form()
+	case FormatMultipart:
+		if form, ok := r.Multipart(); ok {
+			return form
+		}
+	}
+	return nil
+}
+
+// ParsedBodyJSON returns ParsedBody encoded as JSON, empty when there is nothing to show
+func (r *Request) ParsedBodyJSON() string {
+	parsed := r.ParsedBody()
+	if parsed == nil {
+		return ""
+	}
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// parseMultipart reads every part of a multipart/form-data body
+func parseMultipart(contentType string, body []byte) (*MultipartForm, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("multipart: missing boundary")
+	}
+
+	form := &MultipartForm{Fields: url.Values{}, Files: map[string][]FilePart{}}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return form, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := part.FormName()
+		if filename := part.FileName(); filename != "" {
+			size, err := io.Copy(io.Discard, part)
+			if err != nil {
+				return nil, err
+			}
+			form.Files[name] = append(form.Files[name], FilePart{
+				Filename:    filename,
+				ContentType: part.Header.Get("Content-Type"),
+				Size:        size,
+			})
+			continue
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		form.Fields.Add(name, string(value))
+	}
+}
+
+// isXMLMediaType reports whether a media type declares XML (text/xml, application/soap+xml, ...)
+func isXMLMediaType(mediaType string) bool {
+	return strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml")
+}
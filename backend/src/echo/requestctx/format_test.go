@@ -0,0 +1,110 @@
+package requestctx
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func multipartBody(t *testing.T) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	require.NoError(t, writer.WriteField("title", "Holiday"))
+	require.NoError(t, writer.WriteField("tags[]", "beach"))
+	require.NoError(t, writer.WriteField("tags[]", "sun"))
+	file, err := writer.CreateFormFile("avatar", "me.png")
+	require.NoError(t, err)
+	_, err = file.Write([]byte("12345"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return writer.FormDataContentType(), buf.Bytes()
+}
+
+func TestFormat(t *testing.T) {
+	contentType, body := multipartBody(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{name: "JSON object", contentType: "application/json", body: `{"a":1}`, expected: FormatJSON},
+		{name: "JSON sent as form data", contentType: "application/x-www-form-urlencoded", body: `{"a":1}`, expected: FormatJSON},
+		{name: "JSON scalar is text", contentType: "application/json", body: `42`, expected: FormatText},
+		{name: "XML", contentType: "text/xml; charset=utf-8", body: `<a><b>1</b></a>`, expected: FormatXML},
+		{name: "SOAP", contentType: "application/soap+xml", body: `<Envelope/>`, expected: FormatXML},
+		{name: "XML without content type", body: `<a/>`, expected: FormatXML},
+		{name: "Invalid XML", contentType: "application/xml", body: `<a>`, expected: FormatText},
+		{name: "Form", contentType: "application/x-www-form-urlencoded", body: `a=1&b=2`, expected: FormatForm},
+		{name: "Multipart", contentType: contentType, body: string(body), expected: FormatMultipart},
+		{name: "Text", contentType: "text/plain", body: `hello`, expected: FormatText},
+		{name: "Empty", contentType: "application/json", expected: FormatNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			assert.Equal(t, tt.expected, New(req).Format())
+		})
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	contentType, body := multipartBody(t)
+	req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	r := New(req)
+
+	form, ok := r.Multipart()
+	require.True(t, ok)
+	assert.Equal(t, "Holiday", form.Fields.Get("title"))
+	assert.Equal(t, []string{"beach", "sun"}, form.Fields["tags[]"])
+	require.Len(t, form.Files["avatar"], 1)
+	assert.Equal(t, "me.png", form.Files["avatar"][0].Filename)
+	assert.Equal(t, "application/octet-stream", form.Files["avatar"][0].ContentType)
+	assert.Equal(t, int64(5), form.Files["avatar"][0].Size)
+
+	assert.JSONEq(t, `{
+		"fields": {"title": ["Holiday"], "tags[]": ["beach", "sun"]},
+		"files": {"avatar": [{"filename": "me.png", "content_type": "application/octet-stream", "size": 5}]}
+	}`, r.ParsedBodyJSON())
+
+	t.Run("Missing boundary", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "multipart/form-data")
+		_, ok := New(req).Multipart()
+		assert.False(t, ok)
+	})
+}
+
+func TestParsedBodyJSON(t *testing.T) {
+	t.Run("XML", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<user id="7"><name>Jane</name></user>`))
+		req.Header.Set("Content-Type", "application/xml")
+		assert.JSONEq(t, `{"name":"user","attrs":{"id":"7"},"children":[{"name":"name","text":"Jane"}]}`, New(req).ParsedBodyJSON())
+	})
+
+	t.Run("Form", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`email=jane%40example.com&tags[]=a&tags[]=b`))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		assert.JSONEq(t, `{"email":["jane@example.com"],"tags[]":["a","b"]}`, New(req).ParsedBodyJSON())
+	})
+
+	t.Run("JSON and text are not repeated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`))
+		assert.Empty(t, New(req).ParsedBodyJSON())
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`hello`))
+		assert.Empty(t, New(req).ParsedBodyJSON())
+	})
+}
@@ -0,0 +1,21 @@
+package requestctx
+
+import (
+	"beo-echo/backend/src/echo/graphql"
+)
+
+// GraphQL returns the body parsed as a GraphQL request, or the error a GraphQL server would report
+// GET requests are read from the query string. The result is cached, so endpoints, rules and the
+// request logger share one parse.
+func (r *Request) GraphQL() (*graphql.Request, error) {
+	query := r.Query()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.graphqlParsed {
+		r.graphqlParsed = true
+		r.graphql, r.graphqlErr = graphql.ParseRequest(r.Method, query, r.Header.Get("Content-Type"), r.Body)
+	}
+	return r.graphql, r.graphqlErr
+}
@@ -0,0 +1,46 @@
+package requestctx
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// BearerToken returns the token of an "Authorization: Bearer" header, or ""
+func (r *Request) BearerToken() string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Claims returns the claims of the JWT the request carries, or nil
+// Simulated authentication stores the claims of the token it verified with SetClaims. Otherwise the
+// bearer token is decoded without checking its signature, so rules can match on any mock token.
+func (r *Request) Claims() map[string]interface{} {
+	token := r.BearerToken()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.claimsParsed {
+		r.claimsParsed = true
+		if token != "" {
+			claims := jwt.MapClaims{}
+			if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err == nil {
+				r.claims = claims
+			}
+		}
+	}
+	return r.claims
+}
+
+// SetClaims records the claims of a verified JWT, replacing the decoded bearer token
+func (r *Request) SetClaims(claims map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.claims = claims
+	r.claimsParsed = true
+}
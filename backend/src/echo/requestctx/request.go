@@ -0,0 +1,306 @@
+// Package requestctx holds the parsed view of an incoming request.
+//
+// The request body is read once, decoded from its Content-Encoding and parsed on demand
+// (JSON, form, multipart, XML, GraphQL). The result is attached to the request context so response rules,
+// templates, actions, the proxy and the request logger all see the same data without
+// reading or parsing the body again.
+package requestctx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"beo-echo/backend/src/echo/graphql"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Request is the parsed view of an incoming request
+// Header is shared with the http.Request, so header changes made by actions are seen here.
+// Use SetBody to replace the body so the parsed views stay consistent.
+type Request struct {
+	Method     string
+	Header     http.Header
+	PathParams map[string]string // Values captured from the matched endpoint path, set once matched
+
+	// Raw is the body as received and Body is the same body with its Content-Encoding removed
+	Raw             []byte
+	Body            []byte
+	ContentEncoding string
+	Oversized       bool // The body exceeds the cap and was left unread on the request, Raw and Body are empty
+
+	url *url.URL
+
+	mu         sync.Mutex
+	rawQuery   string
+	query      url.Values
+	cookies    map[string]string
+	jsonParsed bool
+	json       interface{}
+	jsonOK     bool
+	form       url.Values
+	xml        *XMLNode
+	xmlParsed  bool
+
+	multipart       *MultipartForm
+	multipartParsed bool
+
+	graphql       *graphql.Request
+	graphqlErr    error
+	graphqlParsed bool
+
+	claims       map[string]interface{}
+	claimsParsed bool
+}
+
+type contextKey struct{}
+
+// DefaultMaxBodyBytes is the request body cap used until SetMaxBodyBytes is called
+const DefaultMaxBodyBytes = 10 << 20
+
+// maxBodyBytes caps the bytes read from a request body and the bytes it decodes to
+var maxBodyBytes atomic.Int64
+
+func init() {
+	maxBodyBytes.Store(DefaultMaxBodyBytes)
+}
+
+// SetMaxBodyBytes sets the request body cap, a value of 0 or less restores the default
+func SetMaxBodyBytes(limit int64) {
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	maxBodyBytes.Store(limit)
+}
+
+// New reads the request body and builds its parsed view
+// The body is restored so the request can still be read or forwarded. A body larger than
+// the cap is not buffered: the view is marked Oversized and the request keeps streaming it.
+func New(req *http.Request) *Request {
+	r := &Request{
+		Method:     req.Method,
+		Header:     req.Header,
+		PathParams: map[string]string{},
+		url:        req.URL,
+	}
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	limit := maxBodyBytes.Load()
+	if req.Body != nil && req.Body != http.NoBody {
+		raw, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+		if err == nil && int64(len(raw)) > limit {
+			r.Oversized = true
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(raw), req.Body), req.Body}
+		} else {
+			req.Body.Close()
+			if err == nil {
+				r.Raw = raw
+			}
+			req.Body = io.NopCloser(bytes.NewReader(r.Raw))
+		}
+	}
+
+	r.ContentEncoding = strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	r.Body = decodeBody(r.Raw, r.ContentEncoding, limit)
+	return r
+}
+
+// Attach builds the parsed view of req, unless it already carries one, and returns the
+// request with the view attached to its context
+func Attach(req *http.Request) (*http.Request, *Request) {
+	if r := FromContext(req.Context()); r != nil {
+		return req, r
+	}
+	r := New(req)
+	return req.WithContext(WithRequest(req.Context(), r)), r
+}
+
+// WithRequest returns a context carrying the parsed request
+func WithRequest(ctx context.Context, r *Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the parsed request attached to ctx, or nil when absent
+func FromContext(ctx context.Context) *Request {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(contextKey{}).(*Request)
+	return r
+}
+
+// From returns the parsed view attached to req, or builds a detached one
+func From(req *http.Request) *Request {
+	if r := FromContext(req.Context()); r != nil {
+		return r
+	}
+	return New(req)
+}
+
+// SetBody replaces the body of req and of its parsed view
+// The new body is plain, so any Content-Encoding header is dropped.
+func SetBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	req.Header.Del("Content-Encoding")
+
+	r := FromContext(req.Context())
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Raw = body
+	r.Body = body
+	r.ContentEncoding = ""
+	r.Oversized = false
+	r.jsonParsed, r.json, r.jsonOK = false, nil, false
+	r.form = nil
+	r.xmlParsed, r.xml = false, nil
+	r.multipartParsed, r.multipart = false, nil
+	r.graphqlParsed, r.graphql, r.graphqlErr = false, nil, nil
+}
+
+// Path returns the URL path of the request
+func (r *Request) Path() string {
+	if r.url == nil {
+		return ""
+	}
+	return r.url.Path
+}
+
+// Query returns the parsed query string, parsed again only when the URL query changes
+func (r *Request) Query() url.Values {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rawQuery := ""
+	if r.url != nil {
+		rawQuery = r.url.RawQuery
+	}
+	if r.query == nil || rawQuery != r.rawQuery {
+		r.query, _ = url.ParseQuery(rawQuery)
+		r.rawQuery = rawQuery
+	}
+	return r.query
+}
+
+// Cookies returns the request cookies by name
+func (r *Request) Cookies() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cookies == nil {
+		r.cookies = map[string]string{}
+		for _, cookie := range (&http.Request{Header: r.Header}).Cookies() {
+			r.cookies[cookie.Name] = cookie.Value
+		}
+	}
+	return r.cookies
+}
+
+// JSON returns the body parsed as JSON and whether it is valid JSON
+func (r *Request) JSON() (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.jsonParsed {
+		r.jsonParsed = true
+		if len(r.Body) > 0 {
+			r.jsonOK = json.Unmarshal(r.Body, &r.json) == nil
+		}
+	}
+	return r.json, r.jsonOK
+}
+
+// Form returns the fields of a form-urlencoded body, empty for other content types
+func (r *Request) Form() url.Values {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.form == nil {
+		r.form = url.Values{}
+		if r.mediaType() == "application/x-www-form-urlencoded" {
+			if values, err := url.ParseQuery(string(r.Body)); err == nil {
+				r.form = values
+			}
+		}
+	}
+	return r.form
+}
+
+// XML returns the body parsed as an XML document and whether it is well-formed XML
+func (r *Request) XML() (*XMLNode, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.xmlParsed {
+		r.xmlParsed = true
+		if looksLikeXML(r.Body) {
+			r.xml, _ = ParseXML(r.Body)
+		}
+	}
+	return r.xml, r.xml != nil
+}
+
+// mediaType returns the lower-cased media type of the Content-Type header
+func (r *Request) mediaType() string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(mediaType)
+}
+
+// decodeBody removes a gzip, br or deflate Content-Encoding, returning raw when it cannot be
+// decoded or decodes to more than limit bytes
+func decodeBody(raw []byte, encoding string, limit int64) []byte {
+	if len(raw) == 0 {
+		return raw
+	}
+
+	var reader io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return raw
+		}
+		defer gz.Close()
+		reader = gz
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(raw))
+	case "deflate":
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return raw
+		}
+		defer zr.Close()
+		reader = zr
+	default:
+		return raw
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil || int64(len(decoded)) > limit {
+		return raw
+	}
+	return decoded
+}
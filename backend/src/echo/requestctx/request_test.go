@@ -0,0 +1,167 @@
+package requestctx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Decodes the content encoding and restores the body", func(t *testing.T) {
+		compressed := gzipBytes(t, `{"user":{"name":"Jane"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/orders?page=2", bytes.NewReader(compressed))
+		req.Header.Set("Content-Encoding", "gzip")
+
+		r := New(req)
+		assert.Equal(t, compressed, r.Raw)
+		assert.Equal(t, `{"user":{"name":"Jane"}}`, string(r.Body))
+		assert.Equal(t, "gzip", r.ContentEncoding)
+
+		restored, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, compressed, restored)
+	})
+
+	t.Run("Undecodable bodies are kept as received", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not gzip"))
+		req.Header.Set("Content-Encoding", "gzip")
+		assert.Equal(t, "not gzip", string(New(req).Body))
+	})
+
+	t.Run("No body", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+		r := New(req)
+		assert.Nil(t, r.Raw)
+		_, ok := r.JSON()
+		assert.False(t, ok)
+	})
+}
+
+func TestNew_MaxBodyBytes(t *testing.T) {
+	SetMaxBodyBytes(64)
+	defer SetMaxBodyBytes(0)
+
+	t.Run("Bodies over the cap are left on the request", func(t *testing.T) {
+		body := strings.Repeat("a", 100)
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body))
+
+		r := New(req)
+		assert.True(t, r.Oversized)
+		assert.Empty(t, r.Raw)
+		assert.Empty(t, r.Body)
+
+		restored, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(restored))
+	})
+
+	t.Run("Decoding stops at the cap and keeps the raw bytes", func(t *testing.T) {
+		compressed := gzipBytes(t, strings.Repeat("a", 1000))
+		require.Less(t, len(compressed), 64)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(compressed))
+		req.Header.Set("Content-Encoding", "gzip")
+
+		r := New(req)
+		assert.False(t, r.Oversized)
+		assert.Equal(t, compressed, r.Raw)
+		assert.Equal(t, compressed, r.Body)
+	})
+
+	t.Run("Bodies at the cap are read", func(t *testing.T) {
+		body := strings.Repeat("a", 64)
+		r := New(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		assert.False(t, r.Oversized)
+		assert.Equal(t, body, string(r.Body))
+	})
+}
+
+func TestRequestParsing(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/search?q=shoes&page=1", strings.NewReader(`{"items":[{"sku":"A1"}]}`))
+	req.Header.Set("Cookie", "session=abc; theme=dark")
+	r := New(req)
+
+	t.Run("JSON is parsed once", func(t *testing.T) {
+		first, ok := r.JSON()
+		require.True(t, ok)
+		first.(map[string]interface{})["parsed"] = true
+
+		second, _ := r.JSON()
+		assert.Equal(t, true, second.(map[string]interface{})["parsed"])
+	})
+
+	t.Run("Query follows URL changes", func(t *testing.T) {
+		assert.Equal(t, "shoes", r.Query().Get("q"))
+		req.URL.RawQuery = "q=boots"
+		assert.Equal(t, "boots", r.Query().Get("q"))
+	})
+
+	t.Run("Cookies", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"session": "abc", "theme": "dark"}, r.Cookies())
+	})
+
+	t.Run("Form is empty for other content types", func(t *testing.T) {
+		assert.Empty(t, r.Form())
+	})
+}
+
+func TestRequestFormAndXML(t *testing.T) {
+	form := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=Jane+Doe&tag=a&tag=b"))
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	values := New(form).Form()
+	assert.Equal(t, "Jane Doe", values.Get("name"))
+	assert.Equal(t, []string{"a", "b"}, values["tag"])
+
+	doc := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<?xml version="1.0"?>
+		<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+			<soap:Body><GetUser id="7"><Name>Jane</Name></GetUser></soap:Body>
+		</soap:Envelope>`))
+	root, ok := New(doc).XML()
+	require.True(t, ok)
+	assert.Equal(t, "Envelope", root.Name)
+	user := root.Children[0].Children[0]
+	assert.Equal(t, "GetUser", user.Name)
+	assert.Equal(t, "7", user.Attrs["id"])
+	assert.Equal(t, "Jane", user.Children[0].Text)
+
+	_, ok = New(httptest.NewRequest(http.MethodPost, "/", strings.NewReader("<open>"))).XML()
+	assert.False(t, ok)
+}
+
+func TestAttachAndSetBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`))
+	req, r := Attach(req)
+
+	again, same := Attach(req)
+	assert.Same(t, req, again)
+	assert.Same(t, r, same)
+	assert.Same(t, r, From(req))
+
+	_, ok := r.JSON()
+	require.True(t, ok)
+
+	SetBody(req, []byte("plain"))
+	assert.Equal(t, "plain", string(r.Body))
+	_, ok = r.JSON()
+	assert.False(t, ok)
+	assert.Equal(t, "5", req.Header.Get("Content-Length"))
+
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, "plain", string(body))
+}
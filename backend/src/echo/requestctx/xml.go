@@ -0,0 +1,77 @@
+package requestctx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// XMLNode is an element of a parsed XML document
+// Names are local names, namespace prefixes are dropped.
+type XMLNode struct {
+	Name     string            `json:"name"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Text     string            `json:"text,omitempty"` // Character data directly inside the element, trimmed
+	Children []*XMLNode        `json:"children,omitempty"`
+	Parent   *XMLNode          `json:"-"`
+}
+
+// ParseXML parses an XML document into a tree and returns its root element
+func ParseXML(data []byte) (*XMLNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var root, current *XMLNode
+	var text []*strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &XMLNode{Name: t.Name.Local, Attrs: map[string]string{}, Parent: current}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("xml: more than one root element")
+				}
+				root = node
+			} else {
+				current.Children = append(current.Children, node)
+			}
+			current = node
+			text = append(text, &strings.Builder{})
+		case xml.EndElement:
+			if current == nil {
+				return nil, errors.New("xml: unexpected end element")
+			}
+			current.Text = strings.TrimSpace(text[len(text)-1].String())
+			text = text[:len(text)-1]
+			current = current.Parent
+		case xml.CharData:
+			if current != nil {
+				text[len(text)-1].Write(t)
+			}
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, errors.New("xml: incomplete document")
+	}
+	return root, nil
+}
+
+// looksLikeXML reports whether data starts like an XML document
+func looksLikeXML(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '<'
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// Results of simulated authentication, see AuthDecision
+const (
+	AuthAllowed      = "allowed"
+	AuthUnauthorized = "unauthorized"
+	AuthForbidden    = "forbidden"
+)
+
+// AuthDecision records how simulated authentication handled a request
+type AuthDecision struct {
+	Type    string `json:"type"`              // api_key, basic or jwt
+	Result  string `json:"result"`            // allowed, unauthorized or forbidden
+	Subject string `json:"subject,omitempty"` // Basic user, JWT sub or the start of the API key
+	Reason  string `json:"reason,omitempty"`  // Why the request was rejected
+}
+
+// forbiddenError is the reason valid credentials are not enough for a request
+type forbiddenError string
+
+func (e forbiddenError) Error() string { return string(e) }
+
+// resolveAuth returns the authentication required for a request, or nil when none is
+// The endpoint the request would match replaces the project config with its own.
+func resolveAuth(project *database.Project, routes *repositories.RouteTable, method, path string) *database.AuthConfig {
+	var auth *database.AuthConfig
+	if project.AdvanceConfig != "" {
+		if config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig); err == nil {
+			auth = config.Auth
+		}
+	}
+	if match, err := routes.Match(method, path); err == nil && match.AdvanceConfig != "" {
+		if config, err := database.ParseEndpointAdvanceConfig(match.AdvanceConfig); err == nil && config.Auth != nil {
+			auth = config.Auth
+		}
+	}
+	if auth == nil || auth.Type == database.AuthNone {
+		return nil
+	}
+	return auth
+}
+
+// authenticate checks the credentials the project or endpoint requires
+// It returns nil when the request may go on, or the rejection to send. The decision is
+// recorded on the request meta for the request log.
+func authenticate(ctx context.Context, project *database.Project, routes *repositories.RouteTable, method, path string, req *http.Request) *http.Response {
+	auth := resolveAuth(project, routes, method, path)
+	if auth == nil {
+		return nil
+	}
+
+	request := requestctx.From(req)
+	decision := &AuthDecision{Type: auth.Type, Result: AuthAllowed}
+	var err error
+	switch auth.Type {
+	case database.AuthAPIKey:
+		decision.Subject, err = checkAPIKey(auth.APIKey, request)
+	case database.AuthBasic:
+		decision.Subject, err = checkBasicAuth(auth.Basic, request)
+	case database.AuthJWT:
+		decision.Subject, err = checkJWT(ctx, auth.JWT, request)
+	}
+	if err != nil {
+		decision.Result = AuthUnauthorized
+		var forbidden forbiddenError
+		if errors.As(err, &forbidden) {
+			decision.Result = AuthForbidden
+		}
+		decision.Reason = err.Error()
+	}
+
+	if meta := RequestMetaFromContext(ctx); meta != nil {
+		meta.Auth = decision
+	}
+	if err == nil {
+		return nil
+	}
+	return authRejection(ctx, auth, decision, path, req)
+}
+
+// checkAPIKey accepts a request carrying one of the configured keys
+func checkAPIKey(config *database.APIKeyAuth, request *requestctx.Request) (string, error) {
+	var key string
+	if config.Header != "" {
+		key = request.Header.Get(config.Header)
+	}
+	if key == "" && config.Query != "" {
+		key = request.Query().Get(config.Query)
+	}
+	if key == "" {
+		return "", errors.New("missing API key")
+	}
+	for _, allowed := range config.Keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+			return maskAPIKey(key), nil
+		}
+	}
+	return maskAPIKey(key), errors.New("invalid API key")
+}
+
+// maskAPIKey keeps the start of a key so logs tell keys apart without revealing them
+func maskAPIKey(key string) string {
+	if len(key) <= 8 {
+		return "***"
+	}
+	return key[:4] + "***"
+}
+
+// checkBasicAuth accepts HTTP Basic credentials of a configured user
+func checkBasicAuth(config *database.BasicAuth, request *requestctx.Request) (string, error) {
+	user, password, ok := (&http.Request{Header: request.Header}).BasicAuth()
+	if !ok {
+		return "", errors.New("missing basic credentials")
+	}
+	expected, known := config.Users[user]
+	if !known || subtle.ConstantTimeCompare([]byte(password), []byte(expected)) != 1 {
+		return user, errors.New("invalid username or password")
+	}
+	return user, nil
+}
+
+// Signing methods accepted for HMAC secrets and for JWKS keys
+var (
+	hmacSigningMethods = []string{"HS256", "HS384", "HS512"}
+	jwksSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+)
+
+// checkJWT accepts a bearer JWT with a valid signature, issuer, audience and lifetime
+// Tokens without the required scopes or claims are forbidden. The claims of an accepted
+// or forbidden token are stored on the request view for rules and templates.
+func checkJWT(ctx context.Context, config *database.JWTAuth, request *requestctx.Request) (string, error) {
+	raw := request.BearerToken()
+	if raw == "" {
+		return "", errors.New("missing bearer token")
+	}
+
+	options := []jwt.ParserOption{jwt.WithJSONNumber(), jwt.WithIssuedAt()}
+	if config.LeewaySeconds > 0 {
+		options = append(options, jwt.WithLeeway(time.Duration(config.LeewaySeconds)*time.Second))
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	var keyfunc jwt.Keyfunc
+	if config.Secret != "" {
+		options = append(options, jwt.WithValidMethods(hmacSigningMethods))
+		keyfunc = func(*jwt.Token) (interface{}, error) { return []byte(config.Secret), nil }
+	} else {
+		options = append(options, jwt.WithValidMethods(jwksSigningMethods))
+		keyfunc = func(token *jwt.Token) (interface{}, error) { return jwksKeyFor(ctx, config, token) }
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.NewParser(options...).ParseWithClaims(raw, claims, keyfunc); err != nil {
+		return "", fmt.Errorf("invalid token: %v", err)
+	}
+	request.SetClaims(claims)
+	subject, _ := claims["sub"].(string)
+
+	granted := claimValues(claims, "scope")
+	if len(granted) == 0 {
+		granted = claimValues(claims, "scp")
+	}
+	for _, scope := range config.RequiredScopes {
+		if !containsClaimValue(granted, scope) {
+			return subject, forbiddenError("missing scope " + scope)
+		}
+	}
+	names := make([]string, 0, len(config.RequiredClaims))
+	for name := range config.RequiredClaims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := config.RequiredClaims[name]; !containsClaimValue(claimValues(claims, name), value) {
+			return subject, forbiddenError(fmt.Sprintf("claim %s is not %s", name, value))
+		}
+	}
+	return subject, nil
+}
+
+// jwksKeyFor finds the JWKS key that signed token, by its kid and key type
+// An unknown kid refreshes a fetched document, in case the keys were rotated.
+func jwksKeyFor(ctx context.Context, config *database.JWTAuth, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, refresh := range []bool{false, true} {
+		if refresh && config.JWKSURL == "" {
+			break
+		}
+		keys, err := jwksKeys(ctx, config, refresh)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if (kid != "" && key.ID != kid) || (key.Alg != "" && key.Alg != token.Method.Alg()) {
+				continue
+			}
+			if signingKeyFits(token.Method, key.Key) {
+				return key.Key, nil
+			}
+		}
+	}
+	if kid != "" {
+		return nil, fmt.Errorf("no JWKS key with kid %q", kid)
+	}
+	return nil, errors.New("no JWKS key for the token algorithm")
+}
+
+// signingKeyFits reports whether key can verify signatures of method
+func signingKeyFits(method jwt.SigningMethod, key interface{}) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := key.(*ecdsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodEd25519:
+		_, ok := key.(ed25519.PublicKey)
+		return ok
+	}
+	return false
+}
+
+// claimValues returns the values of a claim as a list
+// Arrays give their elements and the space-separated scope and scp claims give each scope.
+// A key starting with "$" is a JSONPath over the claims, e.g. "$.org.id".
+func claimValues(claims map[string]interface{}, key string) []interface{} {
+	if strings.HasPrefix(key, "$") {
+		path, err := jsonpath.Compile(key)
+		if err != nil {
+			return nil
+		}
+		return path.Find(claims)
+	}
+
+	value, ok := claims[key]
+	if !ok || value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case string:
+		if key == "scope" || key == "scp" {
+			values := []interface{}{}
+			for _, scope := range strings.Fields(v) {
+				values = append(values, scope)
+			}
+			return values
+		}
+	}
+	return []interface{}{value}
+}
+
+// containsClaimValue reports whether one of the values equals expected
+func containsClaimValue(values []interface{}, expected string) bool {
+	for _, value := range values {
+		if interfaceToString(value) == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// matchJWTRule checks if a jwt rule matches the claims of the bearer token
+// The key is a claim name such as "sub" or "scope", or a JSONPath over the claims. Claims with
+// several values (arrays, scopes) match like body keys selecting several values.
+func matchJWTRule(rule database.MockRule, request *requestctx.Request) bool {
+	values := claimValues(request.Claims(), rule.Key)
+	if isPresenceOperator(rule.Operator) {
+		return matchPresence(rule.Operator, len(values) > 0)
+	}
+	if len(values) == 0 {
+		return matchRuleValue(rule.Operator, "", rule.Value)
+	}
+	return matchEachValue(rule, values)
+}
+
+// authRejection builds the response sent to a request that failed authentication
+// gRPC calls get an UNAUTHENTICATED or PERMISSION_DENIED status instead.
+func authRejection(ctx context.Context, auth *database.AuthConfig, decision *AuthDecision, path string, req *http.Request) *http.Response {
+	forbidden := decision.Result == AuthForbidden
+	if IsGRPCRequest(req) {
+		code := GRPCCodeUnauthenticated
+		if forbidden {
+			code = GRPCCodePermissionDenied
+		}
+		return startGRPCCall(ctx, path).fail(code, decision.Reason)
+	}
+
+	status, custom := http.StatusUnauthorized, auth.Unauthorized
+	if forbidden {
+		status, custom = http.StatusForbidden, auth.Forbidden
+	}
+
+	var resp *http.Response
+	if custom == nil {
+		title := "Unauthorized"
+		if forbidden {
+			title = "Forbidden"
+		}
+		resp = createErrorResponse(status, title+": "+decision.Reason)
+	} else {
+		if custom.Status != 0 {
+			status = custom.Status
+		}
+		resp = &http.Response{
+			StatusCode:    status,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(strings.NewReader(custom.Body)),
+			ContentLength: int64(len(custom.Body)),
+		}
+		for name, value := range custom.Headers {
+			resp.Header.Set(name, value)
+		}
+		if resp.Header.Get("Content-Type") == "" && custom.Body != "" {
+			if json.Valid([]byte(custom.Body)) {
+				resp.Header.Set("Content-Type", "application/json")
+			} else {
+				resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+			}
+		}
+	}
+
+	if resp.Header.Get("WWW-Authenticate") == "" {
+		if challenge := authChallenge(auth, decision); challenge != "" && (resp.StatusCode == http.StatusUnauthorized || forbidden) {
+			resp.Header.Set("WWW-Authenticate", challenge)
+		}
+	}
+	return resp
+}
+
+// authChallenge returns the WWW-Authenticate value telling clients how to authenticate
+func authChallenge(auth *database.AuthConfig, decision *AuthDecision) string {
+	switch auth.Type {
+	case database.AuthBasic:
+		realm := auth.Basic.Realm
+		if realm == "" {
+			realm = "beo-echo"
+		}
+		return fmt.Sprintf("Basic realm=%q", realm)
+	case database.AuthJWT:
+		switch {
+		case decision.Result == AuthForbidden:
+			return `Bearer error="insufficient_scope"`
+		case decision.Reason == "missing bearer token":
+			return "Bearer"
+		default:
+			return `Bearer error="invalid_token"`
+		}
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"beo-echo/backend/src/database"
+)
+
+// JWKS documents fetched from a URL are kept for jwksCacheTTL. A token signed with a key the
+// cached document lacks triggers a new fetch, at most once per jwksRefreshInterval.
+const (
+	jwksCacheTTL        = 5 * time.Minute
+	jwksRefreshInterval = 30 * time.Second
+	jwksFetchTimeout    = 10 * time.Second
+	maxJWKSBytes        = 1 << 20
+)
+
+// jwk is a public key of a JWKS document
+type jwk struct {
+	ID  string // kid
+	Alg string // alg, empty when the key does not restrict it
+	Key crypto.PublicKey
+}
+
+// parseJWKS reads the RSA, EC and Ed25519 public keys of a JWKS document
+// Keys of other types, such as symmetric keys, are skipped.
+func parseJWKS(data []byte) ([]jwk, error) {
+	var document struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	var keys []jwk
+	for i, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaJWK(k.N, k.E)
+		case "EC":
+			key, err = ecJWK(k.Crv, k.X, k.Y)
+		case "OKP":
+			if k.Crv != "Ed25519" {
+				continue
+			}
+			var x []byte
+			if x, err = base64.RawURLEncoding.DecodeString(k.X); err == nil && len(x) != ed25519.PublicKeySize {
+				err = errors.New("invalid Ed25519 key size")
+			}
+			key = ed25519.PublicKey(x)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d: %w", i, err)
+		}
+		keys = append(keys, jwk{ID: k.Kid, Alg: k.Alg, Key: key})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("the JWKS document has no signing keys")
+	}
+	return keys, nil
+}
+
+// rsaJWK builds an RSA public key from its base64url modulus and exponent
+func rsaJWK(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil || len(modulus) == 0 {
+		return nil, errors.New("invalid RSA modulus")
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(new(big.Int).SetBytes(exponent).Int64())}, nil
+}
+
+// ecJWK builds an ECDSA public key from its curve name and base64url coordinates
+func ecJWK(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", crv)
+	}
+	xBytes, errX := base64.RawURLEncoding.DecodeString(x)
+	yBytes, errY := base64.RawURLEncoding.DecodeString(y)
+	if errX != nil || errY != nil {
+		return nil, errors.New("invalid EC coordinates")
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("the EC point is not on the curve")
+	}
+	return key, nil
+}
+
+// jwksCache keeps the JWKS documents fetched from URLs, keyed by URL
+var jwksCache = struct {
+	sync.Mutex
+	entries map[string]*jwksEntry
+}{entries: map[string]*jwksEntry{}}
+
+type jwksEntry struct {
+	keys    []jwk
+	fetched time.Time
+}
+
+// jwksKeys returns the keys a JWT configuration trusts
+// With refresh, a document fetched from a URL is fetched again unless it was just fetched.
+func jwksKeys(ctx context.Context, config *database.JWTAuth, refresh bool) ([]jwk, error) {
+	if len(config.JWKS) > 0 {
+		return parseJWKS(config.JWKS)
+	}
+
+	jwksCache.Lock()
+	entry := jwksCache.entries[config.JWKSURL]
+	jwksCache.Unlock()
+	if entry != nil {
+		age := time.Since(entry.fetched)
+		if age < jwksCacheTTL && (!refresh || age < jwksRefreshInterval) {
+			return entry.keys, nil
+		}
+	}
+
+	keys, err := fetchJWKS(ctx, config.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	jwksCache.Lock()
+	jwksCache.entries[config.JWKSURL] = &jwksEntry{keys: keys, fetched: time.Now()}
+	jwksCache.Unlock()
+	return keys, nil
+}
+
+// fetchJWKS downloads and parses a JWKS document
+func fetchJWKS(ctx context.Context, url string) ([]jwk, error) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the JWKS document: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JWKS document: %w", err)
+	}
+	return parseJWKS(data)
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+// signTestJWT signs claims with an HMAC secret
+func signTestJWT(t *testing.T, secret string, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+// authTestCall sends a request to a mock project and returns the response, its body and the auth decision
+type authTestCall func(method, path string, headers map[string]string) (*http.Response, string, *AuthDecision)
+
+// newAuthTestProject creates a mock project with the advance config, serving GET /users and GET /health
+func newAuthTestProject(t *testing.T, config, healthConfig string) authTestCall {
+	db := database.GetDB()
+	project := &database.Project{
+		ID:            uuid.New().String(),
+		Name:          "Auth",
+		Alias:         "auth-" + uuid.New().String()[:8],
+		Mode:          database.ModeMock,
+		AdvanceConfig: config,
+	}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	users := &database.MockEndpoint{ProjectID: project.ID, Method: "GET", Path: "/users", Enabled: true, ResponseMode: "static"}
+	require.NoError(t, db.Create(users).Error)
+	require.NoError(t, db.Create(&database.MockResponse{EndpointID: users.ID, StatusCode: 200, Body: `{"user":"{{.request.claims.sub}}"}`, Templated: true, Enabled: true}).Error)
+
+	health := &database.MockEndpoint{ProjectID: project.ID, Method: "GET", Path: "/health", Enabled: true, ResponseMode: "static", AdvanceConfig: healthConfig}
+	require.NoError(t, db.Create(health).Error)
+	require.NoError(t, db.Create(&database.MockResponse{EndpointID: health.ID, StatusCode: 200, Body: `ok`, Enabled: true}).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	return func(method, path string, headers map[string]string) (*http.Response, string, *AuthDecision) {
+		req, _ := http.NewRequest(method, "http://localhost/"+project.Alias+path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		meta := &RequestMeta{}
+		ctx := ContextWithRequestMeta(context.Background(), meta)
+		resp, err, _, _, _ := service.HandleRequest(ctx, project.Alias, method, "/"+project.Alias+path, req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body), meta.Auth
+	}
+}
+
+func TestHandleRequest_AuthAPIKey(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	call := newAuthTestProject(t,
+		`{"auth":{"type":"api_key","apiKey":{"header":"X-API-Key","query":"api_key","keys":["key-1234567890"]}}}`,
+		`{"auth":{"type":"none"}}`)
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		status  int
+		result  string
+		subject string
+		reason  string
+	}{
+		{name: "key in header", path: "/users", headers: map[string]string{"X-API-Key": "key-1234567890"}, status: 200, result: AuthAllowed, subject: "key-***"},
+		{name: "key in query", path: "/users?api_key=key-1234567890", status: 200, result: AuthAllowed, subject: "key-***"},
+		{name: "missing key", path: "/users", status: 401, result: AuthUnauthorized, reason: "missing API key"},
+		{name: "invalid key", path: "/users", headers: map[string]string{"X-API-Key": "nope"}, status: 401, result: AuthUnauthorized, subject: "***", reason: "invalid API key"},
+		{name: "public endpoint", path: "/health", status: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body, decision := call(http.MethodGet, tt.path, tt.headers)
+			assert.Equal(t, tt.status, resp.StatusCode, body)
+			if tt.result == "" {
+				assert.Nil(t, decision)
+				return
+			}
+			require.NotNil(t, decision)
+			assert.Equal(t, database.AuthAPIKey, decision.Type)
+			assert.Equal(t, tt.result, decision.Result)
+			assert.Equal(t, tt.subject, decision.Subject)
+			assert.Equal(t, tt.reason, decision.Reason)
+			if tt.status == http.StatusUnauthorized {
+				assert.Contains(t, body, tt.reason)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_AuthBasic(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	call := newAuthTestProject(t,
+		`{"auth":{"type":"basic","basic":{"users":{"alice":"wonderland"},"realm":"orders"},"unauthorized":{"status":401,"headers":{"X-Reason":"login"},"body":"{\"code\":\"LOGIN_REQUIRED\"}"}}}`,
+		"")
+	basic := func(user, password string) map[string]string {
+		return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))}
+	}
+
+	resp, _, decision := call(http.MethodGet, "/users", basic("alice", "wonderland"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, &AuthDecision{Type: database.AuthBasic, Result: AuthAllowed, Subject: "alice"}, decision)
+
+	// The custom unauthorized response replaces the default one and keeps the challenge
+	resp, body, decision := call(http.MethodGet, "/users", basic("alice", "queen"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.JSONEq(t, `{"code":"LOGIN_REQUIRED"}`, body)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "login", resp.Header.Get("X-Reason"))
+	assert.Equal(t, `Basic realm="orders"`, resp.Header.Get("WWW-Authenticate"))
+	assert.Equal(t, "invalid username or password", decision.Reason)
+
+	// Without an endpoint override, every endpoint requires the credentials
+	resp, _, _ = call(http.MethodGet, "/health", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestHandleRequest_AuthJWTSecret(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	call := newAuthTestProject(t,
+		`{"auth":{"type":"jwt","jwt":{"secret":"shh","issuer":"https://auth.example.com","audience":"orders-api","requiredScopes":["orders:read"],"requiredClaims":{"$.org.plan":"pro"}}}}`,
+		"")
+	now := time.Now()
+	claims := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"sub":   "user-42",
+			"iss":   "https://auth.example.com",
+			"aud":   []string{"orders-api"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"scope": "profile orders:read",
+			"org":   map[string]interface{}{"plan": "pro"},
+		}
+		for name, value := range changes {
+			claims[name] = value
+		}
+		return claims
+	}
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		status    int
+		result    string
+		challenge string
+		reason    string
+	}{
+		{name: "valid token", token: signTestJWT(t, "shh", claims(nil)), status: 200, result: AuthAllowed},
+		{name: "missing token", status: 401, result: AuthUnauthorized, challenge: "Bearer", reason: "missing bearer token"},
+		{name: "wrong secret", token: signTestJWT(t, "other", claims(nil)), status: 401, result: AuthUnauthorized, challenge: `Bearer error="invalid_token"`, reason: "signature is invalid"},
+		{name: "expired", token: signTestJWT(t, "shh", claims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()})), status: 401, result: AuthUnauthorized, challenge: `Bearer error="invalid_token"`, reason: "token is expired"},
+		{name: "wrong issuer", token: signTestJWT(t, "shh", claims(jwt.MapClaims{"iss": "https://evil.example.com"})), status: 401, result: AuthUnauthorized, reason: "invalid issuer"},
+		{name: "wrong audience", token: signTestJWT(t, "shh", claims(jwt.MapClaims{"aud": "billing-api"})), status: 401, result: AuthUnauthorized, reason: "invalid audience"},
+		{name: "scopes from scp", token: signTestJWT(t, "shh", claims(jwt.MapClaims{"scope": nil, "scp": []string{"orders:read"}})), status: 200, result: AuthAllowed},
+		{name: "missing scope", token: signTestJWT(t, "shh", claims(jwt.MapClaims{"scope": "profile"})), status: 403, result: AuthForbidden, challenge: `Bearer error="insufficient_scope"`, reason: "missing scope orders:read"},
+		{name: "missing claim", token: signTestJWT(t, "shh", claims(jwt.MapClaims{"org": map[string]interface{}{"plan": "free"}})), status: 403, result: AuthForbidden, reason: "claim $.org.plan is not pro"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.token != "" {
+				headers = bearer(tt.token)
+			}
+			resp, body, decision := call(http.MethodGet, "/users", headers)
+			assert.Equal(t, tt.status, resp.StatusCode, body)
+			require.NotNil(t, decision)
+			assert.Equal(t, tt.result, decision.Result)
+			assert.Contains(t, decision.Reason, tt.reason)
+			if tt.challenge != "" {
+				assert.Equal(t, tt.challenge, resp.Header.Get("WWW-Authenticate"))
+			}
+			if tt.status == http.StatusOK {
+				assert.Equal(t, "user-42", decision.Subject)
+				assert.JSONEq(t, `{"user":"user-42"}`, body)
+			}
+		})
+	}
+}
+
+func TestHandleRequest_AuthJWKS(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	document, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"alg": "RS256",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(document)
+	}))
+	defer server.Close()
+
+	sign := func(kid string, method jwt.SigningMethod, signingKey interface{}) map[string]string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "user-7", "exp": time.Now().Add(time.Hour).Unix()})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(signingKey)
+		require.NoError(t, err)
+		return map[string]string{"Authorization": "Bearer " + signed}
+	}
+
+	configs := map[string]*database.JWTAuth{
+		"inline": {JWKS: document},
+		"url":    {JWKSURL: server.URL + "/.well-known/jwks.json"},
+	}
+	for name, jwtConfig := range configs {
+		t.Run(name, func(t *testing.T) {
+			config, _ := json.Marshal(database.AdvanceConfigProject{Auth: &database.AuthConfig{Type: database.AuthJWT, JWT: jwtConfig}})
+			call := newAuthTestProject(t, string(config), "")
+
+			resp, body, decision := call(http.MethodGet, "/users", sign("key-1", jwt.SigningMethodRS256, key))
+			assert.Equal(t, http.StatusOK, resp.StatusCode, body)
+			assert.Equal(t, "user-7", decision.Subject)
+
+			resp, _, decision = call(http.MethodGet, "/users", sign("key-2", jwt.SigningMethodRS256, key))
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Contains(t, decision.Reason, `no JWKS key with kid "key-2"`)
+
+			// An HMAC token signed with the public key must not pass as an RSA one
+			resp, _, _ = call(http.MethodGet, "/users", sign("key-1", jwt.SigningMethodHS256, key.PublicKey.N.Bytes()))
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+
+	// The fetched document is cached; the unknown kid does not refetch it right away
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestHandleRequest_AuthGRPC(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	call := newAuthTestProject(t, `{"auth":{"type":"api_key","apiKey":{"header":"X-API-Key","keys":["key-1234567890"]}}}`, "")
+
+	resp, _, decision := call(http.MethodPost, "/orders.v1.Orders/GetOrder", map[string]string{"Content-Type": "application/grpc"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "16", resp.Header.Get("Grpc-Status"))
+	assert.Equal(t, AuthUnauthorized, decision.Result)
+}
+
+func TestMatchesRules_JWT(t *testing.T) {
+	token := signTestJWT(t, "any", jwt.MapClaims{
+		"sub":   "user-42",
+		"scope": "orders:read orders:write",
+		"roles": []string{"admin", "billing"},
+		"org":   map[string]interface{}{"id": 7},
+	})
+
+	tests := []struct {
+		name     string
+		rule     database.MockRule
+		token    string
+		expected bool
+	}{
+		{name: "subject", rule: database.MockRule{Type: "jwt", Key: "sub", Operator: OperatorEquals, Value: "user-42"}, token: token, expected: true},
+		{name: "subject mismatch", rule: database.MockRule{Type: "jwt", Key: "sub", Operator: OperatorEquals, Value: "user-1"}, token: token},
+		{name: "one scope", rule: database.MockRule{Type: "jwt", Key: "scope", Operator: OperatorEquals, Value: "orders:write"}, token: token, expected: true},
+		{name: "every role", rule: database.MockRule{Type: "jwt", Key: "roles", Operator: OperatorStartsWith, Value: "b", Match: "all"}, token: token},
+		{name: "jsonpath", rule: database.MockRule{Type: "jwt", Key: "$.org.id", Operator: OperatorGreater, Value: "5"}, token: token, expected: true},
+		{name: "missing claim", rule: database.MockRule{Type: "jwt", Key: "email", Operator: OperatorNotExists}, token: token, expected: true},
+		{name: "no token", rule: database.MockRule{Type: "jwt", Key: "sub", Operator: OperatorExists}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/orders", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			response := database.MockResponse{Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, req))
+		})
+	}
+}
+
+func TestValidateRule_JWT(t *testing.T) {
+	assert.NoError(t, ValidateRule(&database.MockRule{Type: "jwt", Key: "sub", Operator: OperatorEquals, Value: "user-42"}))
+	assert.NoError(t, ValidateRule(&database.MockRule{Type: "jwt", Key: "$.org.id", Operator: OperatorExists}))
+	assert.ErrorIs(t, ValidateRule(&database.MockRule{Type: "jwt", Operator: OperatorExists}), ErrInvalidRule)
+	assert.ErrorIs(t, ValidateRule(&database.MockRule{Type: "jwt", Key: "$.org[", Operator: OperatorExists}), ErrInvalidRule)
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	assert.Equal(t, "***", maskAPIKey("short"))
+	assert.Equal(t, "sk_l***", maskAPIKey("sk_live_123456"))
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/requestctx"
+	"beo-echo/backend/src/echo/xpath"
+)
+
+// selectBodyValues returns the request body values a body rule key selects
+// The key is read according to the body format:
+//
+//	JSON       JSONPath ("items[*].sku")
+//	XML        XPath ("//Order/@id")
+//	form       field name ("email", "tags" also finds "tags[]")
+//	multipart  part name; file parts give their filename, and "avatar.content_type"
+//	           or "avatar.size" give the file's content type or size
+//
+// definite is true when the key can select at most one value, so a JSON array or object
+// it selects is compared as a whole. ok is false when the body is not in a parsed format
+// or the key is not valid for it.
+func selectBodyValues(request *requestctx.Request, key string) (values []interface{}, definite bool, ok bool) {
+	switch request.Format() {
+	case requestctx.FormatJSON:
+		parsed, _ := request.JSON()
+		path, err := jsonpath.Compile(key)
+		if err != nil {
+			return nil, false, false
+		}
+		return path.Find(parsed), path.Definite(), true
+
+	case requestctx.FormatXML:
+		if key == "" {
+			return nil, false, false
+		}
+		root, _ := request.XML()
+		expr, err := xpath.Compile(key)
+		if err != nil {
+			return nil, false, false
+		}
+		return stringValues(expr.Find(root)), false, true
+
+	case requestctx.FormatForm:
+		if key == "" {
+			return nil, false, false
+		}
+		return stringValues(formValues(request.Form(), key)), false, true
+
+	case requestctx.FormatMultipart:
+		form, parsed := request.Multipart()
+		if !parsed || key == "" {
+			return nil, false, false
+		}
+		return stringValues(multipartValues(form, key)), false, true
+	}
+	return nil, false, false
+}
+
+// multipartValues returns the values of a field, the filenames of a file part,
+// or a property of a file part named "<part>.filename", "<part>.content_type" or "<part>.size"
+func multipartValues(form *requestctx.MultipartForm, key string) []string {
+	values := append([]string(nil), formValues(form.Fields, key)...)
+	for _, file := range form.Files[key] {
+		values = append(values, file.Filename)
+	}
+	for _, file := range form.Files[key+"[]"] {
+		values = append(values, file.Filename)
+	}
+	if len(values) > 0 {
+		return values
+	}
+
+	dot := strings.LastIndexByte(key, '.')
+	if dot < 0 {
+		return nil
+	}
+	for _, file := range form.Files[key[:dot]] {
+		switch key[dot+1:] {
+		case "filename":
+			values = append(values, file.Filename)
+		case "content_type":
+			values = append(values, file.ContentType)
+		case "size":
+			values = append(values, strconv.FormatInt(file.Size, 10))
+		}
+	}
+	return values
+}
+
+// formValues returns the values of a form field, also found under the "name[]" array convention
+func formValues(fields url.Values, key string) []string {
+	if values, ok := fields[key]; ok {
+		return values
+	}
+	return fields[key+"[]"]
+}
+
+func stringValues(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
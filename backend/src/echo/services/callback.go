@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+)
+
+// maxCallbackResponseBytes caps how much of a callback response body is kept in the log
+const maxCallbackResponseBytes = 1 << 20
+
+// callbackTimeout bounds a single callback attempt
+const callbackTimeout = 30 * time.Second
+
+var callbackClient = &http.Client{Timeout: callbackTimeout}
+
+// CallbackLogger stores the request log of a sent callback
+type CallbackLogger func(entry *database.RequestLog)
+
+var (
+	callbackLoggerMu sync.RWMutex
+	callbackLogger   CallbackLogger
+)
+
+// SetCallbackLogger sets where callback request logs are sent
+// The request logger middleware registers itself so callbacks are streamed and saved
+// like any other request; without a logger the outcome is only written to the app log.
+func SetCallbackLogger(logger CallbackLogger) {
+	callbackLoggerMu.Lock()
+	defer callbackLoggerMu.Unlock()
+	callbackLogger = logger
+}
+
+func logCallback(entry *database.RequestLog) {
+	callbackLoggerMu.RLock()
+	logger := callbackLogger
+	callbackLoggerMu.RUnlock()
+	if logger != nil {
+		logger(entry)
+	}
+}
+
+// pendingCallback is a callback rendered against the original request, ready to be sent
+type pendingCallback struct {
+	projectID string
+	config    database.CallbackConfig
+	method    string
+	url       string
+	headers   map[string]string
+	body      string
+	renderErr error
+}
+
+// ValidateCallbacks checks the callbacks JSON of a response and the templates it contains
+func ValidateCallbacks(callbacksJSON string) error {
+	callbacks, err := database.ParseCallbacks(callbacksJSON)
+	if err != nil {
+		return err
+	}
+	for i, callback := range callbacks {
+		values := map[string]string{"url": callback.URL, "body": callback.Body}
+		for key, value := range callback.Headers {
+			values["header:"+key] = value
+		}
+		for name, value := range values {
+			if !isTemplate(value) {
+				continue
+			}
+			if _, err := parseResponseTemplate(name, value, nil); err != nil {
+				return fmt.Errorf("callbacks[%d]: invalid %s template: %w", i, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scheduleCallbacks sends the callbacks of a served response in the background
+// Templates are rendered right away against the original request; delays, retries and
+// logging happen after the mock response has been returned.
+func scheduleCallbacks(projectID string, mockResp database.MockResponse, path string, req *http.Request) {
+	callbacks, err := database.ParseCallbacks(mockResp.Callbacks)
+	if err != nil {
+		log.Error().Err(err).Str("response_id", mockResp.ID).Msg("Invalid response callbacks")
+		return
+	}
+	if len(callbacks) == 0 {
+		return
+	}
+
+	tctx := NewTemplateContext(req, path, pathParamsFromRequest(req))
+	for _, callback := range callbacks {
+		pending := renderCallback(projectID, callback, tctx)
+		go pending.run()
+	}
+}
+
+// renderCallback renders the URL, headers and body of a callback
+// A rendering error is kept so the failed callback still shows up in the logs.
+func renderCallback(projectID string, config database.CallbackConfig, tctx *TemplateContext) *pendingCallback {
+	pending := &pendingCallback{
+		projectID: projectID,
+		config:    config,
+		method:    strings.ToUpper(config.Method),
+		url:       config.URL,
+		headers:   map[string]string{},
+	}
+	if pending.method == "" {
+		pending.method = http.MethodPost
+	}
+
+	var err error
+	if pending.url, err = renderTemplate("url", config.URL, tctx); err != nil {
+		pending.renderErr = fmt.Errorf("failed to render callback url template: %w", err)
+		return pending
+	}
+	if pending.body, err = renderTemplate("body", config.Body, tctx); err != nil {
+		pending.renderErr = fmt.Errorf("failed to render callback body template: %w", err)
+		return pending
+	}
+	for key, value := range config.Headers {
+		if pending.headers[key], err = renderTemplate("header:"+key, value, tctx); err != nil {
+			pending.renderErr = fmt.Errorf("failed to render callback header %q template: %w", key, err)
+			return pending
+		}
+	}
+
+	if target, err := url.Parse(pending.url); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		pending.renderErr = fmt.Errorf("callback url must be an absolute http or https URL: %s", pending.url)
+	}
+	return pending
+}
+
+// run waits for the callback delay, sends it with retries and logs the final attempt
+func (p *pendingCallback) run() {
+	l := log.With().Str("func", "services.callback").Str("project_id", p.projectID).Str("url", p.url).Logger()
+
+	if p.config.DelayMs > 0 {
+		time.Sleep(time.Duration(p.config.DelayMs) * time.Millisecond)
+	}
+
+	if p.renderErr != nil {
+		l.Error().Err(p.renderErr).Msg("Callback not sent")
+		logCallback(p.requestLog(nil, nil, p.renderErr, 0))
+		return
+	}
+
+	attempts := 1
+	if p.config.Retry != nil {
+		attempts = p.config.Retry.MaxAttempts
+	}
+
+	var (
+		resp    *http.Response
+		body    []byte
+		err     error
+		latency time.Duration
+	)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(p.config.Retry.Backoff(attempt-1)) * time.Millisecond)
+		}
+
+		start := time.Now()
+		resp, body, err = p.send()
+		latency = time.Since(start)
+
+		if !shouldRetryCallback(resp, err) {
+			break
+		}
+		if attempt < attempts {
+			event := l.Warn().Err(err).Int("attempt", attempt)
+			if resp != nil {
+				event = event.Int("status", resp.StatusCode)
+			}
+			event.Msg("Callback failed, retrying")
+		}
+	}
+
+	if err != nil {
+		l.Error().Err(err).Msg("Callback failed")
+	} else {
+		l.Info().Int("status", resp.StatusCode).Msg("Callback sent")
+	}
+	logCallback(p.requestLog(resp, body, err, latency))
+}
+
+// send performs a single callback attempt, returning the response and its body
+func (p *pendingCallback) send() (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, p.method, p.url, strings.NewReader(p.body))
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	if p.body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCallbackResponseBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// shouldRetryCallback reports whether a callback attempt failed in a way worth retrying
+func shouldRetryCallback(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// requestLog builds the request log of a callback outcome
+// Callbacks that could not be delivered are logged with status 0 and the error as body.
+func (p *pendingCallback) requestLog(resp *http.Response, body []byte, err error, latency time.Duration) *database.RequestLog {
+	entry := &database.RequestLog{
+		ID:             uuid.New().String(),
+		ProjectID:      p.projectID,
+		Method:         p.method,
+		Path:           p.url,
+		RequestHeaders: headersToJSON(p.headers),
+		RequestBody:    p.body,
+		LatencyMS:      int(latency.Milliseconds()),
+		ExecutionMode:  database.ModeMock,
+		Source:         database.RequestSourceCallback,
+		CreatedAt:      time.Now(),
+	}
+	if target, parseErr := url.Parse(p.url); parseErr == nil && target.RawQuery != "" {
+		entry.QueryParams = target.RawQuery
+		target.RawQuery = ""
+		entry.Path = target.String()
+	}
+
+	if err != nil {
+		entry.ResponseBody = err.Error()
+		return entry
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for key, values := range resp.Header {
+		headers[key] = strings.Join(values, "; ")
+	}
+	entry.ResponseStatus = resp.StatusCode
+	entry.ResponseHeaders = headersToJSON(headers)
+	entry.ResponseBody = string(body)
+	return entry
+}
+
+func headersToJSON(headers map[string]string) string {
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
+}
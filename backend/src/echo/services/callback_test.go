@@ -0,0 +1,142 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+// captureCallbackLogs routes callback logs to a channel for the duration of a test
+func captureCallbackLogs(t *testing.T) <-chan *database.RequestLog {
+	logs := make(chan *database.RequestLog, 10)
+	SetCallbackLogger(func(entry *database.RequestLog) { logs <- entry })
+	t.Cleanup(func() { SetCallbackLogger(nil) })
+	return logs
+}
+
+func waitCallbackLog(t *testing.T, logs <-chan *database.RequestLog) *database.RequestLog {
+	t.Helper()
+	select {
+	case entry := <-logs:
+		return entry
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback was not logged")
+		return nil
+	}
+}
+
+func TestScheduleCallbacks_RendersAndLogs(t *testing.T) {
+	type delivery struct {
+		method, path, signature, body string
+	}
+	deliveries := make(chan delivery, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{method: r.Method, path: r.URL.Path, signature: r.Header.Get("X-Signature"), body: string(body)}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	logs := captureCallbackLogs(t)
+	req := newTemplateTestRequest("POST", "http://localhost/payments", `{"id":"pay_1","amount":10}`, map[string]string{"Content-Type": "application/json"})
+	mockResp := database.MockResponse{Callbacks: `[{
+		"url": "` + server.URL + `/hooks/{{body \"id\"}}",
+		"headers": {"X-Signature": "sig-{{body \"amount\"}}"},
+		"body": "{\"event\":\"payment.succeeded\",\"id\":\"{{body \"id\"}}\"}"
+	}]`}
+
+	scheduleCallbacks("project-1", mockResp, "/payments", req)
+	entry := waitCallbackLog(t, logs)
+	received := <-deliveries
+
+	assert.Equal(t, http.MethodPost, received.method)
+	assert.Equal(t, "/hooks/pay_1", received.path)
+	assert.Equal(t, "sig-10", received.signature)
+	assert.JSONEq(t, `{"event":"payment.succeeded","id":"pay_1"}`, received.body)
+
+	assert.Equal(t, "project-1", entry.ProjectID)
+	assert.Equal(t, database.RequestSourceCallback, entry.Source)
+	assert.Equal(t, server.URL+"/hooks/pay_1", entry.Path)
+	assert.Equal(t, http.StatusOK, entry.ResponseStatus)
+	assert.Equal(t, `{"ok":true}`, entry.ResponseBody)
+	assert.Equal(t, received.body, entry.RequestBody)
+}
+
+func TestScheduleCallbacks_RetriesWithBackoff(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logs := captureCallbackLogs(t)
+	mockResp := database.MockResponse{Callbacks: `[{"url":"` + server.URL + `","retry":{"maxAttempts":5,"backoffMs":10}}]`}
+
+	scheduleCallbacks("project-1", mockResp, "/", newTemplateTestRequest("GET", "http://localhost/", "", nil))
+	entry := waitCallbackLog(t, logs)
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	assert.Equal(t, http.StatusNoContent, entry.ResponseStatus)
+}
+
+func TestScheduleCallbacks_Failures(t *testing.T) {
+	t.Run("client errors are not retried", func(t *testing.T) {
+		var hits int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		logs := captureCallbackLogs(t)
+		mockResp := database.MockResponse{Callbacks: `[{"url":"` + server.URL + `","retry":{"maxAttempts":3,"backoffMs":10}}]`}
+		scheduleCallbacks("project-1", mockResp, "/", newTemplateTestRequest("GET", "http://localhost/", "", nil))
+
+		assert.Equal(t, http.StatusBadRequest, waitCallbackLog(t, logs).ResponseStatus)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("unreachable target is logged with status 0", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		logs := captureCallbackLogs(t)
+		mockResp := database.MockResponse{Callbacks: `[{"url":"` + server.URL + `/hook","retry":{"maxAttempts":2,"backoffMs":10}}]`}
+		scheduleCallbacks("project-1", mockResp, "/", newTemplateTestRequest("GET", "http://localhost/", "", nil))
+
+		entry := waitCallbackLog(t, logs)
+		assert.Equal(t, 0, entry.ResponseStatus)
+		assert.Contains(t, entry.ResponseBody, "connection refused")
+	})
+
+	t.Run("rendered url must be absolute", func(t *testing.T) {
+		logs := captureCallbackLogs(t)
+		mockResp := database.MockResponse{Callbacks: `[{"url":"{{body \"callback\"}}"}]`}
+		scheduleCallbacks("project-1", mockResp, "/", newTemplateTestRequest("POST", "http://localhost/", `{"callback":"/relative"}`, map[string]string{"Content-Type": "application/json"}))
+
+		entry := waitCallbackLog(t, logs)
+		assert.Equal(t, 0, entry.ResponseStatus)
+		assert.Contains(t, entry.ResponseBody, "absolute http or https URL")
+	})
+}
+
+func TestValidateCallbacks(t *testing.T) {
+	require.NoError(t, ValidateCallbacks(""))
+	require.NoError(t, ValidateCallbacks(`[{"url":"https://example.com/{{param \"id\"}}","body":"{{.request.rawBody}}"}]`))
+	assert.ErrorContains(t, ValidateCallbacks(`[{"url":"https://example.com","body":"{{.request.body"}]`), "callbacks[0]: invalid body template")
+	assert.ErrorContains(t, ValidateCallbacks(`[{"url":"https://example.com","headers":{"X-Id":"{{end}}"}}]`), `invalid header:X-Id template`)
+	assert.ErrorContains(t, ValidateCallbacks(`[{"method":"POST"}]`), "callback url is required")
+}
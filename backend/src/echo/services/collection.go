@@ -0,0 +1,398 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// ResponseModeCRUD serves REST semantics from a project collection instead of configured responses
+const ResponseModeCRUD = "crud"
+
+// Default page size when _page is given without _limit
+const defaultCollectionPageSize = 10
+
+// collectionState holds the live items of a collection
+type collectionState struct {
+	mu    sync.Mutex
+	items []map[string]interface{}
+}
+
+// Global state map for collections, keyed by collection ID
+// Items live in memory and are seeded on first use, so a restart restores the seed.
+var collectionStates sync.Map
+
+// ParseCollectionSeed parses the seed of a collection into items
+// An empty seed is an empty collection.
+func ParseCollectionSeed(seed string) ([]map[string]interface{}, error) {
+	if strings.TrimSpace(seed) == "" {
+		return []map[string]interface{}{}, nil
+	}
+
+	var items []map[string]interface{}
+	if err := json.Unmarshal([]byte(seed), &items); err != nil {
+		return nil, fmt.Errorf("seed must be a JSON array of objects: %w", err)
+	}
+	return items, nil
+}
+
+// loadCollectionState returns the live state of a collection, seeding it on first use
+func loadCollectionState(collection *database.MockCollection) (*collectionState, error) {
+	if val, ok := collectionStates.Load(collection.ID); ok {
+		return val.(*collectionState), nil
+	}
+
+	items, err := ParseCollectionSeed(collection.Seed)
+	if err != nil {
+		return nil, err
+	}
+	val, _ := collectionStates.LoadOrStore(collection.ID, &collectionState{items: items})
+	return val.(*collectionState), nil
+}
+
+// ResetCollection restores a collection to its seed
+func ResetCollection(collection *database.MockCollection) error {
+	items, err := ParseCollectionSeed(collection.Seed)
+	if err != nil {
+		return err
+	}
+	collectionStates.Store(collection.ID, &collectionState{items: items})
+	return nil
+}
+
+// ForgetCollection drops the live state of a deleted collection
+func ForgetCollection(collectionID string) {
+	collectionStates.Delete(collectionID)
+}
+
+// CollectionItems returns a copy of the live items of a collection
+func CollectionItems(collection *database.MockCollection) ([]map[string]interface{}, error) {
+	state, err := loadCollectionState(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	items := make([]map[string]interface{}, len(state.items))
+	copy(items, state.items)
+	return items, nil
+}
+
+// handleCollectionRequest serves an endpoint in "crud" response mode
+//
+// The collection is named by the endpoint advance_config "collection". When the
+// endpoint path captures an item id (the ":id" param, or the param named by
+// "idParam") the request targets that item, otherwise the whole collection:
+//
+//	GET    /users      list (filter, _sort, _order, _page, _limit)
+//	POST   /users      create
+//	GET    /users/:id  get
+//	PUT    /users/:id  replace
+//	PATCH  /users/:id  merge
+//	DELETE /users/:id  delete
+func (s *MockService) handleCollectionRequest(projectID string, endpoint *database.MockEndpoint, req *http.Request) *http.Response {
+	config, err := database.ParseEndpointAdvanceConfig(endpoint.AdvanceConfig)
+	if err != nil || config.Collection == "" {
+		return createErrorResponse(http.StatusInternalServerError, "Endpoint in crud mode has no collection configured")
+	}
+
+	collection, err := s.Repo.FindCollectionByName(projectID, config.Collection)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Collection %q not found", config.Collection))
+	}
+
+	state, err := loadCollectionState(collection)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Collection %q has an invalid seed: %s", collection.Name, err.Error()))
+	}
+
+	idField := collection.IDField
+	if idField == "" {
+		idField = "id"
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	idParam := config.IDParam
+	if idParam == "" {
+		idParam = "id"
+	}
+	itemID := pathParamsFromRequest(req)[idParam]
+	if itemID == "" {
+		switch req.Method {
+		case http.MethodGet:
+			return listCollectionItems(state.items, req.URL.Query())
+		case http.MethodPost:
+			return createCollectionItem(state, idField, req)
+		default:
+			return createErrorResponse(http.StatusMethodNotAllowed, "Method not allowed on collection")
+		}
+	}
+
+	index := findCollectionItem(state.items, idField, itemID)
+	if index < 0 {
+		return createErrorResponse(http.StatusNotFound, "Item not found")
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		return createJSONValueResponse(http.StatusOK, state.items[index])
+	case http.MethodPut, http.MethodPatch:
+		input, errResp := readCollectionItem(req)
+		if errResp != nil {
+			return errResp
+		}
+		item := input
+		if req.Method == http.MethodPatch {
+			item = make(map[string]interface{}, len(state.items[index])+len(input))
+			for key, value := range state.items[index] {
+				item[key] = value
+			}
+			for key, value := range input {
+				item[key] = value
+			}
+		}
+		// The identifier always comes from the stored item
+		item[idField] = state.items[index][idField]
+		state.items[index] = item
+		return createJSONValueResponse(http.StatusOK, item)
+	case http.MethodDelete:
+		state.items = append(state.items[:index:index], state.items[index+1:]...)
+		return &http.Response{
+			StatusCode: http.StatusNoContent,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+		}
+	default:
+		return createErrorResponse(http.StatusMethodNotAllowed, "Method not allowed on collection item")
+	}
+}
+
+// findCollectionItem returns the index of the item with the given id, or -1
+func findCollectionItem(items []map[string]interface{}, idField, id string) int {
+	for i, item := range items {
+		if interfaceToString(item[idField]) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// readCollectionItem reads a JSON object from the request body
+func readCollectionItem(req *http.Request) (map[string]interface{}, *http.Response) {
+	// Decode into a fresh map since the item is modified and stored
+	var item map[string]interface{}
+	if err := json.Unmarshal(requestctx.From(req).Body, &item); err != nil || item == nil {
+		return nil, createErrorResponse(http.StatusBadRequest, "Request body must be a JSON object")
+	}
+	return item, nil
+}
+
+// createCollectionItem adds an item, generating its id when missing
+func createCollectionItem(state *collectionState, idField string, req *http.Request) *http.Response {
+	item, errResp := readCollectionItem(req)
+	if errResp != nil {
+		return errResp
+	}
+
+	if id, ok := item[idField]; ok && id != nil {
+		if findCollectionItem(state.items, idField, interfaceToString(id)) >= 0 {
+			return createErrorResponse(http.StatusConflict, fmt.Sprintf("Item with %s %s already exists", idField, interfaceToString(id)))
+		}
+	} else {
+		item[idField] = nextCollectionID(state.items, idField)
+	}
+
+	state.items = append(state.items, item)
+	return createJSONValueResponse(http.StatusCreated, item)
+}
+
+// nextCollectionID continues numeric ids, and falls back to UUIDs for anything else
+func nextCollectionID(items []map[string]interface{}, idField string) interface{} {
+	maxID := float64(0)
+	for _, item := range items {
+		id, ok := item[idField].(float64)
+		if !ok {
+			return uuid.New().String()
+		}
+		if id > maxID {
+			maxID = id
+		}
+	}
+	return maxID + 1
+}
+
+// listCollectionItems filters, sorts and paginates items from query params
+//
+//	?status=active         equals (repeat the key to match any value)
+//	?name_like=jo          case-insensitive contains
+//	?status_ne=deleted     not equals
+//	?age_gte=18&age_lte=65 range, numeric when both sides are numbers
+//	?_sort=name,-age       sort fields, "-" for descending (or _order=desc)
+//	?_page=2&_limit=20     pagination; X-Total-Count holds the filtered total
+func listCollectionItems(items []map[string]interface{}, query url.Values) *http.Response {
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if matchesCollectionFilters(item, query) {
+			result = append(result, item)
+		}
+	}
+
+	if sortFields := query.Get("_sort"); sortFields != "" {
+		sortCollectionItems(result, strings.Split(sortFields, ","), strings.Split(query.Get("_order"), ","))
+	}
+
+	total := len(result)
+
+	limit, _ := strconv.Atoi(query.Get("_limit"))
+	page, _ := strconv.Atoi(query.Get("_page"))
+	if page > 0 && limit <= 0 {
+		limit = defaultCollectionPageSize
+	}
+	if limit > 0 {
+		if page < 1 {
+			page = 1
+		}
+		start := (page - 1) * limit
+		if start > len(result) {
+			start = len(result)
+		}
+		end := start + limit
+		if end > len(result) {
+			end = len(result)
+		}
+		result = result[start:end]
+	}
+
+	resp := createJSONValueResponse(http.StatusOK, result)
+	resp.Header.Set("X-Total-Count", strconv.Itoa(total))
+	return resp
+}
+
+// matchesCollectionFilters checks an item against every non-reserved query param
+func matchesCollectionFilters(item map[string]interface{}, query url.Values) bool {
+	for key, values := range query {
+		if strings.HasPrefix(key, "_") || len(values) == 0 {
+			continue
+		}
+
+		field, operator := key, ""
+		for _, suffix := range []string{"_like", "_ne", "_gte", "_lte"} {
+			if strings.HasSuffix(key, suffix) {
+				field, operator = strings.TrimSuffix(key, suffix), suffix
+				break
+			}
+		}
+
+		value, found := getNestedValueEx(item, field)
+		actual := interfaceToString(value)
+
+		switch operator {
+		case "_like":
+			if !found || !strings.Contains(strings.ToLower(actual), strings.ToLower(values[0])) {
+				return false
+			}
+		case "_ne":
+			for _, expected := range values {
+				if found && actual == expected {
+					return false
+				}
+			}
+		case "_gte":
+			if !found || compareCollectionValues(value, values[0]) < 0 {
+				return false
+			}
+		case "_lte":
+			if !found || compareCollectionValues(value, values[0]) > 0 {
+				return false
+			}
+		default:
+			matched := false
+			for _, expected := range values {
+				if found && actual == expected {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// compareCollectionValues compares an item value with a query value
+// Numbers compare numerically, everything else as strings.
+func compareCollectionValues(value interface{}, expected string) int {
+	if number, ok := value.(float64); ok {
+		if expectedNumber, err := strconv.ParseFloat(expected, 64); err == nil {
+			switch {
+			case number < expectedNumber:
+				return -1
+			case number > expectedNumber:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(interfaceToString(value), expected)
+}
+
+// sortCollectionItems sorts items by the given fields in order of precedence
+func sortCollectionItems(items []map[string]interface{}, fields, orders []string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		for n, field := range fields {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			if n < len(orders) && strings.EqualFold(strings.TrimSpace(orders[n]), "desc") {
+				desc = true
+			}
+
+			left, _ := getNestedValueEx(items[i], field)
+			right, _ := getNestedValueEx(items[j], field)
+			cmp := compareCollectionValues(left, interfaceToString(right))
+			if cmp == 0 {
+				continue
+			}
+			if desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// createJSONValueResponse encodes value as a JSON response
+func createJSONValueResponse(statusCode int, value interface{}) *http.Response {
+	jsonBody, err := json.Marshal(value)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, "Failed to encode response: "+err.Error())
+	}
+
+	resp := &http.Response{
+		StatusCode:    statusCode,
+		Body:          io.NopCloser(bytes.NewReader(jsonBody)),
+		Header:        make(http.Header),
+		ContentLength: int64(len(jsonBody)),
+	}
+	resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return resp
+}
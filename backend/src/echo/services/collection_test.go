@@ -0,0 +1,230 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+func decodeCollectionResponse(t *testing.T, resp *http.Response, out interface{}) {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(body, out), string(body))
+}
+
+func TestListCollectionItems(t *testing.T) {
+	items, err := ParseCollectionSeed(`[
+		{"id": 1, "name": "Jane", "age": 31, "status": "active", "address": {"city": "Oslo"}},
+		{"id": 2, "name": "John", "age": 25, "status": "inactive", "address": {"city": "Bergen"}},
+		{"id": 3, "name": "Joan", "age": 40, "status": "active", "address": {"city": "Oslo"}},
+		{"id": 4, "name": "Mark", "age": 19, "status": "active", "address": {"city": "Tromso"}}
+	]`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		query       string
+		expectedIDs []float64
+		total       string
+	}{
+		{name: "no query returns all", query: "", expectedIDs: []float64{1, 2, 3, 4}, total: "4"},
+		{name: "equals filter", query: "status=active", expectedIDs: []float64{1, 3, 4}, total: "3"},
+		{name: "repeated key matches any", query: "name=Jane&name=Mark", expectedIDs: []float64{1, 4}, total: "2"},
+		{name: "nested field", query: "address.city=Oslo", expectedIDs: []float64{1, 3}, total: "2"},
+		{name: "like filter", query: "name_like=jo", expectedIDs: []float64{2, 3}, total: "2"},
+		{name: "not equals filter", query: "status_ne=active", expectedIDs: []float64{2}, total: "1"},
+		{name: "numeric range", query: "age_gte=20&age_lte=35", expectedIDs: []float64{1, 2}, total: "2"},
+		{name: "sort ascending", query: "_sort=age", expectedIDs: []float64{4, 2, 1, 3}, total: "4"},
+		{name: "sort descending with prefix", query: "_sort=-age", expectedIDs: []float64{3, 1, 2, 4}, total: "4"},
+		{name: "sort with _order", query: "_sort=name&_order=desc", expectedIDs: []float64{4, 2, 3, 1}, total: "4"},
+		{name: "pagination", query: "_sort=id&_page=2&_limit=3", expectedIDs: []float64{4}, total: "4"},
+		{name: "limit only", query: "_limit=2", expectedIDs: []float64{1, 2}, total: "4"},
+		{name: "page past the end", query: "_page=5&_limit=2", expectedIDs: []float64{}, total: "4"},
+		{name: "filter then paginate", query: "status=active&_limit=1&_page=2", expectedIDs: []float64{3}, total: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			resp := listCollectionItems(items, query)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.total, resp.Header.Get("X-Total-Count"))
+
+			var result []map[string]interface{}
+			decodeCollectionResponse(t, resp, &result)
+
+			ids := make([]float64, 0, len(result))
+			for _, item := range result {
+				ids = append(ids, item["id"].(float64))
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestParseCollectionSeed(t *testing.T) {
+	items, err := ParseCollectionSeed("")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	_, err = ParseCollectionSeed(`{"id": 1}`)
+	assert.Error(t, err)
+
+	_, err = ParseCollectionSeed(`[1, 2]`)
+	assert.Error(t, err)
+}
+
+func TestHandleCollectionRequest_CRUD(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	db := database.GetDB()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "Collections", Alias: "collections-" + uuid.New().String()[:8]}
+	require.NoError(t, db.Create(project).Error)
+
+	collection := &database.MockCollection{
+		ProjectID: project.ID,
+		Name:      "users",
+		IDField:   "id",
+		Seed:      `[{"id": 1, "name": "Jane"}]`,
+	}
+	require.NoError(t, db.Create(collection).Error)
+	t.Cleanup(func() { ForgetCollection(collection.ID) })
+
+	endpoint := &database.MockEndpoint{ProjectID: project.ID, ResponseMode: ResponseModeCRUD, AdvanceConfig: `{"collection":"users"}`}
+	service := &MockService{Repo: repositories.NewMockRepository(db)}
+
+	call := func(method, target, body string, params map[string]string) *http.Response {
+		req, _ := http.NewRequest(method, "http://localhost"+target, strings.NewReader(body))
+		req = req.WithContext(ContextWithRequestMeta(req.Context(), &RequestMeta{PathParams: params}))
+		return service.handleCollectionRequest(project.ID, endpoint, req)
+	}
+	item := func(id string) map[string]string { return map[string]string{"id": id} }
+
+	// Create assigns the next numeric id
+	resp := call("POST", "/users", `{"name": "John"}`, nil)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created map[string]interface{}
+	decodeCollectionResponse(t, resp, &created)
+	assert.Equal(t, float64(2), created["id"])
+
+	// Duplicate ids are rejected
+	resp = call("POST", "/users", `{"id": 1, "name": "Dup"}`, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// Invalid bodies are rejected
+	resp = call("POST", "/users", `not json`, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Get returns the created item
+	resp = call("GET", "/users/2", "", item("2"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var fetched map[string]interface{}
+	decodeCollectionResponse(t, resp, &fetched)
+	assert.Equal(t, "John", fetched["name"])
+
+	// Patch merges fields and keeps the id
+	resp = call("PATCH", "/users/2", `{"id": 99, "role": "admin"}`, item("2"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var patched map[string]interface{}
+	decodeCollectionResponse(t, resp, &patched)
+	assert.Equal(t, map[string]interface{}{"id": float64(2), "name": "John", "role": "admin"}, patched)
+
+	// Put replaces the item
+	resp = call("PUT", "/users/2", `{"name": "Johnny"}`, item("2"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var replaced map[string]interface{}
+	decodeCollectionResponse(t, resp, &replaced)
+	assert.Equal(t, map[string]interface{}{"id": float64(2), "name": "Johnny"}, replaced)
+
+	// Delete removes it
+	resp = call("DELETE", "/users/2", "", item("2"))
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp = call("GET", "/users/2", "", item("2"))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Unsupported methods on the collection
+	resp = call("DELETE", "/users", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// Reset restores the seed
+	resp = call("POST", "/users", `{"name": "Temp"}`, nil)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.NoError(t, ResetCollection(collection))
+	items, err := CollectionItems(collection)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": float64(1), "name": "Jane"}}, items)
+}
+
+func TestHandleCollectionRequest_IDParam(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	db := database.GetDB()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "Collections", Alias: "collections-" + uuid.New().String()[:8]}
+	require.NoError(t, db.Create(project).Error)
+
+	collection := &database.MockCollection{
+		ProjectID: project.ID,
+		Name:      "users",
+		IDField:   "id",
+		Seed:      `[{"id": 1, "name": "Jane"}, {"id": 2, "name": "John"}]`,
+	}
+	require.NoError(t, db.Create(collection).Error)
+	t.Cleanup(func() { ForgetCollection(collection.ID) })
+
+	service := &MockService{Repo: repositories.NewMockRepository(db)}
+	call := func(advanceConfig, method, target string, params map[string]string) *http.Response {
+		endpoint := &database.MockEndpoint{ProjectID: project.ID, ResponseMode: ResponseModeCRUD, AdvanceConfig: advanceConfig}
+		req, _ := http.NewRequest(method, "http://localhost"+target, nil)
+		req = req.WithContext(ContextWithRequestMeta(req.Context(), &RequestMeta{PathParams: params}))
+		return service.handleCollectionRequest(project.ID, endpoint, req)
+	}
+
+	// A nested collection route lists items; its only param is not an item id
+	resp := call(`{"collection":"users"}`, "GET", "/tenants/acme/users", map[string]string{"tenant": "acme"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var listed []map[string]interface{}
+	decodeCollectionResponse(t, resp, &listed)
+	assert.Len(t, listed, 2)
+
+	// Nested item routes read the id from :id
+	resp = call(`{"collection":"users"}`, "GET", "/tenants/acme/users/2", map[string]string{"tenant": "acme", "id": "2"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var fetched map[string]interface{}
+	decodeCollectionResponse(t, resp, &fetched)
+	assert.Equal(t, "John", fetched["name"])
+
+	// idParam names another param
+	resp = call(`{"collection":"users","idParam":"userId"}`, "GET", "/tenants/acme/users/1", map[string]string{"tenant": "acme", "userId": "1"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	decodeCollectionResponse(t, resp, &fetched)
+	assert.Equal(t, "Jane", fetched["name"])
+
+	_, err := database.ParseEndpointAdvanceConfig(`{"idParam":"userId"}`)
+	assert.Error(t, err)
+}
+
+func TestHandleCollectionRequest_MissingCollection(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	service := &MockService{Repo: repositories.NewMockRepository(database.GetDB())}
+
+	req, _ := http.NewRequest("GET", "http://localhost/things", nil)
+
+	resp := service.handleCollectionRequest("no-project", &database.MockEndpoint{ResponseMode: ResponseModeCRUD}, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp = service.handleCollectionRequest("no-project", &database.MockEndpoint{ResponseMode: ResponseModeCRUD, AdvanceConfig: `{"collection":"things"}`}, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
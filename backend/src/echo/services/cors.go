@@ -0,0 +1,121 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"beo-echo/backend/src/database"
+)
+
+// defaultCORSMethods are allowed by preflights when the policy lists no methods
+var defaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// projectCORS returns the enabled CORS policy of a project, or nil
+func projectCORS(project *database.Project) *database.CORSPolicy {
+	if project == nil || project.AdvanceConfig == "" {
+		return nil
+	}
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil || config.CORS == nil || !config.CORS.Enabled {
+		return nil
+	}
+	return config.CORS
+}
+
+// isCORSPreflight reports whether req is a browser asking whether a cross-origin request is allowed
+func isCORSPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Origin") != "" && req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// handleCORSPreflight answers a preflight request from the CORS policy of the project
+// It returns nil when the project has no policy or req is not a preflight, so the request is served as usual.
+func handleCORSPreflight(project *database.Project, req *http.Request) *http.Response {
+	policy := projectCORS(project)
+	if policy == nil || !isCORSPreflight(req) {
+		return nil
+	}
+
+	origin := req.Header.Get("Origin")
+	if !policy.AllowsOrigin(origin) {
+		return createErrorResponse(http.StatusForbidden, "CORS: origin "+origin+" is not allowed")
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusNoContent,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	setCORSOrigin(resp.Header, policy, origin)
+
+	methods := policy.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	} else if containsWildcard(methods) {
+		methods = []string{req.Header.Get("Access-Control-Request-Method")}
+	}
+	resp.Header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if len(policy.AllowHeaders) > 0 && !containsWildcard(policy.AllowHeaders) {
+		resp.Header.Set("Access-Control-Allow-Headers", strings.Join(policy.AllowHeaders, ", "))
+	} else if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		resp.Header.Set("Access-Control-Allow-Headers", requested)
+		resp.Header.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if policy.MaxAgeSeconds > 0 {
+		resp.Header.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAgeSeconds))
+	}
+	return resp
+}
+
+// applyCORS sets the CORS headers of the project policy on a mock or proxied response
+// The policy replaces any CORS header of the response, so a disallowed origin gets none.
+func applyCORS(project *database.Project, req *http.Request, resp *http.Response) *http.Response {
+	policy := projectCORS(project)
+	if policy == nil || resp == nil {
+		return resp
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	for name := range resp.Header {
+		if strings.HasPrefix(strings.ToLower(name), "access-control-") {
+			resp.Header.Del(name)
+		}
+	}
+
+	origin := req.Header.Get("Origin")
+	if !policy.AllowsOrigin(origin) {
+		return resp
+	}
+	setCORSOrigin(resp.Header, policy, origin)
+	if len(policy.ExposeHeaders) > 0 {
+		resp.Header.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposeHeaders, ", "))
+	}
+	return resp
+}
+
+// setCORSOrigin sets the origin and credentials headers shared by preflights and responses
+// Browsers reject "*" on requests with credentials, so the origin is echoed back for them.
+func setCORSOrigin(header http.Header, policy *database.CORSPolicy, origin string) {
+	if policy.AllowsAnyOrigin() && !policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	}
+	if policy.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// containsWildcard reports whether values include "*"
+func containsWildcard(values []string) bool {
+	for _, value := range values {
+		if value == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+func TestHandleCORSPreflight(t *testing.T) {
+	preflight := func(origin, method, headers string) *http.Request {
+		req, _ := http.NewRequest(http.MethodOptions, "http://localhost/app/users", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		req      *http.Request
+		status   int
+		expected map[string]string
+	}{
+		{
+			name:   "any origin with default methods and requested headers",
+			config: `{"cors":{"enabled":true}}`,
+			req:    preflight("https://app.example.com", "PUT", "content-type, x-api-key"),
+			status: http.StatusNoContent,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS",
+				"Access-Control-Allow-Headers": "content-type, x-api-key",
+			},
+		},
+		{
+			name:   "listed origin with credentials, methods, headers and max age",
+			config: `{"cors":{"enabled":true,"allowOrigins":["https://app.example.com"],"allowMethods":["GET","POST"],"allowHeaders":["Content-Type"],"allowCredentials":true,"maxAgeSeconds":600}}`,
+			req:    preflight("https://app.example.com", "POST", "content-type"),
+			status: http.StatusNoContent,
+			expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "GET, POST",
+				"Access-Control-Allow-Headers":     "Content-Type",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:     "wildcard subdomain",
+			config:   `{"cors":{"enabled":true,"allowOrigins":["https://*.example.com"],"allowMethods":["*"]}}`,
+			req:      preflight("https://admin.example.com", "DELETE", ""),
+			status:   http.StatusNoContent,
+			expected: map[string]string{"Access-Control-Allow-Origin": "https://admin.example.com", "Access-Control-Allow-Methods": "DELETE"},
+		},
+		{
+			name:     "origin not allowed",
+			config:   `{"cors":{"enabled":true,"allowOrigins":["https://*.example.com"]}}`,
+			req:      preflight("https://example.com", "GET", ""),
+			status:   http.StatusForbidden,
+			expected: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:   "disabled policy",
+			config: `{"cors":{"enabled":false}}`,
+			req:    preflight("https://app.example.com", "GET", ""),
+		},
+		{
+			name:   "OPTIONS request that is not a preflight",
+			config: `{"cors":{"enabled":true}}`,
+			req:    preflight("https://app.example.com", "", ""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := handleCORSPreflight(&database.Project{AdvanceConfig: tt.config}, tt.req)
+			if tt.status == 0 {
+				assert.Nil(t, resp)
+				return
+			}
+			require.NotNil(t, resp)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			for name, value := range tt.expected {
+				assert.Equal(t, value, resp.Header.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestApplyCORS(t *testing.T) {
+	project := &database.Project{AdvanceConfig: `{"cors":{"enabled":true,"allowOrigins":["https://app.example.com"],"exposeHeaders":["X-Request-Id"]}}`}
+	newResponse := func() *http.Response {
+		header := http.Header{}
+		header.Set("Access-Control-Allow-Origin", "*")
+		header.Set("Access-Control-Allow-Methods", "GET")
+		header.Set("Content-Type", "application/json")
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody}
+	}
+	request := func(origin string) *http.Request {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/app/users", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		return req
+	}
+
+	resp := applyCORS(project, request("https://app.example.com"), newResponse())
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-Id", resp.Header.Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", resp.Header.Get("Vary"))
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	// The policy replaces the CORS headers of the response, even for origins it rejects
+	resp = applyCORS(project, request("https://evil.example.org"), newResponse())
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = applyCORS(project, request(""), newResponse())
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// Without a policy, responses keep their headers
+	resp = applyCORS(&database.Project{}, request("https://evil.example.org"), newResponse())
+	assert.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestHandleRequest_CORS(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	db := database.GetDB()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "https://upstream.example.com")
+		w.Write([]byte("proxied"))
+	}))
+	defer upstream.Close()
+
+	project := &database.Project{
+		ID:            uuid.New().String(),
+		Name:          "CORS",
+		Alias:         "cors-" + uuid.New().String()[:8],
+		Mode:          database.ModeProxy,
+		AdvanceConfig: `{"cors":{"enabled":true,"allowOrigins":["https://app.example.com"],"allowCredentials":true}}`,
+	}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	target := &database.ProxyTarget{ProjectID: project.ID, Label: "upstream", URL: upstream.URL}
+	require.NoError(t, db.Create(target).Error)
+	require.NoError(t, db.Model(project).Update("active_proxy_id", target.ID).Error)
+
+	endpoint := &database.MockEndpoint{ProjectID: project.ID, Method: "GET", Path: "/users", Enabled: true, ResponseMode: "static"}
+	require.NoError(t, db.Create(endpoint).Error)
+	require.NoError(t, db.Create(&database.MockResponse{EndpointID: endpoint.ID, StatusCode: 200, Body: `[]`, Enabled: true}).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	call := func(method, path string, headers map[string]string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, "http://localhost/"+project.Alias+path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		ctx := ContextWithRequestMeta(context.Background(), &RequestMeta{})
+		resp, err, _, _, _ := service.HandleRequest(ctx, project.Alias, method, "/"+project.Alias+path, req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	// Preflights are answered without an OPTIONS endpoint and never reach the proxy target
+	resp, _ := call(http.MethodOptions, "/orders", map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "POST"})
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+
+	resp, body := call(http.MethodGet, "/users", map[string]string{"Origin": "https://app.example.com"})
+	assert.Equal(t, `[]`, body)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp, body = call(http.MethodGet, "/orders", map[string]string{"Origin": "https://app.example.com"})
+	assert.Equal(t, "proxied", body)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+}
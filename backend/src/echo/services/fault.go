@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+)
+
+// resolveFaults returns the faults configured for a request
+// Faults defined on the endpoint replace the project faults.
+func resolveFaults(project *database.Project, endpoint *database.MockEndpoint) []database.FaultRule {
+	if endpoint != nil && endpoint.AdvanceConfig != "" {
+		if endpointConfig, err := database.ParseEndpointAdvanceConfig(endpoint.AdvanceConfig); err == nil && len(endpointConfig.Faults) > 0 {
+			return endpointConfig.Faults
+		}
+	}
+	if project != nil && project.AdvanceConfig != "" {
+		if projectConfig, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig); err == nil {
+			return projectConfig.Faults
+		}
+	}
+	return nil
+}
+
+// pickFault chooses at most one fault for a roll u in [0, 1)
+// Each fault owns a slice of the range sized by its probability.
+func pickFault(faults []database.FaultRule, u float64) *database.FaultRule {
+	var upper float64
+	for i := range faults {
+		upper += faults[i].Probability
+		if u < upper {
+			return &faults[i]
+		}
+	}
+	return nil
+}
+
+// injectFault rolls the configured faults for a response
+// Error and malformed JSON faults are applied to the returned response. The fault is
+// recorded on the request meta, where the mock handler picks up connection level faults.
+func (s *MockService) injectFault(ctx context.Context, project *database.Project, resp *http.Response) *http.Response {
+	meta := RequestMetaFromContext(ctx)
+	if meta == nil || resp == nil {
+		return resp
+	}
+
+	fault := pickFault(resolveFaults(project, meta.endpoint), rand.Float64())
+	if fault == nil {
+		return resp
+	}
+
+	switch fault.Type {
+	case database.FaultError:
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		resp = createErrorResponse(status, "Injected fault: "+http.StatusText(status))
+	case database.FaultMalformedJSON:
+		// Streams never end, so there is no complete body to corrupt
+		if IsStreamingResponse(resp) {
+			return resp
+		}
+		corrupted, err := corruptResponseBody(resp)
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to read response body for malformed JSON fault")
+			return resp
+		}
+		resp = corrupted
+	}
+
+	meta.Fault = fault
+	return resp
+}
+
+// corruptResponseBody replaces the body with a copy that no longer parses as JSON
+// Compressed bodies are decoded first so the client sees the broken document.
+func corruptResponseBody(resp *http.Response) (*http.Response, error) {
+	var body []byte
+	var err error
+	if resp.Body != nil {
+		defer resp.Body.Close()
+
+		var reader io.Reader = resp.Body
+		switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+		case "gzip":
+			gz, gzErr := gzip.NewReader(resp.Body)
+			if gzErr != nil {
+				return nil, gzErr
+			}
+			defer gz.Close()
+			reader = gz
+		case "br":
+			reader = brotli.NewReader(resp.Body)
+		}
+		if body, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+	}
+
+	body = corruptJSON(body)
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// corruptJSON drops the last character of a document and appends a dangling comma,
+// which leaves objects, arrays, strings and numbers unterminated
+func corruptJSON(body []byte) []byte {
+	trimmed := bytes.TrimRight(body, " \t\r\n")
+	if len(trimmed) == 0 {
+		return []byte("{")
+	}
+	corrupted := make([]byte, 0, len(trimmed))
+	corrupted = append(corrupted, trimmed[:len(trimmed)-1]...)
+	return append(corrupted, ',')
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestPickFault(t *testing.T) {
+	faults := []database.FaultRule{
+		{Type: database.FaultError, Probability: 0.2},
+		{Type: database.FaultReset, Probability: 0.3},
+	}
+
+	tests := []struct {
+		roll     float64
+		expected string
+	}{
+		{roll: 0, expected: database.FaultError},
+		{roll: 0.19, expected: database.FaultError},
+		{roll: 0.2, expected: database.FaultReset},
+		{roll: 0.49, expected: database.FaultReset},
+		{roll: 0.5, expected: ""},
+		{roll: 0.99, expected: ""},
+	}
+
+	for _, tt := range tests {
+		fault := pickFault(faults, tt.roll)
+		if tt.expected == "" {
+			assert.Nil(t, fault, "roll %v", tt.roll)
+			continue
+		}
+		require.NotNil(t, fault, "roll %v", tt.roll)
+		assert.Equal(t, tt.expected, fault.Type)
+	}
+
+	assert.Nil(t, pickFault(nil, 0))
+}
+
+func TestResolveFaults(t *testing.T) {
+	project := &database.Project{AdvanceConfig: `{"faults":[{"type":"reset","probability":0.1}]}`}
+
+	faults := resolveFaults(project, nil)
+	require.Len(t, faults, 1)
+	assert.Equal(t, database.FaultReset, faults[0].Type)
+
+	endpoint := &database.MockEndpoint{AdvanceConfig: `{"faults":[{"type":"empty","probability":0.5}]}`}
+	faults = resolveFaults(project, endpoint)
+	require.Len(t, faults, 1)
+	assert.Equal(t, database.FaultEmpty, faults[0].Type)
+
+	// Endpoints without faults inherit the project faults
+	faults = resolveFaults(project, &database.MockEndpoint{AdvanceConfig: `{"delayMs":10}`})
+	require.Len(t, faults, 1)
+	assert.Equal(t, database.FaultReset, faults[0].Type)
+
+	assert.Empty(t, resolveFaults(&database.Project{}, nil))
+}
+
+func TestCorruptJSON(t *testing.T) {
+	for _, body := range []string{`{"a":1}`, `[1,2]`, `"text"`, `42`, "{\"a\":1}\n", ``} {
+		corrupted := corruptJSON([]byte(body))
+		assert.False(t, json.Valid(corrupted), "body %q became %q", body, corrupted)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	service := &MockService{}
+	newResponse := func(body string, headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(body))}
+		for key, value := range headers {
+			resp.Header.Set(key, value)
+		}
+		return resp
+	}
+	inject := func(config string, resp *http.Response) (*http.Response, *RequestMeta) {
+		meta := &RequestMeta{}
+		ctx := ContextWithRequestMeta(context.Background(), meta)
+		return service.injectFault(ctx, &database.Project{AdvanceConfig: config}, resp), meta
+	}
+
+	t.Run("Error fault replaces the response", func(t *testing.T) {
+		resp, meta := inject(`{"faults":[{"type":"error","probability":1,"status":503}]}`, newResponse(`{"ok":true}`, nil))
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.NotNil(t, meta.Fault)
+		assert.Equal(t, database.FaultError, meta.Fault.Type)
+	})
+
+	t.Run("Malformed JSON fault corrupts the body", func(t *testing.T) {
+		resp, meta := inject(`{"faults":[{"type":"malformed_json","probability":1}]}`, newResponse(`{"ok":true}`, nil))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.False(t, json.Valid(body))
+		assert.Equal(t, int64(len(body)), resp.ContentLength)
+		assert.Equal(t, database.FaultMalformedJSON, meta.Fault.Type)
+	})
+
+	t.Run("Malformed JSON fault decodes compressed bodies", func(t *testing.T) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(`{"ok":true}`))
+		gz.Close()
+
+		resp, _ := inject(`{"faults":[{"type":"malformed_json","probability":1}]}`, newResponse(buf.String(), map[string]string{"Content-Encoding": "gzip"}))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"ok":true,`, string(body))
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("Connection faults are only recorded", func(t *testing.T) {
+		original := newResponse(`{"ok":true}`, nil)
+		resp, meta := inject(`{"faults":[{"type":"trickle","probability":1,"bytesPerSec":10}]}`, original)
+		assert.Same(t, original, resp)
+		assert.Equal(t, database.FaultTrickle, meta.Fault.Type)
+	})
+
+	t.Run("No fault when probabilities miss", func(t *testing.T) {
+		original := newResponse(`{"ok":true}`, nil)
+		resp, meta := inject(`{"faults":[{"type":"reset","probability":0}]}`, original)
+		assert.Same(t, original, resp)
+		assert.Nil(t, meta.Fault)
+	})
+}
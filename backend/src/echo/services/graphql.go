@@ -0,0 +1,313 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/graphql"
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/requestctx"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Keys of graphql rules
+//
+//	operationName        name of the executed operation ("" when anonymous)
+//	operationType        query, mutation or subscription
+//	field                root fields selected by the operation, any of them may match
+//	variables.<jsonpath> a value of the operation variables ("variables.input.email")
+const (
+	graphQLKeyOperationName = "operationName"
+	graphQLKeyOperationType = "operationType"
+	graphQLKeyField         = "field"
+	graphQLKeyVariables     = "variables"
+)
+
+// graphQLMismatchCode is the extensions.code of errors added when a mock response does not match the schema
+const graphQLMismatchCode = "MOCK_SCHEMA_MISMATCH"
+
+// graphQLResponseKeys are the top level keys a GraphQL response may contain
+var graphQLResponseKeys = map[string]bool{"data": true, "errors": true, "extensions": true}
+
+// compiledSchema caches the parsed schema of an endpoint until its SDL changes
+type compiledSchema struct {
+	sdl    string
+	schema *graphql.Schema
+	err    error
+}
+
+var graphQLSchemas sync.Map // endpoint ID -> *compiledSchema
+
+// endpointSchema returns the parsed schema of a graphql endpoint, nil when it has none
+func endpointSchema(endpoint *database.MockEndpoint) (*graphql.Schema, error) {
+	if strings.TrimSpace(endpoint.GraphQLSchema) == "" {
+		return nil, nil
+	}
+	if cached, ok := graphQLSchemas.Load(endpoint.ID); ok {
+		if compiled := cached.(*compiledSchema); compiled.sdl == endpoint.GraphQLSchema {
+			return compiled.schema, compiled.err
+		}
+	}
+	schema, err := graphql.ParseSchema(endpoint.GraphQLSchema)
+	graphQLSchemas.Store(endpoint.ID, &compiledSchema{sdl: endpoint.GraphQLSchema, schema: schema, err: err})
+	return schema, err
+}
+
+// ValidateGraphQLSchema checks that an endpoint schema is valid SDL
+func ValidateGraphQLSchema(sdl string) error {
+	if strings.TrimSpace(sdl) == "" {
+		return nil
+	}
+	if _, err := graphql.ParseSchema(sdl); err != nil {
+		return fmt.Errorf("invalid GraphQL schema: %v", err)
+	}
+	return nil
+}
+
+// ValidateGraphQLErrors checks that graphql_errors is a JSON array of error objects with a message
+func ValidateGraphQLErrors(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var errs []map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &errs); err != nil {
+		return fmt.Errorf("graphql_errors must be a JSON array of error objects: %v", err)
+	}
+	for i, item := range errs {
+		if message, ok := item["message"].(string); !ok || message == "" {
+			return fmt.Errorf("graphql_errors[%d] must have a non-empty message", i)
+		}
+	}
+	return nil
+}
+
+// ValidateGraphQLResponseBody checks that a static body of a graphql endpoint is a GraphQL response
+// Templated bodies are only known when rendered and are not checked.
+func ValidateGraphQLResponseBody(body string, templated bool) error {
+	if strings.TrimSpace(body) == "" || (templated && isTemplate(body)) {
+		return nil
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &object); err != nil {
+		return fmt.Errorf("GraphQL response body must be a JSON object with data and/or errors")
+	}
+	for key := range object {
+		if !graphQLResponseKeys[key] {
+			return fmt.Errorf("GraphQL response body has unexpected key %q, only data, errors and extensions are allowed", key)
+		}
+	}
+	return nil
+}
+
+// validateGraphQLRuleKey checks the key of a graphql rule
+func validateGraphQLRuleKey(key string) error {
+	switch key {
+	case graphQLKeyOperationName, graphQLKeyOperationType, graphQLKeyField, graphQLKeyVariables:
+		return nil
+	}
+	if strings.HasPrefix(key, graphQLKeyVariables+".") || strings.HasPrefix(key, graphQLKeyVariables+"[") {
+		_, err := jsonpath.Compile(key)
+		return err
+	}
+	return fmt.Errorf("graphql rule key must be %s, %s, %s or variables.<path>", graphQLKeyOperationName, graphQLKeyOperationType, graphQLKeyField)
+}
+
+// matchGraphQLRule checks a graphql rule against the operation of the request
+// Requests that are not valid GraphQL only satisfy not_exists.
+func matchGraphQLRule(rule database.MockRule, request *requestctx.Request) bool {
+	operation, err := request.GraphQL()
+	if err != nil {
+		return isPresenceOperator(rule.Operator) && matchPresence(rule.Operator, false)
+	}
+
+	var values []interface{}
+	switch rule.Key {
+	case graphQLKeyOperationName:
+		if operation.Name() != "" {
+			values = []interface{}{operation.Name()}
+		}
+	case graphQLKeyOperationType:
+		values = []interface{}{operation.Type()}
+	case graphQLKeyField:
+		for _, field := range operation.RootFields() {
+			values = append(values, field)
+		}
+	default:
+		path, err := jsonpath.Compile(rule.Key)
+		if err != nil {
+			return false
+		}
+		values = path.Find(map[string]interface{}{graphQLKeyVariables: operation.Variables})
+		if path.Definite() && len(values) == 1 && !isPresenceOperator(rule.Operator) {
+			return matchRuleValueTyped(rule.Operator, values[0], rule.Value)
+		}
+	}
+
+	if isPresenceOperator(rule.Operator) {
+		return matchPresence(rule.Operator, len(values) > 0)
+	}
+	if len(values) == 0 {
+		return matchRuleValue(rule.Operator, "", rule.Value)
+	}
+	return matchEachValue(rule, values)
+}
+
+// prepareGraphQLRequest parses the operation of a request to a graphql endpoint
+// The operation is recorded for the request log. A request that is not valid GraphQL, or
+// that does not validate against the endpoint schema, gets a GraphQL error response;
+// endpoints that proxy leave that to the upstream server.
+func prepareGraphQLRequest(endpoint *database.MockEndpoint, req *http.Request) *http.Response {
+	operation, err := requestctx.From(req).GraphQL()
+	if err == nil {
+		if meta := RequestMetaFromContext(req.Context()); meta != nil {
+			meta.GraphQLOperation = operation.Describe()
+		}
+	}
+	if endpoint.UseProxy && endpoint.ProxyTarget != nil {
+		return nil
+	}
+	if err != nil {
+		return createGraphQLErrorResponse(http.StatusBadRequest, toGraphQLError(err))
+	}
+
+	schema, err := endpointSchema(endpoint)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, "Invalid GraphQL schema: "+err.Error())
+	}
+	if schema == nil {
+		return nil
+	}
+	if errs := schema.Validate(operation.Document, operation.Operation, operation.Variables); len(errs) > 0 {
+		return createGraphQLErrorResponse(http.StatusBadRequest, errs...)
+	}
+	return nil
+}
+
+// checkGraphQLResult validates the data of a mock response against the endpoint schema
+// Mismatches do not fail the request: they are appended to the response errors with
+// extensions.code MOCK_SCHEMA_MISMATCH so the mock can be fixed. Streamed, encoded and
+// non-JSON responses are returned unchanged.
+func checkGraphQLResult(endpoint *database.MockEndpoint, req *http.Request, resp *http.Response) *http.Response {
+	if resp == nil || resp.Body == nil || resp.Header.Get("Content-Encoding") != "" || resp.ContentLength < 0 {
+		return resp
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "json") {
+		return resp
+	}
+	schema, err := endpointSchema(endpoint)
+	if err != nil || schema == nil {
+		return resp
+	}
+	operation, err := requestctx.From(req).GraphQL()
+	if err != nil {
+		return resp
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return resp
+	}
+
+	var object map[string]interface{}
+	if json.Unmarshal(body, &object) != nil {
+		return resp
+	}
+	data, ok := object["data"]
+	if !ok {
+		return resp
+	}
+
+	mismatches := schema.ValidateResult(operation.Document, operation.Operation, data)
+	if len(mismatches) == 0 {
+		return resp
+	}
+
+	errs, _ := object["errors"].([]interface{})
+	for _, mismatch := range mismatches {
+		log.Warn().
+			Str("endpoint_id", endpoint.ID).
+			Str("operation", operation.Describe()).
+			Interface("path", mismatch.Path).
+			Str("error", mismatch.Message).
+			Msg("GraphQL mock response does not match schema")
+		mismatch.Extensions = map[string]interface{}{"code": graphQLMismatchCode}
+		errs = append(errs, mismatch)
+	}
+	object["errors"] = errs
+
+	if body, err = json.Marshal(object); err != nil {
+		return resp
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp
+}
+
+// mergeGraphQLErrors appends the configured GraphQL errors to the "errors" array of the body
+// Bodies that are not a JSON object are left unchanged.
+func mergeGraphQLErrors(mockResp database.MockResponse) database.MockResponse {
+	if strings.TrimSpace(mockResp.GraphQLErrors) == "" {
+		return mockResp
+	}
+	var extra []interface{}
+	if err := json.Unmarshal([]byte(mockResp.GraphQLErrors), &extra); err != nil || len(extra) == 0 {
+		return mockResp
+	}
+
+	object := map[string]interface{}{}
+	if strings.TrimSpace(mockResp.Body) != "" {
+		if err := json.Unmarshal([]byte(mockResp.Body), &object); err != nil {
+			return mockResp
+		}
+	}
+	errs, _ := object["errors"].([]interface{})
+	object["errors"] = append(errs, extra...)
+
+	body, err := json.Marshal(object)
+	if err != nil {
+		return mockResp
+	}
+	mockResp.Body = string(body)
+	return mockResp
+}
+
+// toGraphQLError converts an error to a GraphQL error
+func toGraphQLError(err error) *graphql.Error {
+	if gqlErr, ok := err.(*graphql.Error); ok {
+		return gqlErr
+	}
+	return &graphql.Error{Message: err.Error()}
+}
+
+// createGraphQLErrorResponse creates a GraphQL response holding only errors
+func createGraphQLErrorResponse(statusCode int, errs ...*graphql.Error) *http.Response {
+	jsonBody, _ := json.Marshal(map[string]interface{}{"errors": errs})
+
+	resp := &http.Response{
+		StatusCode:    statusCode,
+		Body:          io.NopCloser(bytes.NewReader(jsonBody)),
+		Header:        make(http.Header),
+		ContentLength: int64(len(jsonBody)),
+	}
+	resp.Header.Set("Content-Type", "application/json")
+	return resp
+}
+
+// ruleMismatchResponse reports that no response rule matched, as a GraphQL error on graphql endpoints
+func ruleMismatchResponse(endpoint *database.MockEndpoint, ruleErr error) *http.Response {
+	if endpoint.IsGraphQL() {
+		return createGraphQLErrorResponse(http.StatusBadRequest, &graphql.Error{Message: ruleErr.Error()})
+	}
+	return createErrorResponse(http.StatusBadRequest, ruleErr.Error())
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"beo-echo/backend/src/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGraphQLSchema = `
+type Query { user(id: ID!): User }
+type Mutation { createUser(name: String!): User! }
+type User { id: ID! name: String! age: Int }
+`
+
+func newGraphQLRequest(body string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestMatchesRules_GraphQL(t *testing.T) {
+	getUser := `{"query":"query GetUser($id: ID!, $opts: Opts) { user(id: $id) { id name } }","operationName":"GetUser","variables":{"id":"42","opts":{"tags":["a","b"]}}}`
+	createUser := `{"query":"mutation { createUser(name: \"x\") { id } }"}`
+
+	tests := []struct {
+		name     string
+		rule     database.MockRule
+		body     string
+		expected bool
+	}{
+		{name: "operation name", rule: database.MockRule{Type: "graphql", Key: "operationName", Operator: OperatorEquals, Value: "GetUser"}, body: getUser, expected: true},
+		{name: "operation name mismatch", rule: database.MockRule{Type: "graphql", Key: "operationName", Operator: OperatorEquals, Value: "GetPosts"}, body: getUser},
+		{name: "anonymous operation", rule: database.MockRule{Type: "graphql", Key: "operationName", Operator: OperatorNotExists}, body: createUser, expected: true},
+		{name: "operation type", rule: database.MockRule{Type: "graphql", Key: "operationType", Operator: OperatorEquals, Value: "mutation"}, body: createUser, expected: true},
+		{name: "root field", rule: database.MockRule{Type: "graphql", Key: "field", Operator: OperatorEquals, Value: "user"}, body: getUser, expected: true},
+		{name: "variable", rule: database.MockRule{Type: "graphql", Key: "variables.id", Operator: OperatorEquals, Value: "42"}, body: getUser, expected: true},
+		{name: "variable number compare", rule: database.MockRule{Type: "graphql", Key: "variables.id", Operator: OperatorGreater, Value: "50"}, body: getUser},
+		{name: "nested variable list", rule: database.MockRule{Type: "graphql", Key: "variables.opts.tags[*]", Operator: OperatorEquals, Value: "b"}, body: getUser, expected: true},
+		{name: "missing variable", rule: database.MockRule{Type: "graphql", Key: "variables.email", Operator: OperatorNotExists}, body: getUser, expected: true},
+		{name: "variables object", rule: database.MockRule{Type: "graphql", Key: "variables", Operator: OperatorHasProperty}, body: getUser, expected: true},
+		{name: "not graphql", rule: database.MockRule{Type: "graphql", Key: "operationType", Operator: OperatorEquals, Value: "query"}, body: `{"id":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := database.MockResponse{Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, newGraphQLRequest(tt.body)))
+		})
+	}
+}
+
+func TestValidateRule_GraphQL(t *testing.T) {
+	for _, key := range []string{"operationName", "operationType", "field", "variables", "variables.input.email", "variables.ids[0]"} {
+		assert.NoError(t, ValidateRule(&database.MockRule{Type: "graphql", Key: key, Operator: OperatorEquals}), key)
+	}
+	assert.NoError(t, ValidateRule(&database.MockRule{Type: "graphql", Key: "variables.input", Operator: OperatorMatchesType, Value: "object"}))
+	assert.ErrorIs(t, ValidateRule(&database.MockRule{Type: "graphql", Key: "operation", Operator: OperatorEquals}), ErrInvalidRule)
+	assert.ErrorIs(t, ValidateRule(&database.MockRule{Type: "graphql", Key: "variables.items[", Operator: OperatorEquals}), ErrInvalidRule)
+}
+
+func TestValidateGraphQLResponse(t *testing.T) {
+	assert.NoError(t, ValidateGraphQLErrors(""))
+	assert.NoError(t, ValidateGraphQLErrors(`[{"message":"Not found","path":["user"],"extensions":{"code":"NOT_FOUND"}}]`))
+	assert.Error(t, ValidateGraphQLErrors(`{"message":"x"}`))
+	assert.Error(t, ValidateGraphQLErrors(`[{"code":"x"}]`))
+
+	assert.NoError(t, ValidateGraphQLResponseBody(`{"data":{"user":null},"errors":[]}`, false))
+	assert.NoError(t, ValidateGraphQLResponseBody(`{"data":{"user":{"id":"{{pathParam "id"}}"}}}`, true))
+	assert.Error(t, ValidateGraphQLResponseBody(`{"user":{"id":1}}`, false))
+	assert.Error(t, ValidateGraphQLResponseBody(`[]`, false))
+
+	assert.NoError(t, ValidateGraphQLSchema(testGraphQLSchema))
+	assert.Error(t, ValidateGraphQLSchema(`type Query { user: Missing }`))
+}
+
+func TestMergeGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		errors   string
+		expected string
+	}{
+		{name: "no errors", body: `{"data":{}}`, expected: `{"data":{}}`},
+		{name: "added", body: `{"data":{"user":null}}`, errors: `[{"message":"Not found"}]`, expected: `{"data":{"user":null},"errors":[{"message":"Not found"}]}`},
+		{name: "appended", body: `{"errors":[{"message":"a"}]}`, errors: `[{"message":"b"}]`, expected: `{"errors":[{"message":"a"},{"message":"b"}]}`},
+		{name: "empty body", errors: `[{"message":"b"}]`, expected: `{"errors":[{"message":"b"}]}`},
+		{name: "not an object", body: `plain`, errors: `[{"message":"b"}]`, expected: `plain`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeGraphQLErrors(database.MockResponse{Body: tt.body, GraphQLErrors: tt.errors})
+			if strings.HasPrefix(tt.expected, "{") {
+				assert.JSONEq(t, tt.expected, merged.Body)
+			} else {
+				assert.Equal(t, tt.expected, merged.Body)
+			}
+		})
+	}
+}
+
+func TestPrepareGraphQLRequest(t *testing.T) {
+	endpoint := &database.MockEndpoint{ID: "gql-prepare", Type: database.EndpointTypeGraphQL, GraphQLSchema: testGraphQLSchema}
+
+	readErrors := func(t *testing.T, resp *http.Response) []string {
+		t.Helper()
+		var body struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		require.NoError(t, json.Unmarshal(raw, &body))
+		messages := []string{}
+		for _, err := range body.Errors {
+			messages = append(messages, err.Message)
+		}
+		return messages
+	}
+
+	t.Run("valid operation is recorded", func(t *testing.T) {
+		meta := &RequestMeta{}
+		req := newGraphQLRequest(`{"query":"query GetUser { user(id: 1) { id } }"}`)
+		req = req.WithContext(ContextWithRequestMeta(context.Background(), meta))
+
+		assert.Nil(t, prepareGraphQLRequest(endpoint, req))
+		assert.Equal(t, "query GetUser", meta.GraphQLOperation)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		resp := prepareGraphQLRequest(endpoint, newGraphQLRequest(`{"query":"{ user(id: 1) { id }"}`))
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, []string{"Syntax Error: Expected Name, found <EOF>."}, readErrors(t, resp))
+	})
+
+	t.Run("schema validation", func(t *testing.T) {
+		resp := prepareGraphQLRequest(endpoint, newGraphQLRequest(`{"query":"{ user(id: 1) { email } }"}`))
+		require.NotNil(t, resp)
+		assert.Equal(t, []string{`Cannot query field "email" on type "User".`}, readErrors(t, resp))
+	})
+
+	t.Run("no schema", func(t *testing.T) {
+		noSchema := &database.MockEndpoint{ID: "gql-no-schema", Type: database.EndpointTypeGraphQL}
+		assert.Nil(t, prepareGraphQLRequest(noSchema, newGraphQLRequest(`{"query":"{ anything }"}`)))
+	})
+}
+
+func TestCheckGraphQLResult(t *testing.T) {
+	endpoint := &database.MockEndpoint{ID: "gql-result", Type: database.EndpointTypeGraphQL, GraphQLSchema: testGraphQLSchema}
+	req := newGraphQLRequest(`{"query":"{ user(id: 1) { id name age } }"}`)
+
+	build := func(body string) *http.Response {
+		resp, err := createMockResponse(database.MockResponse{StatusCode: 200, Body: body, Headers: `{"Content-Type":"application/json"}`})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("matching data is unchanged", func(t *testing.T) {
+		body := `{"data":{"user":{"id":"1","name":"Jane","age":30}}}`
+		resp := checkGraphQLResult(endpoint, req, build(body))
+		raw, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, body, string(raw))
+	})
+
+	t.Run("mismatches are reported as errors", func(t *testing.T) {
+		resp := checkGraphQLResult(endpoint, req, build(`{"data":{"user":{"id":"1","name":null,"age":"old"}}}`))
+		raw, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, int64(len(raw)), resp.ContentLength)
+
+		var body struct {
+			Errors []struct {
+				Message    string                 `json:"message"`
+				Path       []interface{}          `json:"path"`
+				Extensions map[string]interface{} `json:"extensions"`
+			} `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &body))
+		require.Len(t, body.Errors, 2)
+		assert.Equal(t, `Int cannot represent "old".`, body.Errors[0].Message)
+		assert.Equal(t, []interface{}{"user", "age"}, body.Errors[0].Path)
+		assert.Equal(t, graphQLMismatchCode, body.Errors[0].Extensions["code"])
+		assert.Equal(t, []interface{}{"user", "name"}, body.Errors[1].Path)
+	})
+}
@@ -0,0 +1,638 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// maxGRPCMessageBytes limits the size of a request message, like the default of gRPC servers
+const maxGRPCMessageBytes = 4 << 20
+
+const (
+	grpcContentType      = "application/grpc"
+	grpcStatusHeader     = "Grpc-Status"
+	grpcMessageHeader    = "Grpc-Message"
+	grpcFrameHeaderBytes = 5 // Compressed flag and big-endian message length
+)
+
+// GRPCCode is a gRPC status code, written in JSON as its name ("NOT_FOUND") or number (5)
+type GRPCCode int
+
+// gRPC status codes used by the mock itself, responses can use any of grpcCodeNames
+const (
+	GRPCCodeOK               GRPCCode = 0
+	GRPCCodeInvalidArgument  GRPCCode = 3
+	GRPCCodePermissionDenied GRPCCode = 7
+	GRPCCodeUnimplemented    GRPCCode = 12
+	GRPCCodeInternal         GRPCCode = 13
+	GRPCCodeUnauthenticated  GRPCCode = 16
+)
+
+// grpcCodeNames are the names of the status codes, indexed by code
+var grpcCodeNames = [...]string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND",
+	"ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED",
+	"OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+var grpcTrailerName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// String returns the name of the code, e.g. "NOT_FOUND"
+func (c GRPCCode) String() string {
+	if c < 0 || int(c) >= len(grpcCodeNames) {
+		return strconv.Itoa(int(c))
+	}
+	return grpcCodeNames[c]
+}
+
+// UnmarshalJSON accepts a code name, in any case, or number
+func (c *GRPCCode) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		if number < 0 || number >= len(grpcCodeNames) {
+			return fmt.Errorf("unknown gRPC status code %d", number)
+		}
+		*c = GRPCCode(number)
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("gRPC status code must be a name or a number")
+	}
+	for code, codeName := range grpcCodeNames {
+		if strings.EqualFold(name, codeName) {
+			*c = GRPCCode(code)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown gRPC status code %q", name)
+}
+
+// GRPCStatus is the status a gRPC call ends with, stored as JSON in the response grpc_status
+type GRPCStatus struct {
+	Code     GRPCCode          `json:"code"`               // OK when omitted
+	Message  string            `json:"message,omitempty"`  // Sent as grpc-message, a template on templated responses
+	Trailers map[string]string `json:"trailers,omitempty"` // Custom trailers, values are templates on templated responses
+}
+
+// ParseGRPCStatus parses the grpc_status of a response, an empty value stands for OK
+func ParseGRPCStatus(raw string) (*GRPCStatus, error) {
+	status := &GRPCStatus{}
+	if strings.TrimSpace(raw) == "" {
+		return status, nil
+	}
+	if err := json.Unmarshal([]byte(raw), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ValidateGRPCStatus checks the grpc_status of a response before it is saved
+// The message and trailers of templated responses must be valid templates.
+func ValidateGRPCStatus(raw string, templated bool) error {
+	status, err := ParseGRPCStatus(raw)
+	if err != nil {
+		return err
+	}
+	if templated {
+		if _, err := parseResponseTemplate("grpc_status:message", status.Message, nil); err != nil {
+			return fmt.Errorf("message: %w", err)
+		}
+	}
+	for name, value := range status.Trailers {
+		if !grpcTrailerName.MatchString(name) {
+			return fmt.Errorf("trailer %q must be a lowercase header name", name)
+		}
+		if strings.HasPrefix(name, "grpc-") || isHopByHopHeader(name) {
+			return fmt.Errorf("trailer %q is reserved", name)
+		}
+		if !templated {
+			continue
+		}
+		if _, err := parseResponseTemplate("grpc_status:trailer:"+name, value, nil); err != nil {
+			return fmt.Errorf("trailer %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateGRPCResponse checks a response of a grpc endpoint against the method it serves
+// The body is the output message as JSON or, with stream, a stream of output messages.
+// Bodies that are templates are only checked once rendered at request time.
+func ValidateGRPCResponse(descriptors *GRPCDescriptors, endpoint *database.MockEndpoint, response database.MockResponse) error {
+	method := descriptors.Method(endpoint.Path)
+	if method == nil {
+		return fmt.Errorf("method %s is not defined by the proto files of the project", endpoint.Path)
+	}
+	if method.IsStreamingClient() {
+		return fmt.Errorf("client and bidirectional streaming methods are not supported")
+	}
+	if response.BodyType != "" && response.BodyType != BodyTypeText {
+		return fmt.Errorf("gRPC responses must have a text body")
+	}
+	if response.Stream && !method.IsStreamingServer() {
+		return fmt.Errorf("%s is not a server streaming method", endpoint.Path)
+	}
+	if err := ValidateGRPCStatus(response.GRPCStatus, response.Templated); err != nil {
+		return fmt.Errorf("invalid grpc_status: %w", err)
+	}
+	if response.Templated && isTemplate(response.Body) {
+		return nil
+	}
+
+	messages, err := grpcResponseMessages(response.Body, response.Stream)
+	if err != nil {
+		return err
+	}
+	for i, message := range messages {
+		if _, err := transcodeGRPCMessage(descriptors, method.Output(), message.Data); err != nil {
+			if response.Stream {
+				return fmt.Errorf("event %d: %w", i, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// IsGRPCRequest reports whether req is a gRPC call
+func IsGRPCRequest(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return false
+	}
+	contentType := req.Header.Get("Content-Type")
+	return contentType == grpcContentType ||
+		strings.HasPrefix(contentType, grpcContentType+"+") ||
+		strings.HasPrefix(contentType, grpcContentType+";")
+}
+
+// grpcContentSubtype returns the codec of a gRPC call, "proto" unless the content type names another
+func grpcContentSubtype(req *http.Request) string {
+	contentType := strings.TrimPrefix(req.Header.Get("Content-Type"), grpcContentType)
+	contentType, _, _ = strings.Cut(contentType, ";")
+	if subtype, ok := strings.CutPrefix(contentType, "+"); ok && subtype != "" {
+		return strings.ToLower(subtype)
+	}
+	return "proto"
+}
+
+// GRPCCall records a gRPC call for the request log, with its messages decoded to JSON
+type GRPCCall struct {
+	Method string // gRPC path, e.g. "/shop.Catalog/GetItem"
+
+	mu        sync.Mutex
+	request   json.RawMessage
+	responses []json.RawMessage
+	streaming bool
+	status    GRPCCode
+	finished  bool
+}
+
+// startGRPCCall attaches the record of a gRPC call to the request meta
+func startGRPCCall(ctx context.Context, method string) *GRPCCall {
+	call := &GRPCCall{Method: method}
+	if meta := RequestMetaFromContext(ctx); meta != nil {
+		meta.GRPC = call
+	}
+	return call
+}
+
+// fail ends the call with an error status, returning the response carrying it
+func (c *GRPCCall) fail(code GRPCCode, message string) *http.Response {
+	c.finish(code)
+	return createGRPCErrorResponse(code, message)
+}
+
+// finish records the status the call ends with
+func (c *GRPCCall) finish(code GRPCCode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = code
+	c.finished = true
+}
+
+// addResponse records a response message once it is sent
+func (c *GRPCCall) addResponse(message json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses = append(c.responses, message)
+}
+
+// Status returns the name of the status the call ended with, empty while it runs
+func (c *GRPCCall) Status() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.finished {
+		return ""
+	}
+	return c.status.String()
+}
+
+// RequestJSON returns the request message as JSON, empty when it was not decoded
+func (c *GRPCCall) RequestJSON() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return string(c.request)
+}
+
+// ResponseJSON returns the response message as JSON, or the array of messages sent
+// by a server streaming method. It is empty when no message was sent.
+func (c *GRPCCall) ResponseJSON() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.streaming {
+		if len(c.responses) == 0 {
+			return "[]"
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, message := range c.responses {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(message)
+		}
+		buf.WriteByte(']')
+		return buf.String()
+	}
+	if len(c.responses) == 0 {
+		return ""
+	}
+	return string(c.responses[0])
+}
+
+// handleGRPCEndpoint answers a call to a grpc endpoint, or returns nil when the endpoint has no
+// response. The request message is decoded to JSON so rules and templates see its fields like
+// those of a JSON body, and the selected response is transcoded to the output message.
+func (s *MockService) handleGRPCEndpoint(ctx context.Context, project *database.Project, endpoint *database.MockEndpoint, responses []database.MockResponse, path string, req *http.Request) *http.Response {
+	if !IsGRPCRequest(req) {
+		return createErrorResponse(http.StatusUnsupportedMediaType, "gRPC endpoint: the request must be a gRPC call with the application/grpc content type")
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+
+	call := startGRPCCall(ctx, endpoint.Path)
+	if subtype := grpcContentSubtype(req); subtype != "proto" {
+		return call.fail(GRPCCodeUnimplemented, fmt.Sprintf("content subtype %q is not supported, only proto", subtype))
+	}
+
+	descriptors, err := s.grpcDescriptors(project.ID)
+	if err != nil {
+		return call.fail(GRPCCodeInternal, "failed to compile the proto files of the project: "+err.Error())
+	}
+	method := descriptors.Method(endpoint.Path)
+	if method == nil {
+		return call.fail(GRPCCodeUnimplemented, fmt.Sprintf("method %s is not defined by the proto files of the project", endpoint.Path))
+	}
+	if method.IsStreamingClient() {
+		return call.fail(GRPCCodeUnimplemented, "client and bidirectional streaming methods are not supported")
+	}
+
+	input, code, err := decodeGRPCRequest(descriptors, method, req)
+	if err != nil {
+		return call.fail(code, err.Error())
+	}
+	call.mu.Lock()
+	call.request = input
+	call.streaming = method.IsStreamingServer()
+	call.mu.Unlock()
+
+	jsonReq := grpcJSONRequest(req, input)
+	response, ruleErr := s.selectResponse(project, endpoint, responses, jsonReq)
+	if ruleErr != nil {
+		return call.fail(GRPCCodeInvalidArgument, ruleErr.Error())
+	}
+	if response == nil {
+		return call.fail(GRPCCodeUnimplemented, "no response matches the call")
+	}
+
+	s.applyDelay(ctx, project, endpoint, response)
+
+	resp := buildGRPCResponse(ctx, descriptors, method, *response, path, jsonReq, call)
+	scheduleCallbacks(project.ID, *response, path, jsonReq)
+	return resp
+}
+
+// decodeGRPCRequest decodes the request message of a call to JSON
+// Fields are named as in the proto file and every field is present, with its default value when unset.
+func decodeGRPCRequest(descriptors *GRPCDescriptors, method protoreflect.MethodDescriptor, req *http.Request) (json.RawMessage, GRPCCode, error) {
+	encoding := strings.ToLower(req.Header.Get("Grpc-Encoding"))
+	messages, err := readGRPCMessages(requestctx.From(req).Body, encoding)
+	if err != nil {
+		if encoding != "" && encoding != "identity" && encoding != "gzip" {
+			return nil, GRPCCodeUnimplemented, err
+		}
+		return nil, GRPCCodeInvalidArgument, err
+	}
+	if len(messages) > 1 {
+		return nil, GRPCCodeUnimplemented, fmt.Errorf("%s expects a single request message, got %d", GRPCMethodPath(method), len(messages))
+	}
+
+	input := dynamicpb.NewMessage(method.Input())
+	if len(messages) == 1 {
+		if err := proto.Unmarshal(messages[0], input); err != nil {
+			return nil, GRPCCodeInvalidArgument, fmt.Errorf("failed to decode %s: %w", method.Input().FullName(), err)
+		}
+	}
+
+	encoded, err := grpcJSONOptions(descriptors).Marshal(input)
+	if err != nil {
+		return nil, GRPCCodeInternal, fmt.Errorf("failed to encode %s as JSON: %w", method.Input().FullName(), err)
+	}
+	return encoded, GRPCCodeOK, nil
+}
+
+// grpcJSONOptions returns how messages are written to JSON for rules, templates and logs
+func grpcJSONOptions(descriptors *GRPCDescriptors) protojson.MarshalOptions {
+	return protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+		Resolver:        descriptors.types,
+	}
+}
+
+// readGRPCMessages splits the body of a call into its length-prefixed messages
+func readGRPCMessages(body []byte, encoding string) ([][]byte, error) {
+	var messages [][]byte
+	for len(body) > 0 {
+		if len(body) < grpcFrameHeaderBytes {
+			return nil, fmt.Errorf("truncated message header")
+		}
+		compressed := body[0] == 1
+		length := binary.BigEndian.Uint32(body[1:grpcFrameHeaderBytes])
+		if length > maxGRPCMessageBytes {
+			return nil, fmt.Errorf("message of %d bytes exceeds the limit of %d bytes", length, maxGRPCMessageBytes)
+		}
+		body = body[grpcFrameHeaderBytes:]
+		if uint32(len(body)) < length {
+			return nil, fmt.Errorf("truncated message, expected %d bytes, got %d", length, len(body))
+		}
+		message := body[:length]
+		body = body[length:]
+
+		if compressed {
+			if encoding != "gzip" {
+				return nil, fmt.Errorf("message compression %q is not supported, only gzip", encoding)
+			}
+			reader, err := gzip.NewReader(bytes.NewReader(message))
+			if err != nil {
+				return nil, fmt.Errorf("invalid gzip message: %w", err)
+			}
+			message, err = io.ReadAll(io.LimitReader(reader, maxGRPCMessageBytes+1))
+			if err != nil {
+				return nil, fmt.Errorf("invalid gzip message: %w", err)
+			}
+			if len(message) > maxGRPCMessageBytes {
+				return nil, fmt.Errorf("message exceeds the limit of %d bytes", maxGRPCMessageBytes)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// grpcFrame prefixes an uncompressed message with its gRPC frame header
+func grpcFrame(message []byte) []byte {
+	frame := make([]byte, grpcFrameHeaderBytes, grpcFrameHeaderBytes+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// grpcJSONRequest returns a view of req whose body is the request message as JSON
+// The call itself is left untouched so it can still be forwarded.
+func grpcJSONRequest(req *http.Request, body []byte) *http.Request {
+	jsonReq := req.Clone(req.Context())
+	jsonReq.Header.Set("Content-Type", "application/json")
+	jsonReq.Header.Del("Grpc-Encoding")
+	jsonReq.Body = io.NopCloser(bytes.NewReader(body))
+	jsonReq.ContentLength = int64(len(body))
+
+	view := requestctx.New(jsonReq)
+	view.PathParams = requestctx.From(req).PathParams
+	return jsonReq.WithContext(requestctx.WithRequest(jsonReq.Context(), view))
+}
+
+// grpcResponseMessages returns the messages held in the body of a response
+// A stream body is a stream definition whose event data are messages, any other non empty
+// body is a single message.
+func grpcResponseMessages(body string, stream bool) ([]StreamEvent, error) {
+	if stream {
+		def, err := ParseStreamDefinition(body)
+		if err != nil {
+			return nil, err
+		}
+		return def.Events, nil
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, nil
+	}
+	return []StreamEvent{{Data: json.RawMessage(body)}}, nil
+}
+
+// transcodeGRPCMessage converts a JSON message to the protobuf encoding of messageType
+func transcodeGRPCMessage(descriptors *GRPCDescriptors, messageType protoreflect.MessageDescriptor, data json.RawMessage) (proto.Message, error) {
+	message := dynamicpb.NewMessage(messageType)
+	options := protojson.UnmarshalOptions{Resolver: descriptors.types}
+	if err := options.Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", messageType.FullName(), err)
+	}
+	return message, nil
+}
+
+// buildGRPCResponse transcodes the messages of a response and ends the call with its status
+// Server streaming methods send their messages as the delays of the stream events elapse.
+func buildGRPCResponse(ctx context.Context, descriptors *GRPCDescriptors, method protoreflect.MethodDescriptor, mockResp database.MockResponse, path string, req *http.Request, call *GRPCCall) *http.Response {
+	status, err := ParseGRPCStatus(mockResp.GRPCStatus)
+	if err != nil {
+		return call.fail(GRPCCodeInternal, "invalid grpc_status: "+err.Error())
+	}
+
+	tctx := NewTemplateContext(req, path, pathParamsFromRequest(req))
+	render := func(name, value string) (string, error) {
+		if !mockResp.Templated {
+			return value, nil
+		}
+		return renderTemplate(name, value, tctx)
+	}
+	body, err := render("body", mockResp.Body)
+	if err != nil {
+		return call.fail(GRPCCodeInternal, "failed to render body template: "+err.Error())
+	}
+	events, err := grpcResponseMessages(body, mockResp.Stream)
+	if err != nil {
+		return call.fail(GRPCCodeInternal, err.Error())
+	}
+	// A unary call answered without a body gets an empty message
+	if len(events) == 0 && status.Code == GRPCCodeOK && !method.IsStreamingServer() {
+		events = []StreamEvent{{Data: json.RawMessage("{}")}}
+	}
+
+	frames := make([][]byte, len(events))
+	logged := make([]json.RawMessage, len(events))
+	for i, event := range events {
+		message, err := transcodeGRPCMessage(descriptors, method.Output(), event.Data)
+		if err != nil {
+			return call.fail(GRPCCodeInternal, err.Error())
+		}
+		wire, err := proto.Marshal(message)
+		if err != nil {
+			return call.fail(GRPCCodeInternal, err.Error())
+		}
+		frames[i] = grpcFrame(wire)
+		logged[i], _ = grpcJSONOptions(descriptors).Marshal(message)
+	}
+
+	header := http.Header{}
+	if headers, err := repositories.ParseHeaders(mockResp.Headers); err == nil {
+		for key, value := range headers {
+			if !isGRPCMetadata(key) {
+				continue
+			}
+			rendered, err := render("header:"+key, value)
+			if err != nil {
+				return call.fail(GRPCCodeInternal, fmt.Sprintf("failed to render header %q template: %s", key, err.Error()))
+			}
+			header.Set(key, rendered)
+		}
+	}
+
+	trailer := http.Header{}
+	trailer.Set(grpcStatusHeader, strconv.Itoa(int(status.Code)))
+	message, err := render("grpc_status:message", status.Message)
+	if err != nil {
+		return call.fail(GRPCCodeInternal, "failed to render grpc_status message template: "+err.Error())
+	}
+	if message != "" {
+		trailer.Set(grpcMessageHeader, encodeGRPCMessage(message))
+	}
+	for name, value := range status.Trailers {
+		rendered, err := render("grpc_status:trailer:"+name, value)
+		if err != nil {
+			return call.fail(GRPCCodeInternal, fmt.Sprintf("failed to render trailer %q template: %s", name, err.Error()))
+		}
+		trailer.Set(name, rendered)
+	}
+	call.finish(status.Code)
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+	}
+	resp.Header.Set("Content-Type", grpcContentType)
+
+	// Without messages, the status goes in the headers (a trailers-only response)
+	if len(frames) == 0 {
+		for key, values := range trailer {
+			resp.Header[key] = values
+		}
+		resp.Body = http.NoBody
+		return resp
+	}
+	resp.Trailer = trailer
+
+	if !method.IsStreamingServer() {
+		for _, message := range logged {
+			call.addResponse(message)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(bytes.Join(frames, nil)))
+		return resp
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	resp.ContentLength = -1
+	resp.TransferEncoding = []string{"chunked"}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for i, event := range events {
+			if event.DelayMs > 0 {
+				timer := time.NewTimer(time.Duration(event.DelayMs) * time.Millisecond)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					pw.CloseWithError(ctx.Err())
+					return
+				case <-timer.C:
+				}
+			}
+			if _, err := pw.Write(frames[i]); err != nil {
+				return
+			}
+			call.addResponse(logged[i])
+		}
+		pw.Close()
+	}()
+	resp.Body = pr
+	return resp
+}
+
+// isGRPCMetadata reports whether a response header can be sent as initial metadata
+func isGRPCMetadata(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, "grpc-") || isHopByHopHeader(name) {
+		return false
+	}
+	switch lower {
+	case "content-type", "content-length", "content-encoding":
+		return false
+	}
+	return true
+}
+
+// encodeGRPCMessage percent-encodes a status message as the grpc-message header requires
+func encodeGRPCMessage(message string) string {
+	var buf strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			buf.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&buf, "%%%02X", c)
+	}
+	return buf.String()
+}
+
+// createGRPCErrorResponse creates a trailers-only gRPC response ending the call with code
+func createGRPCErrorResponse(code GRPCCode, message string) *http.Response {
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+	}
+	resp.Header.Set("Content-Type", grpcContentType)
+	resp.Header.Set(grpcStatusHeader, strconv.Itoa(int(code)))
+	if message != "" {
+		resp.Header.Set(grpcMessageHeader, encodeGRPCMessage(message))
+	}
+	return resp
+}
+
+// unknownGRPCMethodResponse answers a gRPC call that no endpoint serves
+func unknownGRPCMethodResponse(ctx context.Context, path string) *http.Response {
+	return startGRPCCall(ctx, path).fail(GRPCCodeUnimplemented, fmt.Sprintf("unknown method %s", path))
+}
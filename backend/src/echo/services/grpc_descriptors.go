@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"beo-echo/backend/src/database"
+)
+
+// GRPCDescriptors holds the services compiled from the proto files of a project
+type GRPCDescriptors struct {
+	methods map[string]protoreflect.MethodDescriptor // Keyed by gRPC path, e.g. "/shop.Catalog/GetItem"
+	types   *dynamicpb.Types                         // Message types, used to transcode google.protobuf.Any
+}
+
+// GRPCMethodInfo describes a gRPC method of a project
+type GRPCMethodInfo struct {
+	Path            string `json:"path"`    // Path of the endpoint serving the method, e.g. "/shop.Catalog/GetItem"
+	Service         string `json:"service"` // Full service name, e.g. "shop.Catalog"
+	Method          string `json:"method"`
+	InputType       string `json:"input_type"`
+	OutputType      string `json:"output_type"`
+	ClientStreaming bool   `json:"client_streaming"`
+	ServerStreaming bool   `json:"server_streaming"`
+}
+
+// CompileProtoFiles compiles the proto files of a project together
+// Sources can import each other, the files of the descriptor sets and the well-known types.
+func CompileProtoFiles(files []database.ProtoFile) (*GRPCDescriptors, error) {
+	sources := map[string]string{}
+	descriptors := map[string]*descriptorpb.FileDescriptorProto{}
+	definedBy := map[string]string{}
+
+	define := func(name, uploaded string) error {
+		if other, ok := definedBy[name]; ok {
+			return fmt.Errorf("%s is defined by both %s and %s", name, other, uploaded)
+		}
+		definedBy[name] = uploaded
+		return nil
+	}
+
+	for _, file := range files {
+		switch file.Kind {
+		case database.ProtoFileDescriptorSet:
+			var set descriptorpb.FileDescriptorSet
+			if err := proto.Unmarshal(file.Content, &set); err != nil {
+				return nil, fmt.Errorf("%s: invalid descriptor set: %w", file.Name, err)
+			}
+			for _, fd := range set.GetFile() {
+				if err := define(fd.GetName(), file.Name); err != nil {
+					return nil, err
+				}
+				descriptors[fd.GetName()] = fd
+			}
+		default:
+			if err := define(file.Name, file.Name); err != nil {
+				return nil, err
+			}
+			sources[file.Name] = string(file.Content)
+		}
+	}
+
+	names := make([]string, 0, len(definedBy))
+	for name := range definedBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)},
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				if fd, ok := descriptors[path]; ok {
+					return protocompile.SearchResult{Proto: fd}, nil
+				}
+				return protocompile.SearchResult{}, os.ErrNotExist
+			}),
+		}),
+	}
+	compiled, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &protoregistry.Files{}
+	result := &GRPCDescriptors{methods: map[string]protoreflect.MethodDescriptor{}}
+	for _, file := range compiled {
+		if err := registerProtoFile(registry, file); err != nil {
+			return nil, err
+		}
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				result.methods[GRPCMethodPath(method)] = method
+			}
+		}
+	}
+	result.types = dynamicpb.NewTypes(registry)
+	return result, nil
+}
+
+// registerProtoFile adds file and its imports to registry, unless already there
+func registerProtoFile(registry *protoregistry.Files, file protoreflect.FileDescriptor) error {
+	if _, err := registry.FindFileByPath(file.Path()); err == nil {
+		return nil
+	}
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if err := registerProtoFile(registry, imports.Get(i).FileDescriptor); err != nil {
+			return err
+		}
+	}
+	return registry.RegisterFile(file)
+}
+
+var grpcMethodPath = regexp.MustCompile(`^/[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*/[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateGRPCMethodPath checks that path names a gRPC method, e.g. "/shop.Catalog/GetItem"
+func ValidateGRPCMethodPath(path string) error {
+	if !grpcMethodPath.MatchString(path) {
+		return fmt.Errorf("grpc endpoints must have a path like /package.Service/Method, got %s", path)
+	}
+	return nil
+}
+
+// GRPCMethodPath returns the path gRPC clients call method on, e.g. "/shop.Catalog/GetItem"
+func GRPCMethodPath(method protoreflect.MethodDescriptor) string {
+	return "/" + string(method.Parent().FullName()) + "/" + string(method.Name())
+}
+
+// Method returns the method served on path, or nil when no service defines it
+func (d *GRPCDescriptors) Method(path string) protoreflect.MethodDescriptor {
+	if d == nil {
+		return nil
+	}
+	return d.methods[path]
+}
+
+// Methods describes every method of the project services, sorted by path
+func (d *GRPCDescriptors) Methods() []GRPCMethodInfo {
+	infos := []GRPCMethodInfo{}
+	if d == nil {
+		return infos
+	}
+	for path, method := range d.methods {
+		infos = append(infos, GRPCMethodInfo{
+			Path:            path,
+			Service:         string(method.Parent().FullName()),
+			Method:          string(method.Name()),
+			InputType:       string(method.Input().FullName()),
+			OutputType:      string(method.Output().FullName()),
+			ClientStreaming: method.IsStreamingClient(),
+			ServerStreaming: method.IsStreamingServer(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos
+}
+
+// ProtoFileKind tells a proto source from a descriptor set by the name of an uploaded file
+func ProtoFileKind(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".proto") {
+		return database.ProtoFileSource
+	}
+	return database.ProtoFileDescriptorSet
+}
+
+// grpcDescriptorCache keeps the compiled proto files of every project serving gRPC calls,
+// keyed by project ID. Entries are dropped by InvalidateProjectProtos.
+var grpcDescriptorCache = struct {
+	sync.RWMutex
+	projects   map[string]*GRPCDescriptors
+	generation uint64 // Bumped on every invalidation so descriptors compiled before it are not stored
+}{projects: map[string]*GRPCDescriptors{}}
+
+// grpcDescriptors returns the compiled proto files of a project, compiling them on first use
+func (s *MockService) grpcDescriptors(projectID string) (*GRPCDescriptors, error) {
+	grpcDescriptorCache.RLock()
+	descriptors, ok := grpcDescriptorCache.projects[projectID]
+	generation := grpcDescriptorCache.generation
+	grpcDescriptorCache.RUnlock()
+	if ok {
+		return descriptors, nil
+	}
+
+	files, err := s.Repo.FindProtoFiles(projectID)
+	if err != nil {
+		return nil, err
+	}
+	descriptors, err = CompileProtoFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcDescriptorCache.Lock()
+	if grpcDescriptorCache.generation == generation {
+		grpcDescriptorCache.projects[projectID] = descriptors
+	}
+	grpcDescriptorCache.Unlock()
+	return descriptors, nil
+}
+
+// InvalidateProjectProtos drops the compiled proto files of a project so the next gRPC call
+// compiles them again. Call it after uploading or deleting proto files.
+func InvalidateProjectProtos(projectID string) {
+	grpcDescriptorCache.Lock()
+	defer grpcDescriptorCache.Unlock()
+
+	grpcDescriptorCache.generation++
+	delete(grpcDescriptorCache.projects, projectID)
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+const commonProto = `syntax = "proto3";
+package shop;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+}
+`
+
+const catalogProto = `syntax = "proto3";
+package shop;
+
+import "shop/common.proto";
+import "google/protobuf/timestamp.proto";
+
+service Catalog {
+  rpc GetItem(GetItemRequest) returns (Item);
+  rpc WatchItems(WatchRequest) returns (stream Item);
+  rpc ImportItems(stream Item) returns (Item);
+}
+
+message GetItemRequest {
+  string item_id = 1;
+  repeated string fields = 2;
+}
+
+message WatchRequest {
+  string category = 1;
+}
+
+message Item {
+  string id = 1;
+  string name = 2;
+  Money price = 3;
+  google.protobuf.Timestamp updated_at = 4;
+}
+`
+
+func shopProtoFiles() []database.ProtoFile {
+	return []database.ProtoFile{
+		{Name: "shop/common.proto", Kind: database.ProtoFileSource, Content: []byte(commonProto)},
+		{Name: "shop/catalog.proto", Kind: database.ProtoFileSource, Content: []byte(catalogProto)},
+	}
+}
+
+func TestCompileProtoFiles(t *testing.T) {
+	t.Run("Sources import each other and the well-known types", func(t *testing.T) {
+		descriptors, err := CompileProtoFiles(shopProtoFiles())
+		require.NoError(t, err)
+
+		assert.Equal(t, []GRPCMethodInfo{
+			{Path: "/shop.Catalog/GetItem", Service: "shop.Catalog", Method: "GetItem", InputType: "shop.GetItemRequest", OutputType: "shop.Item"},
+			{Path: "/shop.Catalog/ImportItems", Service: "shop.Catalog", Method: "ImportItems", InputType: "shop.Item", OutputType: "shop.Item", ClientStreaming: true},
+			{Path: "/shop.Catalog/WatchItems", Service: "shop.Catalog", Method: "WatchItems", InputType: "shop.WatchRequest", OutputType: "shop.Item", ServerStreaming: true},
+		}, descriptors.Methods())
+		assert.NotNil(t, descriptors.Method("/shop.Catalog/GetItem"))
+		assert.Nil(t, descriptors.Method("/shop.Catalog/Missing"))
+	})
+
+	t.Run("Sources import files of descriptor sets", func(t *testing.T) {
+		descriptors, err := CompileProtoFiles(shopProtoFiles())
+		require.NoError(t, err)
+		common := descriptors.Method("/shop.Catalog/GetItem").Output().Fields().ByName("price").Message().ParentFile()
+		set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(common)}})
+		require.NoError(t, err)
+
+		descriptors, err = CompileProtoFiles([]database.ProtoFile{
+			{Name: "common.pb", Kind: database.ProtoFileDescriptorSet, Content: set},
+			{Name: "shop/catalog.proto", Kind: database.ProtoFileSource, Content: []byte(catalogProto)},
+		})
+		require.NoError(t, err)
+		assert.Len(t, descriptors.Methods(), 3)
+	})
+
+	tests := []struct {
+		name    string
+		files   []database.ProtoFile
+		wantErr string
+	}{
+		{
+			name:    "Syntax error",
+			files:   []database.ProtoFile{{Name: "broken.proto", Kind: database.ProtoFileSource, Content: []byte(`syntax = "proto3"; message {`)}},
+			wantErr: "broken.proto",
+		},
+		{
+			name:    "Missing import",
+			files:   []database.ProtoFile{{Name: "shop/catalog.proto", Kind: database.ProtoFileSource, Content: []byte(catalogProto)}},
+			wantErr: "shop/common.proto",
+		},
+		{
+			name: "File defined twice",
+			files: []database.ProtoFile{
+				{Name: "shop/common.proto", Kind: database.ProtoFileSource, Content: []byte(commonProto)},
+				{Name: "shop/common.proto", Kind: database.ProtoFileSource, Content: []byte(commonProto)},
+			},
+			wantErr: "defined by both",
+		},
+		{
+			name:    "Invalid descriptor set",
+			files:   []database.ProtoFile{{Name: "set.pb", Kind: database.ProtoFileDescriptorSet, Content: []byte("not a descriptor set")}},
+			wantErr: "invalid descriptor set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileProtoFiles(tt.files)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateGRPCResponse(t *testing.T) {
+	descriptors, err := CompileProtoFiles(shopProtoFiles())
+	require.NoError(t, err)
+
+	getItem := &database.MockEndpoint{Path: "/shop.Catalog/GetItem", Type: database.EndpointTypeGRPC}
+	watchItems := &database.MockEndpoint{Path: "/shop.Catalog/WatchItems", Type: database.EndpointTypeGRPC}
+
+	tests := []struct {
+		name     string
+		endpoint *database.MockEndpoint
+		response database.MockResponse
+		wantErr  string
+	}{
+		{name: "Message", endpoint: getItem, response: database.MockResponse{Body: `{"id":"1","price":{"currency":"EUR","units":"12"},"updated_at":"2025-01-01T00:00:00Z"}`}},
+		{name: "JSON field names", endpoint: getItem, response: database.MockResponse{Body: `{"id":"1","updatedAt":"2025-01-01T00:00:00Z"}`}},
+		{name: "Empty body", endpoint: getItem, response: database.MockResponse{}},
+		{name: "Template body", endpoint: getItem, response: database.MockResponse{Templated: true, Body: `{"id":"{{.request.body.item_id}}"}`}},
+		{name: "Status with trailers", endpoint: getItem, response: database.MockResponse{Templated: true, GRPCStatus: `{"code":"not_found","message":"no item {{.request.body.item_id}}","trailers":{"x-reason":"gone"}}`}},
+		{name: "Stream", endpoint: watchItems, response: database.MockResponse{Stream: true, Body: `[{"data":{"id":"1"}},{"data":{"id":"2"},"delay_ms":100}]`}},
+		{name: "Unknown field", endpoint: getItem, response: database.MockResponse{Body: `{"sku":"1"}`}, wantErr: "invalid shop.Item"},
+		{name: "Not a message", endpoint: getItem, response: database.MockResponse{Body: `[1,2]`}, wantErr: "invalid shop.Item"},
+		{name: "Invalid stream event", endpoint: watchItems, response: database.MockResponse{Stream: true, Body: `[{"data":{"id":"1"}},{"data":{"id":2}}]`}, wantErr: "event 1"},
+		{name: "Stream on a unary method", endpoint: getItem, response: database.MockResponse{Stream: true, Body: `[{"data":{}}]`}, wantErr: "not a server streaming method"},
+		{name: "Unknown status code", endpoint: getItem, response: database.MockResponse{GRPCStatus: `{"code":"GONE"}`}, wantErr: "unknown gRPC status code"},
+		{name: "Status code out of range", endpoint: getItem, response: database.MockResponse{GRPCStatus: `{"code":17}`}, wantErr: "unknown gRPC status code"},
+		{name: "Reserved trailer", endpoint: getItem, response: database.MockResponse{GRPCStatus: `{"code":5,"trailers":{"grpc-status":"0"}}`}, wantErr: "reserved"},
+		{name: "Uppercase trailer", endpoint: getItem, response: database.MockResponse{GRPCStatus: `{"code":5,"trailers":{"X-Reason":"gone"}}`}, wantErr: "lowercase"},
+		{name: "File body", endpoint: getItem, response: database.MockResponse{BodyType: BodyTypeFile}, wantErr: "text body"},
+		{name: "Unknown method", endpoint: &database.MockEndpoint{Path: "/shop.Catalog/Missing"}, response: database.MockResponse{}, wantErr: "not defined"},
+		{name: "Client streaming method", endpoint: &database.MockEndpoint{Path: "/shop.Catalog/ImportItems"}, response: database.MockResponse{}, wantErr: "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGRPCResponse(descriptors, tt.endpoint, tt.response)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestEncodeGRPCMessage(t *testing.T) {
+	assert.Equal(t, "item 42 not found", encodeGRPCMessage("item 42 not found"))
+	assert.Equal(t, "100%25 caf%C3%A9%0A", encodeGRPCMessage("100% café\n"))
+}
+
+// setupGRPCProject creates a project serving the shop protos, with a grpc endpoint for each
+// path and its responses
+func setupGRPCProject(t *testing.T, endpoints map[string][]database.MockResponse) (*MockService, *database.Project) {
+	db := database.GetDB()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "gRPC", Alias: "grpc-" + uuid.New().String()[:8], Mode: database.ModeMock}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() {
+		InvalidateProjectRoutes(project.ID)
+		InvalidateProjectProtos(project.ID)
+	})
+
+	for _, file := range shopProtoFiles() {
+		file.ProjectID = project.ID
+		require.NoError(t, db.Create(&file).Error)
+	}
+
+	for path, responses := range endpoints {
+		endpoint := &database.MockEndpoint{ProjectID: project.ID, Method: "POST", Path: path, Enabled: true, ResponseMode: "static", Type: database.EndpointTypeGRPC}
+		require.NoError(t, db.Create(endpoint).Error)
+		for i := range responses {
+			responses[i].EndpointID = endpoint.ID
+			responses[i].Enabled = true
+			require.NoError(t, db.Create(&responses[i]).Error)
+		}
+	}
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	return service, project
+}
+
+// grpcTestServer serves a project over h2c like the mock handler does, reporting the calls it served
+func grpcTestServer(t *testing.T, service *MockService, project *database.Project) (*httptest.Server, chan *GRPCCall) {
+	calls := make(chan *GRPCCall, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta := &RequestMeta{}
+		r = r.WithContext(ContextWithRequestMeta(r.Context(), meta))
+		resp, err, _, _, _ := service.HandleRequest(r.Context(), project.Alias, r.Method, r.URL.Path, r)
+		require.NoError(t, err)
+
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+		for key, values := range resp.Trailer {
+			for _, value := range values {
+				w.Header().Add(http.TrailerPrefix+key, value)
+			}
+		}
+		calls <- meta.GRPC
+	}))
+	server.Config.Protocols = &http.Protocols{}
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+// callGRPC sends a unary request message as JSON to path, returning the response and its messages
+func callGRPC(t *testing.T, server *httptest.Server, path string, request string, compress bool) (*http.Response, [][]byte) {
+	t.Helper()
+	descriptors, err := CompileProtoFiles(shopProtoFiles())
+	require.NoError(t, err)
+	method := descriptors.Method(path)
+
+	var body []byte
+	header := http.Header{"Content-Type": []string{"application/grpc"}, "Te": []string{"trailers"}}
+	if method != nil {
+		message := dynamicpb.NewMessage(method.Input())
+		require.NoError(t, protojson.Unmarshal([]byte(request), message))
+		wire, err := proto.Marshal(message)
+		require.NoError(t, err)
+
+		body = grpcFrame(wire)
+		if compress {
+			var buf bytes.Buffer
+			writer := gzip.NewWriter(&buf)
+			writer.Write(wire)
+			writer.Close()
+			body = append([]byte{1, 0, 0, 0, 0}, buf.Bytes()...)
+			binary.BigEndian.PutUint32(body[1:5], uint32(buf.Len()))
+			header.Set("Grpc-Encoding", "gzip")
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+path, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header = header
+
+	protocols := &http.Protocols{}
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	messages, err := readGRPCMessages(data, "")
+	require.NoError(t, err)
+	return resp, messages
+}
+
+// decodeItem decodes a shop.Item response message to JSON
+func decodeItem(t *testing.T, message []byte) map[string]interface{} {
+	t.Helper()
+	descriptors, err := CompileProtoFiles(shopProtoFiles())
+	require.NoError(t, err)
+	item := dynamicpb.NewMessage(descriptors.Method("/shop.Catalog/GetItem").Output())
+	require.NoError(t, proto.Unmarshal(message, item))
+
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(item)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	return decoded
+}
+
+func TestGRPCMock(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	service, project := setupGRPCProject(t, map[string][]database.MockResponse{
+		"/shop.Catalog/GetItem": {
+			{
+				Priority:  2,
+				Templated: true,
+				Headers:   `{"x-request-item":"{{.request.body.item_id}}"}`,
+				Body:      `{"id":"{{.request.body.item_id}}","name":"Lamp","price":{"currency":"EUR","units":"12"}}`,
+				Rules:     []database.MockRule{{Type: "body", Key: "$.item_id", Operator: "starts_with", Value: "4"}},
+			},
+			{
+				Priority:   1,
+				IsFallback: true,
+				Templated:  true,
+				GRPCStatus: `{"code":"NOT_FOUND","message":"no item {{.request.body.item_id}}","trailers":{"x-reason":"gone"}}`,
+			},
+		},
+		"/shop.Catalog/WatchItems": {
+			{Stream: true, Body: `[{"data":{"id":"1"}},{"data":{"id":"2"},"delay_ms":20},{"data":{"id":"3"}}]`},
+		},
+	})
+	server, calls := grpcTestServer(t, service, project)
+
+	t.Run("Unary call matched by a rule on the request message", func(t *testing.T) {
+		resp, messages := callGRPC(t, server, "/shop.Catalog/GetItem", `{"item_id":"42"}`, false)
+		call := <-calls
+
+		assert.Equal(t, "application/grpc", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "42", resp.Header.Get("X-Request-Item"))
+		assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+		require.Len(t, messages, 1)
+		assert.Equal(t, map[string]interface{}{
+			"id":    "42",
+			"name":  "Lamp",
+			"price": map[string]interface{}{"currency": "EUR", "units": "12"},
+		}, decodeItem(t, messages[0]))
+
+		assert.Equal(t, "/shop.Catalog/GetItem", call.Method)
+		assert.Equal(t, "OK", call.Status())
+		assert.JSONEq(t, `{"item_id":"42","fields":[]}`, call.RequestJSON())
+		assert.JSONEq(t, `{"id":"42","name":"Lamp","price":{"currency":"EUR","units":"12"},"updated_at":null}`, call.ResponseJSON())
+	})
+
+	t.Run("Compressed request", func(t *testing.T) {
+		_, messages := callGRPC(t, server, "/shop.Catalog/GetItem", `{"item_id":"43"}`, true)
+		<-calls
+
+		require.Len(t, messages, 1)
+		assert.Equal(t, "43", decodeItem(t, messages[0])["id"])
+	})
+
+	t.Run("Error status with trailers", func(t *testing.T) {
+		resp, messages := callGRPC(t, server, "/shop.Catalog/GetItem", `{"item_id":"7"}`, false)
+		call := <-calls
+
+		assert.Empty(t, messages)
+		assert.Equal(t, "5", resp.Header.Get("Grpc-Status"))
+		assert.Equal(t, "no item 7", resp.Header.Get("Grpc-Message"))
+		assert.Equal(t, "gone", resp.Header.Get("X-Reason"))
+		assert.Equal(t, "NOT_FOUND", call.Status())
+		assert.Empty(t, call.ResponseJSON())
+	})
+
+	t.Run("Server streaming", func(t *testing.T) {
+		resp, messages := callGRPC(t, server, "/shop.Catalog/WatchItems", `{"category":"lamps"}`, false)
+		call := <-calls
+
+		require.Len(t, messages, 3)
+		for i, message := range messages {
+			assert.Equal(t, string(rune('1'+i)), decodeItem(t, message)["id"])
+		}
+		assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+		assert.Equal(t, "OK", call.Status())
+
+		var logged []map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(call.ResponseJSON()), &logged))
+		assert.Len(t, logged, 3)
+	})
+
+	t.Run("Unknown method", func(t *testing.T) {
+		resp, messages := callGRPC(t, server, "/shop.Catalog/ListItems", `{}`, false)
+		call := <-calls
+
+		assert.Empty(t, messages)
+		assert.Equal(t, "12", resp.Header.Get("Grpc-Status"))
+		assert.Equal(t, "UNIMPLEMENTED", call.Status())
+	})
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"beo-echo/backend/src/database"
+)
+
+// sampleLatency draws a delay in milliseconds from a latency distribution
+func sampleLatency(config *database.LatencyConfig, rng *rand.Rand) float64 {
+	var delay float64
+	switch config.Distribution {
+	case database.LatencyUniform:
+		delay = float64(config.MinMs) + rng.Float64()*float64(config.MaxMs-config.MinMs)
+	case database.LatencyNormal:
+		delay = config.MeanMs + rng.NormFloat64()*config.StdDevMs
+	case database.LatencyLogNormal:
+		// Convert the mean and standard deviation of the delay into the parameters of the underlying normal
+		sigma2 := math.Log(1 + (config.StdDevMs*config.StdDevMs)/(config.MeanMs*config.MeanMs))
+		mu := math.Log(config.MeanMs) - sigma2/2
+		delay = math.Exp(mu + rng.NormFloat64()*math.Sqrt(sigma2))
+	case database.LatencyPercentile:
+		delay = samplePercentileLatency(config, rng.Float64())
+	}
+
+	if delay < float64(config.MinMs) {
+		delay = float64(config.MinMs)
+	}
+	if config.MaxMs > 0 && delay > float64(config.MaxMs) {
+		delay = float64(config.MaxMs)
+	}
+	return math.Min(delay, database.MaxDelayMs)
+}
+
+// samplePercentileLatency maps a uniform value onto a piecewise linear curve through the
+// percentile targets. Below p50 the curve starts at minMs; above p99 it ends at maxMs, or
+// at p99 + (p99 - p95) when maxMs is not set.
+func samplePercentileLatency(config *database.LatencyConfig, u float64) float64 {
+	upper := float64(config.MaxMs)
+	if upper == 0 {
+		upper = float64(2*config.P99Ms - config.P95Ms)
+	}
+	points := []struct{ p, ms float64 }{
+		{0, float64(config.MinMs)},
+		{0.50, float64(config.P50Ms)},
+		{0.95, float64(config.P95Ms)},
+		{0.99, float64(config.P99Ms)},
+		{1, upper},
+	}
+	for i := 1; i < len(points); i++ {
+		if u <= points[i].p {
+			lo, hi := points[i-1], points[i]
+			return lo.ms + (u-lo.p)/(hi.p-lo.p)*(hi.ms-lo.ms)
+		}
+	}
+	return upper
+}
+
+// resolveDelay picks the delay to apply based on priority: Response > Endpoint > Project.
+// At each level a latency distribution takes precedence over a fixed delay.
+func resolveDelay(project *database.Project, endpoint *database.MockEndpoint, response *database.MockResponse) time.Duration {
+	var latency *database.LatencyConfig
+	var delayMs int
+
+	// Response delay has highest priority
+	if response != nil {
+		if config, err := database.ParseLatencyConfig(response.Latency); err == nil && config != nil {
+			latency = config
+		} else if response.DelayMS > 0 {
+			delayMs = response.DelayMS
+		}
+	}
+
+	// Endpoint delay overrides project delay
+	if endpoint != nil && latency == nil && delayMs == 0 && endpoint.AdvanceConfig != "" {
+		if endpointConfig, err := database.ParseEndpointAdvanceConfig(endpoint.AdvanceConfig); err == nil {
+			latency, delayMs = endpointConfig.Latency, endpointConfig.DelayMs
+		}
+	}
+
+	// Project-level delay from advance config
+	if project != nil && latency == nil && delayMs == 0 && project.AdvanceConfig != "" {
+		if projectConfig, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig); err == nil {
+			latency, delayMs = projectConfig.Latency, projectConfig.DelayMs
+		}
+	}
+
+	if latency != nil {
+		ms := sampleLatency(latency, responseRand)
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	return time.Duration(delayMs) * time.Millisecond
+}
+
+// waitDelay blocks for the delay or until the context is done, whichever comes first
+func waitDelay(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
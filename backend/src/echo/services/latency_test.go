@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"beo-echo/backend/src/database"
+)
+
+// sampleMany draws n delays and returns them sorted
+func sampleMany(config *database.LatencyConfig, n int) []float64 {
+	rng := rand.New(rand.NewSource(42))
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = sampleLatency(config, rng)
+	}
+	sort.Float64s(samples)
+	return samples
+}
+
+func percentileOf(sorted []float64, p float64) float64 {
+	return sorted[int(p*float64(len(sorted)-1))]
+}
+
+func meanOf(samples []float64) float64 {
+	var sum float64
+	for _, v := range samples {
+		sum += v
+	}
+	return sum / float64(len(samples))
+}
+
+func TestSampleLatency(t *testing.T) {
+	t.Run("Uniform stays within bounds", func(t *testing.T) {
+		samples := sampleMany(&database.LatencyConfig{Distribution: database.LatencyUniform, MinMs: 50, MaxMs: 150}, 5000)
+		assert.GreaterOrEqual(t, samples[0], 50.0)
+		assert.LessOrEqual(t, samples[len(samples)-1], 150.0)
+		assert.InDelta(t, 100, meanOf(samples), 3)
+	})
+
+	t.Run("Normal matches mean and is clamped at zero", func(t *testing.T) {
+		samples := sampleMany(&database.LatencyConfig{Distribution: database.LatencyNormal, MeanMs: 200, StdDevMs: 30}, 5000)
+		assert.InDelta(t, 200, meanOf(samples), 3)
+		assert.InDelta(t, 200, percentileOf(samples, 0.5), 3)
+
+		wide := sampleMany(&database.LatencyConfig{Distribution: database.LatencyNormal, MeanMs: 10, StdDevMs: 50}, 1000)
+		assert.GreaterOrEqual(t, wide[0], 0.0)
+	})
+
+	t.Run("Log-normal matches mean with a long right tail", func(t *testing.T) {
+		samples := sampleMany(&database.LatencyConfig{Distribution: database.LatencyLogNormal, MeanMs: 100, StdDevMs: 80}, 20000)
+		assert.InDelta(t, 100, meanOf(samples), 5)
+		assert.Less(t, percentileOf(samples, 0.5), 100.0)
+		assert.Greater(t, samples[0], 0.0)
+	})
+
+	t.Run("Percentile targets are hit", func(t *testing.T) {
+		samples := sampleMany(&database.LatencyConfig{Distribution: database.LatencyPercentile, P50Ms: 100, P95Ms: 400, P99Ms: 900}, 20000)
+		assert.InDelta(t, 100, percentileOf(samples, 0.50), 10)
+		assert.InDelta(t, 400, percentileOf(samples, 0.95), 30)
+		assert.InDelta(t, 900, percentileOf(samples, 0.99), 60)
+		assert.LessOrEqual(t, samples[len(samples)-1], 1400.0)
+	})
+
+	t.Run("Max clamps the distribution", func(t *testing.T) {
+		samples := sampleMany(&database.LatencyConfig{Distribution: database.LatencyNormal, MeanMs: 100, StdDevMs: 100, MinMs: 80, MaxMs: 120}, 1000)
+		assert.GreaterOrEqual(t, samples[0], 80.0)
+		assert.LessOrEqual(t, samples[len(samples)-1], 120.0)
+	})
+
+	t.Run("Never exceeds the global maximum", func(t *testing.T) {
+		samples := sampleMany(&database.LatencyConfig{Distribution: database.LatencyLogNormal, MeanMs: 100000, StdDevMs: 100000}, 1000)
+		assert.LessOrEqual(t, samples[len(samples)-1], float64(database.MaxDelayMs))
+	})
+}
+
+func TestResolveDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  *database.Project
+		endpoint *database.MockEndpoint
+		response *database.MockResponse
+		min, max time.Duration
+	}{
+		{
+			name:    "project latency",
+			project: &database.Project{AdvanceConfig: `{"latency":{"distribution":"uniform","minMs":10,"maxMs":20}}`},
+			min:     10 * time.Millisecond, max: 20 * time.Millisecond,
+		},
+		{
+			name:     "endpoint fixed delay overrides project latency",
+			project:  &database.Project{AdvanceConfig: `{"latency":{"distribution":"uniform","minMs":10,"maxMs":20}}`},
+			endpoint: &database.MockEndpoint{AdvanceConfig: `{"delayMs":5}`},
+			min:      5 * time.Millisecond, max: 5 * time.Millisecond,
+		},
+		{
+			name:     "latency overrides fixed delay on the same level",
+			endpoint: &database.MockEndpoint{AdvanceConfig: `{"delayMs":5,"latency":{"distribution":"uniform","minMs":30,"maxMs":40}}`},
+			min:      30 * time.Millisecond, max: 40 * time.Millisecond,
+		},
+		{
+			name:     "response latency has highest priority",
+			endpoint: &database.MockEndpoint{AdvanceConfig: `{"delayMs":5}`},
+			response: &database.MockResponse{DelayMS: 7, Latency: `{"distribution":"uniform","minMs":50,"maxMs":60}`},
+			min:      50 * time.Millisecond, max: 60 * time.Millisecond,
+		},
+		{
+			name:     "invalid response latency falls back to response delay",
+			response: &database.MockResponse{DelayMS: 7, Latency: `{"distribution":"gamma"}`},
+			min:      7 * time.Millisecond, max: 7 * time.Millisecond,
+		},
+		{
+			name:    "invalid project latency is ignored",
+			project: &database.Project{AdvanceConfig: `{"latency":{"distribution":"uniform"}}`},
+			min:     0, max: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				delay := resolveDelay(tt.project, tt.endpoint, tt.response)
+				assert.GreaterOrEqual(t, delay, tt.min)
+				assert.LessOrEqual(t, delay, tt.max)
+			}
+		})
+	}
+}
+
+func TestApplyDelay_StopsOnCancel(t *testing.T) {
+	project := &database.Project{AdvanceConfig: `{"delayMs": 5000}`}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	(&MockService{}).applyDelay(ctx, project, nil, nil)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWaitDelay(t *testing.T) {
+	assert.NoError(t, waitDelay(context.Background(), 0))
+	assert.NoError(t, waitDelay(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, waitDelay(ctx, time.Hour), context.Canceled)
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,11 +15,14 @@ import (
 	"time"
 
 	"github.com/andybalholm/brotli"
+	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 
 	"beo-echo/backend/src/actions"
 	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/jsonpath"
 	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/echo/requestctx"
 	systemConfig "beo-echo/backend/src/systemConfigs"
 )
 
@@ -46,41 +48,80 @@ func (s *MockService) HandleRequest(ctx context.Context, alias, method, reqPath
 		return createErrorResponse(http.StatusBadRequest, "Invalid request"), nil, "", "", false
 	}
 
-	// Find project by alias
-	project, err := s.Repo.FindProjectByAlias(alias)
+	// Make sure the request carries the meta so rules and the logger see the same captured values
+	meta := RequestMetaFromContext(ctx)
+	if meta == nil {
+		meta = &RequestMeta{}
+		ctx = ContextWithRequestMeta(ctx, meta)
+	}
+	if RequestMetaFromContext(req.Context()) != meta {
+		req = req.WithContext(ContextWithRequestMeta(req.Context(), meta))
+	}
+
+	// Read and parse the request once for rules, templates, actions and the proxy
+	req, request := requestctx.Attach(req)
+	if requestctx.FromContext(ctx) == nil {
+		ctx = requestctx.WithRequest(ctx, request)
+	}
+
+	// Find project by alias together with its compiled routes
+	routes, err := s.routeTable(alias)
 	if err != nil {
 		// Get default response for project not found
 		return createDefaultJSONResponse(systemConfig.DEFAULT_RESPONSE_PROJECT_NOT_FOUND), nil, "", "", false
 	}
-
-	if err := s.ActionSvc.ExecuteBeforeRequestActions(ctx, project.ID, req); err != nil {
-		log.Err(err).Msgf("Failed to execute before request actions for project %s", project.ID)
-	}
+	projectCopy := *routes.Project
+	project := &projectCopy
+	recordScenarioStates(ctx, project)
 
 	// Extract the actual API endpoint path
 	// Path comes in like "/api/users" or "/users" - we need just the endpoint part
 	// First trim any project alias prefix if it exists
 	cleanPath := strings.TrimPrefix(reqPath, "/"+project.Alias)
 
+	if project.Mode != database.ModeDisabled {
+		// Browsers ask the CORS policy of the project before cross-origin requests, no endpoint is involved
+		if resp := handleCORSPreflight(project, req); resp != nil {
+			return resp, nil, project.ID, project.Mode, false
+		}
+		// Requests without the credentials the project or endpoint requires never reach the endpoints
+		if resp := authenticate(ctx, project, routes, method, cleanPath, req); resp != nil {
+			return applyCORS(project, req, resp), nil, project.ID, project.Mode, false
+		}
+	}
+
+	if err := s.ActionSvc.ExecuteBeforeRequestActions(ctx, project.ID, req); err != nil {
+		log.Err(err).Msgf("Failed to execute before request actions for project %s", project.ID)
+	}
+
 	// Check project mode
 	switch project.Mode {
 	case database.ModeMock:
-		resp, err, mode, matched := s.handleMockMode(ctx, project, method, cleanPath, req)
+		resp, err, mode, matched := s.handleMockMode(ctx, project, routes, method, cleanPath, req)
 		if err := s.ActionSvc.ExecuteAfterRequestActions(ctx, project.ID, req, resp); err != nil {
 			log.Err(err).Msgf("Failed to execute after request actions for project %s", project.ID)
 		}
+		if err == nil {
+			resp = applyCORS(project, req, s.injectFault(ctx, project, resp))
+		}
 		return resp, err, project.ID, mode, matched
 	case database.ModeProxy:
-		resp, matched, err := s.handleProxyMode(ctx, project, method, cleanPath, req)
+		resp, matched, err := s.handleProxyMode(ctx, project, routes, method, cleanPath, req)
 		if err := s.ActionSvc.ExecuteAfterRequestActions(ctx, project.ID, req, resp); err != nil {
 			log.Err(err).Msgf("Failed to execute after request actions for project %s", project.ID)
 		}
+		if err == nil {
+			resp = applyCORS(project, req, s.injectFault(ctx, project, resp))
+		}
 		return resp, err, project.ID, project.Mode, matched // Matched is true only if handled by a mock endpoint
 	case database.ModeForwarder:
-		resp, err := s.handleForwarderMode(ctx, project, method, cleanPath, req)
+		resp, err := s.handleForwarderMode(ctx, project, routes, method, cleanPath, req)
 		if err := s.ActionSvc.ExecuteAfterRequestActions(ctx, project.ID, req, resp); err != nil {
 			log.Err(err).Msgf("Failed to execute after request actions for project %s", project.ID)
 		}
+		if err == nil {
+			resp = applyCORS(project, req, s.injectFault(ctx, project, resp))
+		}
 		return resp, err, project.ID, project.Mode, false // Forwarder requests are always considered "not matched"
 	case database.ModeDisabled:
 		return createErrorResponse(http.StatusServiceUnavailable, "Service is disabled"), nil, project.ID, project.Mode, false
@@ -90,40 +131,78 @@ func (s *MockService) HandleRequest(ctx context.Context, alias, method, reqPath
 }
 
 // handleMockMode generates mock response and returns if the request matched an endpoint
-func (s *MockService) handleMockMode(ctx context.Context, project *database.Project, method, path string, req *http.Request) (*http.Response, error, database.ProjectMode, bool) {
-	endpoint, err := s.Repo.FindMatchingEndpoint(project.ID, method, path)
+func (s *MockService) handleMockMode(ctx context.Context, project *database.Project, routes *repositories.RouteTable, method, path string, req *http.Request) (*http.Response, error, database.ProjectMode, bool) {
+	match, err := routes.Match(method, path)
 	if err != nil {
 		// No matching endpoint found - apply project-level delay before returning error
-		s.applyDelay(project, nil, nil)
+		s.applyDelay(ctx, project, nil, nil)
+
+		// gRPC clients expect a gRPC status rather than a JSON body
+		if IsGRPCRequest(req) {
+			return unknownGRPCMethodResponse(ctx, path), nil, database.ModeMock, false
+		}
 
 		// Get default response for endpoint not found
 		return createDefaultJSONResponse(systemConfig.DEFAULT_RESPONSE_ENDPOINT_NOT_FOUND), nil, database.ModeMock, false
 	}
+	endpoint := match.MockEndpoint
+	recordMatch(ctx, match)
+
+	if endpoint.IsGraphQL() {
+		if resp := prepareGraphQLRequest(endpoint, req); resp != nil {
+			s.applyDelay(ctx, project, endpoint, nil)
+			return resp, nil, database.ModeMock, true
+		}
+	}
 
 	// Check if endpoint is configured for proxying
 	if endpoint.UseProxy && endpoint.ProxyTarget != nil {
 		// Apply delays before proxying
-		s.applyDelay(project, endpoint, nil)
+		s.applyDelay(ctx, project, endpoint, nil)
 		// Forward the request to the proxy target
-		resp, err := executeProxyRequest(ctx, endpoint.ProxyTarget.URL, method, path, req.URL.RawQuery, req)
+		resp, err := s.forwardRequest(ctx, project, endpoint, endpoint.ProxyTarget, method, path, req)
 		return resp, err, database.ModeProxy, true
 	}
 
+	// WebSocket endpoints play the script of their response on the upgraded connection
+	if endpoint.IsWebSocket() {
+		if resp := s.handleWebSocketEndpoint(ctx, project, endpoint, routes.Responses(endpoint.ID), path, req); resp != nil {
+			return resp, nil, database.ModeMock, true
+		}
+		s.applyDelay(ctx, project, endpoint, nil)
+		return createDefaultJSONResponse(systemConfig.DEFAULT_RESPONSE_NO_RESPONSE_CONFIGURED), nil, database.ModeMock, true
+	}
+
+	// gRPC endpoints decode the request message and transcode their response to protobuf
+	if endpoint.IsGRPC() {
+		if resp := s.handleGRPCEndpoint(ctx, project, endpoint, routes.Responses(endpoint.ID), path, req); resp != nil {
+			return resp, nil, database.ModeMock, true
+		}
+		s.applyDelay(ctx, project, endpoint, nil)
+		return startGRPCCall(ctx, endpoint.Path).fail(GRPCCodeUnimplemented, "no response configured for "+endpoint.Path), nil, database.ModeMock, true
+	}
+
+	// Collection endpoints serve their own data instead of configured responses
+	if endpoint.ResponseMode == ResponseModeCRUD {
+		s.applyDelay(ctx, project, endpoint, nil)
+		return s.handleCollectionRequest(project.ID, endpoint, req), nil, database.ModeMock, true
+	}
+
 	// Get all responses for this endpoint
-	responses, err := s.Repo.FindResponsesByEndpointID(endpoint.ID)
-	if err != nil || len(responses) == 0 {
+	responses := routes.Responses(endpoint.ID)
+	if len(responses) == 0 {
 		// Apply delays before returning error
-		s.applyDelay(project, endpoint, nil)
+		s.applyDelay(ctx, project, endpoint, nil)
 
 		// Get default response for no response configured
 		return createDefaultJSONResponse(systemConfig.DEFAULT_RESPONSE_NO_RESPONSE_CONFIGURED), nil, database.ModeMock, true
 	}
 
-	// Select response based on ResponseMode
-	response, ruleErr := selectResponseWithEndpoint(endpoint.ID, responses, endpoint.ResponseMode, req)
+	// Select response based on scenario state, rules and ResponseMode
+	response, ruleErr := s.selectResponse(project, endpoint, responses, req)
 	if ruleErr != nil {
 		// Rule mismatch explicitly
-		return createErrorResponse(http.StatusBadRequest, ruleErr.Error()), nil, database.ModeMock, false
+		return ruleMismatchResponse(endpoint, ruleErr), nil, database.ModeMock, false
 	}
 
 	if response == nil {
@@ -132,15 +211,22 @@ func (s *MockService) handleMockMode(ctx context.Context, project *database.Proj
 	}
 
 	// Apply delays (response-level delay overrides endpoint-level delay, which overrides project-level delay)
-	s.applyDelay(project, endpoint, response)
+	s.applyDelay(ctx, project, endpoint, response)
 
 	// Create and return HTTP response with match indicator
-	resp, err := createMockResponse(*response)
+	resp, err := s.buildResponse(*response, path, req)
+	if err == nil {
+		if endpoint.IsGraphQL() {
+			resp = checkGraphQLResult(endpoint, req, resp)
+		}
+		// Callbacks run in the background once the response is on its way
+		scheduleCallbacks(project.ID, *response, path, req)
+	}
 	return resp, err, database.ModeMock, true
 }
 
 // handleProxyMode checks for mock endpoint first, if not found forwards the request to target
-func (s *MockService) handleProxyMode(ctx context.Context, project *database.Project, method, path string, req *http.Request) (*http.Response, bool, error) {
+func (s *MockService) handleProxyMode(ctx context.Context, project *database.Project, routes *repositories.RouteTable, method, path string, req *http.Request) (*http.Response, bool, error) {
 	if project.ActiveProxy == nil {
 		return createErrorResponse(http.StatusInternalServerError, "No proxy target configured"), false, nil
 	}
@@ -153,25 +239,62 @@ func (s *MockService) handleProxyMode(ctx context.Context, project *database.Pro
 	}
 
 	// First check if a mock endpoint exists for this request
-	endpoint, err := s.Repo.FindMatchingEndpoint(project.ID, method, path)
+	match, err := routes.Match(method, path)
 	if err == nil {
 		// Found a matching endpoint, use the mock response
-		responses, err := s.Repo.FindResponsesByEndpointID(endpoint.ID)
-		if err == nil && len(responses) > 0 {
-			// Select response based on ResponseMode
-			response, ruleErr := selectResponseWithEndpoint(endpoint.ID, responses, endpoint.ResponseMode, req)
+		endpoint := match.MockEndpoint
+		recordMatch(ctx, match)
+
+		if endpoint.IsGraphQL() {
+			if resp := prepareGraphQLRequest(endpoint, req); resp != nil {
+				resp.Header.Set("beo-echo-response-type", "mock")
+				return resp, true, nil
+			}
+		}
+
+		// WebSocket endpoints without a response for the request are tunnelled to the target
+		if endpoint.IsWebSocket() {
+			if resp := s.handleWebSocketEndpoint(ctx, project, endpoint, routes.Responses(endpoint.ID), path, req); resp != nil {
+				resp.Header.Set("beo-echo-response-type", "mock")
+				return resp, true, nil
+			}
+		}
+
+		// gRPC endpoints without a response are forwarded to the target
+		if endpoint.IsGRPC() {
+			if resp := s.handleGRPCEndpoint(ctx, project, endpoint, routes.Responses(endpoint.ID), path, req); resp != nil {
+				resp.Header.Set("beo-echo-response-type", "mock")
+				return resp, true, nil
+			}
+		}
+
+		if endpoint.ResponseMode == ResponseModeCRUD {
+			s.applyDelay(ctx, project, endpoint, nil)
+			resp := s.handleCollectionRequest(project.ID, endpoint, req)
+			resp.Header.Set("beo-echo-response-type", "mock")
+			return resp, true, nil
+		}
+
+		responses := routes.Responses(endpoint.ID)
+		if len(responses) > 0 {
+			// Select response based on scenario state, rules and ResponseMode
+			response, ruleErr := s.selectResponse(project, endpoint, responses, req)
 			if ruleErr != nil {
 				// We don't proxy if it explicitly hit an endpoint but rules mismatched: we fail fast
-				return createErrorResponse(http.StatusBadRequest, ruleErr.Error()), false, nil
+				return ruleMismatchResponse(endpoint, ruleErr), false, nil
 			}
 
 			if response != nil {
 				// Apply delay with proper priority: Response > Endpoint > Project
-				s.applyDelay(project, endpoint, response)
+				s.applyDelay(ctx, project, endpoint, response)
 
 				// Create and return HTTP response from mock
-				resp, err := createMockResponse(*response)
+				resp, err := s.buildResponse(*response, path, req)
 				if err == nil {
+					if endpoint.IsGraphQL() {
+						resp = checkGraphQLResult(endpoint, req, resp)
+					}
+					scheduleCallbacks(project.ID, *response, path, req)
 					// Add header to indicate response was mocked
 					resp.Header.Set("beo-echo-response-type", "mock")
 					return resp, true, nil // True because it was handled by a mock endpoint
@@ -182,9 +305,10 @@ func (s *MockService) handleProxyMode(ctx context.Context, project *database.Pro
 
 	// No matching mock endpoint found or error occurred, forward to target
 	// Apply project-level delay before forwarding
-	s.applyDelay(project, nil, nil)
-	resp, err := executeProxyRequest(ctx, project.ActiveProxy.URL, method, path, req.URL.RawQuery, req)
+	s.applyDelay(ctx, project, nil, nil)
+	resp, err := s.forwardRequest(ctx, project, nil, project.ActiveProxy, method, path, req)
 	if err == nil && resp != nil && resp.Header != nil {
+		resp = s.recordExchange(project, routes, method, path, req, resp)
 		// Add header to indicate response was proxied
 		resp.Header.Set("beo-echo-response-type", "proxy")
 	}
@@ -192,7 +316,7 @@ func (s *MockService) handleProxyMode(ctx context.Context, project *database.Pro
 }
 
 // handleForwarderMode always forwards requests to the target without checking for mock endpoints
-func (s *MockService) handleForwarderMode(ctx context.Context, project *database.Project, method, path string, req *http.Request) (*http.Response, error) {
+func (s *MockService) handleForwarderMode(ctx context.Context, project *database.Project, routes *repositories.RouteTable, method, path string, req *http.Request) (*http.Response, error) {
 	if project.ActiveProxy == nil {
 		return createErrorResponse(http.StatusInternalServerError, "No proxy target configured"), nil
 	}
@@ -204,19 +328,23 @@ func (s *MockService) handleForwarderMode(ctx context.Context, project *database
 		}
 	}
 
-	// Use the common executeProxyRequest helper function, but with the path parameter
-	// which might differ from req.URL.Path in this context
+	// Forward with the path parameter, which might differ from req.URL.Path in this context
 	// Note: handleForwarderMode always returns false for match status in HandleRequest
 	// Apply project-level delay before forwarding
-	s.applyDelay(project, nil, nil)
+	s.applyDelay(ctx, project, nil, nil)
 
-	return executeProxyRequest(ctx, project.ActiveProxy.URL, method, path, req.URL.RawQuery, req)
+	resp, err := s.forwardRequest(ctx, project, nil, project.ActiveProxy, method, path, req)
+	if err == nil {
+		resp = s.recordExchange(project, routes, method, path, req, resp)
+	}
+	return resp, err
 }
 
-// executeProxyRequest is a common helper function to forward requests to a target URL
+// executeProxyRequest is a common helper function to forward requests to a proxy target
 // with proper header and body copying. This centralizes the forwarding logic for both
-// proxy and forwarder modes.
-func executeProxyRequest(ctx context.Context, targetURLString, method, pathStr, queryString string, req *http.Request) (*http.Response, error) {
+// proxy and forwarder modes. Requests go through the pooled client of the target,
+// and rewrite, when set, changes the request and the Location and Set-Cookie of the response.
+func executeProxyRequest(ctx context.Context, target *database.ProxyTarget, rewrite *database.ProxyRewriteConfig, method, pathStr, queryString string, req *http.Request) (*http.Response, error) {
 	// Check for recursive proxy loops by checking for any header with beo-echo prefix
 	for name := range req.Header {
 		if strings.HasPrefix(strings.ToLower(name), "beo-echo") {
@@ -224,19 +352,24 @@ func executeProxyRequest(ctx context.Context, targetURLString, method, pathStr,
 		}
 	}
 
-	targetURL, err := url.Parse(targetURLString)
+	// WebSocket upgrades are tunnelled to the target instead of sent as a single request
+	if websocket.IsWebSocketUpgrade(req) {
+		return executeWebSocketProxy(ctx, target, rewrite, pathStr, queryString, req)
+	}
+
+	targetURL, err := url.Parse(target.URL)
 	if err != nil {
 		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Invalid proxy URL: %s", err.Error())), nil
 	}
 
-	// Create a new client with desired configuration
-	client := &http.Client{
-		Timeout: time.Second * 30,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, // Disable SSL verification
-			},
-		},
+	// Reuse the client of the target, configured from its transport settings
+	client, err := proxyClientFor(target)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Invalid proxy transport: %s", err.Error())), nil
+	}
+
+	if rewrite != nil {
+		pathStr = rewriteProxyPath(rewrite.Path, pathStr)
 	}
 
 	// Create new URL for the target
@@ -245,15 +378,12 @@ func executeProxyRequest(ctx context.Context, targetURLString, method, pathStr,
 	forwardURL.Path = path.Join(forwardURL.Path, pathStr)
 	forwardURL.RawQuery = queryString
 
-	// Read the original request body if present
-	var bodyBytes []byte
-	if req.Body != nil {
-		bodyBytes, err = io.ReadAll(req.Body)
-		if err != nil {
-			return createErrorResponse(http.StatusBadGateway, fmt.Sprintf("Failed to read request body: %s", err.Error())), nil
-		}
-		// Restore the original body
-		req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	// Forward the body exactly as received, it was read once into the shared request view
+	// unless it exceeded the body cap, in which case it is streamed from the request
+	request := requestctx.From(req)
+	var body io.Reader = bytes.NewReader(request.Raw)
+	if request.Oversized {
+		body = req.Body
 	}
 
 	// Create a new request with all original attributes
@@ -261,11 +391,14 @@ func executeProxyRequest(ctx context.Context, targetURLString, method, pathStr,
 		ctx, // Use the passed context instead of req.Context()
 		method,
 		forwardURL.String(),
-		bytes.NewReader(bodyBytes),
+		body,
 	)
 	if err != nil {
 		return createErrorResponse(http.StatusBadGateway, fmt.Sprintf("Failed to create request: %s", err.Error())), nil
 	}
+	if request.Oversized {
+		newReq.ContentLength = req.ContentLength
+	}
 
 	// Copy all headers
 	for key, values := range req.Header {
@@ -276,8 +409,14 @@ func executeProxyRequest(ctx context.Context, targetURLString, method, pathStr,
 		}
 	}
 
-	// Set host header to target host
+	// Set host header to target host, unless the client Host is preserved
 	newReq.Host = targetURL.Host
+	if rewrite != nil {
+		if rewrite.PreserveHost && req.Host != "" {
+			newReq.Host = req.Host
+		}
+		rewriteRequestHeaders(rewrite.RequestHeaders, newReq.Header)
+	}
 
 	// Add loop detection header to prevent recursive proxying
 	newReq.Header.Set("beo-echo-loop-detect", "true")
@@ -299,6 +438,8 @@ func executeProxyRequest(ctx context.Context, targetURLString, method, pathStr,
 		resp.Header.Set("beo-echo-latency-ms", fmt.Sprintf("%d", latencyMS))
 	}
 
+	rewriteProxyResponse(rewrite, resp)
+
 	return resp, nil
 }
 
@@ -357,6 +498,9 @@ func selectResponseWithEndpoint(endpointID string, responses []database.MockResp
 		// Use the actual endpoint ID for round-robin selection
 		response := getNextRoundRobinResponse(endpointID, validResponses)
 		return &response, nil
+	case ResponseModeWeighted:
+		// Pick in proportion to each response weight
+		return selectWeightedResponse(validResponses, responseRand), nil
 	default:
 		// Default to random
 		return &validResponses[rand.Intn(len(validResponses))], nil
@@ -405,23 +549,42 @@ func matchesRules(response database.MockResponse, req *http.Request) bool {
 
 	var result bool
 	allMatch := true
+	request := requestctx.From(req)
 
 	for _, rule := range response.Rules {
 		switch rule.Type {
 		case "header":
-			if matchHeaderRule(rule, req) {
+			if matchHeaderRule(rule, request) {
 				result = true
 			} else {
 				allMatch = false
 			}
 		case "query":
-			if matchQueryRule(rule, req) {
+			if matchQueryRule(rule, request) {
 				result = true
 			} else {
 				allMatch = false
 			}
 		case "body":
-			if matchBodyRule(rule, req) {
+			if matchBodyRule(rule, request) {
+				result = true
+			} else {
+				allMatch = false
+			}
+		case "path":
+			if matchPathRule(rule, req) {
+				result = true
+			} else {
+				allMatch = false
+			}
+		case "graphql":
+			if matchGraphQLRule(rule, request) {
+				result = true
+			} else {
+				allMatch = false
+			}
+		case "jwt":
+			if matchJWTRule(rule, request) {
 				result = true
 			} else {
 				allMatch = false
@@ -439,89 +602,112 @@ func matchesRules(response database.MockResponse, req *http.Request) bool {
 }
 
 // matchHeaderRule checks if a header rule matches
-func matchHeaderRule(rule database.MockRule, req *http.Request) bool {
-	headerValue := req.Header.Get(rule.Key)
+func matchHeaderRule(rule database.MockRule, request *requestctx.Request) bool {
+	if isPresenceOperator(rule.Operator) {
+		return matchPresence(rule.Operator, len(request.Header.Values(rule.Key)) > 0)
+	}
+	headerValue := request.Header.Get(rule.Key)
 	return matchRuleValue(rule.Operator, headerValue, rule.Value)
 }
 
 // matchQueryRule checks if a query parameter rule matches
-func matchQueryRule(rule database.MockRule, req *http.Request) bool {
-	queryValue := req.URL.Query().Get(rule.Key)
+func matchQueryRule(rule database.MockRule, request *requestctx.Request) bool {
+	query := request.Query()
+	if isPresenceOperator(rule.Operator) {
+		return matchPresence(rule.Operator, query.Has(rule.Key))
+	}
+	queryValue := query.Get(rule.Key)
 	return matchRuleValue(rule.Operator, queryValue, rule.Value)
 }
 
-// matchBodyRule checks if a body rule matches
-func matchBodyRule(rule database.MockRule, req *http.Request) bool {
-	// Get body content (this is a simplistic approach; in real-world you'd want to cache)
-	if req.Body == nil {
-		return false
+// matchPathRule checks if a captured path parameter rule matches
+// The key is the param name (e.g. "id" for /users/:id), "*0" for wildcards or "$1" for regex groups.
+func matchPathRule(rule database.MockRule, req *http.Request) bool {
+	paramValue, present := pathParamsFromRequest(req)[rule.Key]
+	if isPresenceOperator(rule.Operator) {
+		return matchPresence(rule.Operator, present)
 	}
+	return matchRuleValue(rule.Operator, paramValue, rule.Value)
+}
 
-	// Read body
-	bodyBytes, err := io.ReadAll(req.Body)
-	if err != nil {
-		return false
+// recordMatch stores the matched endpoint and its captured path values on the request meta
+// and the shared request view
+func recordMatch(ctx context.Context, match *repositories.EndpointMatch) {
+	params := match.Params()
+	if meta := RequestMetaFromContext(ctx); meta != nil {
+		meta.PathParams = params
+		meta.endpoint = match.MockEndpoint
+	}
+	if request := requestctx.FromContext(ctx); request != nil {
+		request.PathParams = params
 	}
+}
 
-	// Restore body for subsequent reads
-	req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+// Values for MockRule.Match, deciding how a body key that selects several values matches
+const (
+	ruleMatchAny = "any"
+	ruleMatchAll = "all"
+)
 
-	// For JSON bodies, try to extract nested values
-	var bodyData map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &bodyData); err == nil {
-		// Try to find nested value
-		value, exists := getNestedValueEx(bodyData, rule.Key)
+// matchBodyRule checks if a body rule matches
+// The body is read and parsed once per request and shared by every rule.
+// The key is read according to the body format (JSONPath for JSON, XPath for XML, field
+// names for forms and multipart, see selectBodyValues). When it selects several values the
+// rule matches if any of them matches, or only if all of them match when rule.Match is "all".
+func matchBodyRule(rule database.MockRule, request *requestctx.Request) bool {
+	if request.Raw == nil {
+		return false
+	}
 
-		// Handle has_property operator specifically logic
-		if strings.ToLower(rule.Operator) == "has_property" {
-			return exists // matchRuleValue will not be used
+	if values, definite, ok := selectBodyValues(request, rule.Key); ok {
+		// has_property, exists and not_exists only look at whether the key selects anything
+		if isPresenceOperator(rule.Operator) {
+			return matchPresence(rule.Operator, len(values) > 0)
 		}
 
-		if exists {
+		if len(values) > 0 {
 			// For schema or type matches, we need the raw interface
-			return matchRuleValueTyped(rule.Operator, value, rule.Value)
+			if definite {
+				return matchRuleValueTyped(rule.Operator, values[0], rule.Value)
+			}
+			return matchEachValue(rule, values)
 		}
 	}
 
-	// Basic operators on full string body if not JSON or property not found
-	// Handle has_property gracefully if not JSON
-	if strings.ToLower(rule.Operator) == "has_property" {
-		return false
+	// Basic operators on full string body if not parsed or property not found
+	// Presence operators find nothing in a body that is not parsed
+	if isPresenceOperator(rule.Operator) {
+		return matchPresence(rule.Operator, false)
 	}
 
-	return matchRuleValue(rule.Operator, string(bodyBytes), rule.Value)
+	return matchRuleValue(rule.Operator, string(request.Body), rule.Value)
 }
 
-// matchRuleValue compares string values based on operator
-func matchRuleValue(operator, actual, expected string) bool {
-	switch strings.ToLower(operator) {
-	case "equals":
-		return actual == expected
-	case "contains":
-		return strings.Contains(actual, expected)
-	default:
-		return actual == expected // Default to equals
+// matchEachValue applies a rule to every value a body key selected, with any/all semantics
+func matchEachValue(rule database.MockRule, values []interface{}) bool {
+	all := strings.ToLower(rule.Match) == ruleMatchAll
+	for _, value := range values {
+		matched := matchRuleValueTyped(rule.Operator, value, rule.Value)
+		if matched && !all {
+			return true
+		}
+		if !matched && all {
+			return false
+		}
 	}
+	return all
 }
 
 // matchRuleValueTyped handles advanced type checks and schemas
+// Other operators compare the value as a string, so numbers and dates work with gt/lt/between.
 func matchRuleValueTyped(operator string, actual interface{}, expected string) bool {
 	switch strings.ToLower(operator) {
-	case "matches_type":
+	case OperatorMatchesType:
 		return matchType(actual, expected)
-	case "matches_schema":
+	case OperatorMatchesSchema:
 		return matchSchema(actual, expected)
-	case "equals":
-		// convert actual to string for equals
-		strVal := interfaceToString(actual)
-		return strVal == expected
-	case "contains":
-		// convert actual to string for contains
-		strVal := interfaceToString(actual)
-		return strings.Contains(strVal, expected)
 	default:
-		strVal := interfaceToString(actual)
-		return strVal == expected
+		return matchRuleValue(operator, interfaceToString(actual), expected)
 	}
 }
 
@@ -643,40 +829,16 @@ func compareStructures(actual, expected interface{}) bool {
 	return false
 }
 
-// getNestedValue extracts a string value from nested JSON using dot notation
-func getNestedValue(data map[string]interface{}, key string) string {
+// getNestedValue extracts a string value from nested JSON using a JSONPath key
+func getNestedValue(data interface{}, key string) string {
 	val, _ := getNestedValueEx(data, key)
 	return interfaceToString(val)
 }
 
 // getNestedValueEx extracts the raw interface value and a boolean indicating if it exists
-func getNestedValueEx(data map[string]interface{}, key string) (interface{}, bool) {
-	if key == "" {
-		return data, true // root object
-	}
-	parts := strings.Split(key, ".")
-	var current interface{} = data
-
-	for i, part := range parts {
-		// Try to navigate the object
-		switch v := current.(type) {
-		case map[string]interface{}:
-			val, exists := v[part]
-			if !exists {
-				return nil, false // key completely missing
-			}
-			current = val
-		default:
-			return nil, false // Can't navigate further
-		}
-
-		if current == nil && i < len(parts)-1 {
-			return nil, false // Intermediate object is null
-		}
-	}
-
-	return current, true
-
+// The key is a JSONPath expression ("user.id", "items[0].sku"); the first selected value is returned.
+func getNestedValueEx(data interface{}, key string) (interface{}, bool) {
+	return jsonpath.Get(data, key)
 }
 
 // createMockResponse builds an HTTP response from a mock response
@@ -752,6 +914,32 @@ func createMockResponse(mockResp database.MockResponse) (*http.Response, error)
 	return resp, nil
 }
 
+// createTemplatedMockResponse renders the response templates against the request and builds the HTTP response
+// A template that fails to render produces a 500 error response describing the problem.
+// Responses with Stream enabled are sent event by event instead of as a single body.
+func createTemplatedMockResponse(mockResp database.MockResponse, path string, req *http.Request) (*http.Response, error) {
+	if mockResp.BodyType == BodyTypeBase64 {
+		return createBase64Response(mockResp, path, req)
+	}
+
+	if mockResp.Templated && (isTemplate(mockResp.Body) || isTemplate(mockResp.Headers)) {
+		tctx := NewTemplateContext(req, path, pathParamsFromRequest(req))
+		rendered, err := renderMockResponse(mockResp, tctx)
+		if err != nil {
+			log.Error().Err(err).Str("response_id", mockResp.ID).Msg("Failed to render response template")
+			return createErrorResponse(http.StatusInternalServerError, err.Error()), nil
+		}
+		mockResp = rendered
+	}
+	mockResp = mergeGraphQLErrors(mockResp)
+
+	if mockResp.Stream {
+		return createStreamResponse(req.Context(), mockResp)
+	}
+
+	return createMockResponse(mockResp)
+}
+
 // createErrorResponse creates a standard error response
 func createErrorResponse(statusCode int, message string) *http.Response {
 	respBody := map[string]interface{}{
@@ -795,38 +983,11 @@ func createDefaultJSONResponse(message string) *http.Response {
 	return resp
 }
 
-// applyDelay applies delay based on priority: Response DelayMS > Endpoint DelayMs > Project DelayMs
-// Response parameter is optional - pass nil when response delay is not applicable
-func (s *MockService) applyDelay(project *database.Project, endpoint *database.MockEndpoint, response *database.MockResponse) {
-	var delayMs int
-
-	// Response delay has highest priority
-	if response != nil {
-		if response.DelayMS > 0 {
-			delayMs = response.DelayMS
-		}
-	}
-
-	// Endpoint delay overrides project delay
-	if endpoint != nil && delayMs == 0 {
-		if endpoint.AdvanceConfig != "" {
-			if endpointConfig, err := database.ParseEndpointAdvanceConfig(endpoint.AdvanceConfig); err == nil && endpointConfig.DelayMs > 0 {
-				delayMs = endpointConfig.DelayMs
-			}
-		}
-	}
-
-	// Get project-level delay from advance config
-	if project != nil && delayMs == 0 {
-		if project.AdvanceConfig != "" {
-			if projectConfig, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig); err == nil {
-				delayMs = projectConfig.DelayMs
-			}
-		}
-	}
-
-	// Apply delay if configured
-	if delayMs > 0 {
-		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+// applyDelay waits for the delay resolved with priority Response > Endpoint > Project.
+// Response parameter is optional - pass nil when response delay is not applicable.
+// The wait ends early when the request context is cancelled.
+func (s *MockService) applyDelay(ctx context.Context, project *database.Project, endpoint *database.MockEndpoint, response *database.MockResponse) {
+	if err := waitDelay(ctx, resolveDelay(project, endpoint, response)); err != nil {
+		log.Debug().Err(err).Msg("Response delay interrupted")
 	}
 }
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"bytes"
+	"compress/gzip"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
@@ -356,3 +359,136 @@ func createTestRequestWithBody(method, urlStr, bodyStr string) *http.Request {
 	req.Body = io.NopCloser(strings.NewReader(bodyStr))
 	return req
 }
+
+func TestMatchesRules_PathParams(t *testing.T) {
+	req := createTestRequest("GET", "/users/42", map[string]string{}, map[string]string{})
+	req = req.WithContext(ContextWithRequestMeta(req.Context(), &RequestMeta{
+		PathParams: map[string]string{"id": "42", "*0": "report"},
+	}))
+
+	tests := []struct {
+		name     string
+		rule     database.MockRule
+		expected bool
+	}{
+		{name: "named param equals", rule: database.MockRule{Type: "path", Key: "id", Operator: "equals", Value: "42"}, expected: true},
+		{name: "named param differs", rule: database.MockRule{Type: "path", Key: "id", Operator: "equals", Value: "7"}, expected: false},
+		{name: "wildcard contains", rule: database.MockRule{Type: "path", Key: "*0", Operator: "contains", Value: "rep"}, expected: true},
+		{name: "missing param", rule: database.MockRule{Type: "path", Key: "other", Operator: "equals", Value: "42"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := database.MockResponse{RulesLogic: "and", Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, req))
+		})
+	}
+}
+
+func TestMatchesRules_CompressedBody(t *testing.T) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write([]byte(`{"user":{"role":"admin"}}`))
+	writer.Close()
+
+	req := createTestRequest("POST", "/users", map[string]string{"Content-Encoding": "gzip"}, nil)
+	req.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+
+	response := database.MockResponse{RulesLogic: "and", Rules: []database.MockRule{
+		{Type: "body", Key: "user.role", Operator: "equals", Value: "admin"},
+	}}
+	assert.True(t, matchesRules(response, req))
+
+	// The body is left as received for later readers such as the proxy
+	body, _ := io.ReadAll(req.Body)
+	assert.Equal(t, buf.Bytes(), body)
+}
+
+func TestMatchesRules_BodyJSONPath(t *testing.T) {
+	body := `{"items":[{"sku":"A1","type":"book","qty":1},{"sku":"B2","type":"toy","qty":3}],"customer":{"tier":"gold"}}`
+
+	tests := []struct {
+		name     string
+		rule     database.MockRule
+		expected bool
+	}{
+		{name: "index", rule: database.MockRule{Type: "body", Key: "items[0].sku", Operator: "equals", Value: "A1"}, expected: true},
+		{name: "negative index", rule: database.MockRule{Type: "body", Key: "items[-1].sku", Operator: "equals", Value: "A1"}, expected: false},
+		{name: "root prefix", rule: database.MockRule{Type: "body", Key: "$.customer.tier", Operator: "equals", Value: "gold"}, expected: true},
+		{name: "wildcard any", rule: database.MockRule{Type: "body", Key: "items[*].qty", Operator: "equals", Value: "3"}, expected: true},
+		{name: "wildcard all fails", rule: database.MockRule{Type: "body", Key: "items[*].qty", Operator: "equals", Value: "3", Match: "all"}, expected: false},
+		{name: "wildcard all", rule: database.MockRule{Type: "body", Key: "items[*].sku", Operator: "matches_type", Value: "string", Match: "all"}, expected: true},
+		{name: "filter", rule: database.MockRule{Type: "body", Key: "$.items[?(@.type=='toy')].sku", Operator: "equals", Value: "B2"}, expected: true},
+		{name: "filter has_property", rule: database.MockRule{Type: "body", Key: "$.items[?(@.qty > 5)]", Operator: "has_property"}, expected: false},
+		{name: "recursive descent", rule: database.MockRule{Type: "body", Key: "$..tier", Operator: "equals", Value: "gold"}, expected: true},
+		{name: "definite path to array is compared whole", rule: database.MockRule{Type: "body", Key: "items", Operator: "contains", Value: `"sku":"B2"`}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := database.MockResponse{RulesLogic: "and", Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, createTestRequestWithBody("POST", "/orders", body)))
+		})
+	}
+}
+
+func TestMatchesRules_BodyFormats(t *testing.T) {
+	xmlRequest := func() *http.Request {
+		req := createTestRequestWithBody("POST", "/orders", `<Order id="42"><Item sku="A1" type="book"/><Item sku="B2" type="toy"/><Total>30</Total></Order>`)
+		req.Header.Set("Content-Type", "application/xml")
+		return req
+	}
+	formRequest := func() *http.Request {
+		req := createTestRequestWithBody("POST", "/signup", "email=jane%40example.com&tags[]=new&tags[]=vip")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+	multipartRequest := func() *http.Request {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		writer.WriteField("title", "Holiday")
+		file, _ := writer.CreateFormFile("avatar", "me.png")
+		file.Write([]byte("12345"))
+		writer.Close()
+
+		req := createTestRequestWithBody("POST", "/upload", buf.String())
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		req      func() *http.Request
+		rule     database.MockRule
+		expected bool
+	}{
+		{name: "xml attribute", req: xmlRequest, rule: database.MockRule{Type: "body", Key: "/Order/@id", Operator: "equals", Value: "42"}, expected: true},
+		{name: "xml predicate", req: xmlRequest, rule: database.MockRule{Type: "body", Key: "//Item[@type='toy']/@sku", Operator: "equals", Value: "B2"}, expected: true},
+		{name: "xml comparison", req: xmlRequest, rule: database.MockRule{Type: "body", Key: "//Total", Operator: "gt", Value: "25"}, expected: true},
+		{name: "xml any", req: xmlRequest, rule: database.MockRule{Type: "body", Key: "//Item/@sku", Operator: "equals", Value: "B2"}, expected: true},
+		{name: "xml all", req: xmlRequest, rule: database.MockRule{Type: "body", Key: "//Item/@sku", Operator: "equals", Value: "B2", Match: "all"}, expected: false},
+		{name: "xml exists", req: xmlRequest, rule: database.MockRule{Type: "body", Key: "//Coupon", Operator: "not_exists"}, expected: true},
+		{name: "form field", req: formRequest, rule: database.MockRule{Type: "body", Key: "email", Operator: "equals", Value: "jane@example.com"}, expected: true},
+		{name: "form array", req: formRequest, rule: database.MockRule{Type: "body", Key: "tags", Operator: "equals", Value: "vip"}, expected: true},
+		{name: "form missing", req: formRequest, rule: database.MockRule{Type: "body", Key: "name", Operator: "exists"}, expected: false},
+		{name: "multipart field", req: multipartRequest, rule: database.MockRule{Type: "body", Key: "title", Operator: "equals", Value: "Holiday"}, expected: true},
+		{name: "multipart filename", req: multipartRequest, rule: database.MockRule{Type: "body", Key: "avatar", Operator: "ends_with", Value: ".png"}, expected: true},
+		{name: "multipart file size", req: multipartRequest, rule: database.MockRule{Type: "body", Key: "avatar.size", Operator: "lt", Value: "10"}, expected: true},
+		{name: "multipart content type", req: multipartRequest, rule: database.MockRule{Type: "body", Key: "avatar.content_type", Operator: "equals", Value: "application/octet-stream"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := database.MockResponse{RulesLogic: "and", Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, tt.req()))
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	assert.NoError(t, ValidateRule(&database.MockRule{Type: "body", Key: "items[*].sku", Operator: "equals"}))
+	assert.NoError(t, ValidateRule(&database.MockRule{Type: "body", Key: "//Item[@type='book']/@sku", Operator: "equals"}))
+	assert.NoError(t, ValidateRule(&database.MockRule{Type: "header", Key: "X-Items[", Operator: "equals"}))
+	assert.ErrorIs(t, ValidateRule(&database.MockRule{Type: "body", Key: "items[", Operator: "equals"}), ErrInvalidRule)
+	assert.ErrorIs(t, ValidateRule(&database.MockRule{Type: "body", Key: "id", Operator: "equals", Match: "some"}), ErrInvalidRule)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -16,7 +17,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		project := &database.Project{AdvanceConfig: ""}
 
 		start := time.Now()
-		service.applyDelay(project, nil, nil)
+		service.applyDelay(context.Background(), project, nil, nil)
 		elapsed := time.Since(start)
 
 		// Should complete almost immediately (less than 10ms)
@@ -29,7 +30,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, nil, nil)
+		service.applyDelay(context.Background(), project, nil, nil)
 		elapsed := time.Since(start)
 
 		// Should delay approximately 50ms (allow some tolerance)
@@ -46,7 +47,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, nil)
+		service.applyDelay(context.Background(), project, endpoint, nil)
 		elapsed := time.Since(start)
 
 		// Should delay approximately 30ms (endpoint delay), not 100ms (project delay)
@@ -66,7 +67,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, response)
+		service.applyDelay(context.Background(), project, endpoint, response)
 		elapsed := time.Since(start)
 
 		// Should delay approximately 20ms (response delay), not project or endpoint delay
@@ -80,7 +81,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, nil, nil)
+		service.applyDelay(context.Background(), project, nil, nil)
 		elapsed := time.Since(start)
 
 		// Should complete almost immediately since invalid config is ignored
@@ -96,7 +97,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, nil)
+		service.applyDelay(context.Background(), project, endpoint, nil)
 		elapsed := time.Since(start)
 
 		// Should use project delay since endpoint config is invalid
@@ -116,7 +117,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, response)
+		service.applyDelay(context.Background(), project, endpoint, response)
 		elapsed := time.Since(start)
 
 		// Should complete almost immediately since all delays are zero
@@ -132,7 +133,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, nil)
+		service.applyDelay(context.Background(), project, endpoint, nil)
 		elapsed := time.Since(start)
 
 		// Should use project delay since endpoint delay is zero
@@ -149,7 +150,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, nil)
+		service.applyDelay(context.Background(), project, endpoint, nil)
 		elapsed := time.Since(start)
 
 		// Should use project delay since endpoint config is empty
@@ -169,7 +170,7 @@ func TestMockService_applyDelay(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, endpoint, response)
+		service.applyDelay(context.Background(), project, endpoint, response)
 		elapsed := time.Since(start)
 
 		// Should use response delay (25ms) as it has highest priority
@@ -183,7 +184,7 @@ func TestMockService_applyDelay_EdgeCases(t *testing.T) {
 
 	t.Run("Nil project should not panic", func(t *testing.T) {
 		start := time.Now()
-		service.applyDelay(nil, nil, nil)
+		service.applyDelay(context.Background(), nil, nil, nil)
 		elapsed := time.Since(start)
 
 		// Should complete almost immediately
@@ -194,7 +195,7 @@ func TestMockService_applyDelay_EdgeCases(t *testing.T) {
 		project := &database.Project{} // AdvanceConfig will be empty string by default
 
 		start := time.Now()
-		service.applyDelay(project, nil, nil)
+		service.applyDelay(context.Background(), project, nil, nil)
 		elapsed := time.Since(start)
 
 		// Should complete almost immediately
@@ -207,7 +208,7 @@ func TestMockService_applyDelay_EdgeCases(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, nil, nil)
+		service.applyDelay(context.Background(), project, nil, nil)
 		elapsed := time.Since(start)
 
 		// Even 1ms delay should be detectable (with some tolerance)
@@ -224,7 +225,7 @@ func TestMockService_applyDelay_EdgeCases(t *testing.T) {
 		}
 
 		start := time.Now()
-		service.applyDelay(project, nil, response)
+		service.applyDelay(context.Background(), project, nil, response)
 		elapsed := time.Since(start)
 
 		// Should use project delay since response delay is negative
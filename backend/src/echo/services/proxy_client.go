@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"beo-echo/backend/src/database"
+)
+
+// proxyClient is the HTTP client of a proxy target, built from its transport config
+type proxyClient struct {
+	transport string // Transport JSON the client was built from
+	client    *http.Client
+}
+
+// proxyClients holds one client per proxy target, keyed by target ID, so keep-alive
+// connections to the upstream are reused across requests
+var proxyClients sync.Map
+
+// proxyClientFor returns the pooled client of target, building it on first use and
+// rebuilding it when the target's transport config changed
+func proxyClientFor(target *database.ProxyTarget) (*http.Client, error) {
+	if cached, ok := proxyClients.Load(target.ID); ok {
+		if pc := cached.(*proxyClient); pc.transport == target.Transport {
+			return pc.client, nil
+		}
+	}
+
+	config, err := database.ParseProxyTransport(target.Transport)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newProxyClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, loaded := proxyClients.Swap(target.ID, &proxyClient{transport: target.Transport, client: client})
+	if loaded {
+		previous.(*proxyClient).client.CloseIdleConnections()
+	}
+	return client, nil
+}
+
+// newProxyClient builds an HTTP client from a transport config
+func newProxyClient(config *database.ProxyTransportConfig) (*http.Client, error) {
+	tlsConfig, err := proxyTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   config.ConnectTimeout(),
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // Upstreams are reached directly unless proxyUrl is set
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.ConnectTimeout(),
+		ResponseHeaderTimeout: config.ReadTimeout(),
+		ForceAttemptHTTP2:     config.HTTP2,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   20,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		// Redirects are passed through to the client, with their Location rewritten if configured
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// proxyTLSConfig builds the TLS settings used to reach the upstream of a proxy target
+func proxyTLSConfig(config *database.ProxyTransportConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !config.VerifyTLS,
+		ServerName:         config.ServerName,
+	}
+
+	if config.CABundle != "" {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		roots.AppendCertsFromPEM([]byte(config.CABundle))
+		tlsConfig.RootCAs = roots
+	}
+
+	if config.ClientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(config.ClientCert), []byte(config.ClientKey))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+// clientKeyPairPEM generates a self-signed client certificate and its key as PEM
+func clientKeyPairPEM(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "beo-echo-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func serverCAPEM(server *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+}
+
+func transportJSON(t *testing.T, config database.ProxyTransportConfig) string {
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func proxyStatus(t *testing.T, target *database.ProxyTarget) (int, string) {
+	req := newTemplateTestRequest("GET", "http://localhost/hello", "", nil)
+	resp, err := executeProxyRequest(context.Background(), target, nil, "GET", "/hello", "", req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestExecuteProxyRequest_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.ServerName))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		transport  string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "verification is off by default", wantStatus: http.StatusOK},
+		{name: "verification fails for an unknown CA", transport: transportJSON(t, database.ProxyTransportConfig{VerifyTLS: true}), wantStatus: http.StatusBadGateway, wantBody: "certificate"},
+		{name: "ca bundle is trusted", transport: transportJSON(t, database.ProxyTransportConfig{VerifyTLS: true, CABundle: serverCAPEM(server)}), wantStatus: http.StatusOK},
+		{name: "sni override", transport: transportJSON(t, database.ProxyTransportConfig{VerifyTLS: true, CABundle: serverCAPEM(server), ServerName: "example.com"}), wantStatus: http.StatusOK, wantBody: "example.com"},
+		{name: "sni must match the certificate", transport: transportJSON(t, database.ProxyTransportConfig{VerifyTLS: true, CABundle: serverCAPEM(server), ServerName: "other.internal"}), wantStatus: http.StatusBadGateway, wantBody: "other.internal"},
+		{name: "invalid transport", transport: `{"readTimeoutMs":-1}`, wantStatus: http.StatusInternalServerError, wantBody: "Invalid proxy transport"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &database.ProxyTarget{ID: uuid.New().String(), URL: server.URL, Transport: tt.transport}
+			status, body := proxyStatus(t, target)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Contains(t, body, tt.wantBody)
+		})
+	}
+}
+
+func TestExecuteProxyRequest_MutualTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	status, _ := proxyStatus(t, &database.ProxyTarget{ID: uuid.New().String(), URL: server.URL})
+	assert.Equal(t, http.StatusBadGateway, status)
+
+	cert, key := clientKeyPairPEM(t)
+	target := &database.ProxyTarget{ID: uuid.New().String(), URL: server.URL, Transport: transportJSON(t, database.ProxyTransportConfig{ClientCert: cert, ClientKey: key})}
+	status, body := proxyStatus(t, target)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "beo-echo-client", body)
+}
+
+func TestProxyClientFor_ReusesClients(t *testing.T) {
+	target := &database.ProxyTarget{ID: uuid.New().String(), URL: "http://localhost"}
+
+	first, err := proxyClientFor(target)
+	require.NoError(t, err)
+	second, err := proxyClientFor(target)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// Changing the transport config builds a new client
+	target.Transport = `{"readTimeoutMs":1000}`
+	third, err := proxyClientFor(target)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, time.Second, third.Transport.(*http.Transport).ResponseHeaderTimeout)
+
+	other, err := proxyClientFor(&database.ProxyTarget{ID: uuid.New().String()})
+	require.NoError(t, err)
+	assert.NotSame(t, third, other)
+}
+
+func TestExecuteProxyRequest_ReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	target := &database.ProxyTarget{ID: uuid.New().String(), URL: server.URL, Transport: `{"readTimeoutMs":50}`}
+	status, body := proxyStatus(t, target)
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Contains(t, body, "timeout")
+}
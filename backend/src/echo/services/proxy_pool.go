@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// poolCounters holds the round-robin position of each pool, keyed by pool target ID
+var poolCounters sync.Map
+
+// forwardRequest forwards the request to target. A pool target forwards to its members
+// following the pool strategy, moving on to the next member when failover is enabled.
+// endpoint is the proxying endpoint, nil when the project forwards the request.
+// The target that served the request is reported in the request meta.
+func (s *MockService) forwardRequest(ctx context.Context, project *database.Project, endpoint *database.MockEndpoint, target *database.ProxyTarget, method, path string, req *http.Request) (*http.Response, error) {
+	if !target.IsPool() {
+		resp, err := executeProxyRequest(ctx, target, proxyRewrite(endpoint, target), method, path, req.URL.RawQuery, req)
+		recordProxyTarget(ctx, target)
+		return resp, err
+	}
+
+	pool, err := database.ParseProxyPool(target.Pool)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Invalid proxy pool %s: %s", target.Label, err.Error())), nil
+	}
+
+	members, weights := poolMembers(project, pool)
+	if len(members) == 0 {
+		return createErrorResponse(http.StatusBadGateway, fmt.Sprintf("Proxy pool %s has no available targets", target.Label)), nil
+	}
+
+	order := poolOrder(target.ID, pool, weights, req, responseRand)
+	// A body over the request body cap is streamed once, so it cannot be sent to another member
+	failover := (pool.Failover || pool.Strategy == database.PoolFailover) && !requestctx.From(req).Oversized
+
+	var resp *http.Response
+	for i, index := range order {
+		member := &members[index]
+		resp, err = executeProxyRequest(ctx, member, proxyRewrite(endpoint, member, target), method, path, req.URL.RawQuery, req)
+		recordProxyTarget(ctx, member)
+
+		if !failover || i == len(order)-1 || !upstreamFailed(resp, err) || ctx.Err() != nil {
+			break
+		}
+
+		log.Warn().
+			Str("pool", target.Label).
+			Str("target", member.Label).
+			Int("status", responseStatus(resp)).
+			Msg("proxy target failed, trying the next pool member")
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+	}
+	return resp, err
+}
+
+// poolMembers resolves the pool members against the project's proxy targets.
+// Members that no longer exist and nested pools are skipped.
+func poolMembers(project *database.Project, pool *database.ProxyPoolConfig) ([]database.ProxyTarget, []int) {
+	targets := make(map[string]database.ProxyTarget, len(project.ProxyTargets))
+	for _, target := range project.ProxyTargets {
+		targets[target.ID] = target
+	}
+
+	var members []database.ProxyTarget
+	var weights []int
+	for _, member := range pool.Members {
+		target, ok := targets[member.TargetID]
+		if !ok || target.IsPool() {
+			continue
+		}
+		weight := member.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		members = append(members, target)
+		weights = append(weights, weight)
+	}
+	return members, weights
+}
+
+// poolOrder returns the order in which the pool members are tried: the member picked
+// by the strategy first, followed by the others in declared order
+func poolOrder(poolID string, pool *database.ProxyPoolConfig, weights []int, req *http.Request, rng *rand.Rand) []int {
+	count := len(weights)
+	start := 0
+
+	switch pool.Strategy {
+	case database.PoolRoundRobin:
+		start = nextPoolIndex(poolID, count)
+	case database.PoolWeighted:
+		total := 0
+		for _, weight := range weights {
+			total += weight
+		}
+		pick := rng.Intn(total)
+		for i, weight := range weights {
+			if pick < weight {
+				start = i
+				break
+			}
+			pick -= weight
+		}
+	case database.PoolSticky:
+		if value := req.Header.Get(pool.StickyHeader); value != "" {
+			hash := fnv.New32a()
+			hash.Write([]byte(value))
+			start = int(hash.Sum32() % uint32(count))
+		} else {
+			// Clients without the header are spread evenly
+			start = nextPoolIndex(poolID, count)
+		}
+	}
+
+	order := make([]int, count)
+	for i := range order {
+		order[i] = (start + i) % count
+	}
+	return order
+}
+
+// nextPoolIndex advances the round-robin position of a pool
+func nextPoolIndex(poolID string, count int) int {
+	val, _ := poolCounters.LoadOrStore(poolID, new(uint64))
+	next := atomic.AddUint64(val.(*uint64), 1) - 1
+	return int(next % uint64(count))
+}
+
+// upstreamFailed reports whether a forwarded request failed: the target could not be
+// reached or answered with a server error
+func upstreamFailed(resp *http.Response, err error) bool {
+	if err != nil || resp == nil {
+		return true
+	}
+	return !IsUpstreamResponse(resp) || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// IsUpstreamResponse reports whether resp came from the target rather than being an
+// error generated locally by executeProxyRequest
+func IsUpstreamResponse(resp *http.Response) bool {
+	return resp != nil && resp.Request != nil
+}
+
+func responseStatus(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
+
+// recordProxyTarget reports the proxy target that served the request
+func recordProxyTarget(ctx context.Context, target *database.ProxyTarget) {
+	if meta := RequestMetaFromContext(ctx); meta != nil {
+		meta.ProxyTarget = target
+	}
+}
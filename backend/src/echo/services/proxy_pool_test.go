@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+func TestPoolOrder(t *testing.T) {
+	req := newTemplateTestRequest("GET", "http://localhost/", "", map[string]string{"X-User-Id": "user-42"})
+	noHeader := newTemplateTestRequest("GET", "http://localhost/", "", nil)
+
+	t.Run("round robin takes turns", func(t *testing.T) {
+		pool := &database.ProxyPoolConfig{Strategy: database.PoolRoundRobin}
+		poolID := uuid.New().String()
+		assert.Equal(t, []int{0, 1, 2}, poolOrder(poolID, pool, []int{1, 1, 1}, req, nil))
+		assert.Equal(t, []int{1, 2, 0}, poolOrder(poolID, pool, []int{1, 1, 1}, req, nil))
+		assert.Equal(t, []int{2, 0, 1}, poolOrder(poolID, pool, []int{1, 1, 1}, req, nil))
+		assert.Equal(t, []int{0, 1, 2}, poolOrder(poolID, pool, []int{1, 1, 1}, req, nil))
+	})
+
+	t.Run("weighted follows weights", func(t *testing.T) {
+		pool := &database.ProxyPoolConfig{Strategy: database.PoolWeighted}
+		rng := rand.New(rand.NewSource(1))
+		counts := make([]int, 2)
+		for i := 0; i < 1000; i++ {
+			counts[poolOrder("weighted", pool, []int{3, 1}, req, rng)[0]]++
+		}
+		assert.InDelta(t, 750, counts[0], 60)
+		assert.InDelta(t, 250, counts[1], 60)
+	})
+
+	t.Run("sticky pins a header value to a target", func(t *testing.T) {
+		pool := &database.ProxyPoolConfig{Strategy: database.PoolSticky, StickyHeader: "X-User-Id"}
+		first := poolOrder("sticky", pool, []int{1, 1, 1}, req, nil)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, first, poolOrder("sticky", pool, []int{1, 1, 1}, req, nil))
+		}
+		// Requests without the header are spread evenly
+		poolID := uuid.New().String()
+		assert.Equal(t, 0, poolOrder(poolID, pool, []int{1, 1}, noHeader, nil)[0])
+		assert.Equal(t, 1, poolOrder(poolID, pool, []int{1, 1}, noHeader, nil)[0])
+	})
+
+	t.Run("failover keeps declared order", func(t *testing.T) {
+		pool := &database.ProxyPoolConfig{Strategy: database.PoolFailover}
+		assert.Equal(t, []int{0, 1, 2}, poolOrder("failover", pool, []int{1, 1, 1}, req, nil))
+		assert.Equal(t, []int{0, 1, 2}, poolOrder("failover", pool, []int{1, 1, 1}, req, nil))
+	})
+}
+
+// setupPoolProject creates a forwarder project whose active proxy is a pool over upstreams
+func setupPoolProject(t *testing.T, pool string, upstreams ...string) (*MockService, *database.Project) {
+	db := database.GetDB()
+
+	project := &database.Project{
+		ID:    uuid.New().String(),
+		Name:  "Pool",
+		Alias: "pool-" + uuid.New().String()[:8],
+		Mode:  database.ModeForwarder,
+	}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	args := make([]any, len(upstreams))
+	for i, upstream := range upstreams {
+		target := &database.ProxyTarget{ProjectID: project.ID, Label: fmt.Sprintf("backend-%d", i+1), URL: upstream}
+		require.NoError(t, db.Create(target).Error)
+		args[i] = target.ID
+	}
+
+	poolTarget := &database.ProxyTarget{ProjectID: project.ID, Label: "backends", Pool: fmt.Sprintf(pool, args...)}
+	require.NoError(t, db.Create(poolTarget).Error)
+	require.NoError(t, db.Model(project).Update("active_proxy_id", poolTarget.ID).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	return service, project
+}
+
+func callPoolProject(t *testing.T, service *MockService, project *database.Project) (int, string, *RequestMeta) {
+	req, _ := http.NewRequest("GET", "http://localhost/"+project.Alias+"/status", nil)
+	meta := &RequestMeta{}
+	ctx := ContextWithRequestMeta(context.Background(), meta)
+	resp, err, _, _, _ := service.HandleRequest(ctx, project.Alias, "GET", "/"+project.Alias+"/status", req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), meta
+}
+
+func namedUpstream(name string, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(name))
+	}))
+}
+
+func TestForwardRequest_Pool(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	first := namedUpstream("first", http.StatusOK)
+	defer first.Close()
+	second := namedUpstream("second", http.StatusOK)
+	defer second.Close()
+
+	service, project := setupPoolProject(t, `{"strategy":"round_robin","members":[{"targetId":"%s"},{"targetId":"%s"}]}`, first.URL, second.URL)
+
+	_, body, meta := callPoolProject(t, service, project)
+	assert.Equal(t, "first", body)
+	require.NotNil(t, meta.ProxyTarget)
+	assert.Equal(t, "backend-1", meta.ProxyTarget.Label)
+	assert.Equal(t, first.URL, meta.ProxyTarget.URL)
+
+	_, body, meta = callPoolProject(t, service, project)
+	assert.Equal(t, "second", body)
+	assert.Equal(t, "backend-2", meta.ProxyTarget.Label)
+}
+
+func TestForwardRequest_Failover(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	failing := namedUpstream("failing", http.StatusServiceUnavailable)
+	defer failing.Close()
+	healthy := namedUpstream("healthy", http.StatusOK)
+	defer healthy.Close()
+
+	t.Run("connection errors and 5xx move to the next member", func(t *testing.T) {
+		service, project := setupPoolProject(t, `{"strategy":"failover","members":[{"targetId":"%s"},{"targetId":"%s"},{"targetId":"%s"}]}`, unreachable.URL, failing.URL, healthy.URL)
+
+		status, body, meta := callPoolProject(t, service, project)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "healthy", body)
+		assert.Equal(t, "backend-3", meta.ProxyTarget.Label)
+	})
+
+	t.Run("the last member answers when all fail", func(t *testing.T) {
+		service, project := setupPoolProject(t, `{"strategy":"failover","members":[{"targetId":"%s"},{"targetId":"%s"}]}`, unreachable.URL, failing.URL)
+
+		status, body, meta := callPoolProject(t, service, project)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "failing", body)
+		assert.Equal(t, "backend-2", meta.ProxyTarget.Label)
+	})
+
+	t.Run("without failover errors are returned as is", func(t *testing.T) {
+		service, project := setupPoolProject(t, `{"strategy":"round_robin","members":[{"targetId":"%s"},{"targetId":"%s"}]}`, failing.URL, healthy.URL)
+
+		status, _, _ := callPoolProject(t, service, project)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+	})
+}
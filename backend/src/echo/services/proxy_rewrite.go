@@ -0,0 +1,152 @@
+package services
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+)
+
+// proxyRewrite returns the rewrite rules for a request forwarded to target: those of the
+// endpoint when it has some, otherwise those of the target, falling back to the pool
+// the target was picked from
+func proxyRewrite(endpoint *database.MockEndpoint, targets ...*database.ProxyTarget) *database.ProxyRewriteConfig {
+	if endpoint != nil {
+		if config, err := database.ParseEndpointAdvanceConfig(endpoint.AdvanceConfig); err == nil && config.ProxyRewrite != nil {
+			return config.ProxyRewrite
+		}
+	}
+	for _, target := range targets {
+		if target == nil {
+			continue
+		}
+		rewrite, err := database.ParseProxyRewrite(target.Rewrite)
+		if err != nil {
+			log.Warn().Err(err).Str("target", target.Label).Msg("ignoring invalid proxy rewrite")
+			continue
+		}
+		if rewrite != nil {
+			return rewrite
+		}
+	}
+	return nil
+}
+
+// rewriteProxyPath applies the first path rule matching p
+func rewriteProxyPath(rules []database.PathRewrite, p string) string {
+	for _, rule := range rules {
+		if rule.Prefix != "" {
+			prefix := strings.TrimSuffix(rule.Prefix, "/")
+			if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+				continue
+			}
+			p = strings.TrimSuffix(rule.Replace, "/") + strings.TrimPrefix(p, prefix)
+		} else {
+			re, err := compileRuleRegex(rule.Regex, false)
+			if err != nil || !re.MatchString(p) {
+				continue
+			}
+			p = re.ReplaceAllString(p, rule.Replace)
+		}
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		return p
+	}
+	return p
+}
+
+// rewriteRequestHeaders removes, sets then adds the configured request headers
+func rewriteRequestHeaders(rules *database.HeaderRewrite, header http.Header) {
+	if rules == nil {
+		return
+	}
+	for _, name := range rules.Remove {
+		header.Del(name)
+	}
+	for name, value := range rules.Set {
+		header.Set(name, value)
+	}
+	for name, value := range rules.Add {
+		header.Add(name, value)
+	}
+}
+
+// rewriteProxyResponse rewrites the Location and Set-Cookie headers of an upstream response
+func rewriteProxyResponse(rewrite *database.ProxyRewriteConfig, resp *http.Response) {
+	if rewrite == nil || resp == nil || resp.Header == nil {
+		return
+	}
+
+	if location := resp.Header.Get("Location"); location != "" && len(rewrite.Location) > 0 {
+		resp.Header.Set("Location", rewriteLocation(rewrite.Location, location))
+	}
+
+	if cookies := resp.Header.Values("Set-Cookie"); len(cookies) > 0 && len(rewrite.CookieDomain) > 0 {
+		rewritten := make([]string, len(cookies))
+		for i, cookie := range cookies {
+			rewritten[i] = rewriteCookieDomain(rewrite.CookieDomain, cookie)
+		}
+		resp.Header["Set-Cookie"] = rewritten
+	}
+}
+
+// rewriteLocation replaces the longest configured URL prefix of location
+func rewriteLocation(rules map[string]string, location string) string {
+	prefixes := make([]string, 0, len(rules))
+	for prefix := range rules {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(location, strings.TrimSuffix(prefix, "/")) {
+			continue
+		}
+		rest := strings.TrimPrefix(location, strings.TrimSuffix(prefix, "/"))
+		if rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?") && !strings.HasPrefix(rest, "#") {
+			// Prefix only matched part of the host, e.g. api.example.com against api.example.co
+			continue
+		}
+		replacement := strings.TrimSuffix(rules[prefix], "/")
+		if replacement == "" && rest == "" {
+			return "/"
+		}
+		return replacement + rest
+	}
+	return location
+}
+
+var cookieDomainAttr = regexp.MustCompile(`(?i);\s*domain=([^;]*)`)
+
+// rewriteCookieDomain replaces the Domain attribute of a Set-Cookie header value
+func rewriteCookieDomain(rules map[string]string, cookie string) string {
+	match := cookieDomainAttr.FindStringSubmatchIndex(cookie)
+	if match == nil {
+		return cookie
+	}
+	domain := strings.TrimPrefix(strings.TrimSpace(cookie[match[2]:match[3]]), ".")
+
+	replacement, ok := "", false
+	for from, to := range rules {
+		if strings.EqualFold(strings.TrimPrefix(from, "."), domain) {
+			replacement, ok = to, true
+			break
+		}
+	}
+	if !ok {
+		replacement, ok = rules["*"]
+	}
+	if !ok {
+		return cookie
+	}
+
+	if replacement == "" {
+		return cookie[:match[0]] + cookie[match[1]:]
+	}
+	return cookie[:match[2]] + replacement + cookie[match[3]:]
+}
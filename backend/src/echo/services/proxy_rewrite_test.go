@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestRewriteProxyPath(t *testing.T) {
+	rules := []database.PathRewrite{
+		{Prefix: "/api/v1", Replace: "/v2"},
+		{Prefix: "/legacy/"},
+		{Regex: `^/users/(?P<id>\d+)/profile$`, Replace: "/profiles/${id}"},
+		{Regex: `^/shop`, Replace: "store"},
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/api/v1/users", expected: "/v2/users"},
+		{path: "/api/v1", expected: "/v2"},
+		{path: "/api/v10/users", expected: "/api/v10/users"},
+		{path: "/legacy/orders", expected: "/orders"},
+		{path: "/users/42/profile", expected: "/profiles/42"},
+		{path: "/users/42", expected: "/users/42"},
+		{path: "/shop/cart", expected: "/store/cart"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rewriteProxyPath(rules, tt.path))
+		})
+	}
+
+	// A root prefix moves every path under the replacement
+	assert.Equal(t, "/legacy/users", rewriteProxyPath([]database.PathRewrite{{Prefix: "/", Replace: "/legacy/"}}, "/users"))
+}
+
+func TestRewriteLocation(t *testing.T) {
+	rules := map[string]string{
+		"https://api.internal":        "",
+		"https://api.internal/admin/": "https://admin.example.com",
+	}
+
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{location: "https://api.internal/login?next=/", expected: "/login?next=/"},
+		{location: "https://api.internal", expected: "/"},
+		{location: "https://api.internal/admin/users", expected: "https://admin.example.com/users"},
+		{location: "https://api.internal.evil.com/login", expected: "https://api.internal.evil.com/login"},
+		{location: "/relative", expected: "/relative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rewriteLocation(rules, tt.location))
+		})
+	}
+}
+
+func TestRewriteCookieDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    map[string]string
+		cookie   string
+		expected string
+	}{
+		{name: "replaced", rules: map[string]string{"api.internal": "localhost"}, cookie: "sid=1; Domain=.api.internal; Path=/", expected: "sid=1; Domain=localhost; Path=/"},
+		{name: "removed", rules: map[string]string{"api.internal": ""}, cookie: "sid=1; Path=/; domain=api.internal; HttpOnly", expected: "sid=1; Path=/; HttpOnly"},
+		{name: "wildcard", rules: map[string]string{"*": "mock.local"}, cookie: "sid=1; Domain=other.com", expected: "sid=1; Domain=mock.local"},
+		{name: "other domain untouched", rules: map[string]string{"api.internal": ""}, cookie: "sid=1; Domain=other.com", expected: "sid=1; Domain=other.com"},
+		{name: "no domain", rules: map[string]string{"*": ""}, cookie: "sid=1; Path=/", expected: "sid=1; Path=/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rewriteCookieDomain(tt.rules, tt.cookie))
+		})
+	}
+}
+
+func TestExecuteProxyRequest_Rewrite(t *testing.T) {
+	type received struct {
+		path, host string
+		header     http.Header
+	}
+	requests := make(chan received, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- received{path: r.URL.Path, host: r.Host, header: r.Header.Clone()}
+		w.Header().Set("Location", "http://"+r.Host+"/next")
+		w.Header().Add("Set-Cookie", "sid=1; Domain=api.internal; Path=/")
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer upstream.Close()
+
+	rewrite, err := database.ParseProxyRewrite(`{
+		"path": [{"prefix": "/api", "replace": "/v2"}],
+		"preserveHost": true,
+		"requestHeaders": {"set": {"X-Env": "staging"}, "add": {"X-Trace": "beo"}, "remove": ["Cookie"]},
+		"location": {"http://mock.example.com": ""},
+		"cookieDomain": {"api.internal": ""}
+	}`)
+	require.NoError(t, err)
+
+	req := newTemplateTestRequest("GET", "http://mock.example.com/api/users", "", map[string]string{
+		"Cookie":  "session=secret",
+		"X-Env":   "production",
+		"X-Trace": "client",
+	})
+	target := &database.ProxyTarget{ID: uuid.New().String(), URL: upstream.URL + "/base"}
+
+	resp, err := executeProxyRequest(context.Background(), target, rewrite, "GET", "/api/users", "", req)
+	require.NoError(t, err)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	got := <-requests
+	assert.Equal(t, "/base/v2/users", got.path)
+	assert.Equal(t, "mock.example.com", got.host)
+	assert.Empty(t, got.header.Get("Cookie"))
+	assert.Equal(t, "staging", got.header.Get("X-Env"))
+	assert.Equal(t, []string{"client", "beo"}, got.header.Values("X-Trace"))
+
+	assert.Equal(t, "/next", resp.Header.Get("Location"))
+	assert.Equal(t, "sid=1; Path=/", resp.Header.Get("Set-Cookie"))
+}
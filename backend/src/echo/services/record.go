@@ -0,0 +1,497 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/andybalholm/brotli"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// maxRecordedBodyBytes is the largest upstream body turned into a mock response
+const maxRecordedBodyBytes = 1 << 20
+
+// recordedNote is the note of responses created by record mode
+const recordedNote = "Recorded from forwarded traffic"
+
+// recordSkippedHeaders are upstream headers not stored on recorded responses, the mock sets its own
+var recordSkippedHeaders = map[string]bool{
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Date":              true,
+}
+
+var (
+	// uuidSegment and hexSegment recognise path segments holding identifiers
+	uuidSegment = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexSegment  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+	// tokenSegment matches ids such as "pay_8Xk2mQ91" that mix letters and digits
+	tokenSegment = regexp.MustCompile(`^[A-Za-z0-9_-]{8,}$`)
+	// recordFieldName limits generated rules to keys that are plain JSONPath names
+	recordFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// recordMu serialises recordings so concurrent requests do not create the same endpoint twice
+var recordMu sync.Mutex
+
+// recordedResponse is an upstream response reduced to what a mock response stores
+type recordedResponse struct {
+	status   int
+	headers  string
+	body     string
+	bodyType string
+}
+
+// recordExchange stores a forwarded request and its upstream response as a mock endpoint and
+// response when the project records traffic. The body is recorded as it streams to the client,
+// once it has been read to the end, so the response is returned without being held back.
+//
+// Requests are grouped by method and path template ("/users/42" records into "/users/:id"),
+// and go into the endpoint that already matches them when there is one. Responses edited by
+// hand since they were recorded are never overwritten.
+func (s *MockService) recordExchange(project *database.Project, routes *repositories.RouteTable, method, path string, req *http.Request, resp *http.Response) *http.Response {
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil || config.Record == nil || !config.Record.Enabled {
+		return resp
+	}
+	// Only responses that came from upstream are recorded, not the errors Beo Echo reports itself
+	// WebSocket tunnels have no response body to record
+	if !IsUpstreamResponse(resp) || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+		return resp
+	}
+
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		limit:      maxRecordedBodyBytes,
+		onComplete: func(body []byte, complete bool) {
+			if !complete {
+				log.Warn().Str("project_id", project.ID).Str("path", path).Msg("Upstream response not recorded: body too large")
+				return
+			}
+
+			recorded, err := newRecordedResponse(resp, body)
+			if err != nil {
+				log.Warn().Err(err).Str("project_id", project.ID).Str("path", path).Msg("Upstream response not recorded")
+				return
+			}
+
+			recordMu.Lock()
+			defer recordMu.Unlock()
+
+			if err := s.saveRecording(project, routes, config.Record, method, path, req, recorded); err != nil {
+				log.Error().Err(err).Str("project_id", project.ID).Str("path", path).Msg("Failed to record upstream response")
+			}
+		},
+	}
+	return resp
+}
+
+// recordingBody passes an upstream body through while keeping a copy of up to limit bytes.
+// onComplete runs once the body has been read to the end; complete is false when it was larger
+// than limit. Bodies the client stops reading early are not recorded.
+type recordingBody struct {
+	io.ReadCloser
+	limit      int
+	buf        bytes.Buffer
+	overflow   bool
+	done       bool
+	onComplete func(body []byte, complete bool)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.overflow {
+		if b.buf.Len()+n > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.done {
+		b.done = true
+		b.onComplete(b.buf.Bytes(), !b.overflow)
+	}
+	return n, err
+}
+
+// newRecordedResponse decodes an upstream response into the fields of a mock response
+// Compressed bodies are stored decoded and binary bodies as base64.
+func newRecordedResponse(resp *http.Response, body []byte) (*recordedResponse, error) {
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case "":
+	case "gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		if body, err = io.ReadAll(gz); err != nil {
+			return nil, err
+		}
+	case "br":
+		decoded, err := io.ReadAll(brotli.NewReader(bytes.NewReader(body)))
+		if err != nil {
+			return nil, err
+		}
+		body = decoded
+	default:
+		return nil, errors.New("unsupported content encoding: " + resp.Header.Get("Content-Encoding"))
+	}
+
+	headers := map[string]string{}
+	for key, values := range resp.Header {
+		if recordSkippedHeaders[key] || strings.HasPrefix(strings.ToLower(key), "beo-echo") {
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := &recordedResponse{status: resp.StatusCode, headers: string(headersJSON), bodyType: BodyTypeText, body: string(body)}
+	if !utf8.Valid(body) || bytes.IndexByte(body, 0) != -1 {
+		recorded.bodyType = BodyTypeBase64
+		recorded.body = base64.StdEncoding.EncodeToString(body)
+	}
+	return recorded, nil
+}
+
+// saveRecording finds or creates the endpoint of a request and records the response variant
+func (s *MockService) saveRecording(project *database.Project, routes *repositories.RouteTable, config *database.RecordConfig, method, path string, req *http.Request, recorded *recordedResponse) error {
+	fields := map[string]string{}
+	if config.GenerateRules {
+		fields = recordedFields(req, config.IgnoreFields)
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	err = s.Repo.DB.Transaction(func(tx *gorm.DB) error {
+		endpoint, err := findOrCreateRecordEndpoint(tx, project, routes, method, path)
+		if err != nil || endpoint == nil {
+			return err
+		}
+
+		var responses []database.MockResponse
+		if err := tx.Preload("Rules").Where("endpoint_id = ?", endpoint.ID).Order("created_at").Find(&responses).Error; err != nil {
+			return err
+		}
+
+		var variant *database.MockResponse
+		hasRecorded := false
+		for i := range responses {
+			if responses[i].RecordedHash == "" {
+				continue
+			}
+			hasRecorded = true
+			if responses[i].RecordedRequest == string(fieldsJSON) {
+				variant = &responses[i]
+			}
+		}
+
+		if variant != nil {
+			if isHandEdited(variant) {
+				return nil
+			}
+			variant.StatusCode = recorded.status
+			variant.Headers = recorded.headers
+			variant.Body = recorded.body
+			variant.BodyType = recorded.bodyType
+			variant.RecordedHash = recordedHash(variant)
+			if err := tx.Omit("Rules").Save(variant).Error; err != nil {
+				return err
+			}
+			changed = true
+			return nil
+		}
+
+		// The first recorded variant also answers requests no other variant matches
+		response := database.MockResponse{
+			EndpointID:      endpoint.ID,
+			StatusCode:      recorded.status,
+			Headers:         recorded.headers,
+			Body:            recorded.body,
+			BodyType:        recorded.bodyType,
+			Enabled:         true,
+			IsFallback:      !hasRecorded,
+			RulesLogic:      "and",
+			Note:            recordedNote,
+			RecordedRequest: string(fieldsJSON),
+		}
+		response.RecordedHash = recordedHash(&response)
+		if err := tx.Create(&response).Error; err != nil {
+			return err
+		}
+		changed = true
+
+		if config.GenerateRules {
+			return regenerateRecordedRules(tx, append(responses, response))
+		}
+		return nil
+	})
+	if changed {
+		InvalidateProjectRoutes(project.ID)
+	}
+	return err
+}
+
+// findOrCreateRecordEndpoint returns the endpoint a request records into
+// An endpoint that already routes the request wins, then one with the same method and path
+// template; otherwise a new endpoint is created. Collection and proxied endpoints are not
+// recorded into, nil is returned for them.
+func findOrCreateRecordEndpoint(tx *gorm.DB, project *database.Project, routes *repositories.RouteTable, method, path string) (*database.MockEndpoint, error) {
+	var endpoint *database.MockEndpoint
+	if routes != nil {
+		if match, err := routes.Match(method, path); err == nil {
+			endpoint = match.MockEndpoint
+		}
+	}
+
+	if endpoint == nil {
+		template := pathTemplate(path)
+		var existing database.MockEndpoint
+		err := tx.Where("project_id = ? AND method = ? AND path = ?", project.ID, strings.ToUpper(method), template).First(&existing).Error
+		switch {
+		case err == nil:
+			endpoint = &existing
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			endpoint = &database.MockEndpoint{
+				ProjectID:    project.ID,
+				Method:       strings.ToUpper(method),
+				Path:         template,
+				Enabled:      true,
+				ResponseMode: "static",
+				Type:         database.EndpointTypeHTTP,
+			}
+			if err := tx.Create(endpoint).Error; err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+	}
+
+	if endpoint.ResponseMode == ResponseModeCRUD || endpoint.UseProxy {
+		return nil, nil
+	}
+	return endpoint, nil
+}
+
+// pathTemplate replaces the identifier segments of a path with parameters
+// "/users/42/orders/7f3c9a12-..." becomes "/users/:id/orders/:id2".
+func pathTemplate(path string) string {
+	parts := strings.Split(path, "/")
+	params := 0
+	for i, part := range parts {
+		if !isIdentifierSegment(part) {
+			continue
+		}
+		params++
+		parts[i] = ":id"
+		if params > 1 {
+			parts[i] += strconv.Itoa(params)
+		}
+	}
+	template := strings.Join(parts, "/")
+	if !strings.HasPrefix(template, "/") {
+		template = "/" + template
+	}
+	return template
+}
+
+// isIdentifierSegment reports whether a path segment looks like an id rather than a resource name
+func isIdentifierSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+		return true
+	}
+	if uuidSegment.MatchString(segment) || hexSegment.MatchString(segment) {
+		return true
+	}
+	return tokenSegment.MatchString(segment) &&
+		strings.ContainsAny(segment, "0123456789") &&
+		strings.ContainsAny(strings.ToLower(segment), "abcdefghijklmnopqrstuvwxyz")
+}
+
+// recordedFields returns the query parameters and top level scalar body fields of a request,
+// keyed "query.<name>" and "body.<name>", without the ignored ones
+func recordedFields(req *http.Request, ignore []string) map[string]string {
+	fields := map[string]string{}
+	request := requestctx.From(req)
+
+	for key, values := range request.Query() {
+		if len(values) > 0 {
+			fields["query."+key] = values[0]
+		}
+	}
+
+	switch request.Format() {
+	case requestctx.FormatForm:
+		for key, values := range request.Form() {
+			if recordFieldName.MatchString(key) {
+				fields["body."+key] = values[0]
+			}
+		}
+	default:
+		parsed, _ := request.JSON()
+		object, _ := parsed.(map[string]interface{})
+		for key, value := range object {
+			if !recordFieldName.MatchString(key) {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				fields["body."+key] = v
+			case float64:
+				fields["body."+key] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				fields["body."+key] = strconv.FormatBool(v)
+			}
+		}
+	}
+
+	for _, field := range ignore {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// regenerateRecordedRules gives every recorded variant of an endpoint equals rules on the fields
+// that tell the variants apart. Fields with the same value in every variant get no rule, and
+// variants edited by hand keep their rules. Variants with more rules get a higher priority, so
+// the most specific one wins.
+func regenerateRecordedRules(tx *gorm.DB, responses []database.MockResponse) error {
+	variants := map[string]map[string]string{}
+	values := map[string]map[string]bool{}
+	recorded := 0
+	for _, response := range responses {
+		if response.RecordedHash == "" {
+			continue
+		}
+		recorded++
+		fields := map[string]string{}
+		json.Unmarshal([]byte(response.RecordedRequest), &fields)
+		variants[response.ID] = fields
+		for key, value := range fields {
+			if values[key] == nil {
+				values[key] = map[string]bool{}
+			}
+			values[key][value] = true
+		}
+	}
+
+	// A field distinguishes variants when they disagree on its value or some lack it
+	distinguishing := map[string]bool{}
+	for key, seen := range values {
+		present := 0
+		for _, fields := range variants {
+			if _, ok := fields[key]; ok {
+				present++
+			}
+		}
+		distinguishing[key] = len(seen) > 1 || present < recorded
+	}
+
+	for i := range responses {
+		response := &responses[i]
+		if response.RecordedHash == "" || isHandEdited(response) {
+			continue
+		}
+
+		var rules []database.MockRule
+		for key, value := range variants[response.ID] {
+			if !distinguishing[key] {
+				continue
+			}
+			ruleType, ruleKey, _ := strings.Cut(key, ".")
+			rules = append(rules, database.MockRule{ResponseID: response.ID, Type: ruleType, Key: ruleKey, Operator: OperatorEquals, Value: value})
+		}
+		sortRules(rules)
+
+		if err := tx.Where("response_id = ?", response.ID).Delete(&database.MockRule{}).Error; err != nil {
+			return err
+		}
+		for j := range rules {
+			if err := tx.Create(&rules[j]).Error; err != nil {
+				return err
+			}
+		}
+
+		response.Rules = rules
+		response.Priority = len(rules)
+		response.RecordedHash = recordedHash(response)
+		if err := tx.Model(&database.MockResponse{}).Where("id = ?", response.ID).
+			Updates(map[string]interface{}{"priority": response.Priority, "recorded_hash": response.RecordedHash}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isHandEdited reports whether a recorded response changed since it was recorded
+func isHandEdited(response *database.MockResponse) bool {
+	return recordedHash(response) != response.RecordedHash
+}
+
+// recordedHash digests the parts of a response that record mode writes
+func recordedHash(response *database.MockResponse) string {
+	rules := append([]database.MockRule(nil), response.Rules...)
+	sortRules(rules)
+
+	type ruleDigest struct{ Type, Key, Operator, Value string }
+	digest := struct {
+		Status   int
+		Headers  string
+		Body     string
+		BodyType string
+		Rules    []ruleDigest
+	}{response.StatusCode, response.Headers, response.Body, response.BodyType, nil}
+	for _, rule := range rules {
+		digest.Rules = append(digest.Rules, ruleDigest{rule.Type, rule.Key, rule.Operator, rule.Value})
+	}
+
+	encoded, _ := json.Marshal(digest)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// sortRules orders rules by type, key and value so their order does not change digests
+func sortRules(rules []database.MockRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Type != rules[j].Type {
+			return rules[i].Type < rules[j].Type
+		}
+		if rules[i].Key != rules[j].Key {
+			return rules[i].Key < rules[j].Key
+		}
+		return rules[i].Value < rules[j].Value
+	})
+}
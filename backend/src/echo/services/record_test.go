@@ -0,0 +1,210 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+func TestPathTemplate(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/", expected: "/"},
+		{path: "/users", expected: "/users"},
+		{path: "/users/42", expected: "/users/:id"},
+		{path: "/users/42/orders/7f3c9a12-5b1e-4c0a-9d2b-0e8f6a4b3c21", expected: "/users/:id/orders/:id2"},
+		{path: "/commits/9fceb02d0ae598e95dc970b74767f19372d61af8", expected: "/commits/:id"},
+		{path: "/payments/pay_8Xk2mQ91", expected: "/payments/:id"},
+		{path: "/api/v1/settings", expected: "/api/v1/settings"},
+		{path: "/reports/quarterly", expected: "/reports/quarterly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pathTemplate(tt.path))
+		})
+	}
+}
+
+// setupRecordingProject creates a project forwarding to upstream with record mode configured
+func setupRecordingProject(t *testing.T, upstream string, record string) (*MockService, *database.Project) {
+	db := database.GetDB()
+
+	project := &database.Project{
+		ID:            uuid.New().String(),
+		Name:          "Recording",
+		Alias:         "record-" + uuid.New().String()[:8],
+		Mode:          database.ModeForwarder,
+		AdvanceConfig: `{"record":` + record + `}`,
+	}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	target := &database.ProxyTarget{ProjectID: project.ID, Label: "Staging", URL: upstream}
+	require.NoError(t, db.Create(target).Error)
+	require.NoError(t, db.Model(project).Update("active_proxy_id", target.ID).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	return service, project
+}
+
+func callRecordingProject(t *testing.T, service *MockService, project *database.Project, target string) (int, string) {
+	req, _ := http.NewRequest("GET", "http://localhost/"+project.Alias+target, nil)
+	path, _, _ := strings.Cut(target, "?")
+	resp, err, _, _, _ := service.HandleRequest(context.Background(), project.Alias, "GET", "/"+project.Alias+path, req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func recordedEndpoints(t *testing.T, projectID string) []database.MockEndpoint {
+	var endpoints []database.MockEndpoint
+	require.NoError(t, database.GetDB().Preload("Responses", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Responses.Rules").Where("project_id = ?", projectID).Find(&endpoints).Error)
+	return endpoints
+}
+
+func TestRecordExchange(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		fmt.Fprintf(gz, `{"path":%q}`, r.URL.Path)
+		gz.Close()
+	}))
+	defer upstream.Close()
+
+	service, project := setupRecordingProject(t, upstream.URL, `{"enabled":true}`)
+
+	// Requests are forwarded untouched while they are recorded
+	status, body := callRecordingProject(t, service, project, "/users/1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, body)
+	callRecordingProject(t, service, project, "/users/2")
+
+	endpoints := recordedEndpoints(t, project.ID)
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "/users/:id", endpoints[0].Path)
+	assert.Equal(t, "GET", endpoints[0].Method)
+	require.Len(t, endpoints[0].Responses, 1)
+
+	response := endpoints[0].Responses[0]
+	assert.Equal(t, `{"path":"/users/2"}`, response.Body)
+	assert.JSONEq(t, `{"Content-Type":"application/json"}`, response.Headers)
+	assert.True(t, response.IsFallback)
+	assert.NotEmpty(t, response.RecordedHash)
+
+	// A response edited by hand is never overwritten
+	require.NoError(t, database.GetDB().Model(&response).Update("body", `{"edited":true}`).Error)
+	callRecordingProject(t, service, project, "/users/3")
+
+	endpoints = recordedEndpoints(t, project.ID)
+	require.Len(t, endpoints[0].Responses, 1)
+	assert.Equal(t, `{"edited":true}`, endpoints[0].Responses[0].Body)
+}
+
+func TestRecordExchange_GenerateRules(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "results for %s", r.URL.Query().Get("q"))
+	}))
+	defer upstream.Close()
+
+	service, project := setupRecordingProject(t, upstream.URL, `{"enabled":true,"generateRules":true,"ignoreFields":["query.ts"]}`)
+
+	callRecordingProject(t, service, project, "/search?q=shoes&ts=1")
+	callRecordingProject(t, service, project, "/search?q=socks&ts=2")
+	callRecordingProject(t, service, project, "/search?q=socks&ts=3")
+
+	endpoints := recordedEndpoints(t, project.ID)
+	require.Len(t, endpoints, 1)
+	responses := endpoints[0].Responses
+	require.Len(t, responses, 2)
+
+	for i, q := range []string{"shoes", "socks"} {
+		assert.Equal(t, "results for "+q, responses[i].Body)
+		require.Len(t, responses[i].Rules, 1)
+		assert.Equal(t, database.MockRule{ID: responses[i].Rules[0].ID, ResponseID: responses[i].ID, Type: "query", Key: "q", Operator: OperatorEquals, Value: q}, responses[i].Rules[0])
+	}
+	assert.True(t, responses[0].IsFallback)
+	assert.False(t, responses[1].IsFallback)
+
+	// Once switched to mock mode the recording answers on its own
+	require.NoError(t, database.GetDB().Model(project).Update("mode", database.ModeMock).Error)
+	InvalidateProjectRoutes(project.ID)
+	upstream.Close()
+
+	_, body := callRecordingProject(t, service, project, "/search?q=socks")
+	assert.Equal(t, "results for socks", body)
+	_, body = callRecordingProject(t, service, project, "/search?q=boots")
+	assert.Equal(t, "results for shoes", body)
+}
+
+func TestRecordExchange_SkipsLocalErrors(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstream.Close()
+
+	service, project := setupRecordingProject(t, upstream.URL, `{"enabled":true}`)
+
+	status, _ := callRecordingProject(t, service, project, "/users/1")
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Empty(t, recordedEndpoints(t, project.ID))
+}
+
+func TestRecordExchange_StreamsBeforeRecording(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer upstream.Close()
+
+	service, project := setupRecordingProject(t, upstream.URL, `{"enabled":true}`)
+
+	req, _ := http.NewRequest("GET", "http://localhost/"+project.Alias+"/events", nil)
+	resp, err, _, _, _ := service.HandleRequest(context.Background(), project.Alias, "GET", "/"+project.Alias+"/events", req)
+	require.NoError(t, err)
+
+	// The first event is readable while the upstream is still sending
+	first := make([]byte, len("data: first\n\n"))
+	_, err = io.ReadFull(resp.Body, first)
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n\n", string(first))
+	assert.Empty(t, recordedEndpoints(t, project.ID))
+
+	close(release)
+	rest, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "data: second\n\n", string(rest))
+
+	endpoints := recordedEndpoints(t, project.ID)
+	require.Len(t, endpoints, 1)
+	require.Len(t, endpoints[0].Responses, 1)
+	assert.Equal(t, "data: first\n\ndata: second\n\n", endpoints[0].Responses[0].Body)
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// RequestMeta collects details about how a mock request was handled
+// The mock handler attaches it to the request context before calling HandleRequest,
+// the service fills it in, and the request logger stores it on the RequestLog.
+type RequestMeta struct {
+	PathParams     map[string]string    // Values captured from the matched endpoint path
+	ScenarioStates []ScenarioTransition // Scenario states seen while selecting the response
+	Fault          *database.FaultRule  // Fault injected into the response, if any
+
+	GraphQLOperation string // Operation of a request to a graphql endpoint, e.g. "query GetUser"
+
+	ProxyTarget *database.ProxyTarget // Proxy target that served the request; the member for pools
+
+	WebSocket *WebSocketSession // Session the mock handler serves once it upgrades the connection
+
+	GRPC *GRPCCall // Call to a grpc endpoint, with its messages decoded to JSON
+
+	Auth *AuthDecision // Outcome of the authentication the project or endpoint requires
+
+	endpoint *database.MockEndpoint // Endpoint the request matched, if any
+}
+
+type requestMetaKey struct{}
+
+// ContextWithRequestMeta returns a context carrying meta
+func ContextWithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext returns the request meta attached to ctx, or nil when absent
+func RequestMetaFromContext(ctx context.Context) *RequestMeta {
+	if ctx == nil {
+		return nil
+	}
+	meta, _ := ctx.Value(requestMetaKey{}).(*RequestMeta)
+	return meta
+}
+
+// pathParamsFromRequest returns the path params captured for the request, if any
+func pathParamsFromRequest(req *http.Request) map[string]string {
+	if req == nil {
+		return nil
+	}
+	if meta := RequestMetaFromContext(req.Context()); meta != nil {
+		return meta.PathParams
+	}
+	if request := requestctx.FromContext(req.Context()); request != nil {
+		return request.PathParams
+	}
+	return nil
+}
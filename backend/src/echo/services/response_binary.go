@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/lib"
+)
+
+// Body types of a mock response
+const (
+	BodyTypeText   = "text"   // Body is served as is (default)
+	BodyTypeBase64 = "base64" // Body holds base64 encoded bytes
+	BodyTypeFile   = "file"   // Body is the uploaded file referenced by FileID
+)
+
+// IsBinaryResponse reports whether a response serves raw bytes instead of its text body
+func IsBinaryResponse(response *database.MockResponse) bool {
+	return response.BodyType == BodyTypeBase64 || response.BodyType == BodyTypeFile
+}
+
+// ValidateResponseBody checks the body type of a response and the body it carries
+// File references are checked against the project by the caller.
+func ValidateResponseBody(response *database.MockResponse) error {
+	switch response.BodyType {
+	case "", BodyTypeText:
+		return nil
+	case BodyTypeBase64:
+		if _, err := decodeBase64Body(response.Body); err != nil {
+			return fmt.Errorf("body is not valid base64: %w", err)
+		}
+	case BodyTypeFile:
+		if response.FileID == "" {
+			return errors.New("file_id is required when body_type is file")
+		}
+	default:
+		return fmt.Errorf("unknown body_type: %s", response.BodyType)
+	}
+
+	if response.Stream {
+		return errors.New("binary responses cannot be streamed")
+	}
+	return nil
+}
+
+// decodeBase64Body decodes a base64 body, ignoring line breaks and spaces
+func decodeBase64Body(body string) ([]byte, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, body)
+	return base64.StdEncoding.DecodeString(cleaned)
+}
+
+// MockFilePath returns where the content of an uploaded file is stored
+func MockFilePath(fileID string) string {
+	return filepath.Join(lib.UPLOAD_DIR, "mock-files", fileID)
+}
+
+// StoreMockFile writes content to disk for file, filling in its size, digest and,
+// when missing, its content type
+func StoreMockFile(file *database.MockFile, content io.Reader) error {
+	path := MockFilePath(file.ID)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+
+	// Keep the first bytes for content sniffing while hashing and copying the rest
+	hash := sha256.New()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+	head = head[:n]
+
+	size, err := io.Copy(io.MultiWriter(out, hash), io.MultiReader(bytes.NewReader(head), content))
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	file.Size = size
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if file.ContentType == "" || file.ContentType == "application/octet-stream" {
+		file.ContentType = detectContentType(file.Name, head)
+	}
+	return nil
+}
+
+// RemoveMockFile deletes the stored content of a file
+func RemoveMockFile(fileID string) error {
+	if err := os.Remove(MockFilePath(fileID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// detectContentType guesses a content type from the file extension, then the content
+func detectContentType(name string, head []byte) string {
+	if byExt := mime.TypeByExtension(filepath.Ext(name)); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(head)
+}
+
+// buildResponse creates the HTTP response for a selected mock response
+func (s *MockService) buildResponse(mockResp database.MockResponse, path string, req *http.Request) (*http.Response, error) {
+	if mockResp.BodyType != BodyTypeFile {
+		return createTemplatedMockResponse(mockResp, path, req)
+	}
+
+	file, err := s.Repo.FindFileByID(mockResp.FileID)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, "Response file not found"), nil
+	}
+	return createFileResponse(mockResp, file, path, req)
+}
+
+// createFileResponse serves an uploaded file as the response body
+func createFileResponse(mockResp database.MockResponse, file *database.MockFile, path string, req *http.Request) (*http.Response, error) {
+	mockResp, err := renderBinaryHeaders(mockResp, path, req)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, err.Error()), nil
+	}
+
+	content, err := os.Open(MockFilePath(file.ID))
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, "Failed to open response file: "+err.Error()), nil
+	}
+	return createBinaryResponse(mockResp, content, file.Size, file.ContentType, req), nil
+}
+
+// createBase64Response serves the decoded bytes of a base64 body
+func createBase64Response(mockResp database.MockResponse, path string, req *http.Request) (*http.Response, error) {
+	mockResp, err := renderBinaryHeaders(mockResp, path, req)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, err.Error()), nil
+	}
+
+	data, err := decodeBase64Body(mockResp.Body)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, "Failed to decode base64 body: "+err.Error()), nil
+	}
+	return createBinaryResponse(mockResp, nopSeekCloser{bytes.NewReader(data)}, int64(len(data)), "", req), nil
+}
+
+// renderBinaryHeaders renders the header templates of a binary response
+// The body is never rendered since it holds raw or encoded bytes.
+func renderBinaryHeaders(mockResp database.MockResponse, path string, req *http.Request) (database.MockResponse, error) {
+	if !mockResp.Templated || !isTemplate(mockResp.Headers) {
+		return mockResp, nil
+	}
+
+	body := mockResp.Body
+	mockResp.Body = ""
+	rendered, err := renderMockResponse(mockResp, NewTemplateContext(req, path, pathParamsFromRequest(req)))
+	if err != nil {
+		return mockResp, err
+	}
+	rendered.Body = body
+	return rendered, nil
+}
+
+// createBinaryResponse builds a response serving content, honouring single byte Range requests
+// The content type comes from the response headers, then defaultType, then content sniffing.
+func createBinaryResponse(mockResp database.MockResponse, content io.ReadSeekCloser, size int64, defaultType string, req *http.Request) *http.Response {
+	resp := &http.Response{
+		StatusCode: mockResp.StatusCode,
+		Header:     make(http.Header),
+	}
+	if headers, err := repositories.ParseHeaders(mockResp.Headers); err == nil {
+		for key, value := range headers {
+			resp.Header.Set(key, value)
+		}
+	}
+
+	if resp.Header.Get("Content-Type") == "" {
+		contentType := defaultType
+		if contentType == "" {
+			head := make([]byte, 512)
+			n, _ := io.ReadFull(content, head)
+			content.Seek(0, io.SeekStart)
+			contentType = http.DetectContentType(head[:n])
+		}
+		resp.Header.Set("Content-Type", contentType)
+	}
+	resp.Header.Set("Accept-Ranges", "bytes")
+
+	start, length := int64(0), size
+	if rangeHeader := requestRange(req); rangeHeader != "" && resp.StatusCode == http.StatusOK {
+		rangeStart, rangeLength, ok := parseByteRange(rangeHeader, size)
+		switch {
+		case !ok:
+			content.Close()
+			resp.StatusCode = http.StatusRequestedRangeNotSatisfiable
+			resp.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			resp.Header.Set("Content-Length", "0")
+			resp.Body = http.NoBody
+			return resp
+		case rangeLength >= 0:
+			start, length = rangeStart, rangeLength
+			resp.StatusCode = http.StatusPartialContent
+			resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+		}
+	}
+
+	resp.Header.Set("Content-Length", strconv.FormatInt(length, 10))
+	resp.ContentLength = length
+	resp.Body = &sectionReadCloser{Reader: io.NewSectionReader(readerAtSeeker{content}, start, length), closer: content}
+	return resp
+}
+
+// requestRange returns the Range header of a GET request
+func requestRange(req *http.Request) string {
+	if req == nil || req.Method != http.MethodGet {
+		return ""
+	}
+	return req.Header.Get("Range")
+}
+
+// parseByteRange parses a single "bytes=" range against a body of size bytes
+// It returns ok=false when the range cannot be satisfied, and a negative length when the
+// header should be ignored (another unit or several ranges) so the full body is served.
+func parseByteRange(header string, size int64) (start, length int64, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, -1, true
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		// Suffix range: the last N bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true
+}
+
+// readerAtSeeker adapts a ReadSeeker to io.ReaderAt for sequential section reads
+type readerAtSeeker struct {
+	io.ReadSeeker
+}
+
+func (r readerAtSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if ra, ok := r.ReadSeeker.(io.ReaderAt); ok {
+		return ra.ReadAt(p, off)
+	}
+	if _, err := r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(r.ReadSeeker, p)
+}
+
+// nopSeekCloser adds a no-op Close to an in-memory reader
+type nopSeekCloser struct {
+	*bytes.Reader
+}
+
+func (nopSeekCloser) Close() error { return nil }
+
+// sectionReadCloser reads a section of the content and closes the underlying source
+type sectionReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (s *sectionReadCloser) Close() error {
+	return s.closer.Close()
+}
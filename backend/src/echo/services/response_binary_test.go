@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/lib"
+)
+
+// pngHeader is the start of a PNG image, enough for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantStart  int64
+		wantLength int64
+		wantOK     bool
+	}{
+		{"Explicit range", "bytes=0-9", 0, 10, true},
+		{"Open ended range", "bytes=90-", 90, 10, true},
+		{"Suffix range", "bytes=-5", 95, 5, true},
+		{"End past size is clamped", "bytes=50-500", 50, 50, true},
+		{"Suffix longer than body", "bytes=-500", 0, 100, true},
+		{"Start past size", "bytes=100-", 0, 0, false},
+		{"End before start", "bytes=10-5", 0, 0, false},
+		{"Not a number", "bytes=a-b", 0, 0, false},
+		{"Multiple ranges are ignored", "bytes=0-1,5-6", 0, -1, true},
+		{"Other units are ignored", "items=0-1", 0, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, length, ok := parseByteRange(tt.header, 100)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantStart, start)
+				assert.Equal(t, tt.wantLength, length)
+			}
+		})
+	}
+}
+
+func TestCreateBase64Response(t *testing.T) {
+	data := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0xff}, 16)...)
+	encoded := base64.StdEncoding.EncodeToString(data)
+	// Line breaks are allowed, as produced by base64 tools
+	mockResp := database.MockResponse{
+		StatusCode: 200,
+		BodyType:   BodyTypeBase64,
+		Body:       encoded[:10] + "\n" + encoded[10:],
+		Headers:    `{"X-Request":"{{.request.method}}"}`,
+		Templated:  true,
+	}
+
+	t.Run("Full body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/avatar", nil)
+		resp, err := createTemplatedMockResponse(mockResp, "/avatar", req)
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, data, body)
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "32", resp.Header.Get("Content-Length"))
+		assert.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+		assert.Equal(t, "GET", resp.Header.Get("X-Request"))
+	})
+
+	t.Run("Partial content", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/avatar", nil)
+		req.Header.Set("Range", "bytes=4-7")
+		resp, err := createTemplatedMockResponse(mockResp, "/avatar", req)
+		require.NoError(t, err)
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, data[4:8], body)
+		assert.Equal(t, "bytes 4-7/32", resp.Header.Get("Content-Range"))
+		assert.Equal(t, "4", resp.Header.Get("Content-Length"))
+	})
+
+	t.Run("Unsatisfiable range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/avatar", nil)
+		req.Header.Set("Range", "bytes=100-")
+		resp, err := createTemplatedMockResponse(mockResp, "/avatar", req)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+		assert.Equal(t, "bytes */32", resp.Header.Get("Content-Range"))
+	})
+
+	t.Run("Content-Type header wins over detection", func(t *testing.T) {
+		withType := mockResp
+		withType.Headers = `{"Content-Type":"application/x-protobuf"}`
+		resp, err := createTemplatedMockResponse(withType, "/avatar", httptest.NewRequest(http.MethodGet, "/avatar", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-protobuf", resp.Header.Get("Content-Type"))
+	})
+}
+
+func TestFileResponse(t *testing.T) {
+	originalDir := lib.UPLOAD_DIR
+	lib.UPLOAD_DIR = t.TempDir()
+	defer func() { lib.UPLOAD_DIR = originalDir }()
+
+	content := strings.Repeat("%PDF-1.4 sample ", 64)
+	file := &database.MockFile{ID: "file-1", Name: "report.pdf"}
+	require.NoError(t, StoreMockFile(file, strings.NewReader(content)))
+
+	assert.Equal(t, int64(len(content)), file.Size)
+	assert.Equal(t, "application/pdf", file.ContentType)
+	assert.Len(t, file.SHA256, 64)
+
+	mockResp := database.MockResponse{StatusCode: 200, BodyType: BodyTypeFile, FileID: file.ID}
+
+	t.Run("Serves the stored file", func(t *testing.T) {
+		resp, err := createFileResponse(mockResp, file, "/report", httptest.NewRequest(http.MethodGet, "/report", nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, content, string(body))
+		assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("Serves a range of the stored file", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+		req.Header.Set("Range", "bytes=-8")
+		resp, err := createFileResponse(mockResp, file, "/report", req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, content[len(content)-8:], string(body))
+	})
+
+	t.Run("Missing content is a server error", func(t *testing.T) {
+		require.NoError(t, RemoveMockFile(file.ID))
+		_, statErr := os.Stat(MockFilePath(file.ID))
+		assert.True(t, os.IsNotExist(statErr))
+
+		resp, err := createFileResponse(mockResp, file, "/report", httptest.NewRequest(http.MethodGet, "/report", nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestValidateResponseBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		response database.MockResponse
+		wantErr  string
+	}{
+		{"Empty body type is text", database.MockResponse{Body: "hello"}, ""},
+		{"Valid base64", database.MockResponse{BodyType: BodyTypeBase64, Body: "aGVsbG8="}, ""},
+		{"Invalid base64", database.MockResponse{BodyType: BodyTypeBase64, Body: "not base64!"}, "not valid base64"},
+		{"File without id", database.MockResponse{BodyType: BodyTypeFile}, "file_id is required"},
+		{"Binary cannot stream", database.MockResponse{BodyType: BodyTypeFile, FileID: "f", Stream: true}, "cannot be streamed"},
+		{"Unknown body type", database.MockResponse{BodyType: "xml"}, "unknown body_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResponseBody(&tt.response)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+// Stream formats
+const (
+	StreamFormatSSE     = "sse"     // Server-Sent Events (text/event-stream)
+	StreamFormatChunked = "chunked" // Raw chunks using chunked transfer encoding
+)
+
+// StreamEvent is a single SSE event or chunk of a streaming response
+type StreamEvent struct {
+	Data    json.RawMessage `json:"data"`               // String, or any JSON value which is sent compacted
+	Event   string          `json:"event,omitempty"`    // SSE event name
+	ID      string          `json:"id,omitempty"`       // SSE event id
+	Retry   int             `json:"retry,omitempty"`    // SSE reconnection time in milliseconds
+	DelayMs int             `json:"delay_ms,omitempty"` // Delay before this event is sent
+}
+
+// StreamDefinition describes the body of a response with Stream enabled
+//
+// The response body holds either the full definition:
+//
+//	{"format": "sse", "events": [{"event": "message", "id": "1", "data": {"text": "Hel"}, "delay_ms": 100}]}
+//
+// or just the events array. When format is omitted it is "sse" if the
+// Content-Type header is text/event-stream and "chunked" otherwise.
+type StreamDefinition struct {
+	Format string        `json:"format,omitempty"`
+	Events []StreamEvent `json:"events"`
+}
+
+// ParseStreamDefinition parses a streaming response body
+func ParseStreamDefinition(body string) (*StreamDefinition, error) {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return nil, fmt.Errorf("stream body must define at least one event")
+	}
+
+	def := &StreamDefinition{}
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &def.Events); err != nil {
+			return nil, fmt.Errorf("invalid stream events: %w", err)
+		}
+	} else if err := json.Unmarshal([]byte(trimmed), def); err != nil {
+		return nil, fmt.Errorf("invalid stream definition: %w", err)
+	}
+
+	switch def.Format {
+	case "", StreamFormatSSE, StreamFormatChunked:
+		// Valid formats
+	default:
+		return nil, fmt.Errorf("invalid stream format: %s, must be sse or chunked", def.Format)
+	}
+
+	if len(def.Events) == 0 {
+		return nil, fmt.Errorf("stream body must define at least one event")
+	}
+
+	for i, event := range def.Events {
+		if event.DelayMs < 0 {
+			return nil, fmt.Errorf("event %d: delay_ms must not be negative", i)
+		}
+	}
+
+	return def, nil
+}
+
+// ValidateStreamResponse checks the body of a streaming response before it is saved
+// Templated bodies are only checked once rendered at request time.
+func ValidateStreamResponse(stream, templated bool, body string) error {
+	if !stream || (templated && isTemplate(body)) {
+		return nil
+	}
+	_, err := ParseStreamDefinition(body)
+	return err
+}
+
+// payload returns the bytes sent for the event data
+func (e StreamEvent) payload() string {
+	return dataPayload(e.Data)
+}
+
+// dataPayload returns the text of a data field: a JSON string as is, any other JSON value compacted
+func dataPayload(data json.RawMessage) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return text
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, data); err != nil {
+		return string(data)
+	}
+	return compacted.String()
+}
+
+// SSE lines end at \r, \n or \r\n, so line breaks in rendered values could start new fields
+var (
+	sseFieldCleaner = strings.NewReplacer("\r", "", "\n", "")
+	sseLineBreaks   = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+)
+
+// formatSSEEvent encodes an event in the text/event-stream wire format
+// Line breaks are removed from the id and event fields; data is sent as one data field per line.
+func formatSSEEvent(event StreamEvent) string {
+	var b strings.Builder
+	if id := sseFieldCleaner.Replace(event.ID); id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	if name := sseFieldCleaner.Replace(event.Event); name != "" {
+		b.WriteString("event: " + name + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.Itoa(event.Retry) + "\n")
+	}
+	for _, line := range strings.Split(sseLineBreaks.Replace(event.payload()), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// createStreamResponse builds a response whose body is written event by event
+// Each event waits for its own delay; the stream stops early when ctx is cancelled.
+// The response uses chunked transfer encoding so the handler flushes as data arrives.
+func createStreamResponse(ctx context.Context, mockResp database.MockResponse) (*http.Response, error) {
+	def, err := ParseStreamDefinition(mockResp.Body)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, "Invalid stream response: "+err.Error()), nil
+	}
+
+	headers, err := repositories.ParseHeaders(mockResp.Headers)
+	if err != nil {
+		headers = map[string]string{}
+	}
+
+	resp := &http.Response{
+		StatusCode:       mockResp.StatusCode,
+		Header:           make(http.Header),
+		ContentLength:    -1,
+		TransferEncoding: []string{"chunked"},
+	}
+	for key, value := range headers {
+		// Stream bodies are never compressed and their length is unknown
+		if strings.EqualFold(key, "Content-Encoding") || strings.EqualFold(key, "Content-Length") {
+			continue
+		}
+		resp.Header.Set(key, value)
+	}
+
+	format := def.Format
+	if format == "" {
+		format = StreamFormatChunked
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			format = StreamFormatSSE
+		}
+	}
+	if format == StreamFormatSSE {
+		resp.Header.Set("Content-Type", "text/event-stream")
+		resp.Header.Set("Cache-Control", "no-cache")
+	} else if resp.Header.Get("Content-Type") == "" {
+		resp.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		for _, event := range def.Events {
+			if event.DelayMs > 0 {
+				timer := time.NewTimer(time.Duration(event.DelayMs) * time.Millisecond)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					pw.CloseWithError(ctx.Err())
+					return
+				case <-timer.C:
+				}
+			}
+
+			chunk := event.payload()
+			if format == StreamFormatSSE {
+				chunk = formatSSEEvent(event)
+			}
+			if _, err := io.WriteString(pw, chunk); err != nil {
+				log.Debug().Err(err).Str("response_id", mockResp.ID).Msg("Stream closed by reader")
+				return
+			}
+		}
+		pw.Close()
+	}()
+
+	resp.Body = pr
+	return resp, nil
+}
+
+// IsStreamingResponse reports whether a response body should be flushed as it is read
+func IsStreamingResponse(resp *http.Response) bool {
+	for _, encoding := range resp.TransferEncoding {
+		if strings.EqualFold(encoding, "chunked") {
+			return true
+		}
+	}
+	return false
+}
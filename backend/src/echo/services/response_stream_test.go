@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestParseStreamDefinition(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		expectErr  bool
+		eventCount int
+	}{
+		{name: "full definition", body: `{"format":"sse","events":[{"data":"a"},{"data":"b"}]}`, eventCount: 2},
+		{name: "events array", body: `[{"data":"a","delay_ms":10}]`, eventCount: 1},
+		{name: "empty body", body: ``, expectErr: true},
+		{name: "no events", body: `{"events":[]}`, expectErr: true},
+		{name: "unknown format", body: `{"format":"ws","events":[{"data":"a"}]}`, expectErr: true},
+		{name: "negative delay", body: `[{"data":"a","delay_ms":-1}]`, expectErr: true},
+		{name: "not json", body: `hello`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := ParseStreamDefinition(tt.body)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, def.Events, tt.eventCount)
+		})
+	}
+}
+
+func TestValidateStreamResponse(t *testing.T) {
+	assert.NoError(t, ValidateStreamResponse(false, false, "plain body"))
+	assert.NoError(t, ValidateStreamResponse(true, true, `{{.request.body.events}}`))
+	assert.Error(t, ValidateStreamResponse(true, false, `{{.request.body.events}}`))
+	assert.NoError(t, ValidateStreamResponse(true, false, `[{"data":"a"}]`))
+	assert.Error(t, ValidateStreamResponse(true, false, "plain body"))
+}
+
+func TestCreateStreamResponse_SSE(t *testing.T) {
+	mockResp := database.MockResponse{
+		StatusCode: 200,
+		Stream:     true,
+		Headers:    `{"Content-Type":"text/event-stream","Content-Encoding":"gzip"}`,
+		Body: `[
+			{"event":"message","id":"1","data":{"text":"Hel"}},
+			{"event":"message","id":"2","data":"lo\nworld","delay_ms":20},
+			{"event":"done","data":"[DONE]","retry":1000}
+		]`,
+	}
+
+	start := time.Now()
+	resp, err := createStreamResponse(context.Background(), mockResp)
+	require.NoError(t, err)
+	assert.True(t, IsStreamingResponse(resp))
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	expected := "id: 1\nevent: message\ndata: {\"text\":\"Hel\"}\n\n" +
+		"id: 2\nevent: message\ndata: lo\ndata: world\n\n" +
+		"event: done\nretry: 1000\ndata: [DONE]\n\n"
+	assert.Equal(t, expected, string(body))
+}
+
+func TestCreateStreamResponse_Chunked(t *testing.T) {
+	mockResp := database.MockResponse{
+		StatusCode: 200,
+		Stream:     true,
+		Headers:    `{"Content-Type":"text/plain"}`,
+		Body:       `{"format":"chunked","events":[{"data":"part1,"},{"data":"part2"}]}`,
+	}
+
+	resp, err := createStreamResponse(context.Background(), mockResp)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Equal(t, int64(-1), resp.ContentLength)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "part1,part2", string(body))
+}
+
+func TestCreateStreamResponse_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mockResp := database.MockResponse{
+		StatusCode: 200,
+		Stream:     true,
+		Body:       `[{"data":"first"},{"data":"never","delay_ms":10000}]`,
+	}
+
+	resp, err := createStreamResponse(ctx, mockResp)
+	require.NoError(t, err)
+
+	buf := make([]byte, 16)
+	n, err := resp.Body.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(buf[:n]))
+
+	cancel()
+	_, err = io.ReadAll(resp.Body)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCreateTemplatedMockResponse_Stream(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost/chat?name=Ann", nil)
+	mockResp := database.MockResponse{
+		StatusCode: 200,
+		Stream:     true,
+		Templated:  true,
+		Headers:    `{"Content-Type":"text/event-stream"}`,
+		Body:       `[{"data":"hi {{query "name"}}"}]`,
+	}
+
+	resp, err := createTemplatedMockResponse(mockResp, "/chat", req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "data: hi Ann\n\n", string(body))
+}
+
+func TestFormatSSEEvent_LineBreaks(t *testing.T) {
+	// Rendered request data cannot add fields or events to the stream
+	event := StreamEvent{
+		ID:    "1\nevent: admin",
+		Event: "message\r\ndata: injected",
+		Data:  json.RawMessage(`"line 1\rid: 9\r\nline 3"`),
+	}
+	assert.Equal(t, "id: 1event: admin\nevent: messagedata: injected\ndata: line 1\ndata: id: 9\ndata: line 3\n\n", formatSSEEvent(event))
+}
@@ -0,0 +1,455 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/google/uuid"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/echo/requestctx"
+	"beo-echo/backend/src/echo/xpath"
+)
+
+// templateMarker is the opening delimiter that marks a body or header value as a template.
+// Values without it are sent as-is so plain responses never pay the parsing cost.
+const templateMarker = "{{"
+
+// TemplateContext holds the request data exposed to response templates
+//
+// Inside a template the data is available under .request, for example:
+//
+//	{{.request.method}} {{.request.path}}
+//	{{.request.params.id}} {{.request.query.page}} {{.request.body.user.name}}
+//	{{.request.form.email}} {{.request.files.avatar.filename}} {{.request.claims.sub}}
+//
+// Helper functions (param, query, header, cookie, body) are safer for keys that
+// contain dashes or may be missing. body and jsonGet take JSONPath keys such as
+// "items[0].sku"; bodyAll and jsonPath return every value a key selects. For XML bodies,
+// xpath and xpathAll return the first or every value an XPath expression selects.
+type TemplateContext struct {
+	Method     string
+	Path       string
+	PathParams map[string]string
+	Query      map[string]string
+	Headers    map[string]string
+	Cookies    map[string]string
+	RawBody    string
+	Body       interface{}                       // Parsed JSON body, nil when the body is not JSON
+	Form       map[string]string                 // Fields of form-urlencoded and multipart bodies
+	Files      map[string]map[string]interface{} // Uploaded files of multipart bodies by part name
+	XML        *requestctx.XMLNode               // Root element of XML bodies
+	Message    map[string]interface{}            // WebSocket message a reply answers, nil otherwise
+	Claims     map[string]interface{}            // Claims of the bearer JWT, nil without one
+}
+
+// NewTemplateContext builds a template context from the incoming request
+// The request data comes from the shared request view, so the body is not read again.
+func NewTemplateContext(req *http.Request, path string, pathParams map[string]string) *TemplateContext {
+	ctx := &TemplateContext{
+		Path:       path,
+		PathParams: pathParams,
+		Query:      map[string]string{},
+		Headers:    map[string]string{},
+		Cookies:    map[string]string{},
+		Form:       map[string]string{},
+		Files:      map[string]map[string]interface{}{},
+	}
+	if ctx.PathParams == nil {
+		ctx.PathParams = map[string]string{}
+	}
+
+	if req == nil {
+		return ctx
+	}
+
+	request := requestctx.From(req)
+	ctx.Method = request.Method
+
+	for key, values := range request.Query() {
+		if len(values) > 0 {
+			ctx.Query[key] = values[0]
+		}
+	}
+
+	for key, values := range request.Header {
+		ctx.Headers[key] = strings.Join(values, ", ")
+	}
+
+	for name, value := range request.Cookies() {
+		ctx.Cookies[name] = value
+	}
+
+	ctx.Claims = request.Claims()
+
+	ctx.RawBody = string(request.Body)
+	if parsed, ok := request.JSON(); ok {
+		ctx.Body = parsed
+	}
+
+	switch request.Format() {
+	case requestctx.FormatXML:
+		ctx.XML, _ = request.XML()
+	case requestctx.FormatForm:
+		for key, values := range request.Form() {
+			ctx.Form[key] = values[0]
+		}
+	case requestctx.FormatMultipart:
+		if form, ok := request.Multipart(); ok {
+			for key, values := range form.Fields {
+				ctx.Form[key] = values[0]
+			}
+			for key, files := range form.Files {
+				ctx.Files[key] = map[string]interface{}{
+					"filename":    files[0].Filename,
+					"contentType": files[0].ContentType,
+					"size":        files[0].Size,
+				}
+			}
+		}
+	}
+
+	return ctx
+}
+
+// data returns the value passed to template execution
+func (c *TemplateContext) data() map[string]interface{} {
+	return map[string]interface{}{
+		"request": map[string]interface{}{
+			"method":  c.Method,
+			"path":    c.Path,
+			"params":  c.PathParams,
+			"query":   c.Query,
+			"headers": c.Headers,
+			"cookies": c.Cookies,
+			"body":    c.Body,
+			"rawBody": c.RawBody,
+			"form":    c.Form,
+			"files":   c.Files,
+			"claims":  c.Claims,
+		},
+		"message": c.Message,
+	}
+}
+
+// isTemplate reports whether a string should be rendered as a template
+// Response bodies and headers are only templates on responses with Templated set.
+func isTemplate(value string) bool {
+	return strings.Contains(value, templateMarker)
+}
+
+// templateFuncs returns the helper functions available to response templates
+// ctx may be nil when templates are only parsed for validation.
+func templateFuncs(ctx *TemplateContext) template.FuncMap {
+	if ctx == nil {
+		ctx = &TemplateContext{}
+	}
+
+	return template.FuncMap{
+		// Request accessors
+		"param": func(name string) string {
+			return ctx.PathParams[name]
+		},
+		"query": func(name string) string {
+			return ctx.Query[name]
+		},
+		"header": func(name string) string {
+			for key, value := range ctx.Headers {
+				if strings.EqualFold(key, name) {
+					return value
+				}
+			}
+			return ""
+		},
+		"cookie": func(name string) string {
+			return ctx.Cookies[name]
+		},
+		"body": func(key string) interface{} {
+			value, _ := getNestedValueEx(ctx.Body, key)
+			return value
+		},
+		"bodyAll": func(key string) ([]interface{}, error) {
+			return jsonpath.Find(ctx.Body, key)
+		},
+		"form": func(name string) string {
+			if value, ok := ctx.Form[name]; ok {
+				return value
+			}
+			return ctx.Form[name+"[]"]
+		},
+		"xpath": func(expr string) (string, error) {
+			values, err := xpath.Find(ctx.XML, expr)
+			if err != nil || len(values) == 0 {
+				return "", err
+			}
+			return values[0], nil
+		},
+		"xpathAll": func(expr string) ([]string, error) {
+			return xpath.Find(ctx.XML, expr)
+		},
+
+		// Dates
+		"now": func(layout ...string) string {
+			return formatTime(time.Now(), layout...)
+		},
+		"timestamp": func() int64 {
+			return time.Now().Unix()
+		},
+		"timestampMs": func() int64 {
+			return time.Now().UnixMilli()
+		},
+		"dateAdd": func(duration string, layout ...string) (string, error) {
+			d, err := time.ParseDuration(duration)
+			if err != nil {
+				return "", fmt.Errorf("dateAdd: %w", err)
+			}
+			return formatTime(time.Now().Add(d), layout...), nil
+		},
+		"formatDate": func(value, fromLayout, toLayout string) (string, error) {
+			t, err := time.Parse(fromLayout, value)
+			if err != nil {
+				return "", fmt.Errorf("formatDate: %w", err)
+			}
+			return t.Format(toLayout), nil
+		},
+
+		// Random values
+		"uuid": func() string {
+			return uuid.New().String()
+		},
+		"randomInt": func(min, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + rand.Intn(max-min+1)
+		},
+		"randomFloat": func(min, max float64) float64 {
+			if max <= min {
+				return min
+			}
+			return min + rand.Float64()*(max-min)
+		},
+		"randomString": func(length int) string {
+			const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+			b := make([]byte, length)
+			for i := range b {
+				b[i] = charset[rand.Intn(len(charset))]
+			}
+			return string(b)
+		},
+		"randomChoice": func(values ...interface{}) interface{} {
+			if len(values) == 0 {
+				return nil
+			}
+			return values[rand.Intn(len(values))]
+		},
+
+		// Encoding
+		"base64Encode": func(value string) string {
+			return base64.StdEncoding.EncodeToString([]byte(value))
+		},
+		"base64Decode": func(value string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return "", fmt.Errorf("base64Decode: %w", err)
+			}
+			return string(decoded), nil
+		},
+
+		// JSON
+		"toJSON": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return "", fmt.Errorf("toJSON: %w", err)
+			}
+			return string(data), nil
+		},
+		"fromJSON": func(value string) (interface{}, error) {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+				return nil, fmt.Errorf("fromJSON: %w", err)
+			}
+			return parsed, nil
+		},
+		"jsonGet": func(value interface{}, key string) interface{} {
+			result, _ := getNestedValueEx(value, key)
+			return result
+		},
+		"jsonPath": func(value interface{}, key string) ([]interface{}, error) {
+			return jsonpath.Find(value, key)
+		},
+
+		// Strings
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+		"replace": strings.ReplaceAll,
+		"default": func(fallback, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return fallback
+			}
+			return value
+		},
+
+		// Appended to every printed pipeline by parseResponseTemplate
+		missingAsEmptyFunc: func(value interface{}) interface{} {
+			if value == nil {
+				return ""
+			}
+			return value
+		},
+	}
+}
+
+// formatTime formats t with an optional layout, defaulting to RFC3339
+func formatTime(t time.Time, layout ...string) string {
+	if len(layout) > 0 && layout[0] != "" {
+		return t.Format(layout[0])
+	}
+	return t.Format(time.RFC3339)
+}
+
+// missingAsEmptyFunc names the helper that prints missing values as empty text
+const missingAsEmptyFunc = "missingAsEmpty"
+
+// parseResponseTemplate parses a template string with the response helpers
+// missingkey=zero only applies to typed maps: text/template prints "<no value>" for a key
+// missing from the decoded request body (a map[string]interface{}) or a helper returning nil,
+// so every printed pipeline is ended with missingAsEmpty.
+func parseResponseTemplate(name, value string, ctx *TemplateContext) (*template.Template, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=zero").
+		Funcs(templateFuncs(ctx)).
+		Parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			printMissingAsEmpty(t.Tree, t.Tree.Root)
+		}
+	}
+	return tmpl, nil
+}
+
+// printMissingAsEmpty appends missingAsEmpty to the pipeline of every action that prints its value
+func printMissingAsEmpty(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			printMissingAsEmpty(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(missingAsEmptyFunc).SetTree(tree).SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		printMissingAsEmpty(tree, n.List)
+		printMissingAsEmpty(tree, n.ElseList)
+	case *parse.RangeNode:
+		printMissingAsEmpty(tree, n.List)
+		printMissingAsEmpty(tree, n.ElseList)
+	case *parse.WithNode:
+		printMissingAsEmpty(tree, n.List)
+		printMissingAsEmpty(tree, n.ElseList)
+	}
+}
+
+// renderTemplate renders a single template string against the request context
+// Strings without template markers are returned unchanged.
+func renderTemplate(name, value string, ctx *TemplateContext) (string, error) {
+	if !isTemplate(value) {
+		return value, nil
+	}
+
+	tmpl, err := parseResponseTemplate(name, value, ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx.data()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderMockResponse returns a copy of the mock response with its body and
+// header values rendered as templates against the incoming request
+// Responses without Templated are returned unchanged.
+func renderMockResponse(mockResp database.MockResponse, ctx *TemplateContext) (database.MockResponse, error) {
+	if ctx == nil || !mockResp.Templated {
+		return mockResp, nil
+	}
+
+	body, err := renderTemplate("body", mockResp.Body, ctx)
+	if err != nil {
+		return mockResp, fmt.Errorf("failed to render response body template: %w", err)
+	}
+	mockResp.Body = body
+
+	if isTemplate(mockResp.Headers) {
+		headers, err := repositories.ParseHeaders(mockResp.Headers)
+		if err != nil {
+			return mockResp, fmt.Errorf("failed to parse response headers: %w", err)
+		}
+
+		for key, value := range headers {
+			rendered, err := renderTemplate("header:"+key, value, ctx)
+			if err != nil {
+				return mockResp, fmt.Errorf("failed to render header %q template: %w", key, err)
+			}
+			headers[key] = rendered
+		}
+
+		headersJSON, err := json.Marshal(headers)
+		if err != nil {
+			return mockResp, fmt.Errorf("failed to encode rendered headers: %w", err)
+		}
+		mockResp.Headers = string(headersJSON)
+	}
+
+	return mockResp, nil
+}
+
+// ValidateResponseTemplate checks that a response body and header values are valid templates
+// It is called when a response is saved so syntax errors surface before the mock is served.
+func ValidateResponseTemplate(body, headersJSON string) error {
+	if isTemplate(body) {
+		if _, err := parseResponseTemplate("body", body, nil); err != nil {
+			return fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+
+	if isTemplate(headersJSON) {
+		headers, err := repositories.ParseHeaders(headersJSON)
+		if err != nil {
+			return fmt.Errorf("invalid headers JSON: %w", err)
+		}
+		for key, value := range headers {
+			if _, err := parseResponseTemplate("header:"+key, value, nil); err != nil {
+				return fmt.Errorf("invalid template in header %q: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+func newTemplateTestRequest(method, target, body string, headers map[string]string) *http.Request {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return req
+}
+
+func TestRenderTemplate_RequestData(t *testing.T) {
+	req := newTemplateTestRequest("POST", "http://localhost/users/42?page=3", `{"user":{"name":"Jane"}}`, map[string]string{
+		"Content-Type": "application/json",
+		"X-Trace-Id":   "trace-1",
+		"Cookie":       "session=abc",
+	})
+	ctx := NewTemplateContext(req, "/users/42", map[string]string{"id": "42"})
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "method", template: "{{.request.method}}", expected: "POST"},
+		{name: "path param via data", template: "{{.request.params.id}}", expected: "42"},
+		{name: "path param via helper", template: `{{param "id"}}`, expected: "42"},
+		{name: "query", template: `{{query "page"}}`, expected: "3"},
+		{name: "header is case insensitive", template: `{{header "x-trace-id"}}`, expected: "trace-1"},
+		{name: "cookie", template: `{{cookie "session"}}`, expected: "abc"},
+		{name: "nested body via data", template: "{{.request.body.user.name}}", expected: "Jane"},
+		{name: "nested body via helper", template: `{{body "user.name"}}`, expected: "Jane"},
+		{name: "body helper with JSONPath", template: `{{body "$['user']['name']"}}`, expected: "Jane"},
+		{name: "bodyAll helper", template: `{{len (bodyAll "$..name")}}`, expected: "1"},
+		{name: "missing query is empty", template: `{{query "missing"}}`, expected: ""},
+		{name: "missing body key is empty", template: `[{{.request.body.email}}]`, expected: "[]"},
+		{name: "missing param is empty", template: `[{{.request.params.missing}}]`, expected: "[]"},
+		{name: "default helper", template: `{{default "guest" (query "missing")}}`, expected: "guest"},
+		{name: "missing body helper key is empty", template: `[{{body "email"}}]`, expected: "[]"},
+		{name: "missing key inside if and range", template: `{{if true}}[{{.request.body.email}}]{{end}}{{range $k, $v := .request.params}}[{{$.request.body.email}}]{{end}}`, expected: "[][]"},
+		{name: "missing key in a defined template", template: `{{define "email"}}[{{.request.body.email}}]{{end}}{{template "email" .}}`, expected: "[]"},
+		{name: "assignments print nothing", template: `{{$email := .request.body.email}}[{{$email}}]`, expected: "[]"},
+		{name: "plain text untouched", template: `{"id": 1}`, expected: `{"id": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderTemplate("test", tt.template, ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// Body must still be readable after building the context
+	bodyBytes, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user":{"name":"Jane"}}`, string(bodyBytes))
+}
+
+func TestRenderTemplate_KeepsLiteralNoValue(t *testing.T) {
+	req := newTemplateTestRequest("POST", "http://localhost/notes", `{"note":"<no value>"}`, map[string]string{
+		"Content-Type": "application/json",
+		"X-Note":       "<no value>",
+	})
+	ctx := NewTemplateContext(req, "/notes", nil)
+
+	// Only missing values render as empty, the text "<no value>" from the request or the template is kept
+	result, err := renderTemplate("test", `{{.request.body.note}}|{{header "X-Note"}}|{{.request.rawBody}}|<no value>|{{.request.body.missing}}`, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `<no value>|<no value>|{"note":"<no value>"}|<no value>|`, result)
+}
+
+func TestRenderTemplate_BodyFormats(t *testing.T) {
+	render := func(t *testing.T, req *http.Request, template string) string {
+		t.Helper()
+		result, err := renderTemplate("test", template, NewTemplateContext(req, req.URL.Path, nil))
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("xml", func(t *testing.T) {
+		req := newTemplateTestRequest("POST", "http://localhost/orders", `<Order id="42"><Item sku="A1"/><Item sku="B2"/></Order>`,
+			map[string]string{"Content-Type": "text/xml"})
+		assert.Equal(t, "42", render(t, req, `{{xpath "/Order/@id"}}`))
+		assert.Equal(t, "A1,B2,", render(t, req, `{{range xpathAll "//Item/@sku"}}{{.}},{{end}}`))
+		assert.Equal(t, "", render(t, req, `{{xpath "//Missing"}}`))
+	})
+
+	t.Run("form", func(t *testing.T) {
+		req := newTemplateTestRequest("POST", "http://localhost/signup", "email=jane%40example.com&tags[]=vip",
+			map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+		assert.Equal(t, "jane@example.com vip", render(t, req, `{{.request.form.email}} {{form "tags"}}`))
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		writer.WriteField("title", "Holiday")
+		file, _ := writer.CreateFormFile("avatar", "me.png")
+		file.Write([]byte("12345"))
+		writer.Close()
+
+		req := newTemplateTestRequest("POST", "http://localhost/upload", buf.String(),
+			map[string]string{"Content-Type": writer.FormDataContentType()})
+		assert.Equal(t, "Holiday me.png 5", render(t, req, `{{form "title"}} {{.request.files.avatar.filename}} {{.request.files.avatar.size}}`))
+	})
+}
+
+func TestRenderTemplate_Helpers(t *testing.T) {
+	ctx := NewTemplateContext(nil, "/", nil)
+
+	t.Run("uuid", func(t *testing.T) {
+		result, err := renderTemplate("test", "{{uuid}}", ctx)
+		require.NoError(t, err)
+		assert.Len(t, result, 36)
+	})
+
+	t.Run("randomInt stays in range", func(t *testing.T) {
+		for i := 0; i < 50; i++ {
+			result, err := renderTemplate("test", "{{randomInt 5 7}}", ctx)
+			require.NoError(t, err)
+			assert.Contains(t, []string{"5", "6", "7"}, result)
+		}
+	})
+
+	t.Run("base64 round trip", func(t *testing.T) {
+		result, err := renderTemplate("test", `{{base64Encode "hello"}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("hello")), result)
+
+		result, err = renderTemplate("test", `{{base64Decode "aGVsbG8="}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", result)
+	})
+
+	t.Run("json helpers", func(t *testing.T) {
+		result, err := renderTemplate("test", `{{jsonGet (fromJSON "{\"a\":{\"b\":1}}") "a.b"}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "1", result)
+
+		result, err = renderTemplate("test", `{{toJSON (fromJSON "[1,2]")}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "[1,2]", result)
+	})
+
+	t.Run("JSONPath helpers", func(t *testing.T) {
+		order := `{\"items\":[{\"sku\":\"A1\",\"qty\":1},{\"sku\":\"B2\",\"qty\":3}]}`
+
+		result, err := renderTemplate("test", `{{jsonGet (fromJSON "`+order+`") "items[-1].sku"}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "B2", result)
+
+		result, err = renderTemplate("test", `{{range jsonPath (fromJSON "`+order+`") "$.items[?(@.qty > 0)].sku"}}{{.}};{{end}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "A1;B2;", result)
+
+		_, err = renderTemplate("test", `{{jsonPath (fromJSON "`+order+`") "items["}}`, ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("formatDate", func(t *testing.T) {
+		result, err := renderTemplate("test", `{{formatDate "2024-01-02" "2006-01-02" "02/01/2006"}}`, ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "02/01/2024", result)
+	})
+
+	t.Run("helper error surfaces", func(t *testing.T) {
+		_, err := renderTemplate("test", `{{base64Decode "%%%"}}`, ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestRenderMockResponse_BodyAndHeaders(t *testing.T) {
+	req := newTemplateTestRequest("GET", "http://localhost/orders/99", "", nil)
+	ctx := NewTemplateContext(req, "/orders/99", map[string]string{"orderId": "99"})
+
+	mockResp := database.MockResponse{
+		StatusCode: 200,
+		Templated:  true,
+		Body:       `{"id": "{{param "orderId"}}"}`,
+		Headers:    `{"Content-Type": "application/json", "Location": "/orders/{{param \"orderId\"}}"}`,
+	}
+
+	rendered, err := renderMockResponse(mockResp, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": "99"}`, rendered.Body)
+
+	resp, err := createMockResponse(rendered)
+	require.NoError(t, err)
+	assert.Equal(t, "/orders/99", resp.Header.Get("Location"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestCreateTemplatedMockResponse_RenderFailure(t *testing.T) {
+	req := newTemplateTestRequest("GET", "http://localhost/x", "", nil)
+	mockResp := database.MockResponse{StatusCode: 200, Templated: true, Body: `{{base64Decode "%%%"}}`}
+
+	resp, err := createTemplatedMockResponse(mockResp, "/x", req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestCreateTemplatedMockResponse_NotTemplated(t *testing.T) {
+	req := newTemplateTestRequest("GET", "http://localhost/x", "", nil)
+	// Handlebars snippets and literal braces are served as stored unless the response is templated
+	mockResp := database.MockResponse{StatusCode: 200, Body: `{"greeting":"Hello {{name}}","broken":"{{"}`, Headers: `{"X-Tpl":"{{.request.method}}"}`}
+
+	resp, err := createTemplatedMockResponse(mockResp, "/x", req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, mockResp.Body, string(body))
+	assert.Equal(t, "{{.request.method}}", resp.Header.Get("X-Tpl"))
+}
+
+func TestValidateResponseTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		headers   string
+		expectErr bool
+	}{
+		{name: "plain body", body: `{"ok": true}`, headers: `{"Content-Type":"application/json"}`},
+		{name: "valid body template", body: `{"id": "{{param "id"}}"}`},
+		{name: "unclosed action", body: `{"id": "{{param "id"}"}`, expectErr: true},
+		{name: "unknown function", body: `{{notAHelper}}`, expectErr: true},
+		{name: "valid header template", headers: `{"X-Id":"{{uuid}}"}`},
+		{name: "invalid header template", headers: `{"X-Id":"{{uuid"}`, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResponseTemplate(tt.body, tt.headers)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"sync"
+
+	"beo-echo/backend/src/echo/repositories"
+)
+
+// routeCache keeps the compiled route table of every project that received traffic, keyed by alias
+// Tables are dropped by InvalidateProjectRoutes whenever a project's routing data changes.
+var routeCache = struct {
+	sync.RWMutex
+	tables     map[string]*repositories.RouteTable
+	generation uint64 // Bumped on every invalidation so tables loaded before it are not stored
+}{tables: map[string]*repositories.RouteTable{}}
+
+// routeTable returns the compiled routes of a project, loading them on first use
+func (s *MockService) routeTable(alias string) (*repositories.RouteTable, error) {
+	routeCache.RLock()
+	table, ok := routeCache.tables[alias]
+	generation := routeCache.generation
+	routeCache.RUnlock()
+	if ok {
+		return table, nil
+	}
+
+	table, err := s.Repo.LoadRouteTable(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	routeCache.Lock()
+	if routeCache.generation == generation {
+		routeCache.tables[alias] = table
+	}
+	routeCache.Unlock()
+	return table, nil
+}
+
+// InvalidateProjectRoutes drops the compiled routes of a project so the next request reloads them
+// Call it after changing the project, its endpoints, responses, rules or proxy targets.
+func InvalidateProjectRoutes(projectID string) {
+	routeCache.Lock()
+	defer routeCache.Unlock()
+
+	routeCache.generation++
+	for alias, table := range routeCache.tables {
+		if table.Project.ID == projectID {
+			delete(routeCache.tables, alias)
+		}
+	}
+}
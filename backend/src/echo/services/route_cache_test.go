@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+	"beo-echo/backend/src/utils"
+)
+
+// noActionsRepo is an action repository for projects without actions
+type noActionsRepo struct {
+	actions.ActionRepository
+}
+
+func (noActionsRepo) GetEnabledActionsByProjectAndPoint(ctx context.Context, projectID string, executionPoint database.ExecutionPoint) ([]database.Action, error) {
+	return nil, nil
+}
+
+// setupRoutedProject creates a mock project with one endpoint and response and a service to call it
+func setupRoutedProject(t testing.TB) (*MockService, *database.Project, *database.MockResponse) {
+	db := database.GetDB()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "Routes", Alias: "routes-" + uuid.New().String()[:8], Mode: database.ModeMock}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	endpoint := &database.MockEndpoint{ProjectID: project.ID, Method: "GET", Path: "/users/:id", Enabled: true, ResponseMode: "static"}
+	require.NoError(t, db.Create(endpoint).Error)
+
+	response := &database.MockResponse{EndpointID: endpoint.ID, StatusCode: 200, Body: "first", Headers: `{"Content-Type":"text/plain"}`, Enabled: true}
+	require.NoError(t, db.Create(response).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	return service, project, response
+}
+
+// callRoutedProject sends a GET through HandleRequest and returns the status and body
+func callRoutedProject(t testing.TB, service *MockService, project *database.Project, path string) (int, string) {
+	req, _ := http.NewRequest("GET", "http://localhost/"+project.Alias+path, nil)
+	resp, err, _, _, _ := service.HandleRequest(context.Background(), project.Alias, "GET", "/"+project.Alias+path, req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestRouteCache(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	service, project, response := setupRoutedProject(t)
+	db := database.GetDB()
+
+	status, body := callRoutedProject(t, service, project, "/users/1")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "first", body)
+
+	// Changes made without invalidation are not seen while the table is cached
+	require.NoError(t, db.Model(response).Update("body", "second").Error)
+	_, body = callRoutedProject(t, service, project, "/users/1")
+	assert.Equal(t, "first", body)
+
+	InvalidateProjectRoutes(project.ID)
+	_, body = callRoutedProject(t, service, project, "/users/1")
+	assert.Equal(t, "second", body)
+
+	// Disabling the endpoint removes the route once invalidated
+	require.NoError(t, db.Model(&database.MockEndpoint{}).Where("id = ?", response.EndpointID).Update("enabled", false).Error)
+	InvalidateProjectRoutes(project.ID)
+	_, body = callRoutedProject(t, service, project, "/users/1")
+	assert.NotEqual(t, "second", body)
+}
+
+func BenchmarkHandleRequest(b *testing.B) {
+	utils.SetupFolderConfigForTest()
+	require.NoError(b, database.CheckAndHandle())
+	b.Cleanup(utils.CleanupTestFolders)
+	service, project, _ := setupRoutedProject(b)
+
+	run := func(b *testing.B, invalidate bool) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if invalidate {
+				InvalidateProjectRoutes(project.ID)
+			}
+			callRoutedProject(b, service, project, fmt.Sprintf("/users/%d", i))
+		}
+	}
+
+	b.Run("Cached", func(b *testing.B) { run(b, false) })
+	b.Run("Reloaded", func(b *testing.B) { run(b, true) })
+}
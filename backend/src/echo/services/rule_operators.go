@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule operators
+// The string operators also have a case-insensitive variant named with the _ci suffix
+// (equals_ci, contains_ci, in_ci, regex_ci, ...).
+const (
+	OperatorEquals        = "equals"
+	OperatorNotEquals     = "not_equals"
+	OperatorContains      = "contains"
+	OperatorNotContains   = "not_contains"
+	OperatorStartsWith    = "starts_with"
+	OperatorEndsWith      = "ends_with"
+	OperatorRegex         = "regex"
+	OperatorGreater       = "gt"
+	OperatorGreaterEqual  = "gte"
+	OperatorLess          = "lt"
+	OperatorLessEqual     = "lte"
+	OperatorBetween       = "between"
+	OperatorIn            = "in"
+	OperatorNotIn         = "not_in"
+	OperatorExists        = "exists"
+	OperatorNotExists     = "not_exists"
+	OperatorHasProperty   = "has_property"
+	OperatorMatchesType   = "matches_type"
+	OperatorMatchesSchema = "matches_schema"
+
+	caseInsensitiveSuffix = "_ci"
+)
+
+// ruleOperatorSpec describes where an operator can be used
+type ruleOperatorSpec struct {
+	bodyOnly        bool // Only meaningful on parsed JSON values (body and GraphQL variables)
+	caseInsensitive bool // Has a _ci variant
+}
+
+var ruleOperators = map[string]ruleOperatorSpec{
+	OperatorEquals:        {caseInsensitive: true},
+	OperatorNotEquals:     {caseInsensitive: true},
+	OperatorContains:      {caseInsensitive: true},
+	OperatorNotContains:   {caseInsensitive: true},
+	OperatorStartsWith:    {caseInsensitive: true},
+	OperatorEndsWith:      {caseInsensitive: true},
+	OperatorRegex:         {caseInsensitive: true},
+	OperatorGreater:       {},
+	OperatorGreaterEqual:  {},
+	OperatorLess:          {},
+	OperatorLessEqual:     {},
+	OperatorBetween:       {},
+	OperatorIn:            {caseInsensitive: true},
+	OperatorNotIn:         {caseInsensitive: true},
+	OperatorExists:        {},
+	OperatorNotExists:     {},
+	OperatorHasProperty:   {bodyOnly: true},
+	OperatorMatchesType:   {bodyOnly: true},
+	OperatorMatchesSchema: {bodyOnly: true},
+}
+
+// ruleTypes are the request parts a rule can match
+var ruleTypes = map[string]bool{"header": true, "query": true, "body": true, "path": true, "graphql": true, "jwt": true}
+
+// parseRuleOperator splits an operator into its base name and case-insensitive flag
+// ok is false for unknown operators.
+func parseRuleOperator(operator string) (base string, caseInsensitive bool, ok bool) {
+	operator = strings.ToLower(strings.TrimSpace(operator))
+	if _, known := ruleOperators[operator]; known {
+		return operator, false, true
+	}
+	if trimmed := strings.TrimSuffix(operator, caseInsensitiveSuffix); trimmed != operator {
+		if spec, known := ruleOperators[trimmed]; known && spec.caseInsensitive {
+			return trimmed, true, true
+		}
+	}
+	return "", false, false
+}
+
+// isPresenceOperator reports whether an operator tests for presence rather than a value
+func isPresenceOperator(operator string) bool {
+	base, _, _ := parseRuleOperator(operator)
+	return base == OperatorExists || base == OperatorNotExists || base == OperatorHasProperty
+}
+
+// matchPresence evaluates a presence operator
+func matchPresence(operator string, present bool) bool {
+	base, _, _ := parseRuleOperator(operator)
+	if base == OperatorNotExists {
+		return !present
+	}
+	return present
+}
+
+// matchRuleValue compares string values based on operator
+// Unknown operators never match; they are rejected when rules are saved.
+func matchRuleValue(operator, actual, expected string) bool {
+	base, caseInsensitive, ok := parseRuleOperator(operator)
+	if !ok {
+		return false
+	}
+	if caseInsensitive && base != OperatorRegex {
+		actual = strings.ToLower(actual)
+		expected = strings.ToLower(expected)
+	}
+
+	switch base {
+	case OperatorEquals:
+		return actual == expected
+	case OperatorNotEquals:
+		return actual != expected
+	case OperatorContains:
+		return strings.Contains(actual, expected)
+	case OperatorNotContains:
+		return !strings.Contains(actual, expected)
+	case OperatorStartsWith:
+		return strings.HasPrefix(actual, expected)
+	case OperatorEndsWith:
+		return strings.HasSuffix(actual, expected)
+	case OperatorRegex:
+		re, err := compileRuleRegex(expected, caseInsensitive)
+		return err == nil && re.MatchString(actual)
+	case OperatorGreater, OperatorGreaterEqual, OperatorLess, OperatorLessEqual:
+		cmp, ok := compareRuleValues(actual, expected)
+		if !ok {
+			return false
+		}
+		switch base {
+		case OperatorGreater:
+			return cmp > 0
+		case OperatorGreaterEqual:
+			return cmp >= 0
+		case OperatorLess:
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	case OperatorBetween:
+		bounds := parseRuleList(expected)
+		if len(bounds) != 2 {
+			return false
+		}
+		low, okLow := compareRuleValues(actual, bounds[0])
+		high, okHigh := compareRuleValues(actual, bounds[1])
+		return okLow && okHigh && low >= 0 && high <= 0
+	case OperatorIn, OperatorNotIn:
+		found := false
+		for _, candidate := range parseRuleList(expected) {
+			if actual == candidate {
+				found = true
+				break
+			}
+		}
+		return found == (base == OperatorIn)
+	case OperatorExists, OperatorHasProperty:
+		return actual != ""
+	case OperatorNotExists:
+		return actual == ""
+	default:
+		return false
+	}
+}
+
+var ruleRegexCache sync.Map // pattern -> *regexp.Regexp
+
+// compileRuleRegex compiles a rule pattern once and reuses it for later requests
+func compileRuleRegex(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	if caseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	if cached, ok := ruleRegexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	ruleRegexCache.Store(pattern, re)
+	return re, nil
+}
+
+// ruleTimeLayouts are the date formats accepted by the comparison operators
+var ruleTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// parseRuleTime parses a date in one of the accepted layouts
+func parseRuleTime(value string) (time.Time, bool) {
+	for _, layout := range ruleTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareRuleValues orders two values as numbers, or as dates when both are dates
+// ok is false when they are neither.
+func compareRuleValues(actual, expected string) (int, bool) {
+	actual, expected = strings.TrimSpace(actual), strings.TrimSpace(expected)
+
+	a, errA := strconv.ParseFloat(actual, 64)
+	b, errB := strconv.ParseFloat(expected, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	ta, okA := parseRuleTime(actual)
+	tb, okB := parseRuleTime(expected)
+	if okA && okB {
+		return ta.Compare(tb), true
+	}
+	return 0, false
+}
+
+// parseRuleList parses the value of in, not_in and between
+// The value is a JSON array (["a", "b"]) or a comma-separated list (a, b).
+func parseRuleList(value string) []string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") {
+		var items []interface{}
+		if err := json.Unmarshal([]byte(value), &items); err == nil {
+			list := make([]string, len(items))
+			for i, item := range items {
+				list[i] = interfaceToString(item)
+			}
+			return list
+		}
+	}
+	if value == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// validateRuleOperator checks the operator is known, fits the rule type and has a usable value
+func validateRuleOperator(ruleType, operator, value string) error {
+	base, caseInsensitive, ok := parseRuleOperator(operator)
+	if !ok {
+		return fmt.Errorf("unknown operator %q", operator)
+	}
+	if ruleOperators[base].bodyOnly && ruleType != "body" && ruleType != "graphql" {
+		return fmt.Errorf("operator %q can only be used on body and graphql rules", base)
+	}
+
+	switch base {
+	case OperatorRegex:
+		if _, err := compileRuleRegex(value, caseInsensitive); err != nil {
+			return fmt.Errorf("invalid regex: %v", err)
+		}
+	case OperatorGreater, OperatorGreaterEqual, OperatorLess, OperatorLessEqual:
+		if !isOrderedRuleValue(value) {
+			return fmt.Errorf("operator %q needs a number or a date value", base)
+		}
+	case OperatorBetween:
+		bounds := parseRuleList(value)
+		if len(bounds) != 2 || !isOrderedRuleValue(bounds[0]) || !isOrderedRuleValue(bounds[1]) {
+			return fmt.Errorf("operator %q needs two numbers or dates, e.g. \"1,10\"", base)
+		}
+		if cmp, ok := compareRuleValues(bounds[0], bounds[1]); !ok || cmp > 0 {
+			return fmt.Errorf("operator %q needs a lower bound followed by a higher bound of the same kind", base)
+		}
+	case OperatorIn, OperatorNotIn:
+		if len(parseRuleList(value)) == 0 {
+			return fmt.Errorf("operator %q needs at least one value", base)
+		}
+	}
+	return nil
+}
+
+// isOrderedRuleValue reports whether a value is a number or a date
+func isOrderedRuleValue(value string) bool {
+	value = strings.TrimSpace(value)
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	_, ok := parseRuleTime(value)
+	return ok
+}
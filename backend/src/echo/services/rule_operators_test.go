@@ -0,0 +1,138 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestMatchRuleValue_Operators(t *testing.T) {
+	tests := []struct {
+		operator string
+		actual   string
+		expected string
+		result   bool
+	}{
+		{OperatorEquals, "abc", "abc", true},
+		{OperatorEquals, "ABC", "abc", false},
+		{"equals_ci", "ABC", "abc", true},
+		{"EQUALS", "abc", "abc", true},
+		{OperatorNotEquals, "abc", "abd", true},
+		{OperatorNotEquals, "abc", "abc", false},
+		{OperatorContains, "hello world", "lo w", true},
+		{OperatorNotContains, "hello world", "bye", true},
+		{"not_contains_ci", "Hello", "HELL", false},
+		{OperatorStartsWith, "Bearer token", "Bearer ", true},
+		{"starts_with_ci", "bearer token", "Bearer ", true},
+		{OperatorEndsWith, "report.pdf", ".pdf", true},
+		{OperatorRegex, "order-123", `^order-\d+$`, true},
+		{OperatorRegex, "ORDER-123", `^order-\d+$`, false},
+		{"regex_ci", "ORDER-123", `^order-\d+$`, true},
+		{OperatorRegex, "x", `(`, false},
+		{OperatorGreater, "10", "9", true},
+		{OperatorGreater, "10", "10", false},
+		{OperatorGreaterEqual, "10", "10", true},
+		{OperatorLess, "-1.5", "0", true},
+		{OperatorLessEqual, "abc", "10", false},
+		{OperatorGreater, "2024-03-01", "2024-02-29", true},
+		{OperatorLess, "2024-03-01T10:30:00Z", "2024-03-01T11:00:00+02:00", false},
+		{OperatorBetween, "5", "1,10", true},
+		{OperatorBetween, "10", "[1, 10]", true},
+		{OperatorBetween, "11", "1,10", false},
+		{OperatorBetween, "2024-06-15", "2024-01-01,2024-12-31", true},
+		{OperatorIn, "gold", "silver, gold", true},
+		{OperatorIn, "Gold", `["silver","gold"]`, false},
+		{"in_ci", "Gold", `["silver","gold"]`, true},
+		{OperatorIn, "2", "[1, 2, 3]", true},
+		{OperatorNotIn, "bronze", "silver,gold", true},
+		{OperatorNotIn, "gold", "silver,gold", false},
+		{"unknown", "abc", "abc", false},
+		{"gt_ci", "2", "1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator+" "+tt.actual+" "+tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.result, matchRuleValue(tt.operator, tt.actual, tt.expected))
+		})
+	}
+}
+
+func TestMatchesRules_Existence(t *testing.T) {
+	req := createTestRequest("GET", "/items", map[string]string{"X-Empty": "", "X-Trace": "1"}, map[string]string{"debug": ""})
+
+	tests := []struct {
+		name     string
+		rule     database.MockRule
+		expected bool
+	}{
+		{name: "header exists", rule: database.MockRule{Type: "header", Key: "x-trace", Operator: OperatorExists}, expected: true},
+		{name: "empty header exists", rule: database.MockRule{Type: "header", Key: "X-Empty", Operator: OperatorExists}, expected: true},
+		{name: "header not exists", rule: database.MockRule{Type: "header", Key: "Authorization", Operator: OperatorNotExists}, expected: true},
+		{name: "empty query exists", rule: database.MockRule{Type: "query", Key: "debug", Operator: OperatorExists}, expected: true},
+		{name: "query not exists", rule: database.MockRule{Type: "query", Key: "debug", Operator: OperatorNotExists}, expected: false},
+		{name: "missing header not_equals", rule: database.MockRule{Type: "header", Key: "X-Role", Operator: OperatorNotEquals, Value: "admin"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := database.MockResponse{RulesLogic: "and", Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, req))
+		})
+	}
+}
+
+func TestMatchesRules_BodyOperators(t *testing.T) {
+	body := `{"amount":250.5,"createdAt":"2024-05-01T08:00:00Z","status":"PAID","items":[{"qty":1},{"qty":4}]}`
+
+	tests := []struct {
+		name     string
+		rule     database.MockRule
+		expected bool
+	}{
+		{name: "number between", rule: database.MockRule{Type: "body", Key: "amount", Operator: OperatorBetween, Value: "100,500"}, expected: true},
+		{name: "date after", rule: database.MockRule{Type: "body", Key: "createdAt", Operator: OperatorGreaterEqual, Value: "2024-01-01"}, expected: true},
+		{name: "case-insensitive in", rule: database.MockRule{Type: "body", Key: "status", Operator: "in_ci", Value: "paid,refunded"}, expected: true},
+		{name: "all quantities above zero", rule: database.MockRule{Type: "body", Key: "items[*].qty", Operator: OperatorGreater, Value: "0", Match: "all"}, expected: true},
+		{name: "any quantity above three", rule: database.MockRule{Type: "body", Key: "items[*].qty", Operator: OperatorGreater, Value: "3"}, expected: true},
+		{name: "key exists", rule: database.MockRule{Type: "body", Key: "status", Operator: OperatorExists}, expected: true},
+		{name: "key not exists", rule: database.MockRule{Type: "body", Key: "refund", Operator: OperatorNotExists}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := database.MockResponse{RulesLogic: "and", Rules: []database.MockRule{tt.rule}}
+			assert.Equal(t, tt.expected, matchesRules(response, createTestRequestWithBody("POST", "/payments", body)))
+		})
+	}
+}
+
+func TestValidateRule_Operators(t *testing.T) {
+	valid := []database.MockRule{
+		{Type: "header", Key: "X-Id", Operator: "regex_ci", Value: `^[a-z]+$`},
+		{Type: "query", Key: "page", Operator: OperatorGreater, Value: "2"},
+		{Type: "body", Key: "date", Operator: OperatorBetween, Value: "2024-01-01,2024-12-31"},
+		{Type: "path", Key: "id", Operator: OperatorIn, Value: "[1,2]"},
+		{Type: "header", Key: "X-Id", Operator: OperatorExists},
+		{Type: "body", Key: "user", Operator: OperatorHasProperty},
+	}
+	for _, rule := range valid {
+		assert.NoError(t, ValidateRule(&rule), rule.Operator)
+	}
+
+	invalid := []database.MockRule{
+		{Type: "header", Key: "X-Id", Operator: "like", Value: "a"},
+		{Type: "cookie", Key: "session", Operator: OperatorEquals, Value: "a"},
+		{Type: "header", Key: "X-Id", Operator: OperatorRegex, Value: "("},
+		{Type: "query", Key: "page", Operator: OperatorGreater, Value: "many"},
+		{Type: "query", Key: "page", Operator: "gt_ci", Value: "1"},
+		{Type: "query", Key: "page", Operator: OperatorBetween, Value: "10,1"},
+		{Type: "query", Key: "page", Operator: OperatorBetween, Value: "1,2024-01-01"},
+		{Type: "query", Key: "page", Operator: OperatorIn, Value: " "},
+		{Type: "header", Key: "X-Id", Operator: OperatorHasProperty},
+	}
+	for _, rule := range invalid {
+		assert.ErrorIs(t, ValidateRule(&rule), ErrInvalidRule, rule.Operator+" "+rule.Value)
+	}
+}
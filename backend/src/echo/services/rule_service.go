@@ -1,12 +1,19 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/xpath"
 )
 
+// ErrInvalidRule is returned, wrapped, when rule data fails validation
+var ErrInvalidRule = errors.New("invalid rule")
+
 type ruleRepository interface {
 	FindRulesByResponseID(responseID string) ([]database.MockRule, error)
 	FindRuleByID(ruleID string) (*database.MockRule, error)
@@ -81,19 +88,23 @@ func (s *RuleService) ValidateRuleHierarchy(projectID string, endpointID string,
 func (s *RuleService) CreateRule(rule *database.MockRule) (*database.MockRule, error) {
 	// Validate rule data
 	if rule.Type == "" {
-		return nil, fmt.Errorf("rule type is required")
+		return nil, fmt.Errorf("%w: rule type is required", ErrInvalidRule)
 	}
 
 	if rule.Key == "" && rule.Type != "body" {
-		return nil, fmt.Errorf("rule key is required")
+		return nil, fmt.Errorf("%w: rule key is required", ErrInvalidRule)
 	}
 
 	if rule.Operator == "" {
-		return nil, fmt.Errorf("rule operator is required")
+		return nil, fmt.Errorf("%w: rule operator is required", ErrInvalidRule)
 	}
 
 	// Value can be empty (checking for absence of a header/query param)
 
+	if err := ValidateRule(rule); err != nil {
+		return nil, err
+	}
+
 	// Create rule
 	err := s.RuleRepo.CreateRule(rule)
 	if err != nil {
@@ -132,9 +143,17 @@ func (s *RuleService) UpdateRule(ruleID string, updates *database.MockRule) (*da
 		existingRule.Operator = updates.Operator
 	}
 
+	if updates.Match != "" {
+		existingRule.Match = updates.Match
+	}
+
 	// Value can be updated to empty string intentionally
 	existingRule.Value = updates.Value
 
+	if err := ValidateRule(existingRule); err != nil {
+		return nil, err
+	}
+
 	// Save updates
 	err = s.RuleRepo.UpdateRule(existingRule)
 	if err != nil {
@@ -173,3 +192,53 @@ func (s *RuleService) DeleteRulesByResponse(responseID string) error {
 
 	return nil
 }
+
+// ValidateRule checks the parts of a rule that are interpreted when matching
+// The type and operator must be known, operator values must parse (regex, numbers, dates, lists)
+// and body keys must be valid JSONPath or XPath expressions. GraphQL keys name a part of the operation
+// and JWT keys a claim.
+func ValidateRule(rule *database.MockRule) error {
+	if !ruleTypes[rule.Type] {
+		return fmt.Errorf("%w: unknown rule type %q", ErrInvalidRule, rule.Type)
+	}
+
+	if err := validateRuleOperator(rule.Type, rule.Operator, rule.Value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	// The body format is only known per request, so a key must be valid JSONPath or XPath;
+	// form and multipart field names are valid as one or the other
+	if rule.Type == "body" && rule.Key != "" {
+		if _, err := jsonpath.Compile(rule.Key); err != nil {
+			if _, xpathErr := xpath.Compile(rule.Key); xpathErr != nil {
+				return fmt.Errorf("%w: %v; as XPath: %v", ErrInvalidRule, err, xpathErr)
+			}
+		}
+	}
+
+	if rule.Type == "graphql" {
+		if err := validateGraphQLRuleKey(rule.Key); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	// JWT keys name a claim, or select claims with JSONPath
+	if rule.Type == "jwt" {
+		if rule.Key == "" {
+			return fmt.Errorf("%w: jwt rules need a claim name, e.g. \"sub\", or a JSONPath key", ErrInvalidRule)
+		}
+		if strings.HasPrefix(rule.Key, "$") {
+			if _, err := jsonpath.Compile(rule.Key); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+			}
+		}
+	}
+
+	switch strings.ToLower(rule.Match) {
+	case "", ruleMatchAny, ruleMatchAll:
+	default:
+		return fmt.Errorf("%w: match must be %q or %q", ErrInvalidRule, ruleMatchAny, ruleMatchAll)
+	}
+
+	return nil
+}
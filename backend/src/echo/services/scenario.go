@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"beo-echo/backend/src/database"
+)
+
+// ScenarioStatus is a scenario definition together with its current state
+type ScenarioStatus struct {
+	database.ScenarioConfig
+	CurrentState string `json:"currentState"`
+}
+
+// ScenarioTransition records the state a request saw for a scenario and where it moved it
+type ScenarioTransition struct {
+	Scenario string `json:"scenario"`
+	State    string `json:"state"`
+	NewState string `json:"new_state,omitempty"`
+}
+
+// Global state map for scenarios, keyed by "projectID/scenario"
+// States live in memory, so a restart returns every scenario to its initial state.
+var scenarioStates sync.Map
+
+// Per-project locks, keyed by project ID
+// They serialize read-then-transition so concurrent requests of a project see consistent states.
+var scenarioLocks sync.Map
+
+func scenarioKey(projectID, name string) string {
+	return projectID + "/" + name
+}
+
+// scenarioLock returns the lock guarding the scenario states of a project
+func scenarioLock(projectID string) *sync.Mutex {
+	val, _ := scenarioLocks.LoadOrStore(projectID, &sync.Mutex{})
+	return val.(*sync.Mutex)
+}
+
+// snapshotScenarioStates returns the current state of every scenario
+// Callers hold the project scenario lock.
+func snapshotScenarioStates(projectID string, scenarios []database.ScenarioConfig) []ScenarioTransition {
+	transitions := make([]ScenarioTransition, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		transitions = append(transitions, ScenarioTransition{Scenario: scenario.Name, State: currentScenarioState(projectID, scenario)})
+	}
+	return transitions
+}
+
+// recordScenarioStates stores the current scenario states of a project on the request meta
+// Requests that select a response overwrite them with the states seen during selection.
+func recordScenarioStates(ctx context.Context, project *database.Project) {
+	meta := RequestMetaFromContext(ctx)
+	if meta == nil {
+		return
+	}
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil || len(config.Scenarios) == 0 {
+		return
+	}
+
+	lock := scenarioLock(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
+	meta.ScenarioStates = snapshotScenarioStates(project.ID, config.Scenarios)
+}
+
+// currentScenarioState returns the state of a scenario, falling back to its initial state
+func currentScenarioState(projectID string, scenario database.ScenarioConfig) string {
+	if val, ok := scenarioStates.Load(scenarioKey(projectID, scenario.Name)); ok {
+		return val.(string)
+	}
+	return scenario.Initial()
+}
+
+// ListScenarios returns every scenario of a project with its current state
+func ListScenarios(project *database.Project) ([]ScenarioStatus, error) {
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]ScenarioStatus, 0, len(config.Scenarios))
+	for _, scenario := range config.Scenarios {
+		statuses = append(statuses, ScenarioStatus{
+			ScenarioConfig: scenario,
+			CurrentState:   currentScenarioState(project.ID, scenario),
+		})
+	}
+	return statuses, nil
+}
+
+// SetScenarioState moves a scenario to the given state
+func SetScenarioState(project *database.Project, name, state string) (*ScenarioStatus, error) {
+	scenario, err := findProjectScenario(project, name)
+	if err != nil {
+		return nil, err
+	}
+	if state == "" || !scenario.HasState(state) {
+		return nil, fmt.Errorf("state %q is not defined for scenario %s", state, name)
+	}
+
+	lock := scenarioLock(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
+	scenarioStates.Store(scenarioKey(project.ID, name), state)
+	return &ScenarioStatus{ScenarioConfig: scenario, CurrentState: state}, nil
+}
+
+// ResetScenario returns a scenario to its initial state
+func ResetScenario(project *database.Project, name string) (*ScenarioStatus, error) {
+	scenario, err := findProjectScenario(project, name)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := scenarioLock(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
+	scenarioStates.Delete(scenarioKey(project.ID, name))
+	return &ScenarioStatus{ScenarioConfig: scenario, CurrentState: scenario.Initial()}, nil
+}
+
+// ResetAllScenarios returns every scenario of a project to its initial state
+func ResetAllScenarios(project *database.Project) ([]ScenarioStatus, error) {
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := scenarioLock(project.ID)
+	lock.Lock()
+	for _, scenario := range config.Scenarios {
+		scenarioStates.Delete(scenarioKey(project.ID, scenario.Name))
+	}
+	lock.Unlock()
+
+	return ListScenarios(project)
+}
+
+// findProjectScenario looks up a scenario in the project advance config
+func findProjectScenario(project *database.Project, name string) (database.ScenarioConfig, error) {
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil {
+		return database.ScenarioConfig{}, err
+	}
+	scenario, ok := config.FindScenario(name)
+	if !ok {
+		return database.ScenarioConfig{}, fmt.Errorf("scenario %s not found", name)
+	}
+	return scenario, nil
+}
+
+// ValidateResponseScenario checks that a response references a defined scenario and states
+func ValidateResponseScenario(project *database.Project, response *database.MockResponse) error {
+	if response.Scenario == "" {
+		if response.RequiredState != "" || response.NewState != "" {
+			return fmt.Errorf("required_state and new_state need a scenario")
+		}
+		return nil
+	}
+
+	scenario, err := findProjectScenario(project, response.Scenario)
+	if err != nil {
+		return err
+	}
+	for _, state := range []string{response.RequiredState, response.NewState} {
+		if state != "" && !scenario.HasState(state) {
+			return fmt.Errorf("state %q is not defined for scenario %s", state, scenario.Name)
+		}
+	}
+	return nil
+}
+
+// selectResponse picks the response to serve for an endpoint
+// Responses waiting for another scenario state are skipped, then rules and the
+// response mode decide. The chosen response moves its scenario to NewState.
+func (s *MockService) selectResponse(project *database.Project, endpoint *database.MockEndpoint, responses []database.MockResponse, req *http.Request) (*database.MockResponse, error) {
+	config, err := database.ParseProjectAdvanceConfig(project.AdvanceConfig)
+	if err != nil || len(config.Scenarios) == 0 {
+		return selectResponseWithEndpoint(endpoint.ID, responses, endpoint.ResponseMode, req)
+	}
+
+	lock := scenarioLock(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Snapshot the state of every scenario of the project
+	transitions := snapshotScenarioStates(project.ID, config.Scenarios)
+	states := make(map[string]string, len(transitions))
+	for _, transition := range transitions {
+		states[transition.Scenario] = transition.State
+	}
+
+	candidates := make([]database.MockResponse, 0, len(responses))
+	for _, response := range responses {
+		if response.Scenario != "" && response.RequiredState != "" && states[response.Scenario] != response.RequiredState {
+			continue
+		}
+		candidates = append(candidates, response)
+	}
+
+	selected, selErr := selectResponseWithEndpoint(endpoint.ID, candidates, endpoint.ResponseMode, req)
+	if selErr == nil && selected != nil && selected.Scenario != "" && selected.NewState != "" {
+		if _, ok := states[selected.Scenario]; ok {
+			scenarioStates.Store(scenarioKey(project.ID, selected.Scenario), selected.NewState)
+			for i := range transitions {
+				if transitions[i].Scenario == selected.Scenario {
+					transitions[i].NewState = selected.NewState
+				}
+			}
+		}
+	}
+
+	if meta := RequestMetaFromContext(req.Context()); meta != nil {
+		meta.ScenarioStates = transitions
+	}
+
+	return selected, selErr
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+func TestSelectResponse_ScenarioFlow(t *testing.T) {
+	project := &database.Project{
+		ID:            uuid.New().String(),
+		AdvanceConfig: `{"scenarios":[{"name":"order","initialState":"Started","states":["Started","polled","polled_twice","shipped"]}]}`,
+	}
+	endpoint := &database.MockEndpoint{ID: uuid.New().String(), ResponseMode: "static"}
+	responses := []database.MockResponse{
+		{ID: "first", Body: "pending", Scenario: "order", RequiredState: "Started", NewState: "polled"},
+		{ID: "second", Body: "pending", Scenario: "order", RequiredState: "polled", NewState: "polled_twice"},
+		{ID: "third", Body: "shipped", Scenario: "order", RequiredState: "polled_twice", NewState: "shipped"},
+		{ID: "done", Body: "shipped", Scenario: "order", RequiredState: "shipped"},
+	}
+	service := &MockService{}
+	t.Cleanup(func() { ResetAllScenarios(project) })
+
+	poll := func() (*database.MockResponse, *RequestMeta) {
+		meta := &RequestMeta{}
+		req, _ := http.NewRequest("GET", "http://localhost/orders/1", nil)
+		req = req.WithContext(ContextWithRequestMeta(req.Context(), meta))
+		selected, err := service.selectResponse(project, endpoint, responses, req)
+		require.NoError(t, err)
+		require.NotNil(t, selected)
+		return selected, meta
+	}
+
+	expected := []struct {
+		id       string
+		state    string
+		newState string
+	}{
+		{id: "first", state: "Started", newState: "polled"},
+		{id: "second", state: "polled", newState: "polled_twice"},
+		{id: "third", state: "polled_twice", newState: "shipped"},
+		{id: "done", state: "shipped"},
+		{id: "done", state: "shipped"},
+	}
+	for _, step := range expected {
+		selected, meta := poll()
+		assert.Equal(t, step.id, selected.ID)
+		assert.Equal(t, []ScenarioTransition{{Scenario: "order", State: step.state, NewState: step.newState}}, meta.ScenarioStates)
+	}
+
+	// Reset returns to the first step
+	_, err := ResetScenario(project, "order")
+	require.NoError(t, err)
+	selected, _ := poll()
+	assert.Equal(t, "first", selected.ID)
+
+	// States can be set explicitly
+	_, err = SetScenarioState(project, "order", "shipped")
+	require.NoError(t, err)
+	selected, _ = poll()
+	assert.Equal(t, "done", selected.ID)
+
+	_, err = SetScenarioState(project, "order", "lost")
+	assert.Error(t, err)
+	_, err = SetScenarioState(project, "missing", "Started")
+	assert.Error(t, err)
+
+	statuses, err := ListScenarios(project)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "shipped", statuses[0].CurrentState)
+}
+
+func TestSelectResponse_RecordsAllScenarios(t *testing.T) {
+	project := &database.Project{
+		ID:            uuid.New().String(),
+		AdvanceConfig: `{"scenarios":[{"name":"order","states":["shipped"]},{"name":"payment","initialState":"unpaid","states":["unpaid","paid"]}]}`,
+	}
+	t.Cleanup(func() { ResetAllScenarios(project) })
+	_, err := SetScenarioState(project, "payment", "paid")
+	require.NoError(t, err)
+
+	// Endpoints without scenario responses still log the project states
+	endpoint := &database.MockEndpoint{ID: uuid.New().String(), ResponseMode: "static"}
+	meta := &RequestMeta{}
+	req, _ := http.NewRequest("GET", "http://localhost/health", nil)
+	req = req.WithContext(ContextWithRequestMeta(req.Context(), meta))
+	_, err = (&MockService{}).selectResponse(project, endpoint, []database.MockResponse{{ID: "only"}}, req)
+	require.NoError(t, err)
+	expected := []ScenarioTransition{{Scenario: "order", State: "Started"}, {Scenario: "payment", State: "paid"}}
+	assert.Equal(t, expected, meta.ScenarioStates)
+
+	// So do requests that never select a response
+	meta = &RequestMeta{}
+	recordScenarioStates(ContextWithRequestMeta(context.Background(), meta), project)
+	assert.Equal(t, expected, meta.ScenarioStates)
+
+	meta = &RequestMeta{}
+	recordScenarioStates(ContextWithRequestMeta(context.Background(), meta), &database.Project{ID: uuid.New().String()})
+	assert.Empty(t, meta.ScenarioStates)
+}
+
+func TestScenarioLock_PerProject(t *testing.T) {
+	first, second := uuid.New().String(), uuid.New().String()
+	assert.Same(t, scenarioLock(first), scenarioLock(first))
+	assert.NotSame(t, scenarioLock(first), scenarioLock(second))
+
+	// A busy project does not block another one
+	scenarioLock(first).Lock()
+	defer scenarioLock(first).Unlock()
+	assert.True(t, scenarioLock(second).TryLock())
+	scenarioLock(second).Unlock()
+}
+
+func TestSelectResponse_WithoutScenarios(t *testing.T) {
+	project := &database.Project{ID: uuid.New().String()}
+	endpoint := &database.MockEndpoint{ID: uuid.New().String(), ResponseMode: "static"}
+	responses := []database.MockResponse{{ID: "only", Priority: 1}}
+
+	req, _ := http.NewRequest("GET", "http://localhost/", nil)
+	selected, err := (&MockService{}).selectResponse(project, endpoint, responses, req)
+	require.NoError(t, err)
+	assert.Equal(t, "only", selected.ID)
+}
+
+func TestValidateResponseScenario(t *testing.T) {
+	project := &database.Project{
+		AdvanceConfig: `{"scenarios":[{"name":"order","states":["pending","shipped"]},{"name":"free"}]}`,
+	}
+
+	tests := []struct {
+		name      string
+		response  database.MockResponse
+		expectErr bool
+	}{
+		{name: "no scenario", response: database.MockResponse{}},
+		{name: "known states", response: database.MockResponse{Scenario: "order", RequiredState: "pending", NewState: "shipped"}},
+		{name: "initial state is always allowed", response: database.MockResponse{Scenario: "order", RequiredState: "Started"}},
+		{name: "scenario without declared states", response: database.MockResponse{Scenario: "free", NewState: "anything"}},
+		{name: "unknown state", response: database.MockResponse{Scenario: "order", NewState: "lost"}, expectErr: true},
+		{name: "unknown scenario", response: database.MockResponse{Scenario: "payment"}, expectErr: true},
+		{name: "state without scenario", response: database.MockResponse{RequiredState: "pending"}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateResponseScenario(project, &tt.response)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
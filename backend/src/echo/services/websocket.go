@@ -0,0 +1,633 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/jsonpath"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+// Limits of the frames kept for the log of a WebSocket session
+const (
+	maxLoggedWebSocketFrames     = 1000
+	maxLoggedWebSocketFrameBytes = 64 << 10
+
+	minWebSocketPushIntervalMs = 10
+	webSocketWriteTimeout      = 10 * time.Second
+	webSocketCloseTimeout      = 5 * time.Second
+)
+
+// WebSocket frame directions, seen from Beo Echo
+const (
+	WebSocketFrameIn  = "in"  // Sent by the client
+	WebSocketFrameOut = "out" // Sent to the client, by the script or the upstream
+)
+
+// WebSocketMessage is a message sent by a WebSocket script
+type WebSocketMessage struct {
+	Data    json.RawMessage `json:"data"`               // String, or any JSON value which is sent compacted
+	Binary  bool            `json:"binary,omitempty"`   // Data is base64 and sent as a binary frame
+	DelayMs int             `json:"delay_ms,omitempty"` // Delay before this message is sent
+}
+
+// WebSocketMatch selects the client messages a reply answers
+type WebSocketMatch struct {
+	Path     string `json:"path,omitempty"`     // JSONPath into a JSON message, empty matches the whole message text
+	Operator string `json:"operator,omitempty"` // Rule operator, "equals" when omitted
+	Value    string `json:"value,omitempty"`
+}
+
+// WebSocketReply sends messages when a client message matches
+type WebSocketReply struct {
+	Match    *WebSocketMatch    `json:"match,omitempty"` // Answers every message when omitted
+	Messages []WebSocketMessage `json:"messages,omitempty"`
+	Close    bool               `json:"close,omitempty"` // Close the connection once the messages are sent
+}
+
+// WebSocketPush is a message the server sends on its own, once or at an interval
+type WebSocketPush struct {
+	WebSocketMessage
+	IntervalMs int `json:"interval_ms,omitempty"` // Time between repeats
+	Count      int `json:"count,omitempty"`       // Times the message is sent; 0 with an interval repeats until the connection closes
+}
+
+// WebSocketScript describes the body of a response of a websocket endpoint
+//
+//	{
+//	  "subprotocols": ["chat"],
+//	  "on_connect": [{"data": {"type": "welcome"}}],
+//	  "replies": [{"match": {"path": "$.type", "value": "ping"}, "messages": [{"data": {"type": "pong"}}]}],
+//	  "pushes": [{"data": {"type": "tick"}, "interval_ms": 1000}],
+//	  "close_after_ms": 60000
+//	}
+//
+// Message data of templated responses can use response templates, with the client message
+// a reply answers available as {{.message.text}} and, for JSON messages, {{.message.json}}.
+type WebSocketScript struct {
+	Subprotocols []string           `json:"subprotocols,omitempty"` // Subprotocols accepted, in order of preference
+	OnConnect    []WebSocketMessage `json:"on_connect,omitempty"`   // Sent once the connection is open
+	Replies      []WebSocketReply   `json:"replies,omitempty"`      // The first matching reply answers a client message
+	Pushes       []WebSocketPush    `json:"pushes,omitempty"`
+	CloseAfterMs int                `json:"close_after_ms,omitempty"` // Close the connection after this time, 0 keeps it open
+
+	templated bool // Message data is rendered as templates
+}
+
+// ParseWebSocketScript parses and checks the script of a websocket response
+// An empty body is a script that accepts the connection and sends nothing.
+// With templated, message data holding template markers must be valid templates.
+func ParseWebSocketScript(body string, templated bool) (*WebSocketScript, error) {
+	script := &WebSocketScript{templated: templated}
+	if trimmed := strings.TrimSpace(body); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), script); err != nil {
+			return nil, fmt.Errorf("invalid websocket script: %w", err)
+		}
+	}
+
+	if script.CloseAfterMs < 0 {
+		return nil, fmt.Errorf("close_after_ms must not be negative")
+	}
+	for i, message := range script.OnConnect {
+		if err := message.validate(templated); err != nil {
+			return nil, fmt.Errorf("on_connect %d: %w", i, err)
+		}
+	}
+	for i, reply := range script.Replies {
+		if err := reply.validate(templated); err != nil {
+			return nil, fmt.Errorf("reply %d: %w", i, err)
+		}
+	}
+	for i, push := range script.Pushes {
+		if err := push.validate(templated); err != nil {
+			return nil, fmt.Errorf("push %d: %w", i, err)
+		}
+	}
+	return script, nil
+}
+
+// AcceptsWebSocket reports whether a response of a websocket endpoint accepts the connection
+// Responses with a 3xx, 4xx or 5xx status reject the handshake with a plain HTTP response.
+func AcceptsWebSocket(response database.MockResponse) bool {
+	return response.StatusCode < http.StatusMultipleChoices
+}
+
+// ValidateWebSocketResponse checks a response of a websocket endpoint before it is saved
+func ValidateWebSocketResponse(response database.MockResponse) error {
+	if !AcceptsWebSocket(response) {
+		return nil
+	}
+	if response.Stream {
+		return fmt.Errorf("stream is not supported on responses that accept a websocket connection")
+	}
+	_, err := ParseWebSocketScript(response.Body, response.Templated)
+	return err
+}
+
+func (m WebSocketMessage) validate(templated bool) error {
+	if m.DelayMs < 0 {
+		return fmt.Errorf("delay_ms must not be negative")
+	}
+	payload := dataPayload(m.Data)
+	if templated && isTemplate(payload) {
+		if _, err := parseResponseTemplate("message", payload, nil); err != nil {
+			return fmt.Errorf("invalid data template: %w", err)
+		}
+		return nil
+	}
+	if m.Binary {
+		if _, err := base64.StdEncoding.DecodeString(payload); err != nil {
+			return fmt.Errorf("binary data must be base64: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r WebSocketReply) validate(templated bool) error {
+	if len(r.Messages) == 0 && !r.Close {
+		return fmt.Errorf("a reply needs messages or close")
+	}
+	if r.Match != nil {
+		if r.Match.Path != "" {
+			if _, err := jsonpath.Compile(r.Match.Path); err != nil {
+				return fmt.Errorf("invalid match path: %w", err)
+			}
+		}
+		if err := validateRuleOperator("body", r.Match.operator(), r.Match.Value); err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+	}
+	for i, message := range r.Messages {
+		if err := message.validate(templated); err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (p WebSocketPush) validate(templated bool) error {
+	if p.IntervalMs < 0 || p.Count < 0 {
+		return fmt.Errorf("interval_ms and count must not be negative")
+	}
+	repeats := p.Count > 1 || (p.Count == 0 && p.IntervalMs > 0)
+	if repeats && p.IntervalMs < minWebSocketPushIntervalMs {
+		return fmt.Errorf("repeated pushes need an interval_ms of at least %d", minWebSocketPushIntervalMs)
+	}
+	return p.WebSocketMessage.validate(templated)
+}
+
+func (m *WebSocketMatch) operator() string {
+	if m.Operator == "" {
+		return OperatorEquals
+	}
+	return m.Operator
+}
+
+// matches reports whether a client message matches
+// parsed is the decoded JSON of the message, nil when it is not JSON.
+func (m *WebSocketMatch) matches(text string, parsed interface{}) bool {
+	operator := m.operator()
+	if m.Path == "" {
+		if isPresenceOperator(operator) {
+			return matchPresence(operator, true)
+		}
+		return matchRuleValue(operator, text, m.Value)
+	}
+
+	var values []interface{}
+	if parsed != nil {
+		values, _ = jsonpath.Find(parsed, m.Path)
+	}
+	if isPresenceOperator(operator) {
+		return matchPresence(operator, len(values) > 0)
+	}
+	for _, value := range values {
+		if matchRuleValueTyped(operator, value, m.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// reply returns the first reply matching a client message, or nil
+func (s *WebSocketScript) reply(text string, parsed interface{}) *WebSocketReply {
+	for i := range s.Replies {
+		if match := s.Replies[i].Match; match == nil || match.matches(text, parsed) {
+			return &s.Replies[i]
+		}
+	}
+	return nil
+}
+
+// WebSocketFrame is a frame of a WebSocket session, as stored in the request log
+type WebSocketFrame struct {
+	Direction string    `json:"direction"`           // "in" from the client, "out" to the client
+	Type      string    `json:"type"`                // "text", "binary", "close", or "dropped" for frames left out of the log
+	Data      string    `json:"data,omitempty"`      // Text, base64 for binary frames, the reason of close frames
+	Code      int       `json:"code,omitempty"`      // Close code of close frames
+	Size      int       `json:"size"`                // Size of the data in bytes, or the number of dropped frames
+	Truncated bool      `json:"truncated,omitempty"` // Data was cut to the logged size
+	Time      time.Time `json:"time"`
+}
+
+// WebSocketSession is a WebSocket connection the mock service prepared for a request.
+// The mock handler serves it by upgrading the client connection, and the request
+// logger stores the frames it exchanged.
+type WebSocketSession struct {
+	upgrader websocket.Upgrader
+	header   http.Header                                           // Headers of the 101 response
+	run      func(session *WebSocketSession, conn *websocket.Conn) // Runs the upgraded connection until it closes
+	release  func()                                                // Frees what the session holds when it is not served
+
+	mu       sync.Mutex
+	upgraded bool
+	frames   []WebSocketFrame
+	dropped  int
+}
+
+func newWebSocketSession(subprotocols []string, header http.Header, run func(*WebSocketSession, *websocket.Conn), release func()) *WebSocketSession {
+	return &WebSocketSession{
+		upgrader: websocket.Upgrader{
+			Subprotocols: subprotocols,
+			// Mocks accept connections from any page, like they accept any HTTP request
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		header:  header,
+		run:     run,
+		release: release,
+	}
+}
+
+// Serve upgrades the client connection and runs the session until the connection closes
+// When the upgrade fails the error response has already been written.
+func (s *WebSocketSession) Serve(w http.ResponseWriter, r *http.Request) error {
+	conn, err := s.upgrader.Upgrade(w, r, s.header)
+	if err != nil {
+		s.Close()
+		return err
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.upgraded = true
+	s.mu.Unlock()
+
+	s.run(s, conn)
+	return nil
+}
+
+// Close releases a session that is not going to be served
+func (s *WebSocketSession) Close() {
+	if s.release != nil {
+		s.release()
+	}
+}
+
+// Upgraded reports whether the client connection was upgraded
+func (s *WebSocketSession) Upgraded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.upgraded
+}
+
+// Frames returns the frames recorded so far
+func (s *WebSocketSession) Frames() []WebSocketFrame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	frames := append([]WebSocketFrame(nil), s.frames...)
+	if s.dropped > 0 {
+		frames = append(frames, WebSocketFrame{Type: "dropped", Size: s.dropped, Time: time.Now()})
+	}
+	return frames
+}
+
+// FramesJSON returns the recorded frames as stored in the request log
+func (s *WebSocketSession) FramesJSON() string {
+	frames := s.Frames()
+	if len(frames) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(frames)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// record stores a data frame for the log
+func (s *WebSocketSession) record(direction string, messageType int, data []byte) {
+	frame := WebSocketFrame{Direction: direction, Type: "text", Size: len(data), Time: time.Now()}
+	if len(data) > maxLoggedWebSocketFrameBytes {
+		data = data[:maxLoggedWebSocketFrameBytes]
+		frame.Truncated = true
+	}
+	if messageType == websocket.BinaryMessage {
+		frame.Type = "binary"
+		frame.Data = base64.StdEncoding.EncodeToString(data)
+	} else {
+		frame.Data = string(trimIncompleteRune(data))
+	}
+	s.add(frame)
+}
+
+// recordClose stores a close frame for the log
+func (s *WebSocketSession) recordClose(direction string, code int, reason string) {
+	s.add(WebSocketFrame{Direction: direction, Type: "close", Code: code, Data: reason, Size: len(reason), Time: time.Now()})
+}
+
+func (s *WebSocketSession) add(frame WebSocketFrame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.frames) >= maxLoggedWebSocketFrames {
+		s.dropped++
+		return
+	}
+	s.frames = append(s.frames, frame)
+}
+
+// trimIncompleteRune drops a UTF-8 character cut in half at the end of data
+func trimIncompleteRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// handleWebSocketEndpoint answers a request to a websocket endpoint. The selected response either
+// rejects the handshake with its HTTP status, or holds the script the connection plays, which is
+// handed to the mock handler through the request meta. Returns nil when the endpoint has no
+// response for the request.
+func (s *MockService) handleWebSocketEndpoint(ctx context.Context, project *database.Project, endpoint *database.MockEndpoint, responses []database.MockResponse, path string, req *http.Request) *http.Response {
+	if !websocket.IsWebSocketUpgrade(req) {
+		return createErrorResponse(http.StatusUpgradeRequired, "WebSocket endpoint: the request must be a WebSocket upgrade")
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+
+	response, ruleErr := s.selectResponse(project, endpoint, responses, req)
+	if ruleErr != nil {
+		return ruleMismatchResponse(endpoint, ruleErr)
+	}
+	if response == nil {
+		return nil
+	}
+
+	s.applyDelay(ctx, project, endpoint, response)
+
+	if !AcceptsWebSocket(*response) {
+		resp, err := s.buildResponse(*response, path, req)
+		if err != nil {
+			return createErrorResponse(http.StatusInternalServerError, err.Error())
+		}
+		return resp
+	}
+
+	script, err := ParseWebSocketScript(response.Body, response.Templated)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+
+	meta := RequestMetaFromContext(ctx)
+	if meta == nil {
+		return createErrorResponse(http.StatusInternalServerError, "WebSocket sessions need the request meta")
+	}
+
+	tctx := NewTemplateContext(req, path, pathParamsFromRequest(req))
+	header := http.Header{}
+	if headers, err := repositories.ParseHeaders(response.Headers); err == nil {
+		for key, value := range headers {
+			if response.Templated {
+				if value, err = renderTemplate("header:"+key, value, tctx); err != nil {
+					return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("failed to render header %q template: %s", key, err.Error()))
+				}
+			}
+			header.Set(key, value)
+		}
+	}
+	header = webSocketResponseHeader(header)
+
+	meta.WebSocket = newWebSocketSession(script.Subprotocols, header, func(session *WebSocketSession, conn *websocket.Conn) {
+		newWebSocketMock(script, tctx, session, conn).run()
+	}, nil)
+	return webSocketUpgradeResponse(header)
+}
+
+// webSocketUpgradeResponse stands for the 101 response the session sends once served
+func webSocketUpgradeResponse(header http.Header) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusSwitchingProtocols,
+		Header:     header.Clone(),
+		Body:       http.NoBody,
+	}
+}
+
+// webSocketResponseHeader returns the headers that can be added to a 101 response
+// The handshake headers are set by the upgrade itself.
+func webSocketResponseHeader(header http.Header) http.Header {
+	out := http.Header{}
+	for key, values := range header {
+		canonical := http.CanonicalHeaderKey(key)
+		if isHopByHopHeader(canonical) || strings.HasPrefix(canonical, "Sec-Websocket-") || canonical == "Content-Length" {
+			continue
+		}
+		out[canonical] = values
+	}
+	return out
+}
+
+// isHopByHopHeader reports whether a header only applies to a single connection
+func isHopByHopHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Connection", "Upgrade", "Keep-Alive", "Te", "Trailer", "Transfer-Encoding", "Proxy-Connection", "Proxy-Authorization", "Proxy-Authenticate":
+		return true
+	}
+	return false
+}
+
+// webSocketMock plays a script on an upgraded connection
+type webSocketMock struct {
+	script  *WebSocketScript
+	tctx    *TemplateContext
+	session *WebSocketSession
+	conn    *websocket.Conn
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	writeMu   sync.Mutex // The connection allows a single writer at a time
+	closeOnce sync.Once
+}
+
+func newWebSocketMock(script *WebSocketScript, tctx *TemplateContext, session *WebSocketSession, conn *websocket.Conn) *webSocketMock {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &webSocketMock{script: script, tctx: tctx, session: session, conn: conn, ctx: ctx, cancel: cancel}
+}
+
+// run plays the script until the connection closes
+func (m *webSocketMock) run() {
+	defer m.wg.Wait()
+	defer m.cancel()
+
+	m.spawn(func() { m.sendAll(m.script.OnConnect, nil, false) })
+	for _, push := range m.script.Pushes {
+		m.spawn(func() { m.push(push) })
+	}
+	if m.script.CloseAfterMs > 0 {
+		m.spawn(func() {
+			if m.wait(m.script.CloseAfterMs) {
+				m.close(websocket.CloseNormalClosure, "")
+			}
+		})
+	}
+
+	for {
+		messageType, data, err := m.conn.ReadMessage()
+		if err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				m.session.recordClose(WebSocketFrameIn, closeErr.Code, closeErr.Text)
+			}
+			return
+		}
+		m.session.record(WebSocketFrameIn, messageType, data)
+
+		text := string(data)
+		var parsed interface{}
+		if messageType == websocket.TextMessage {
+			if err := json.Unmarshal(data, &parsed); err != nil {
+				parsed = nil
+			}
+		}
+
+		if reply := m.script.reply(text, parsed); reply != nil {
+			message := map[string]interface{}{"text": text, "json": parsed}
+			m.spawn(func() { m.sendAll(reply.Messages, message, reply.Close) })
+		}
+	}
+}
+
+func (m *webSocketMock) spawn(fn func()) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		fn()
+	}()
+}
+
+// sendAll sends messages in order, then closes the connection when asked to
+func (m *webSocketMock) sendAll(messages []WebSocketMessage, incoming map[string]interface{}, close bool) {
+	for _, message := range messages {
+		if !m.wait(message.DelayMs) || !m.send(message, incoming) {
+			return
+		}
+	}
+	if close {
+		m.close(websocket.CloseNormalClosure, "")
+	}
+}
+
+// push sends a push message at its interval until its count is reached
+func (m *webSocketMock) push(push WebSocketPush) {
+	count := push.Count
+	if count == 0 && push.IntervalMs == 0 {
+		count = 1
+	}
+
+	delay := push.DelayMs
+	for i := 0; count == 0 || i < count; i++ {
+		if i > 0 {
+			delay = push.IntervalMs
+		}
+		if !m.wait(delay) || !m.send(push.WebSocketMessage, nil) {
+			return
+		}
+	}
+}
+
+// wait sleeps for delayMs, returning false when the connection closed meanwhile
+func (m *webSocketMock) wait(delayMs int) bool {
+	if delayMs <= 0 {
+		return m.ctx.Err() == nil
+	}
+	timer := time.NewTimer(time.Duration(delayMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-m.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// send renders and writes a message, returning false when the connection is gone
+func (m *webSocketMock) send(message WebSocketMessage, incoming map[string]interface{}) bool {
+	payload := dataPayload(message.Data)
+	if m.script.templated && isTemplate(payload) {
+		tctx := *m.tctx
+		tctx.Message = incoming
+		rendered, err := renderTemplate("message", payload, &tctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to render websocket message template")
+			return true
+		}
+		payload = rendered
+	}
+
+	messageType := websocket.TextMessage
+	data := []byte(payload)
+	if message.Binary {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			log.Warn().Err(err).Msg("WebSocket binary message is not base64")
+			return true
+		}
+		messageType = websocket.BinaryMessage
+		data = decoded
+	}
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	if m.ctx.Err() != nil {
+		return false
+	}
+	m.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	if err := m.conn.WriteMessage(messageType, data); err != nil {
+		m.cancel()
+		return false
+	}
+	m.session.record(WebSocketFrameOut, messageType, data)
+	return true
+}
+
+// close sends a close frame and stops the script. The read loop ends once the client
+// answers the close, or after a timeout.
+func (m *webSocketMock) close(code int, reason string) {
+	m.closeOnce.Do(func() {
+		m.writeMu.Lock()
+		m.cancel()
+		err := m.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(webSocketCloseTimeout))
+		m.writeMu.Unlock()
+
+		if err == nil {
+			m.session.recordClose(WebSocketFrameOut, code, reason)
+		}
+		m.conn.SetReadDeadline(time.Now().Add(webSocketCloseTimeout))
+	})
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"beo-echo/backend/src/database"
+)
+
+// executeWebSocketProxy opens the WebSocket connection to target for an upgrade request.
+// Once the upstream accepted it, the tunnel between the client and the upstream is handed
+// to the mock handler through the request meta and the upstream 101 response is returned.
+// When the upstream refuses the upgrade its HTTP response is returned instead.
+func executeWebSocketProxy(ctx context.Context, target *database.ProxyTarget, rewrite *database.ProxyRewriteConfig, pathStr, queryString string, req *http.Request) (*http.Response, error) {
+	meta := RequestMetaFromContext(ctx)
+	if meta == nil {
+		return createErrorResponse(http.StatusInternalServerError, "WebSocket sessions need the request meta"), nil
+	}
+
+	targetURL, err := url.Parse(target.URL)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Invalid proxy URL: %s", err.Error())), nil
+	}
+
+	config, err := database.ParseProxyTransport(target.Transport)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Invalid proxy transport: %s", err.Error())), nil
+	}
+	dialer, err := newWebSocketDialer(config)
+	if err != nil {
+		return createErrorResponse(http.StatusInternalServerError, fmt.Sprintf("Invalid proxy transport: %s", err.Error())), nil
+	}
+
+	if rewrite != nil {
+		pathStr = rewriteProxyPath(rewrite.Path, pathStr)
+	}
+
+	forwardURL := *targetURL
+	forwardURL.Scheme = "ws"
+	if targetURL.Scheme == "https" || targetURL.Scheme == "wss" {
+		forwardURL.Scheme = "wss"
+	}
+	forwardURL.Path = path.Join(forwardURL.Path, pathStr)
+	forwardURL.RawQuery = queryString
+
+	// The handshake headers are generated by the dialer, the subprotocols are passed on as is
+	header := http.Header{}
+	for key, values := range req.Header {
+		if key == "Referer" || key == "Content-Length" || isHopByHopHeader(key) ||
+			(strings.HasPrefix(key, "Sec-Websocket-") && key != "Sec-Websocket-Protocol") {
+			continue
+		}
+		header[key] = append([]string(nil), values...)
+	}
+
+	host := targetURL.Host
+	if rewrite != nil {
+		if rewrite.PreserveHost && req.Host != "" {
+			host = req.Host
+		}
+		rewriteRequestHeaders(rewrite.RequestHeaders, header)
+	}
+	header.Set("Host", host)
+	header.Set("beo-echo-loop-detect", "true")
+
+	startTime := time.Now()
+	upstream, resp, err := dialer.DialContext(ctx, forwardURL.String(), header)
+	if err != nil {
+		if resp == nil {
+			return createErrorResponse(http.StatusBadGateway, fmt.Sprintf("WebSocket error: %s", err.Error())), nil
+		}
+		if upstream != nil {
+			upstream.Close()
+		}
+	}
+
+	resp.Header.Set("beo-echo-latency-ms", fmt.Sprintf("%d", time.Since(startTime).Milliseconds()))
+	rewriteProxyResponse(rewrite, resp)
+
+	// The upstream refused the upgrade, its response is passed to the client
+	if err != nil {
+		return resp, nil
+	}
+
+	var subprotocols []string
+	if subprotocol := upstream.Subprotocol(); subprotocol != "" {
+		subprotocols = []string{subprotocol}
+	}
+	meta.WebSocket = newWebSocketSession(subprotocols, webSocketResponseHeader(resp.Header), func(session *WebSocketSession, client *websocket.Conn) {
+		tunnelWebSocket(session, client, upstream)
+	}, func() {
+		upstream.Close()
+	})
+	return resp, nil
+}
+
+// newWebSocketDialer builds the dialer reaching the upstream of a proxy target
+// from its transport config
+func newWebSocketDialer(config *database.ProxyTransportConfig) (*websocket.Dialer, error) {
+	tlsConfig, err := proxyTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	netDialer := &net.Dialer{
+		Timeout:   config.ConnectTimeout(),
+		KeepAlive: 30 * time.Second,
+	}
+
+	dialer := &websocket.Dialer{
+		NetDialContext:   netDialer.DialContext,
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: config.ConnectTimeout() + config.ReadTimeout(),
+	}
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		dialer.Proxy = http.ProxyURL(proxyURL)
+	}
+	return dialer, nil
+}
+
+// tunnelWebSocket relays messages between the client and the upstream until either side
+// closes, recording every frame in the session
+func tunnelWebSocket(session *WebSocketSession, client, upstream *websocket.Conn) {
+	defer upstream.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		relayWebSocket(session, client, upstream, WebSocketFrameIn)
+		done <- struct{}{}
+	}()
+	go func() {
+		relayWebSocket(session, upstream, client, WebSocketFrameOut)
+		done <- struct{}{}
+	}()
+
+	// Once one side is gone, closing both connections ends the other relay
+	<-done
+	client.Close()
+	upstream.Close()
+	<-done
+}
+
+// relayWebSocket copies messages from src to dst, passing on the close frame that ends src
+func relayWebSocket(session *WebSocketSession, src, dst *websocket.Conn, direction string) {
+	for {
+		messageType, data, err := src.ReadMessage()
+		if err != nil {
+			if closeErr, ok := err.(*websocket.CloseError); ok {
+				session.recordClose(direction, closeErr.Code, closeErr.Text)
+				dst.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeErr.Code, closeErr.Text), time.Now().Add(webSocketCloseTimeout))
+			}
+			return
+		}
+		session.record(direction, messageType, data)
+
+		dst.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+		if err := dst.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
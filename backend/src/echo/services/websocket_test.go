@@ -0,0 +1,321 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/actions"
+	"beo-echo/backend/src/actions/modules"
+	"beo-echo/backend/src/database"
+	"beo-echo/backend/src/echo/repositories"
+)
+
+func TestParseWebSocketScript(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		templated bool
+		expectErr string
+	}{
+		{name: "empty body", body: ""},
+		{name: "full script", body: `{"subprotocols":["chat"],"on_connect":[{"data":{"type":"hi"}}],"replies":[{"match":{"path":"$.type","value":"ping"},"messages":[{"data":"pong"}]}],"pushes":[{"data":"tick","interval_ms":100}],"close_after_ms":1000}`},
+		{name: "reply that only closes", body: `{"replies":[{"match":{"value":"bye"},"close":true}]}`},
+		{name: "templated message", body: `{"replies":[{"messages":[{"data":"echo {{.message.text}}"}]}]}`, templated: true},
+		{name: "binary message", body: `{"on_connect":[{"data":"AAEC","binary":true}]}`},
+		{name: "invalid JSON", body: `{"replies":`, expectErr: "invalid websocket script"},
+		{name: "negative delay", body: `{"on_connect":[{"data":"hi","delay_ms":-1}]}`, expectErr: "on_connect 0: delay_ms must not be negative"},
+		{name: "reply without messages", body: `{"replies":[{"match":{"value":"x"}}]}`, expectErr: "a reply needs messages or close"},
+		{name: "unknown operator", body: `{"replies":[{"match":{"operator":"like","value":"x"},"close":true}]}`, expectErr: "unknown operator"},
+		{name: "invalid path", body: `{"replies":[{"match":{"path":"$[","value":"x"},"close":true}]}`, expectErr: "invalid match path"},
+		{name: "repeated push without interval", body: `{"pushes":[{"data":"tick","count":5}]}`, expectErr: "interval_ms of at least 10"},
+		{name: "binary data not base64", body: `{"on_connect":[{"data":"not base64!","binary":true}]}`, expectErr: "binary data must be base64"},
+		{name: "invalid template", body: `{"on_connect":[{"data":"{{.message"}]}`, templated: true, expectErr: "invalid data template"},
+		{name: "braces in a message that is not templated", body: `{"on_connect":[{"data":"{{.message"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := ParseWebSocketScript(tt.body, tt.templated)
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, script)
+		})
+	}
+}
+
+func TestWebSocketMatch(t *testing.T) {
+	parsed := map[string]interface{}{"type": "subscribe", "channel": "prices", "qty": float64(5)}
+
+	tests := []struct {
+		name   string
+		match  WebSocketMatch
+		text   string
+		parsed interface{}
+		expect bool
+	}{
+		{name: "whole text equals", match: WebSocketMatch{Value: "ping"}, text: "ping", expect: true},
+		{name: "whole text differs", match: WebSocketMatch{Value: "ping"}, text: "pong", expect: false},
+		{name: "whole text contains", match: WebSocketMatch{Operator: "contains_ci", Value: "HELLO"}, text: "oh hello there", expect: true},
+		{name: "path equals", match: WebSocketMatch{Path: "$.type", Value: "subscribe"}, parsed: parsed, expect: true},
+		{name: "path compares numbers", match: WebSocketMatch{Path: "$.qty", Operator: "gt", Value: "3"}, parsed: parsed, expect: true},
+		{name: "path exists", match: WebSocketMatch{Path: "$.channel", Operator: "exists"}, parsed: parsed, expect: true},
+		{name: "path missing", match: WebSocketMatch{Path: "$.user", Operator: "not_exists"}, parsed: parsed, expect: true},
+		{name: "path on text message", match: WebSocketMatch{Path: "$.type", Value: "subscribe"}, text: "subscribe", expect: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.match.matches(tt.text, tt.parsed))
+		})
+	}
+}
+
+// setupWebSocketProject creates a mock project with a websocket endpoint at /ws answering with response
+func setupWebSocketProject(t *testing.T, response database.MockResponse) (*MockService, *database.Project) {
+	db := database.GetDB()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "WebSocket", Alias: "ws-" + uuid.New().String()[:8], Mode: database.ModeMock}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	endpoint := &database.MockEndpoint{ProjectID: project.ID, Method: "GET", Path: "/ws", Enabled: true, ResponseMode: "static", Type: database.EndpointTypeWebSocket}
+	require.NoError(t, db.Create(endpoint).Error)
+
+	response.EndpointID = endpoint.ID
+	response.Enabled = true
+	require.NoError(t, db.Create(&response).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	return service, project
+}
+
+// webSocketTestServer serves a project like the mock handler does, reporting the sessions it served
+func webSocketTestServer(t *testing.T, service *MockService, project *database.Project) (*httptest.Server, chan *WebSocketSession) {
+	sessions := make(chan *WebSocketSession, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta := &RequestMeta{}
+		r = r.WithContext(ContextWithRequestMeta(r.Context(), meta))
+		resp, err, _, _, _ := service.HandleRequest(r.Context(), project.Alias, r.Method, r.URL.Path, r)
+		require.NoError(t, err)
+
+		if meta.WebSocket != nil && resp.StatusCode == http.StatusSwitchingProtocols {
+			meta.WebSocket.Serve(w, r)
+			sessions <- meta.WebSocket
+			return
+		}
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server, sessions
+}
+
+func webSocketURL(server *httptest.Server, path string) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + path
+}
+
+func readText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestWebSocketMock(t *testing.T) {
+	database.SetupTestEnvironment(t)
+
+	t.Run("plays the script", func(t *testing.T) {
+		service, project := setupWebSocketProject(t, database.MockResponse{
+			StatusCode: 101,
+			Templated:  true,
+			Headers:    `{"X-Session":"{{.request.query.user}}"}`,
+			Body: `{
+				"subprotocols": ["chat"],
+				"on_connect": [{"data": {"type": "welcome", "user": "{{.request.query.user}}"}}],
+				"replies": [
+					{"match": {"path": "$.type", "value": "ping"}, "messages": [{"data": {"type": "pong", "id": "{{.message.json.id}}"}}]},
+					{"match": {"value": "bye"}, "messages": [{"data": "see you"}], "close": true}
+				]
+			}`,
+		})
+		server, sessions := webSocketTestServer(t, service, project)
+
+		dialer := websocket.Dialer{Subprotocols: []string{"other", "chat"}}
+		conn, resp, err := dialer.Dial(webSocketURL(server, "/"+project.Alias+"/ws?user=jane"), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		assert.Equal(t, "chat", conn.Subprotocol())
+		assert.Equal(t, "jane", resp.Header.Get("X-Session"))
+
+		assert.Equal(t, `{"type":"welcome","user":"jane"}`, readText(t, conn))
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"ping","id":7}`)))
+		assert.Equal(t, `{"type":"pong","id":"7"}`, readText(t, conn))
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("ignored")))
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("bye")))
+		assert.Equal(t, "see you", readText(t, conn))
+
+		_, _, err = conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+
+		session := <-sessions
+		assert.True(t, session.Upgraded())
+		frames := session.Frames()
+		require.Len(t, frames, 8)
+		expected := []struct{ direction, kind, data string }{
+			{WebSocketFrameOut, "text", `{"type":"welcome","user":"jane"}`},
+			{WebSocketFrameIn, "text", `{"type":"ping","id":7}`},
+			{WebSocketFrameOut, "text", `{"type":"pong","id":"7"}`},
+			{WebSocketFrameIn, "text", "ignored"},
+			{WebSocketFrameIn, "text", "bye"},
+			{WebSocketFrameOut, "text", "see you"},
+			{WebSocketFrameOut, "close", ""},
+			{WebSocketFrameIn, "close", ""}, // The client answers the close
+		}
+		for i, frame := range expected {
+			assert.Equal(t, frame.direction, frames[i].Direction, "frame %d", i)
+			assert.Equal(t, frame.kind, frames[i].Type, "frame %d", i)
+			assert.Equal(t, frame.data, frames[i].Data, "frame %d", i)
+		}
+		assert.Equal(t, websocket.CloseNormalClosure, frames[6].Code)
+	})
+
+	t.Run("sends pushes at their interval", func(t *testing.T) {
+		service, project := setupWebSocketProject(t, database.MockResponse{
+			StatusCode: 101,
+			Body:       `{"pushes": [{"data": "tick", "interval_ms": 10, "count": 3}, {"data": "AAEC", "binary": true, "delay_ms": 100}]}`,
+		})
+		server, sessions := webSocketTestServer(t, service, project)
+
+		conn, _, err := websocket.DefaultDialer.Dial(webSocketURL(server, "/"+project.Alias+"/ws"), nil)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, "tick", readText(t, conn))
+		}
+		messageType, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, websocket.BinaryMessage, messageType)
+		assert.Equal(t, []byte{0, 1, 2}, data)
+
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "done"))
+		conn.Close()
+
+		frames := (<-sessions).Frames()
+		require.Len(t, frames, 5)
+		assert.Equal(t, "binary", frames[3].Type)
+		assert.Equal(t, "AAEC", frames[3].Data)
+		assert.Equal(t, WebSocketFrame{Direction: WebSocketFrameIn, Type: "close", Code: websocket.CloseGoingAway, Data: "done", Size: 4, Time: frames[4].Time}, frames[4])
+	})
+
+	t.Run("response with an error status rejects the handshake", func(t *testing.T) {
+		service, project := setupWebSocketProject(t, database.MockResponse{StatusCode: 401, Body: `{"error":"unauthorized"}`, Headers: `{"Content-Type":"application/json"}`})
+		server, _ := webSocketTestServer(t, service, project)
+
+		_, resp, err := websocket.DefaultDialer.Dial(webSocketURL(server, "/"+project.Alias+"/ws"), nil)
+		assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("plain requests need an upgrade", func(t *testing.T) {
+		service, project := setupWebSocketProject(t, database.MockResponse{StatusCode: 101})
+		server, _ := webSocketTestServer(t, service, project)
+
+		resp, err := http.Get(server.URL + "/" + project.Alias + "/ws")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	})
+}
+
+func TestWebSocketProxy(t *testing.T) {
+	database.SetupTestEnvironment(t)
+	db := database.GetDB()
+
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat"}}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, http.Header{"X-Upstream-Path": {r.URL.Path + "?" + r.URL.RawQuery}, "X-Client-Header": {r.Header.Get("X-Client")}})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(messageType, append([]byte("echo: "), data...))
+		}
+	}))
+	defer upstream.Close()
+
+	project := &database.Project{ID: uuid.New().String(), Name: "WebSocket Proxy", Alias: "wsp-" + uuid.New().String()[:8], Mode: database.ModeForwarder}
+	require.NoError(t, db.Create(project).Error)
+	t.Cleanup(func() { InvalidateProjectRoutes(project.ID) })
+
+	target := &database.ProxyTarget{ProjectID: project.ID, Label: "upstream", URL: upstream.URL + "/base"}
+	require.NoError(t, db.Create(target).Error)
+	require.NoError(t, db.Model(project).Update("active_proxy_id", target.ID).Error)
+
+	service := NewMockService(repositories.NewMockRepository(db), actions.NewActionService(noActionsRepo{}, modules.NewActionModules()))
+	server, sessions := webSocketTestServer(t, service, project)
+
+	dialer := websocket.Dialer{Subprotocols: []string{"chat"}}
+	conn, resp, err := dialer.Dial(webSocketURL(server, "/"+project.Alias+"/live?room=1"), http.Header{"X-Client": {"web"}})
+	require.NoError(t, err)
+	assert.Equal(t, "chat", conn.Subprotocol())
+	assert.Equal(t, "/base/live?room=1", resp.Header.Get("X-Upstream-Path"))
+	assert.Equal(t, "web", resp.Header.Get("X-Client-Header"))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	assert.Equal(t, "echo: hello", readText(t, conn))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{9}))
+	messageType, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, messageType)
+	assert.Equal(t, append([]byte("echo: "), 9), data)
+
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	conn.Close()
+
+	session := <-sessions
+	frames := session.Frames()
+	require.GreaterOrEqual(t, len(frames), 5)
+	assert.Equal(t, []string{WebSocketFrameIn, WebSocketFrameOut, WebSocketFrameIn, WebSocketFrameOut, WebSocketFrameIn},
+		[]string{frames[0].Direction, frames[1].Direction, frames[2].Direction, frames[3].Direction, frames[4].Direction})
+	assert.Equal(t, "hello", frames[0].Data)
+	assert.Equal(t, "echo: hello", frames[1].Data)
+	assert.Equal(t, "binary", frames[2].Type)
+	assert.Equal(t, "close", frames[4].Type)
+
+	t.Run("refused upgrade is passed to the client", func(t *testing.T) {
+		refusing := namedUpstream("no websocket here", http.StatusForbidden)
+		defer refusing.Close()
+		require.NoError(t, db.Model(target).Update("url", refusing.URL).Error)
+		InvalidateProjectRoutes(project.ID)
+
+		_, resp, err := websocket.DefaultDialer.Dial(webSocketURL(server, "/"+project.Alias+"/live"), nil)
+		assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
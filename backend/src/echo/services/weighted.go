@@ -0,0 +1,49 @@
+package services
+
+import (
+	"math/rand"
+
+	"beo-echo/backend/src/database"
+)
+
+// ResponseModeWeighted picks responses at random in proportion to their Weight
+const ResponseModeWeighted = "weighted"
+
+// selectWeightedResponse picks a response with a probability proportional to its weight
+// Responses with a weight of zero or less are never picked, unless no response has a
+// positive weight, in which case every response gets an equal chance.
+func selectWeightedResponse(responses []database.MockResponse, rng *rand.Rand) *database.MockResponse {
+	if len(responses) == 0 {
+		return nil
+	}
+
+	total := 0
+	for i := range responses {
+		total += responseWeight(&responses[i])
+	}
+	if total == 0 {
+		return &responses[rng.Intn(len(responses))]
+	}
+
+	roll := rng.Intn(total)
+	for i := range responses {
+		weight := responseWeight(&responses[i])
+		if weight == 0 {
+			continue
+		}
+		if roll < weight {
+			return &responses[i]
+		}
+		roll -= weight
+	}
+	return nil
+}
+
+// responseWeight returns the weight a response takes part in the draw with
+// Saved responses always have a weight, the database sets 1 when none was given.
+func responseWeight(response *database.MockResponse) int {
+	if response.Weight == nil || *response.Weight < 0 {
+		return 0
+	}
+	return *response.Weight
+}
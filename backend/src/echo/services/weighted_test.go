@@ -0,0 +1,82 @@
+package services
+
+import (
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/database"
+)
+
+// weight returns a pointer to a response weight
+func weight(n int) *int {
+	return &n
+}
+
+func TestSelectWeightedResponse(t *testing.T) {
+	countPicks := func(responses []database.MockResponse, n int) map[string]int {
+		rng := rand.New(rand.NewSource(7))
+		counts := map[string]int{}
+		for i := 0; i < n; i++ {
+			selected := selectWeightedResponse(responses, rng)
+			require.NotNil(t, selected)
+			counts[selected.ID]++
+		}
+		return counts
+	}
+
+	t.Run("Picks in proportion to weight", func(t *testing.T) {
+		counts := countPicks([]database.MockResponse{
+			{ID: "ok", StatusCode: 200, Weight: weight(95)},
+			{ID: "unavailable", StatusCode: 503, Weight: weight(5)},
+		}, 10000)
+		assert.InDelta(t, 9500, counts["ok"], 150)
+		assert.InDelta(t, 500, counts["unavailable"], 150)
+	})
+
+	t.Run("Zero weights are never picked", func(t *testing.T) {
+		counts := countPicks([]database.MockResponse{
+			{ID: "a", Weight: weight(1)},
+			{ID: "never", Weight: weight(0)},
+			{ID: "b", Weight: weight(3)},
+		}, 2000)
+		assert.Zero(t, counts["never"])
+		assert.InDelta(t, 500, counts["a"], 100)
+		assert.InDelta(t, 1500, counts["b"], 100)
+	})
+
+	t.Run("All zero weights fall back to equal chances", func(t *testing.T) {
+		counts := countPicks([]database.MockResponse{{ID: "a", Weight: weight(0)}, {ID: "b", Weight: weight(0)}}, 2000)
+		assert.InDelta(t, 1000, counts["a"], 150)
+		assert.InDelta(t, 1000, counts["b"], 150)
+	})
+
+	t.Run("Same seed gives the same sequence", func(t *testing.T) {
+		responses := []database.MockResponse{{ID: "a", Weight: weight(1)}, {ID: "b", Weight: weight(1)}, {ID: "c", Weight: weight(1)}}
+		first, second := rand.New(rand.NewSource(99)), rand.New(rand.NewSource(99))
+		for i := 0; i < 50; i++ {
+			assert.Equal(t, selectWeightedResponse(responses, first).ID, selectWeightedResponse(responses, second).ID)
+		}
+	})
+
+	t.Run("No responses", func(t *testing.T) {
+		assert.Nil(t, selectWeightedResponse(nil, rand.New(rand.NewSource(1))))
+	})
+}
+
+func TestSelectResponseWithEndpoint_Weighted(t *testing.T) {
+	responses := []database.MockResponse{
+		{ID: "ok", Weight: weight(1)},
+		{ID: "never", Weight: weight(0)},
+	}
+	req, _ := http.NewRequest("GET", "http://localhost/", nil)
+
+	for i := 0; i < 50; i++ {
+		selected, err := selectResponseWithEndpoint("weighted-endpoint", responses, ResponseModeWeighted, req)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", selected.ID)
+	}
+}
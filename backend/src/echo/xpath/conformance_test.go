@@ -0,0 +1,104 @@
+package xpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// Conformance cases follow the abbreviated syntax examples of the XPath 1.0 specification
+// (section 2.5), checking every syntax the package documents. Relative paths start at the
+// document, so the spec's "chapter//para" is written "/doc/chapter//para".
+
+const specDocument = `<doc lang="en">` +
+	`<chapter id="c1"><title>Introduction</title><para type="note">p1</para><section><para>p2</para></section></chapter>` +
+	`<chapter id="c2"><title>Usage</title><para type="warning">w1</para><para>p3</para><para type="warning">w2</para></chapter>` +
+	`<chapter id="c3"><para type="warning">w3</para></chapter>` +
+	`<olist><item>i1</item><item>i2</item></olist>` +
+	`<employee secretary="a" assistant="b">e1</employee><employee secretary="c">e2</employee>` +
+	`</doc>`
+
+func TestConformance_AbbreviatedSyntax(t *testing.T) {
+	root, err := requestctx.ParseXML([]byte(specDocument))
+	require.NoError(t, err)
+
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		// Child, wildcard, text and attribute steps
+		{expr: "/doc/chapter/para", expected: []string{"p1", "w1", "p3", "w2", "w3"}},
+		{expr: "/doc/*", expected: []string{"Introductionp1p2", "Usagew1p3w2", "w3", "i1i2", "e1", "e2"}},
+		{expr: "/doc/chapter[1]/title/text()", expected: []string{"Introduction"}},
+		{expr: "/doc/@lang", expected: []string{"en"}},
+		{expr: "/doc/*/para", expected: []string{"p1", "w1", "p3", "w2", "w3"}},
+		{expr: "doc/olist/item", expected: []string{"i1", "i2"}},
+
+		// Positions count among the nodes a step selects for each parent
+		{expr: "/doc/chapter/para[1]", expected: []string{"p1", "w1", "w3"}},
+		{expr: "/doc/chapter/para[last()]", expected: []string{"p1", "w2", "w3"}},
+		{expr: "/doc/chapter[2]/para[3]", expected: []string{"w2"}},
+		{expr: "//item[position() = 2]", expected: []string{"i2"}},
+
+		// Descendants, self and parent
+		{expr: "/doc/chapter//para", expected: []string{"p1", "p2", "w1", "p3", "w2", "w3"}},
+		{expr: "//para", expected: []string{"p1", "p2", "w1", "p3", "w2", "w3"}},
+		{expr: "//olist/item", expected: []string{"i1", "i2"}},
+		{expr: "/doc/chapter[1]/.", expected: []string{"Introductionp1p2"}},
+		{expr: "//chapter[.//para = 'p2']/@id", expected: []string{"c1"}},
+		{expr: "//section/../@id", expected: []string{"c1"}},
+		{expr: "//title/../../@lang", expected: []string{"en"}},
+
+		// Predicates
+		{expr: "//para[@type='warning']", expected: []string{"w1", "w2", "w3"}},
+		{expr: `//para[@type="warning"]`, expected: []string{"w1", "w2", "w3"}},
+		{expr: "//chapter/para[@type='warning'][2]", expected: []string{"w2"}},
+		{expr: "//chapter/para[3][@type='warning']", expected: []string{"w2"}},
+		{expr: "//chapter[title='Introduction']/@id", expected: []string{"c1"}},
+		{expr: "//chapter[title]/@id", expected: []string{"c1", "c2"}},
+		{expr: "//employee[@secretary and @assistant]", expected: []string{"e1"}},
+		{expr: "//employee[@secretary or @assistant]", expected: []string{"e1", "e2"}},
+		{expr: "//para[not(@type)]", expected: []string{"p2", "p3"}},
+		{expr: "//item[. != 'i1']", expected: []string{"i2"}},
+
+		// Functions
+		{expr: "//chapter[count(para) > 1]/@id", expected: []string{"c2"}},
+		{expr: "//chapter[normalize-space(title) = 'Usage']/@id", expected: []string{"c2"}},
+		{expr: "//para[contains(@type, 'warn')]", expected: []string{"w1", "w2", "w3"}},
+		{expr: "//para[starts-with(., 'p')]", expected: []string{"p1", "p2", "p3"}},
+		{expr: "//chapter[ends-with(@id, '3')]/para", expected: []string{"w3"}},
+		{expr: "//title[string-length(.) = 5]", expected: []string{"Usage"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			values, err := Find(root, tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+
+	// The order of attributes is not defined by XPath
+	values, err := Find(root, "/doc/employee[1]/@*")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, values)
+}
+
+func TestConformance_InvalidSyntax(t *testing.T) {
+	for _, expr := range []string{
+		"//para[",
+		"/doc/chapter[@id=]",
+		"//para[@type='x' and]",
+		"///para",
+		"//para[position(]",
+		"//para[@type='x']]",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Compile(expr)
+			assert.Error(t, err)
+		})
+	}
+}
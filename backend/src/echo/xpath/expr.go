@@ -0,0 +1,229 @@
+package xpath
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// evalContext is the item a predicate is evaluated for and its position among the candidates
+type evalContext struct {
+	item     item
+	position int
+	size     int
+	doc      *requestctx.XMLNode
+}
+
+// expr is a predicate expression
+// eval returns a bool, a float64, a string or a []item node set.
+type expr interface {
+	eval(ctx evalContext) interface{}
+}
+
+// predicateMatches applies the XPath rule that a number predicate selects a position
+func predicateMatches(value interface{}, position int) bool {
+	if number, ok := value.(float64); ok {
+		return number == float64(position)
+	}
+	return toBool(value)
+}
+
+type literalExpr struct{ value interface{} }
+
+func (e literalExpr) eval(evalContext) interface{} { return e.value }
+
+type pathExpr struct{ path locationPath }
+
+func (e pathExpr) eval(ctx evalContext) interface{} {
+	if ctx.item.node == nil {
+		// An attribute or text item only has itself as context
+		if !e.path.absolute && len(e.path.steps) == 1 && e.path.steps[0].kind == stepSelf {
+			return []item{ctx.item}
+		}
+		if !e.path.absolute {
+			return []item(nil)
+		}
+	}
+	return e.path.evaluate(ctx.item, ctx.doc)
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right expr
+}
+
+func (e logicalExpr) eval(ctx evalContext) interface{} {
+	left := toBool(e.left.eval(ctx))
+	if e.and {
+		return left && toBool(e.right.eval(ctx))
+	}
+	return left || toBool(e.right.eval(ctx))
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e compareExpr) eval(ctx evalContext) interface{} {
+	return compareValues(e.op, e.left.eval(ctx), e.right.eval(ctx))
+}
+
+type callExpr struct {
+	name string
+	args []expr
+}
+
+func (e callExpr) eval(ctx evalContext) interface{} {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.eval(ctx)
+	}
+
+	switch e.name {
+	case "position":
+		return float64(ctx.position)
+	case "last":
+		return float64(ctx.size)
+	case "not":
+		return !toBool(args[0])
+	case "count":
+		nodes, _ := args[0].([]item)
+		return float64(len(nodes))
+	case "contains":
+		return strings.Contains(toString(args[0]), toString(args[1]))
+	case "starts-with":
+		return strings.HasPrefix(toString(args[0]), toString(args[1]))
+	case "ends-with":
+		return strings.HasSuffix(toString(args[0]), toString(args[1]))
+	case "string-length":
+		if len(args) == 0 {
+			return float64(len([]rune(ctx.item.String())))
+		}
+		return float64(len([]rune(toString(args[0]))))
+	case "normalize-space":
+		value := ctx.item.String()
+		if len(args) > 0 {
+			value = toString(args[0])
+		}
+		return strings.Join(strings.Fields(value), " ")
+	}
+	return false
+}
+
+// functionArity is the number of arguments each supported function takes, -1 for 0 or 1
+var functionArity = map[string]int{
+	"position":        0,
+	"last":            0,
+	"not":             1,
+	"count":           1,
+	"contains":        2,
+	"starts-with":     2,
+	"ends-with":       2,
+	"string-length":   -1,
+	"normalize-space": -1,
+}
+
+// compareValues compares two values the XPath 1.0 way: a node set matches when any of its
+// string values satisfies the comparison
+func compareValues(op string, left, right interface{}) bool {
+	if nodes, ok := left.([]item); ok {
+		for _, n := range nodes {
+			if compareValues(op, n.String(), right) {
+				return true
+			}
+		}
+		return false
+	}
+	if nodes, ok := right.([]item); ok {
+		for _, n := range nodes {
+			if compareValues(op, left, n.String()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if op == "=" || op == "!=" {
+		var equal bool
+		_, leftBool := left.(bool)
+		_, rightBool := right.(bool)
+		_, leftNumber := left.(float64)
+		_, rightNumber := right.(float64)
+		switch {
+		case leftBool || rightBool:
+			equal = toBool(left) == toBool(right)
+		case leftNumber || rightNumber:
+			equal = toNumber(left) == toNumber(right)
+		default:
+			equal = toString(left) == toString(right)
+		}
+		return equal == (op == "=")
+	}
+
+	l, r := toNumber(left), toNumber(right)
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+func toBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case []item:
+		return len(v) > 0
+	}
+	return false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []item:
+		if len(v) == 0 {
+			return ""
+		}
+		return v[0].String()
+	}
+	return ""
+}
+
+func toNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(toString(value)), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return number
+}
@@ -0,0 +1,314 @@
+package xpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parser is a recursive descent parser over an XPath expression
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c == '-' || c == '.' || c == ':' || (c >= '0' && c <= '9')
+}
+
+// parseName reads an XML name, including any namespace prefix
+func (p *parser) parseName() string {
+	start := p.pos
+	if p.eof() || !isNameStart(p.peek()) {
+		return ""
+	}
+	for !p.eof() && isNameChar(p.peek()) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseLocationPath parses an absolute or relative path
+func (p *parser) parseLocationPath() (locationPath, error) {
+	var path locationPath
+	descendant := false
+	switch {
+	case p.consume("//"):
+		path.absolute, descendant = true, true
+	case p.consume("/"):
+		path.absolute = true
+		if p.eof() || !p.atStepStart() {
+			return path, nil // "/" selects the document
+		}
+	}
+
+	for {
+		s, err := p.parseStep(descendant)
+		if err != nil {
+			return path, err
+		}
+		path.steps = append(path.steps, s)
+
+		switch {
+		case p.consume("//"):
+			descendant = true
+		case p.consume("/"):
+			descendant = false
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) atStepStart() bool {
+	c := p.peek()
+	return c == '.' || c == '@' || c == '*' || isNameStart(c)
+}
+
+// parseStep parses one step and its predicates
+func (p *parser) parseStep(descendant bool) (step, error) {
+	s := step{descendant: descendant}
+	switch {
+	case p.consume(".."):
+		s.kind = stepParent
+	case p.consume("."):
+		s.kind = stepSelf
+	case p.consume("@"):
+		s.kind = stepAttribute
+		if p.consume("*") {
+			s.name = "*"
+		} else if s.name = localName(p.parseName()); s.name == "" {
+			return s, p.errorf("expected an attribute name")
+		}
+	case p.consume("*"):
+		s.kind, s.name = stepElement, "*"
+	default:
+		name := p.parseName()
+		if name == "" {
+			if p.eof() {
+				return s, p.errorf("unexpected end of expression")
+			}
+			return s, p.errorf("unexpected %q", p.peek())
+		}
+		switch {
+		case name == "text" && p.consume("()"):
+			s.kind = stepText
+		case name == "node" && p.consume("()"):
+			s.kind, s.name = stepElement, "*"
+		default:
+			s.kind, s.name = stepElement, localName(name)
+		}
+	}
+
+	for p.consume("[") {
+		if s.kind == stepSelf || s.kind == stepParent {
+			return s, p.errorf("predicates are not supported on . and ..")
+		}
+		predicate, err := p.parseOr()
+		if err != nil {
+			return s, err
+		}
+		p.skipSpaces()
+		if !p.consume("]") {
+			return s, p.errorf("expected ]")
+		}
+		s.predicates = append(s.predicates, predicate)
+	}
+	return s, nil
+}
+
+// consumeKeyword consumes and or or when followed by something that cannot continue a name
+func (p *parser) consumeKeyword(keyword string) bool {
+	if !strings.HasPrefix(p.src[p.pos:], keyword) {
+		return false
+	}
+	end := p.pos + len(keyword)
+	if end < len(p.src) && isNameChar(p.src[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consumeKeyword("or") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: false, left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.consumeKeyword("and") {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+}
+
+var comparisonOperators = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, op := range comparisonOperators {
+		if p.consume(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOperand() (expr, error) {
+	p.skipSpaces()
+	c := p.peek()
+	switch {
+	case p.eof():
+		return nil, p.errorf("unexpected end of expression")
+	case c == '\'' || c == '"':
+		return p.parseString()
+	case c >= '0' && c <= '9', c == '-', c == '.' && p.pos+1 < len(p.src) && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9':
+		return p.parseNumber()
+	case c == '(':
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return inner, nil
+	}
+
+	// A name directly followed by ( is a function call, except the text() and node() tests
+	start := p.pos
+	if name := p.parseName(); name != "" && p.peek() == '(' && name != "text" && name != "node" {
+		return p.parseCall(name)
+	}
+	p.pos = start
+
+	path, err := p.parseLocationPath()
+	if err != nil {
+		return nil, err
+	}
+	return pathExpr{path}, nil
+}
+
+func (p *parser) parseCall(name string) (expr, error) {
+	arity, ok := functionArity[name]
+	if !ok {
+		return nil, p.errorf("unsupported function %s()", name)
+	}
+	p.pos++ // (
+
+	var args []expr
+	p.skipSpaces()
+	if !p.consume(")") {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			p.skipSpaces()
+			if p.consume(")") {
+				break
+			}
+			if !p.consume(",") {
+				return nil, p.errorf("expected , or )")
+			}
+		}
+	}
+
+	if (arity >= 0 && len(args) != arity) || (arity < 0 && len(args) > 1) {
+		return nil, p.errorf("wrong number of arguments for %s()", name)
+	}
+	return callExpr{name: name, args: args}, nil
+}
+
+func (p *parser) parseString() (expr, error) {
+	quote := p.peek()
+	p.pos++
+	end := strings.IndexByte(p.src[p.pos:], quote)
+	if end < 0 {
+		return nil, p.errorf("unterminated string")
+	}
+	value := p.src[p.pos : p.pos+end]
+	p.pos += end + 1
+	return literalExpr{value}, nil
+}
+
+func (p *parser) parseNumber() (expr, error) {
+	start := p.pos
+	p.consume("-")
+	for !p.eof() && (p.peek() == '.' || (p.peek() >= '0' && p.peek() <= '9')) {
+		p.pos++
+	}
+	number, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+	}
+	return literalExpr{number}, nil
+}
@@ -0,0 +1,283 @@
+// Package xpath evaluates a practical subset of XPath 1.0 against parsed XML request bodies.
+//
+// Supported syntax:
+//
+//	/Envelope/Body/GetUser     absolute path from the document
+//	//Name                     elements at any depth
+//	Envelope/Body              relative paths start at the document, like absolute ones
+//	*  @id  @*  text()  .  ..  wildcards, attributes, text, self and parent
+//	soap:Body                  namespace prefixes are ignored, names are matched locally
+//	[1]  [last()]              positions among the elements a step selects for each parent
+//	[@type='gift']  [qty > 1]  comparisons with = != < <= > >=, combined with and / or
+//	[contains(Name, 'Jo')]     functions: contains, starts-with, ends-with, not, count,
+//	                           position, last, string-length, normalize-space
+//
+// The string value of an element is its text together with the text of its descendants.
+package xpath
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"beo-echo/backend/src/echo/lrucache"
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+// Expr is a compiled XPath expression
+type Expr struct {
+	expr string
+	path locationPath
+}
+
+// item is a selected element, or the string value of a selected attribute or text node
+type item struct {
+	node  *requestctx.XMLNode
+	value string
+}
+
+func (i item) String() string {
+	if i.node != nil {
+		return stringValue(i.node)
+	}
+	return i.value
+}
+
+// cacheSize bounds the compiled expressions kept in memory; the xpath template helpers
+// may compile expressions built from request data
+const cacheSize = 1024
+
+var cache = lrucache.New[string, *Expr](cacheSize)
+
+// Compile parses an XPath expression
+// Compiled expressions are immutable and the most recently used ones are cached, so
+// compiling the same expression again is cheap.
+func Compile(expr string) (*Expr, error) {
+	if cached, ok := cache.Get(expr); ok {
+		return cached, nil
+	}
+
+	p := &parser{src: strings.TrimSpace(expr)}
+	path, err := p.parseLocationPath()
+	if err == nil {
+		p.skipSpaces()
+		if !p.eof() {
+			err = p.errorf("unexpected %q", p.peek())
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid XPath %q: %w", expr, err)
+	}
+
+	compiled := &Expr{expr: expr, path: path}
+	cache.Add(expr, compiled)
+	return compiled, nil
+}
+
+// Find returns the string values of everything expr selects in the document rooted at root
+func Find(root *requestctx.XMLNode, expr string) ([]string, error) {
+	compiled, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Find(root), nil
+}
+
+// Get returns the string value of the first match of expr and whether there was one
+// Invalid expressions select nothing.
+func Get(root *requestctx.XMLNode, expr string) (string, bool) {
+	values, err := Find(root, expr)
+	if err != nil || len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// String returns the source expression
+func (e *Expr) String() string {
+	return e.expr
+}
+
+// Find returns the string values of everything the expression selects, in document order
+func (e *Expr) Find(root *requestctx.XMLNode) []string {
+	if root == nil {
+		return nil
+	}
+	doc := document(root)
+	items := e.path.evaluate(item{node: doc}, doc)
+
+	values := make([]string, len(items))
+	for i, it := range items {
+		values[i] = it.String()
+	}
+	return values
+}
+
+// document wraps root in a document node so absolute paths can select the root element
+func document(root *requestctx.XMLNode) *requestctx.XMLNode {
+	return &requestctx.XMLNode{Children: []*requestctx.XMLNode{root}}
+}
+
+// stringValue concatenates the text of node and its descendants
+func stringValue(node *requestctx.XMLNode) string {
+	if len(node.Children) == 0 {
+		return node.Text
+	}
+	var b strings.Builder
+	b.WriteString(node.Text)
+	for _, child := range node.Children {
+		b.WriteString(stringValue(child))
+	}
+	return b.String()
+}
+
+// localName drops a namespace prefix from a name test
+func localName(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+type stepKind int
+
+const (
+	stepElement stepKind = iota
+	stepAttribute
+	stepText
+	stepSelf
+	stepParent
+)
+
+// step selects nodes relative to a context node, from its children or, when descendant
+// is set, from all of its descendants
+type step struct {
+	kind       stepKind
+	descendant bool
+	name       string // Local name, or * for any
+	predicates []expr
+}
+
+type locationPath struct {
+	absolute bool
+	steps    []step
+}
+
+// evaluate selects the path's items starting from context
+func (p locationPath) evaluate(context item, doc *requestctx.XMLNode) []item {
+	current := []item{context}
+	if p.absolute {
+		current = []item{{node: doc}}
+	}
+
+	for _, s := range p.steps {
+		var next []item
+		seen := map[*requestctx.XMLNode]bool{}
+		for _, ctx := range current {
+			if ctx.node == nil {
+				continue // Attributes and text have no children
+			}
+			for _, selected := range s.apply(ctx.node, doc) {
+				if selected.node != nil {
+					if seen[selected.node] {
+						continue
+					}
+					seen[selected.node] = true
+				}
+				next = append(next, selected)
+			}
+		}
+		current = next
+		if len(current) == 0 {
+			return nil
+		}
+	}
+	return current
+}
+
+// apply selects the step's candidates for one context node and filters them by the predicates
+func (s step) apply(node, doc *requestctx.XMLNode) []item {
+	var candidates []item
+	switch s.kind {
+	case stepSelf:
+		candidates = []item{{node: node}}
+	case stepParent:
+		if node.Parent != nil {
+			candidates = []item{{node: node.Parent}}
+		} else if node != doc && isDocumentChild(node, doc) {
+			candidates = []item{{node: doc}}
+		}
+	case stepElement:
+		for _, n := range s.scope(node) {
+			if s.name == "*" || n.Name == s.name {
+				candidates = append(candidates, item{node: n})
+			}
+		}
+	case stepAttribute:
+		for _, n := range s.attributeScope(node) {
+			names := make([]string, 0, len(n.Attrs))
+			for name := range n.Attrs {
+				if s.name == "*" || name == s.name {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				candidates = append(candidates, item{value: n.Attrs[name]})
+			}
+		}
+	case stepText:
+		for _, n := range s.attributeScope(node) {
+			if n.Text != "" {
+				candidates = append(candidates, item{value: n.Text})
+			}
+		}
+	}
+
+	for _, predicate := range s.predicates {
+		filtered := candidates[:0:0]
+		for i, candidate := range candidates {
+			ctx := evalContext{item: candidate, position: i + 1, size: len(candidates), doc: doc}
+			if predicateMatches(predicate.eval(ctx), ctx.position) {
+				filtered = append(filtered, candidate)
+			}
+		}
+		candidates = filtered
+	}
+	return candidates
+}
+
+// scope returns the elements a step chooses from: the children, or every descendant
+func (s step) scope(node *requestctx.XMLNode) []*requestctx.XMLNode {
+	if !s.descendant {
+		return node.Children
+	}
+	var nodes []*requestctx.XMLNode
+	var walk func(*requestctx.XMLNode)
+	walk = func(n *requestctx.XMLNode) {
+		for _, child := range n.Children {
+			nodes = append(nodes, child)
+			walk(child)
+		}
+	}
+	walk(node)
+	return nodes
+}
+
+// attributeScope returns the elements whose attributes or text a step reads:
+// the node itself, or the node and every descendant
+func (s step) attributeScope(node *requestctx.XMLNode) []*requestctx.XMLNode {
+	if !s.descendant {
+		return []*requestctx.XMLNode{node}
+	}
+	return append([]*requestctx.XMLNode{node}, s.scope(node)...)
+}
+
+func isDocumentChild(node, doc *requestctx.XMLNode) bool {
+	for _, child := range doc.Children {
+		if child == node {
+			return true
+		}
+	}
+	return false
+}
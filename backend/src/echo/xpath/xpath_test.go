@@ -0,0 +1,103 @@
+package xpath
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"beo-echo/backend/src/echo/requestctx"
+)
+
+const soapOrder = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:o="urn:orders">
+	<soap:Header><o:Auth token="abc"/></soap:Header>
+	<soap:Body>
+		<o:PlaceOrder id="42" channel="web">
+			<o:Customer tier="gold">Jane <o:Last>Doe</o:Last></o:Customer>
+			<o:Item sku="A1" type="book"><o:Qty>1</o:Qty></o:Item>
+			<o:Item sku="B2" type="toy"><o:Qty>3</o:Qty></o:Item>
+			<o:Item sku="C3" type="book"><o:Qty>2</o:Qty></o:Item>
+		</o:PlaceOrder>
+	</soap:Body>
+</soap:Envelope>`
+
+func TestFind(t *testing.T) {
+	root, err := requestctx.ParseXML([]byte(soapOrder))
+	require.NoError(t, err)
+
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: "/Envelope/Body/PlaceOrder/@id", expected: []string{"42"}},
+		{expr: "/soap:Envelope/soap:Body/o:PlaceOrder/@channel", expected: []string{"web"}},
+		{expr: "Envelope/Header/Auth/@token", expected: []string{"abc"}},
+		{expr: "//Item/@sku", expected: []string{"A1", "B2", "C3"}},
+		{expr: "//Item[1]/@sku", expected: []string{"A1"}},
+		{expr: "//Item[last()]/@sku", expected: []string{"C3"}},
+		{expr: "//Item[@type='book']/@sku", expected: []string{"A1", "C3"}},
+		{expr: "//Item[Qty > 1]/@sku", expected: []string{"B2", "C3"}},
+		{expr: "//Item[Qty >= 2 and @type = 'book']/@sku", expected: []string{"C3"}},
+		{expr: "//Item[@type='toy' or Qty=1]/@sku", expected: []string{"A1", "B2"}},
+		{expr: "//Item[not(@type='book')]/Qty", expected: []string{"3"}},
+		{expr: "//Item[starts-with(@sku, 'C')]/Qty/text()", expected: []string{"2"}},
+		{expr: "//Customer", expected: []string{"JaneDoe"}},
+		{expr: "//Customer/text()", expected: []string{"Jane"}},
+		{expr: "//Customer[contains(., 'Doe')]/@tier", expected: []string{"gold"}},
+		{expr: "//Last/../@tier", expected: []string{"gold"}},
+		{expr: "//PlaceOrder[count(Item) = 3]/@id", expected: []string{"42"}},
+		{expr: "//PlaceOrder/*[2]/@sku", expected: []string{"A1"}},
+		{expr: "//Item[@sku='A1']/@*", expected: []string{"A1", "book"}},
+		{expr: "//@sku[. = 'B2']", expected: []string{"B2"}},
+		{expr: "//Missing", expected: []string{}},
+		{expr: "/Body", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			values, err := Find(root, tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{"", "//", "/a/[1]", "//a[", "//a[@id='x]", "//a[unknown(1)]", "//a[contains(.)]", "a b"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Compile(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompileCacheIsBounded(t *testing.T) {
+	first, err := Compile("//Name")
+	require.NoError(t, err)
+	again, err := Compile("//Name")
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+
+	for i := 0; i < cacheSize*2; i++ {
+		_, err := Compile(fmt.Sprintf("//item%d", i))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, cacheSize, cache.Len())
+}
+
+func TestGet(t *testing.T) {
+	root, err := requestctx.ParseXML([]byte(`<user id="7"><name>Jane</name></user>`))
+	require.NoError(t, err)
+
+	value, ok := Get(root, "/user/name")
+	assert.True(t, ok)
+	assert.Equal(t, "Jane", value)
+
+	_, ok = Get(root, "/user/email")
+	assert.False(t, ok)
+
+	_, ok = Get(nil, "/user")
+	assert.False(t, ok)
+}
@@ -6,6 +6,7 @@ import (
 	"beo-echo/backend/src/logs/services"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,6 +39,10 @@ func LogService() *services.LogService {
 }
 
 // GetLogsHandler handles retrieving logs with pagination
+// Logs can be filtered by captured path params with "param.<name>" query keys.
+//
+// Sample curl:
+// curl -X GET "http://localhost:3600/api/workspaces/{workspaceId}/projects/{projectId}/logs?page=1&pageSize=50&param.id=42"
 func GetLogsHandler(c *gin.Context) {
 	EnsureLogService()
 	if logService == nil {
@@ -60,8 +65,16 @@ func GetLogsHandler(c *gin.Context) {
 		return
 	}
 
+	// Path param filters use the "param." prefix, e.g. ?param.id=42
+	filter := repositories.LogFilter{PathParams: map[string]string{}}
+	for key, values := range c.Request.URL.Query() {
+		if name := strings.TrimPrefix(key, "param."); name != key && name != "" && len(values) > 0 {
+			filter.PathParams[name] = values[0]
+		}
+	}
+
 	// Get logs
-	logs, total, err := logService.GetPaginatedLogs(page, pageSize, projectID)
+	logs, total, err := logService.GetPaginatedLogs(page, pageSize, projectID, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   true,
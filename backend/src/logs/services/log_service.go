@@ -25,8 +25,8 @@ func NewLogService(repo *repositories.LogRepository) *LogService {
 	}
 }
 
-// GetPaginatedLogs retrieves logs with pagination
-func (s *LogService) GetPaginatedLogs(page, pageSize int, projectID string) ([]database.RequestLog, int64, error) {
+// GetPaginatedLogs retrieves logs with pagination, narrowed by the optional filter
+func (s *LogService) GetPaginatedLogs(page, pageSize int, projectID string, filter repositories.LogFilter) ([]database.RequestLog, int64, error) {
 	// Default to page 1 if invalid
 	if page < 1 {
 		page = 1
@@ -37,7 +37,7 @@ func (s *LogService) GetPaginatedLogs(page, pageSize int, projectID string) ([]d
 		pageSize = 100
 	}
 
-	return s.Repo.GetLogs(page, pageSize, projectID)
+	return s.Repo.GetLogs(page, pageSize, projectID, filter)
 }
 
 // GetLatestLogs retrieves the most recent logs
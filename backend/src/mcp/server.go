@@ -37,6 +37,18 @@ Typical workflow:
 Notes:
   - Always resolve ids with the list tools before acting; do not guess ids.
   - Response "headers" and action "config" are JSON encoded as a string.
+  - Responses render body and headers as templates ({{.request.params.id}}, {{query "page"}}) only with templated set; otherwise they are served as stored.
+  - Scenarios are defined in the project advance config; responses opt in with scenario/required_state/new_state.
+  - GraphQL endpoints (type "graphql") pick responses with graphql rules on operationName, operationType, field or variables.<path>.
+  - WebSocket endpoints (type "websocket") accept upgrades; the body of a response with a status below 300 is a JSON script (on_connect, replies matched on incoming messages, timed pushes, close_after_ms), other statuses reject the handshake. Proxied WebSocket connections are tunnelled to the proxy target; logs_list shows every frame in websocket_frames.
+  - gRPC endpoints (type "grpc") serve the methods of proto files uploaded to the project; rules and templates see the request message as JSON and the response body is the output message as JSON (stream events for server streaming). Clients connect over h2c to the mock port with x-beo-project metadata; logs_list shows grpc_method and grpc_status.
+  - Response "callbacks" send webhooks after the response is served; they appear in logs_list with source "callback".
+  - A proxy target with a "pool" balances requests over other proxy targets; logs show the member that served each request in proxy_target.
+  - Proxy target "transport" (JSON string) sets TLS verification, CA bundle, mTLS client cert, SNI, timeouts, http2 and an outbound proxy.
+  - Proxy target "rewrite" (JSON string) rewrites forwarded paths and headers, Host, Location and Set-Cookie domains; endpoint advance config "proxyRewrite" overrides it.
+  - Project advance config "cors" answers browser preflights and sets CORS headers on mock and proxied responses; no OPTIONS endpoints are needed.
+  - Advance config "auth" (project, or endpoint to override) requires an API key, Basic credentials or a JWT before endpoints are matched; jwt rules match on token claims, and logs_list shows the decision in auth.
+  - Record mode (advance config "record") turns proxied/forwarded traffic into endpoints and responses; switch the project to mock afterwards.
   - System config and auto-invite tools require an instance owner.`
 
 // Server bundles the MCP server with the REST client it drives.
@@ -62,6 +74,7 @@ func NewServer(apiHandler http.Handler) *Server {
 	// Register tools grouped by area.
 	s.registerWorkspaceTools()
 	s.registerProjectTools()
+	s.registerScenarioTools()
 	s.registerRouteTools()
 	s.registerLogTools()
 	s.registerReplayTools()
@@ -16,13 +16,14 @@ func (s *Server) registerLogTools() {
 	logsBase := func(ws, proj string) string { return projectPath(ws, proj) + "/logs" }
 
 	type listLogsIn struct {
-		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
-		ProjectID   string `json:"project_id" jsonschema:"the project id"`
-		Page        int    `json:"page,omitempty" jsonschema:"page number (default 1)"`
-		PageSize    int    `json:"page_size,omitempty" jsonschema:"logs per page (default 100)"`
+		WorkspaceID string            `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID   string            `json:"project_id" jsonschema:"the project id"`
+		Page        int               `json:"page,omitempty" jsonschema:"page number (default 1)"`
+		PageSize    int               `json:"page_size,omitempty" jsonschema:"logs per page (default 100)"`
+		PathParams  map[string]string `json:"path_params,omitempty" jsonschema:"only return logs whose captured path params match, e.g. {\"id\":\"42\"}"`
 	}
 	addTool(s, "logs_list",
-		"List request logs for a project, paginated. Each log includes method, path, status, latency, and bodies.",
+		"List request logs for a project, paginated. Each log includes method, path, captured path params, status, latency, and bodies.",
 		func(ctx context.Context, req *mcp.CallToolRequest, in listLogsIn) (*mcp.CallToolResult, any, error) {
 			token := tokenFromRequest(req)
 			q := url.Values{}
@@ -32,6 +33,9 @@ func (s *Server) registerLogTools() {
 			if in.PageSize > 0 {
 				q.Set("pageSize", strconv.Itoa(in.PageSize))
 			}
+			for name, value := range in.PathParams {
+				q.Set("param."+name, value)
+			}
 			var out raw
 			if err := s.client.Get(ctx, token, logsBase(in.WorkspaceID, in.ProjectID), q, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -191,9 +195,9 @@ func (s *Server) registerLogWaitTool(logsBase func(ws, proj string) string) {
 				}
 				if newer := newerThan(ids, baseline); len(newer) > 0 {
 					return jsonResult(map[string]any{
-						"new_log_ids": newer,
+						"new_log_ids":   newer,
 						"newest_log_id": ids[0],
-						"count":       len(newer),
+						"count":         len(newer),
 					})
 				}
 				// Special case: started with no logs at all, then some appeared.
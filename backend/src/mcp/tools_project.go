@@ -146,17 +146,76 @@ func (s *Server) registerProjectTools() {
 		})
 
 	type advConfigIn struct {
-		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
-		ProjectID   string `json:"project_id" jsonschema:"the project id"`
-		DelayMs     int    `json:"delay_ms" jsonschema:"global response delay in milliseconds (0-120000)"`
+		WorkspaceID string           `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID   string           `json:"project_id" jsonschema:"the project id"`
+		DelayMs     *int             `json:"delay_ms,omitempty" jsonschema:"global response delay in milliseconds (0-120000)"`
+		Latency     map[string]any   `json:"latency,omitempty" jsonschema:"global latency distribution, e.g. {\"distribution\":\"uniform\",\"minMs\":50,\"maxMs\":300}; distributions: uniform (minMs/maxMs), normal or lognormal (meanMs/stdDevMs), percentile (p50Ms/p95Ms/p99Ms); an empty object removes it"`
+		Faults      []map[string]any `json:"faults,omitempty" jsonschema:"faults injected into responses, e.g. [{\"type\":\"error\",\"probability\":0.1,\"status\":503}]; types: error (status), reset, empty, truncate (bytes), trickle (bytesPerSec), malformed_json; an empty list removes them"`
+		Record      map[string]any   `json:"record,omitempty" jsonschema:"record mode for proxy and forwarder projects, e.g. {\"enabled\":true,\"generateRules\":true,\"ignoreFields\":[\"query.ts\"]}; forwarded traffic becomes endpoints and responses; an empty object removes it"`
+		CORS        map[string]any   `json:"cors,omitempty" jsonschema:"CORS policy of the mock endpoints, e.g. {\"enabled\":true,\"allowOrigins\":[\"https://app.example.com\"],\"allowMethods\":[\"GET\",\"POST\"],\"allowHeaders\":[\"Content-Type\"],\"exposeHeaders\":[\"X-Request-Id\"],\"allowCredentials\":true,\"maxAgeSeconds\":600}; preflights are answered automatically; an empty object removes it"`
+		Auth        map[string]any   `json:"auth,omitempty" jsonschema:"simulated authentication required before endpoints are matched, e.g. {\"type\":\"api_key\",\"apiKey\":{\"header\":\"X-API-Key\",\"keys\":[\"k1\"]}}, {\"type\":\"basic\",\"basic\":{\"users\":{\"alice\":\"secret\"}}} or {\"type\":\"jwt\",\"jwt\":{\"secret\":\"s\",\"issuer\":\"https://issuer\",\"requiredScopes\":[\"read\"]}} (or jwks/jwksUrl); unauthorized/forbidden {status,headers,body} replace the 401/403 responses; an empty object removes it"`
 	}
 	addTool(s, "project_update_advance_config",
-		"Update a project's advanced config (sets the global response delay in ms).",
+		"Update a project's advanced config (global response delay, latency distribution, fault injection, record mode, CORS policy or simulated authentication). Other config sections are kept.",
 		func(ctx context.Context, req *mcp.CallToolRequest, in advConfigIn) (*mcp.CallToolResult, any, error) {
 			token := tokenFromRequest(req)
-			body := map[string]any{"delayMs": in.DelayMs}
+			path := projectPath(in.WorkspaceID, in.ProjectID) + "/advance-config"
+
+			// The API replaces the whole config, so start from the current one
+			var current struct {
+				Data struct {
+					AdvanceConfig raw `json:"advance_config"`
+				} `json:"data"`
+			}
+			if err := s.client.Get(ctx, token, path, nil, &current); err != nil {
+				r, _, e, _ := handleErr(err)
+				return r, nil, e
+			}
+			body := current.Data.AdvanceConfig
+			if body == nil {
+				body = raw{}
+			}
+			if in.DelayMs != nil {
+				body["delayMs"] = *in.DelayMs
+			}
+			if in.Latency != nil {
+				if len(in.Latency) == 0 {
+					delete(body, "latency")
+				} else {
+					body["latency"] = in.Latency
+				}
+			}
+			if in.Faults != nil {
+				if len(in.Faults) == 0 {
+					delete(body, "faults")
+				} else {
+					body["faults"] = in.Faults
+				}
+			}
+			if in.Record != nil {
+				if len(in.Record) == 0 {
+					delete(body, "record")
+				} else {
+					body["record"] = in.Record
+				}
+			}
+			if in.CORS != nil {
+				if len(in.CORS) == 0 {
+					delete(body, "cors")
+				} else {
+					body["cors"] = in.CORS
+				}
+			}
+			if in.Auth != nil {
+				if len(in.Auth) == 0 {
+					delete(body, "auth")
+				} else {
+					body["auth"] = in.Auth
+				}
+			}
+
 			var out raw
-			if err := s.client.Put(ctx, token, projectPath(in.WorkspaceID, in.ProjectID)+"/advance-config", body, &out); err != nil {
+			if err := s.client.Put(ctx, token, path, body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
 				return r, nil, e
 			}
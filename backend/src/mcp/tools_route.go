@@ -72,8 +72,10 @@ func (s *Server) registerEndpointTools() {
 		Method        string `json:"method" jsonschema:"HTTP method: GET, POST, PUT, DELETE, PATCH, etc."`
 		Path          string `json:"path" jsonschema:"endpoint path, e.g. /users/:id"`
 		Enabled       *bool  `json:"enabled,omitempty" jsonschema:"whether the endpoint is enabled (default true)"`
-		ResponseMode  string `json:"response_mode,omitempty" jsonschema:"how responses are picked: static, random, or round_robin"`
+		ResponseMode  string `json:"response_mode,omitempty" jsonschema:"how responses are picked: static, random, round_robin, weighted (by response weight), or crud (serve a project collection)"`
 		Documentation string `json:"documentation,omitempty" jsonschema:"optional documentation"`
+		Type          string `json:"type,omitempty" jsonschema:"http (default), graphql (responses match on the GraphQL operation) websocket (responses script the messages of the connection; method must be GET) or grpc (path /package.Service/Method of an uploaded proto file; method must be POST)"`
+		GraphQLSchema string `json:"graphql_schema,omitempty" jsonschema:"optional SDL schema for a graphql endpoint; operations and response data are validated against it"`
 	}
 	addTool(s, "route_create_endpoint",
 		"Create a new endpoint (route) in a project.",
@@ -89,6 +91,12 @@ func (s *Server) registerEndpointTools() {
 			if in.Documentation != "" {
 				body["documentation"] = in.Documentation
 			}
+			if in.Type != "" {
+				body["type"] = in.Type
+			}
+			if in.GraphQLSchema != "" {
+				body["graphql_schema"] = in.GraphQLSchema
+			}
 			var out raw
 			if err := s.client.Post(ctx, token, endpointsBase(in.WorkspaceID, in.ProjectID), body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -104,11 +112,13 @@ func (s *Server) registerEndpointTools() {
 		Method        *string `json:"method,omitempty" jsonschema:"new HTTP method"`
 		Path          *string `json:"path,omitempty" jsonschema:"new path"`
 		Enabled       *bool   `json:"enabled,omitempty" jsonschema:"enable/disable the endpoint"`
-		ResponseMode  *string `json:"response_mode,omitempty" jsonschema:"static, random, or round_robin"`
+		ResponseMode  *string `json:"response_mode,omitempty" jsonschema:"static, random, round_robin, weighted, or crud (serve a project collection)"`
 		Documentation *string `json:"documentation,omitempty" jsonschema:"new documentation for the endpoint"`
-		AdvanceConfig *string `json:"advance_config,omitempty" jsonschema:"endpoint advanced config as a JSON string"`
+		AdvanceConfig *string `json:"advance_config,omitempty" jsonschema:"endpoint advanced config as a JSON string, e.g. {\"delayMs\":100}, {\"latency\":{\"distribution\":\"uniform\",\"minMs\":50,\"maxMs\":300}}, {\"faults\":[{\"type\":\"reset\",\"probability\":0.05}]}, {\"collection\":\"users\"} for crud mode (add \"idParam\" when the item id param is not :id), {\"proxyRewrite\":{...}} to override the proxy target rewrite rules, or {\"auth\":{...}} to replace the project auth ({\"auth\":{\"type\":\"none\"}} makes the endpoint public)"`
 		UseProxy      *bool   `json:"use_proxy,omitempty" jsonschema:"forward this endpoint to a proxy target"`
 		ProxyTargetID *string `json:"proxy_target_id,omitempty" jsonschema:"proxy target id when use_proxy is true"`
+		Type          *string `json:"type,omitempty" jsonschema:"http, graphql, websocket or grpc"`
+		GraphQLSchema *string `json:"graphql_schema,omitempty" jsonschema:"SDL schema for a graphql endpoint (empty to remove)"`
 	}
 	addTool(s, "route_update_endpoint",
 		"Update an endpoint. Only provided fields are changed.",
@@ -139,6 +149,12 @@ func (s *Server) registerEndpointTools() {
 			if in.ProxyTargetID != nil {
 				body["proxy_target_id"] = *in.ProxyTargetID
 			}
+			if in.Type != nil {
+				body["type"] = *in.Type
+			}
+			if in.GraphQLSchema != nil {
+				body["graphql_schema"] = *in.GraphQLSchema
+			}
 			var out raw
 			if err := s.client.Put(ctx, token, endpointPath(in.WorkspaceID, in.ProjectID, in.EndpointID), body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -181,19 +197,30 @@ func (s *Server) registerResponseTools() {
 		})
 
 	type createRespIn struct {
-		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
-		ProjectID   string `json:"project_id" jsonschema:"the project id"`
-		EndpointID  string `json:"endpoint_id" jsonschema:"the endpoint id"`
-		StatusCode  int    `json:"status_code" jsonschema:"HTTP status code to return, e.g. 200"`
-		Body        string `json:"body,omitempty" jsonschema:"response body (often JSON as a string)"`
-		Headers     string `json:"headers,omitempty" jsonschema:"response headers as a JSON string"`
-		Priority    int    `json:"priority,omitempty" jsonschema:"match priority (higher wins)"`
-		DelayMs     int    `json:"delay_ms,omitempty" jsonschema:"per-response delay in milliseconds"`
-		Note        string `json:"note,omitempty" jsonschema:"human note describing this response"`
-		Enabled     *bool  `json:"enabled,omitempty" jsonschema:"whether this response is active"`
-		IsFallback  *bool  `json:"is_fallback,omitempty" jsonschema:"use this when no other response matches"`
-		RulesLogic  string `json:"rules_logic,omitempty" jsonschema:"how rules combine: and / or"`
-		Stream      *bool  `json:"stream,omitempty" jsonschema:"stream the response body instead of sending it at once"`
+		WorkspaceID   string `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID     string `json:"project_id" jsonschema:"the project id"`
+		EndpointID    string `json:"endpoint_id" jsonschema:"the endpoint id"`
+		StatusCode    int    `json:"status_code" jsonschema:"HTTP status code to return, e.g. 200"`
+		Body          string `json:"body,omitempty" jsonschema:"response body (often JSON as a string)"`
+		BodyType      string `json:"body_type,omitempty" jsonschema:"text (default), base64 (body holds base64 bytes) or file (serve the uploaded file_id)"`
+		FileID        string `json:"file_id,omitempty" jsonschema:"id of an uploaded project file, used when body_type is file"`
+		Headers       string `json:"headers,omitempty" jsonschema:"response headers as a JSON string"`
+		Priority      int    `json:"priority,omitempty" jsonschema:"match priority (higher wins)"`
+		Weight        *int   `json:"weight,omitempty" jsonschema:"relative chance of being picked when the endpoint response_mode is weighted (default 1, 0 never picks this response)"`
+		DelayMs       int    `json:"delay_ms,omitempty" jsonschema:"per-response delay in milliseconds"`
+		Latency       string `json:"latency,omitempty" jsonschema:"per-response latency distribution as a JSON string, e.g. {\"distribution\":\"normal\",\"meanMs\":200,\"stdDevMs\":50}; overrides delay_ms"`
+		Note          string `json:"note,omitempty" jsonschema:"human note describing this response"`
+		Enabled       *bool  `json:"enabled,omitempty" jsonschema:"whether this response is active"`
+		IsFallback    *bool  `json:"is_fallback,omitempty" jsonschema:"use this when no other response matches"`
+		RulesLogic    string `json:"rules_logic,omitempty" jsonschema:"how rules combine: and / or"`
+		Stream        *bool  `json:"stream,omitempty" jsonschema:"stream the response body instead of sending it at once"`
+		Templated     *bool  `json:"templated,omitempty" jsonschema:"render body and header values as templates against the request, e.g. {{.request.params.id}}"`
+		Scenario      string `json:"scenario,omitempty" jsonschema:"project scenario this response takes part in"`
+		RequiredState string `json:"required_state,omitempty" jsonschema:"only serve this response while the scenario is in this state"`
+		NewState      string `json:"new_state,omitempty" jsonschema:"move the scenario to this state after serving"`
+		GraphQLErrors string `json:"graphql_errors,omitempty" jsonschema:"graphql endpoints only: JSON array of GraphQL errors (each with a message) added to the body errors, for partial responses"`
+		GRPCStatus    string `json:"grpc_status,omitempty" jsonschema:"grpc endpoints only: JSON {code (name like NOT_FOUND or number), message, trailers} sent as the call status; the body is the output message as JSON"`
+		Callbacks     string `json:"callbacks,omitempty" jsonschema:"JSON array of HTTP callbacks sent in the background after the response is served, e.g. [{\"url\":\"https://example.com/hook\",\"method\":\"POST\",\"body\":\"{{.request.rawBody}}\",\"delayMs\":2000,\"retry\":{\"maxAttempts\":3,\"backoffMs\":1000}}]; url, header values and body are templates rendered against the request"`
 	}
 	addTool(s, "route_create_response",
 		"Create a new response for an endpoint.",
@@ -203,15 +230,27 @@ func (s *Server) registerResponseTools() {
 			if in.Body != "" {
 				body["body"] = in.Body
 			}
+			if in.BodyType != "" {
+				body["body_type"] = in.BodyType
+			}
+			if in.FileID != "" {
+				body["file_id"] = in.FileID
+			}
 			if in.Headers != "" {
 				body["headers"] = in.Headers
 			}
 			if in.Priority != 0 {
 				body["priority"] = in.Priority
 			}
+			if in.Weight != nil {
+				body["weight"] = *in.Weight
+			}
 			if in.DelayMs != 0 {
 				body["delay_ms"] = in.DelayMs
 			}
+			if in.Latency != "" {
+				body["latency"] = in.Latency
+			}
 			if in.Note != "" {
 				body["note"] = in.Note
 			}
@@ -227,6 +266,27 @@ func (s *Server) registerResponseTools() {
 			if in.Stream != nil {
 				body["stream"] = *in.Stream
 			}
+			if in.Templated != nil {
+				body["templated"] = *in.Templated
+			}
+			if in.Scenario != "" {
+				body["scenario"] = in.Scenario
+			}
+			if in.RequiredState != "" {
+				body["required_state"] = in.RequiredState
+			}
+			if in.NewState != "" {
+				body["new_state"] = in.NewState
+			}
+			if in.GraphQLErrors != "" {
+				body["graphql_errors"] = in.GraphQLErrors
+			}
+			if in.GRPCStatus != "" {
+				body["grpc_status"] = in.GRPCStatus
+			}
+			if in.Callbacks != "" {
+				body["callbacks"] = in.Callbacks
+			}
 			var out raw
 			if err := s.client.Post(ctx, token, responsesBase(in.WorkspaceID, in.ProjectID, in.EndpointID), body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -254,20 +314,31 @@ func (s *Server) registerResponseTools() {
 		})
 
 	type updateRespIn struct {
-		WorkspaceID string  `json:"workspace_id" jsonschema:"the workspace id"`
-		ProjectID   string  `json:"project_id" jsonschema:"the project id"`
-		EndpointID  string  `json:"endpoint_id" jsonschema:"the endpoint id"`
-		ResponseID  string  `json:"response_id" jsonschema:"the response id"`
-		StatusCode  *int    `json:"status_code,omitempty" jsonschema:"new status code"`
-		Body        *string `json:"body,omitempty" jsonschema:"new body"`
-		Headers     *string `json:"headers,omitempty" jsonschema:"new headers (JSON string)"`
-		Priority    *int    `json:"priority,omitempty" jsonschema:"new match priority"`
-		DelayMs     *int    `json:"delay_ms,omitempty" jsonschema:"new per-response delay (ms)"`
-		Note        *string `json:"note,omitempty" jsonschema:"new note"`
-		Enabled     *bool   `json:"enabled,omitempty" jsonschema:"enable/disable"`
-		IsFallback  *bool   `json:"is_fallback,omitempty" jsonschema:"mark as fallback"`
-		RulesLogic  *string `json:"rules_logic,omitempty" jsonschema:"and / or"`
-		Stream      *bool   `json:"stream,omitempty" jsonschema:"stream the response body instead of sending it at once"`
+		WorkspaceID   string  `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID     string  `json:"project_id" jsonschema:"the project id"`
+		EndpointID    string  `json:"endpoint_id" jsonschema:"the endpoint id"`
+		ResponseID    string  `json:"response_id" jsonschema:"the response id"`
+		StatusCode    *int    `json:"status_code,omitempty" jsonschema:"new status code"`
+		Body          *string `json:"body,omitempty" jsonschema:"new body"`
+		BodyType      *string `json:"body_type,omitempty" jsonschema:"text, base64 or file"`
+		FileID        *string `json:"file_id,omitempty" jsonschema:"id of an uploaded project file for body_type file"`
+		Headers       *string `json:"headers,omitempty" jsonschema:"new headers (JSON string)"`
+		Priority      *int    `json:"priority,omitempty" jsonschema:"new match priority"`
+		Weight        *int    `json:"weight,omitempty" jsonschema:"new weight for weighted response_mode (0 never picks this response)"`
+		DelayMs       *int    `json:"delay_ms,omitempty" jsonschema:"new per-response delay (ms)"`
+		Latency       *string `json:"latency,omitempty" jsonschema:"new per-response latency distribution as a JSON string (empty to remove)"`
+		Note          *string `json:"note,omitempty" jsonschema:"new note"`
+		Enabled       *bool   `json:"enabled,omitempty" jsonschema:"enable/disable"`
+		IsFallback    *bool   `json:"is_fallback,omitempty" jsonschema:"mark as fallback"`
+		RulesLogic    *string `json:"rules_logic,omitempty" jsonschema:"and / or"`
+		Stream        *bool   `json:"stream,omitempty" jsonschema:"stream the response body instead of sending it at once"`
+		Templated     *bool   `json:"templated,omitempty" jsonschema:"render body and header values as templates against the request, e.g. {{.request.params.id}}"`
+		Scenario      *string `json:"scenario,omitempty" jsonschema:"project scenario this response takes part in (empty to detach)"`
+		RequiredState *string `json:"required_state,omitempty" jsonschema:"only serve this response while the scenario is in this state"`
+		NewState      *string `json:"new_state,omitempty" jsonschema:"move the scenario to this state after serving"`
+		GraphQLErrors *string `json:"graphql_errors,omitempty" jsonschema:"graphql endpoints only: JSON array of GraphQL errors added to the body errors (empty to remove)"`
+		GRPCStatus    *string `json:"grpc_status,omitempty" jsonschema:"grpc endpoints only: JSON {code, message, trailers} sent as the call status (empty for OK)"`
+		Callbacks     *string `json:"callbacks,omitempty" jsonschema:"JSON array of HTTP callbacks sent after the response is served (empty to remove)"`
 	}
 	addTool(s, "route_update_response",
 		"Update a response. Only provided fields are changed.",
@@ -280,15 +351,27 @@ func (s *Server) registerResponseTools() {
 			if in.Body != nil {
 				body["body"] = *in.Body
 			}
+			if in.BodyType != nil {
+				body["body_type"] = *in.BodyType
+			}
+			if in.FileID != nil {
+				body["file_id"] = *in.FileID
+			}
 			if in.Headers != nil {
 				body["headers"] = *in.Headers
 			}
 			if in.Priority != nil {
 				body["priority"] = *in.Priority
 			}
+			if in.Weight != nil {
+				body["weight"] = *in.Weight
+			}
 			if in.DelayMs != nil {
 				body["delay_ms"] = *in.DelayMs
 			}
+			if in.Latency != nil {
+				body["latency"] = *in.Latency
+			}
 			if in.Note != nil {
 				body["note"] = *in.Note
 			}
@@ -304,6 +387,27 @@ func (s *Server) registerResponseTools() {
 			if in.Stream != nil {
 				body["stream"] = *in.Stream
 			}
+			if in.Templated != nil {
+				body["templated"] = *in.Templated
+			}
+			if in.Scenario != nil {
+				body["scenario"] = *in.Scenario
+			}
+			if in.RequiredState != nil {
+				body["required_state"] = *in.RequiredState
+			}
+			if in.NewState != nil {
+				body["new_state"] = *in.NewState
+			}
+			if in.GraphQLErrors != nil {
+				body["graphql_errors"] = *in.GraphQLErrors
+			}
+			if in.GRPCStatus != nil {
+				body["grpc_status"] = *in.GRPCStatus
+			}
+			if in.Callbacks != nil {
+				body["callbacks"] = *in.Callbacks
+			}
 			var out raw
 			if err := s.client.Put(ctx, token, responsePath(in.WorkspaceID, in.ProjectID, in.EndpointID, in.ResponseID), body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -385,10 +489,11 @@ func (s *Server) registerRuleTools() {
 		ProjectID   string `json:"project_id" jsonschema:"the project id"`
 		EndpointID  string `json:"endpoint_id" jsonschema:"the endpoint id"`
 		ResponseID  string `json:"response_id" jsonschema:"the response id"`
-		Type        string `json:"type" jsonschema:"what to match: header, body, query, or path"`
-		Key         string `json:"key,omitempty" jsonschema:"the key to match (e.g. header name or query param)"`
-		Operator    string `json:"operator" jsonschema:"comparison: equals, contains, or regex"`
+		Type        string `json:"type" jsonschema:"what to match: header, body, query, path, graphql, or jwt (claims of the bearer token)"`
+		Key         string `json:"key,omitempty" jsonschema:"the key to match (e.g. header name, query param, a JSONPath such as items[*].sku for body rules, operationName, operationType, field, variables.<path> for graphql rules, or a claim such as sub or scope for jwt rules)"`
+		Operator    string `json:"operator" jsonschema:"comparison: equals, not_equals, contains, not_contains, starts_with, ends_with, regex, gt, gte, lt, lte, between, in, not_in, exists, not_exists; body also has_property, matches_type, matches_schema. Add _ci to string operators for case-insensitive matching (e.g. equals_ci)"`
 		Value       string `json:"value" jsonschema:"value to compare against"`
+		Match       string `json:"match,omitempty" jsonschema:"when a body key selects several values: any (default) or all must match"`
 	}
 	addTool(s, "route_create_rule",
 		"Add a matching rule to a response (used to pick the response based on request content).",
@@ -398,6 +503,9 @@ func (s *Server) registerRuleTools() {
 			if in.Key != "" {
 				body["key"] = in.Key
 			}
+			if in.Match != "" {
+				body["match"] = in.Match
+			}
 			var out raw
 			if err := s.client.Post(ctx, token, rulesBase(in.WorkspaceID, in.ProjectID, in.EndpointID, in.ResponseID), body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -412,10 +520,11 @@ func (s *Server) registerRuleTools() {
 		EndpointID  string  `json:"endpoint_id" jsonschema:"the endpoint id"`
 		ResponseID  string  `json:"response_id" jsonschema:"the response id"`
 		RuleID      string  `json:"rule_id" jsonschema:"the rule id"`
-		Type        *string `json:"type,omitempty" jsonschema:"header, body, query, or path"`
+		Type        *string `json:"type,omitempty" jsonschema:"header, body, query, path, graphql, or jwt"`
 		Key         *string `json:"key,omitempty" jsonschema:"the key to match"`
-		Operator    *string `json:"operator,omitempty" jsonschema:"equals, contains, or regex"`
+		Operator    *string `json:"operator,omitempty" jsonschema:"comparison operator, e.g. equals, regex, gt, between, in, exists (see route_create_rule)"`
 		Value       *string `json:"value,omitempty" jsonschema:"value to compare against"`
+		Match       *string `json:"match,omitempty" jsonschema:"any or all, for body keys selecting several values"`
 	}
 	addTool(s, "route_update_rule",
 		"Update a matching rule on a response.",
@@ -434,6 +543,9 @@ func (s *Server) registerRuleTools() {
 			if in.Value != nil {
 				body["value"] = *in.Value
 			}
+			if in.Match != nil {
+				body["match"] = *in.Match
+			}
 			path := rulesBase(in.WorkspaceID, in.ProjectID, in.EndpointID, in.ResponseID) + "/" + in.RuleID
 			var out raw
 			if err := s.client.Put(ctx, token, path, body, &out); err != nil {
@@ -481,13 +593,25 @@ func (s *Server) registerProxyTools() {
 		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
 		ProjectID   string `json:"project_id" jsonschema:"the project id"`
 		Label       string `json:"label" jsonschema:"human label for the proxy target"`
-		URL         string `json:"url" jsonschema:"base URL to forward requests to"`
+		URL         string `json:"url,omitempty" jsonschema:"base URL to forward requests to (not used by pools)"`
+		Pool        string `json:"pool,omitempty" jsonschema:"pool config as a JSON string, e.g. {\"strategy\":\"round_robin\",\"failover\":true,\"members\":[{\"targetId\":\"<proxy id>\"}]}; strategy is round_robin, weighted (members take a weight), sticky (needs stickyHeader) or failover"`
+		Transport   string `json:"transport,omitempty" jsonschema:"transport config as a JSON string: verifyTls, caBundle, clientCert, clientKey (PEM), serverName, connectTimeoutMs, readTimeoutMs, http2, proxyUrl"`
+		Rewrite     string `json:"rewrite,omitempty" jsonschema:"rewrite rules as a JSON string: path [{prefix|regex, replace}], requestHeaders {add, set, remove}, preserveHost, location {urlPrefix: replacement}, cookieDomain {domain: replacement}"`
 	}
 	addTool(s, "route_create_proxy",
-		"Create a proxy target (an upstream the project can forward to).",
+		"Create a proxy target (an upstream the project can forward to), or a pool spreading requests over other proxy targets.",
 		func(ctx context.Context, req *mcp.CallToolRequest, in createProxyIn) (*mcp.CallToolResult, any, error) {
 			token := tokenFromRequest(req)
 			body := map[string]any{"label": in.Label, "url": in.URL}
+			if in.Pool != "" {
+				body["pool"] = in.Pool
+			}
+			if in.Transport != "" {
+				body["transport"] = in.Transport
+			}
+			if in.Rewrite != "" {
+				body["rewrite"] = in.Rewrite
+			}
 			var out raw
 			if err := s.client.Post(ctx, token, proxiesBase(in.WorkspaceID, in.ProjectID), body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -502,6 +626,9 @@ func (s *Server) registerProxyTools() {
 		ProxyID     string  `json:"proxy_id" jsonschema:"the proxy target id"`
 		Label       *string `json:"label,omitempty" jsonschema:"new label"`
 		URL         *string `json:"url,omitempty" jsonschema:"new base URL"`
+		Pool        *string `json:"pool,omitempty" jsonschema:"new pool config as a JSON string; empty turns the pool back into a single target"`
+		Transport   *string `json:"transport,omitempty" jsonschema:"new transport config as a JSON string; empty restores the defaults"`
+		Rewrite     *string `json:"rewrite,omitempty" jsonschema:"new rewrite rules as a JSON string; empty removes them"`
 	}
 	addTool(s, "route_update_proxy",
 		"Update a proxy target.",
@@ -514,6 +641,15 @@ func (s *Server) registerProxyTools() {
 			if in.URL != nil {
 				body["url"] = *in.URL
 			}
+			if in.Pool != nil {
+				body["pool"] = *in.Pool
+			}
+			if in.Transport != nil {
+				body["transport"] = *in.Transport
+			}
+			if in.Rewrite != nil {
+				body["rewrite"] = *in.Rewrite
+			}
 			var out raw
 			if err := s.client.Put(ctx, token, proxiesBase(in.WorkspaceID, in.ProjectID)+"/"+in.ProxyID, body, &out); err != nil {
 				r, _, e, _ := handleErr(err)
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerScenarioTools wires scenario state access. Scenarios themselves are
+// defined in the project advance config ("scenarios"); these tools read and
+// move their current state.
+func (s *Server) registerScenarioTools() {
+	scenariosBase := func(ws, proj string) string { return projectPath(ws, proj) + "/scenarios" }
+
+	type projIn struct {
+		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID   string `json:"project_id" jsonschema:"the project id"`
+	}
+	addTool(s, "project_list_scenarios",
+		"List a project's scenarios with their allowed states and current state.",
+		func(ctx context.Context, req *mcp.CallToolRequest, in projIn) (*mcp.CallToolResult, any, error) {
+			token := tokenFromRequest(req)
+			var out raw
+			if err := s.client.Get(ctx, token, scenariosBase(in.WorkspaceID, in.ProjectID), nil, &out); err != nil {
+				r, _, e, _ := handleErr(err)
+				return r, nil, e
+			}
+			return jsonResult(out)
+		})
+
+	type setStateIn struct {
+		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID   string `json:"project_id" jsonschema:"the project id"`
+		Scenario    string `json:"scenario" jsonschema:"the scenario name"`
+		State       string `json:"state" jsonschema:"the state to move the scenario to"`
+	}
+	addTool(s, "project_set_scenario_state",
+		"Move a scenario to a given state.",
+		func(ctx context.Context, req *mcp.CallToolRequest, in setStateIn) (*mcp.CallToolResult, any, error) {
+			token := tokenFromRequest(req)
+			body := map[string]any{"state": in.State}
+			var out raw
+			if err := s.client.Put(ctx, token, scenariosBase(in.WorkspaceID, in.ProjectID)+"/"+url.PathEscape(in.Scenario)+"/state", body, &out); err != nil {
+				r, _, e, _ := handleErr(err)
+				return r, nil, e
+			}
+			return jsonResult(out)
+		})
+
+	type resetIn struct {
+		WorkspaceID string `json:"workspace_id" jsonschema:"the workspace id"`
+		ProjectID   string `json:"project_id" jsonschema:"the project id"`
+		Scenario    string `json:"scenario,omitempty" jsonschema:"the scenario name; omit to reset every scenario"`
+	}
+	addTool(s, "project_reset_scenario",
+		"Return a scenario (or every scenario of the project) to its initial state.",
+		func(ctx context.Context, req *mcp.CallToolRequest, in resetIn) (*mcp.CallToolResult, any, error) {
+			token := tokenFromRequest(req)
+			path := scenariosBase(in.WorkspaceID, in.ProjectID) + "/reset"
+			if in.Scenario != "" {
+				path = scenariosBase(in.WorkspaceID, in.ProjectID) + "/" + url.PathEscape(in.Scenario) + "/reset"
+			}
+			var out raw
+			if err := s.client.Post(ctx, token, path, nil, &out); err != nil {
+				r, _, e, _ := handleErr(err)
+				return r, nil, e
+			}
+			return jsonResult(out)
+		})
+}
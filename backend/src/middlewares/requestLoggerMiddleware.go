@@ -4,16 +4,23 @@ import (
 	"beo-echo/backend/src/auth"
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/requestctx"
+	"beo-echo/backend/src/echo/services"
 	handlerLogs "beo-echo/backend/src/logs/handlers"
 	systemConfig "beo-echo/backend/src/systemConfigs"
 	"beo-echo/backend/src/utils"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/andybalholm/brotli"
 	"github.com/gin-gonic/gin"
@@ -23,35 +30,130 @@ import (
 	"gorm.io/gorm"
 )
 
-// bodyWriter wraps gin.ResponseWriter to capture the response body
+// bodyWriter wraps gin.ResponseWriter to capture part of the response body
 type bodyWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body *bodyCapture
 }
 
 func (w bodyWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)                  // Copy to buffer
+	w.body.Write(b)                  // Keep the logged part
 	return w.ResponseWriter.Write(b) // Write as usual
 }
 
+// bodyCapture keeps the first or the last limit bytes of a response body, so large and
+// streamed responses are logged without holding them in memory
+type bodyCapture struct {
+	tail  bool // Keep the last bytes instead of the first ones
+	limit int
+	buf   []byte
+	total int64 // Bytes written in total
+}
+
+// captureSettings caches the body capture and request body cap system config, the logger runs on every request
+var captureSettings struct {
+	sync.Mutex
+	tail         bool
+	limit        int
+	requestLimit int
+	loadedAt     time.Time
+}
+
+// captureSettingsTTL is how long the body capture settings are cached
+const captureSettingsTTL = 10 * time.Second
+
+// loadCaptureSettings refreshes the cached settings once captureSettingsTTL has passed
+// Callers hold captureSettings.
+func loadCaptureSettings() {
+	if time.Since(captureSettings.loadedAt) <= captureSettingsTTL {
+		return
+	}
+	captureSettings.tail, captureSettings.limit, captureSettings.requestLimit = false, 1<<20, requestctx.DefaultMaxBodyBytes
+	if mode, err := systemConfig.GetSystemConfigWithType[string](systemConfig.LOG_BODY_CAPTURE_MODE); err == nil {
+		captureSettings.tail = strings.EqualFold(strings.TrimSpace(mode), "tail")
+	}
+	if limit, err := systemConfig.GetSystemConfigWithType[int](systemConfig.LOG_BODY_CAPTURE_BYTES); err == nil {
+		captureSettings.limit = limit
+	}
+	if limit, err := systemConfig.GetSystemConfigWithType[int](systemConfig.REQUEST_BODY_MAX_BYTES); err == nil && limit > 0 {
+		captureSettings.requestLimit = limit
+	}
+	captureSettings.loadedAt = time.Now()
+}
+
+// newBodyCapture returns a capture following the LOG_BODY_CAPTURE_* system config
+func newBodyCapture() *bodyCapture {
+	captureSettings.Lock()
+	defer captureSettings.Unlock()
+	loadCaptureSettings()
+	return &bodyCapture{tail: captureSettings.tail, limit: captureSettings.limit}
+}
+
+// applyRequestBodyLimit sets the request body cap from the REQUEST_BODY_MAX_BYTES system config and returns it
+func applyRequestBodyLimit() int {
+	captureSettings.Lock()
+	defer captureSettings.Unlock()
+	loadCaptureSettings()
+	requestctx.SetMaxBodyBytes(int64(captureSettings.requestLimit))
+	return captureSettings.requestLimit
+}
+
+func (b *bodyCapture) Write(p []byte) {
+	b.total += int64(len(p))
+	if b.limit <= 0 {
+		return
+	}
+
+	if !b.tail {
+		if room := b.limit - len(b.buf); room > 0 {
+			b.buf = append(b.buf, p[:min(len(p), room)]...)
+		}
+		return
+	}
+
+	b.buf = append(b.buf, p...)
+	// Drop older bytes once the buffer holds twice the limit, rather than on every write
+	if len(b.buf) > 2*b.limit {
+		b.buf = append(b.buf[:0], b.buf[len(b.buf)-b.limit:]...)
+	}
+}
+
+// Bytes returns the captured part of the body
+func (b *bodyCapture) Bytes() []byte {
+	if b.limit > 0 && len(b.buf) > b.limit {
+		return b.buf[len(b.buf)-b.limit:]
+	}
+	return b.buf
+}
+
+// Truncated reports whether part of the body was not captured
+func (b *bodyCapture) Truncated() bool {
+	return b.total > int64(len(b.Bytes()))
+}
+
 // RequestLoggerMiddleware logs each HTTP request and response
 func RequestLoggerMiddleware(db *gorm.DB) gin.HandlerFunc {
+	// Callbacks sent by mock responses are logged alongside the requests that triggered them
+	services.SetCallbackLogger(func(entry *database.RequestLog) {
+		StoreRequestLog(db, entry)
+	})
+
 	return func(c *gin.Context) {
-		l := log.With().Str("func", "middlewares.RequestLoggerMiddleware").Logger()
 		start := time.Now()
 
-		// Clone request body
-		var requestBody string
-		if c.Request.Body != nil {
-			bodyBytes, _ := io.ReadAll(c.Request.Body)
-			requestBody = string(bodyBytes)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		// Read the request once into the shared view used by the mock service,
+		// logging the body with its Content-Encoding removed
+		var request *requestctx.Request
+		requestLimit := applyRequestBodyLimit()
+		c.Request, request = requestctx.Attach(c.Request)
+		requestBody := loggableBody(request.Body)
+		if request.Oversized {
+			requestBody = fmt.Sprintf("[body over the %d bytes request body cap, not logged]", requestLimit)
 		}
 
-		// Wrap response writer
-		respBodyBuf := new(bytes.Buffer)
-		bw := &bodyWriter{body: respBodyBuf, ResponseWriter: c.Writer}
-		c.Writer = bw
+		// Wrap response writer, keeping the configured head or tail of the body
+		respBody := newBodyCapture()
+		c.Writer = &bodyWriter{body: respBody, ResponseWriter: c.Writer}
 
 		// Continue
 		c.Next()
@@ -78,83 +180,206 @@ func RequestLoggerMiddleware(db *gorm.DB) gin.HandlerFunc {
 			id = uuid.New().String()
 		}
 
-		// Get response body - prefer raw response from handler context
-		var responseBody string
-		// Check if response is compressed and decompress if needed
-		responseBody = respBodyBuf.String()
-		contentEncoding := c.Writer.Header().Get("Content-Encoding")
-
-		if contentEncoding != "" {
-			compressedData := respBodyBuf.Bytes()
-
-			switch strings.ToLower(contentEncoding) {
-			case "gzip":
-				if reader, err := gzip.NewReader(bytes.NewReader(compressedData)); err == nil {
-					if decompressed, err := io.ReadAll(reader); err == nil {
-						responseBody = string(decompressed)
-					}
-					reader.Close()
-				}
-			case "br":
-				decompressed := brotli.NewReader(bytes.NewReader(compressedData))
-				if data, err := io.ReadAll(decompressed); err == nil {
-					responseBody = string(data)
-				}
-			}
-		}
+		responseBody := capturedResponseBody(respBody, c.Writer.Header().Get("Content-Encoding"))
 
 		// Save log with hashed JWTs
 		logEntry := &database.RequestLog{
-			ID:              id,
-			ProjectID:       toString(projectID),
-			Method:          c.Request.Method,
-			Path:            toString(path),
-			QueryParams:     c.Request.URL.RawQuery,
-			RequestHeaders:  MapSliceToJSONJoined(c.Request.Header),
-			RequestBody:     requestBody,
-			ResponseStatus:  c.Writer.Status(),
-			ResponseHeaders: MapSliceToJSONJoined(c.Writer.Header()),
-			ResponseBody:    responseBody, // Use the responseBody variable
-			LatencyMS:       int(latency),
-			ExecutionMode:   database.ProjectMode(toString(executionMode)),
-			Matched:         toBool(matched),
-			CreatedAt:       time.Now(),
-		}
-
-		entry, err := json.Marshal(logEntry)
-		if err != nil {
-			l.Error().Err(err).Msg("Failed to marshal log entry to JSON")
-		} else {
-			md5Hash := utils.HashMD5(string(entry))
-			logHash, errJwt := auth.GenerateJWTFromString(md5Hash)
-			if errJwt != nil {
-				l.Error().Err(errJwt).Msg("Failed to generate JWT from MD5 hash")
-			}
-			logEntry.LogsHash = logHash
+			ID:                id,
+			ProjectID:         toString(projectID),
+			Method:            c.Request.Method,
+			Path:              toString(path),
+			QueryParams:       c.Request.URL.RawQuery,
+			RequestHeaders:    MapSliceToJSONJoined(c.Request.Header),
+			RequestBody:       requestBody,
+			RequestBodyParsed: request.ParsedBodyJSON(),
+			ResponseStatus:    c.Writer.Status(),
+			ResponseHeaders:   MapSliceToJSONJoined(c.Writer.Header()),
+			ResponseBody:      responseBody, // Use the responseBody variable
+			LatencyMS:         int(latency),
+			ExecutionMode:     database.ProjectMode(toString(executionMode)),
+			Matched:           toBool(matched),
+			CreatedAt:         time.Now(),
 		}
 
-		handlerLogs.EnsureLogService()
-		if ls := handlerLogs.LogService(); ls != nil {
-			ls.NotifySubscribers(*logEntry)
+		if meta, ok := c.Get(handler.KeyRequestMeta); ok {
+			applyRequestMeta(logEntry, meta)
 		}
 
-		// Check if auto-save is enabled
-		autoSaveEnabled, err := systemConfig.GetSystemConfigWithType[bool](systemConfig.AUTO_SAVE_LOGS_IN_DB_ENABLED)
-		if err != nil {
-			l.Error().Err(err).Msg("Failed to get AUTO_SAVE_LOGS_IN_DB_ENABLED config")
-			return
+		StoreRequestLog(db, logEntry)
+	}
+}
+
+// StoreRequestLog signs a request log, streams it to subscribers and saves it
+// when auto-save is enabled
+func StoreRequestLog(db *gorm.DB, logEntry *database.RequestLog) {
+	l := log.With().Str("func", "middlewares.StoreRequestLog").Logger()
+
+	entry, err := json.Marshal(logEntry)
+	if err != nil {
+		l.Error().Err(err).Msg("Failed to marshal log entry to JSON")
+	} else {
+		md5Hash := utils.HashMD5(string(entry))
+		logHash, errJwt := auth.GenerateJWTFromString(md5Hash)
+		if errJwt != nil {
+			l.Error().Err(errJwt).Msg("Failed to generate JWT from MD5 hash")
+		}
+		logEntry.LogsHash = logHash
+	}
+
+	handlerLogs.EnsureLogService()
+	if ls := handlerLogs.LogService(); ls != nil {
+		ls.NotifySubscribers(*logEntry)
+	}
+
+	// Check if auto-save is enabled
+	autoSaveEnabled, err := systemConfig.GetSystemConfigWithType[bool](systemConfig.AUTO_SAVE_LOGS_IN_DB_ENABLED)
+	if err != nil {
+		l.Error().Err(err).Msg("Failed to get AUTO_SAVE_LOGS_IN_DB_ENABLED config")
+		return
+	}
+
+	if autoSaveEnabled {
+		// Save to database
+		if err := db.Create(logEntry).Error; err != nil {
+			// Log error if saving to DB fails
+			l.Error().Err(err).
+				Str("project_id", logEntry.ProjectID).
+				Msg("Failed to save request log to database")
 		}
+	}
+}
+
+// applyRequestMeta copies details reported by the mock service onto the log entry
+func applyRequestMeta(logEntry *database.RequestLog, value interface{}) {
+	meta, ok := value.(*services.RequestMeta)
+	if !ok || meta == nil {
+		return
+	}
+
+	if len(meta.PathParams) > 0 {
+		if paramsJSON, err := json.Marshal(meta.PathParams); err == nil {
+			logEntry.PathParams = string(paramsJSON)
+		}
+	}
+
+	if len(meta.ScenarioStates) > 0 {
+		if statesJSON, err := json.Marshal(meta.ScenarioStates); err == nil {
+			logEntry.ScenarioStates = string(statesJSON)
+		}
+	}
+
+	logEntry.GraphQLOperation = meta.GraphQLOperation
+
+	if meta.ProxyTarget != nil {
+		logEntry.ProxyTarget = meta.ProxyTarget.Label
+		logEntry.ProxyTargetURL = meta.ProxyTarget.URL
+	}
+
+	// The response of a WebSocket session is the frames exchanged after the upgrade
+	if meta.WebSocket != nil && meta.WebSocket.Upgraded() {
+		logEntry.ResponseStatus = http.StatusSwitchingProtocols
+		logEntry.WebSocketFrames = meta.WebSocket.FramesJSON()
+	}
+
+	// gRPC messages are logged decoded to JSON instead of their protobuf frames
+	if meta.GRPC != nil {
+		logEntry.GRPCMethod = meta.GRPC.Method
+		logEntry.GRPCStatus = meta.GRPC.Status()
+		if request := meta.GRPC.RequestJSON(); request != "" {
+			logEntry.RequestBody = request
+		}
+		logEntry.ResponseBody = meta.GRPC.ResponseJSON()
+	}
 
-		if autoSaveEnabled {
-			// Save to database
-			if err := db.Create(logEntry).Error; err != nil {
-				// Log error if saving to DB fails
-				l.Error().Err(err).
-					Str("project_id", toString(projectID)).
-					Msg("Failed to save request log to database")
+	if meta.Auth != nil {
+		if authJSON, err := json.Marshal(meta.Auth); err == nil {
+			logEntry.Auth = string(authJSON)
+		}
+	}
+
+	if meta.Fault != nil {
+		logEntry.Fault = meta.Fault.Type
+		// The connection was dropped before any response was sent
+		if meta.Fault.Type == database.FaultReset || meta.Fault.Type == database.FaultEmpty {
+			logEntry.ResponseStatus = 0
+			logEntry.ResponseBody = ""
+		}
+	}
+}
+
+// capturedResponseBody turns the captured response body into the logged body, decoding
+// compressed bodies and noting how much of a truncated body was left out
+func capturedResponseBody(capture *bodyCapture, contentEncoding string) string {
+	data := capture.Bytes()
+	truncated := capture.Truncated()
+
+	if contentEncoding != "" {
+		if truncated && capture.tail {
+			// The end of a compressed stream cannot be decoded without its start
+			return fmt.Sprintf("[%s-encoded body: %d bytes, tail not decodable]", contentEncoding, capture.total)
+		}
+
+		var decoder io.Reader
+		switch strings.ToLower(contentEncoding) {
+		case "gzip":
+			if reader, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+				defer reader.Close()
+				decoder = reader
+			}
+		case "br":
+			decoder = brotli.NewReader(bytes.NewReader(data))
+		}
+		if decoder != nil {
+			// A truncated head decodes as far as it goes
+			if decoded, err := io.ReadAll(decoder); err == nil || (truncated && len(decoded) > 0) {
+				data = decoded
 			}
 		}
 	}
+
+	if !truncated {
+		return loggableBody(data)
+	}
+
+	note := fmt.Sprintf("[truncated: %d of %d bytes logged]", len(capture.Bytes()), capture.total)
+	if capture.tail {
+		return note + "\n" + loggableBody(trimPartialRunes(data, true))
+	}
+	return loggableBody(trimPartialRunes(data, false)) + "\n" + note
+}
+
+// trimPartialRunes drops the bytes of a UTF-8 character cut in half at the start (leading)
+// or at the end of data, so truncated text is not mistaken for binary
+func trimPartialRunes(data []byte, leading bool) []byte {
+	if leading {
+		for i := 0; i < utf8.UTFMax && i < len(data); i++ {
+			if utf8.RuneStart(data[i]) {
+				return data[i:]
+			}
+		}
+		return data
+	}
+
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// loggableBody returns body as text, or a placeholder with its size and digest when it is binary
+// so images, PDFs and protobuf payloads are not dumped into the log.
+func loggableBody(body []byte) string {
+	if utf8.Valid(body) && bytes.IndexByte(body, 0) == -1 {
+		return string(body)
+	}
+
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf("[binary body: %d bytes, sha256:%s]", len(body), hex.EncodeToString(sum[:]))
 }
 
 func MapSliceToJSONJoined(m map[string][]string) string {
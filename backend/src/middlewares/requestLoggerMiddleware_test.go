@@ -0,0 +1,95 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggableBody(t *testing.T) {
+	t.Run("Text is kept", func(t *testing.T) {
+		assert.Equal(t, `{"ok":true}`, loggableBody([]byte(`{"ok":true}`)))
+		assert.Equal(t, "héllo", loggableBody([]byte("héllo")))
+	})
+
+	t.Run("Binary is replaced by a placeholder", func(t *testing.T) {
+		body := loggableBody([]byte("\x89PNG\r\n\x1a\n\x00\x00"))
+		assert.Equal(t, "[binary body: 10 bytes, sha256:", body[:31])
+		assert.Len(t, body, 31+64+1)
+	})
+
+	t.Run("NUL bytes count as binary", func(t *testing.T) {
+		assert.Contains(t, loggableBody([]byte("a\x00b")), "[binary body: 3 bytes")
+	})
+}
+
+func TestBodyCapture(t *testing.T) {
+	write := func(capture *bodyCapture, chunks ...string) *bodyCapture {
+		for _, chunk := range chunks {
+			capture.Write([]byte(chunk))
+		}
+		return capture
+	}
+
+	t.Run("Small bodies are kept whole", func(t *testing.T) {
+		capture := write(&bodyCapture{limit: 10}, "abc", "def")
+		assert.Equal(t, "abcdef", string(capture.Bytes()))
+		assert.False(t, capture.Truncated())
+	})
+
+	t.Run("Head keeps the first bytes", func(t *testing.T) {
+		capture := write(&bodyCapture{limit: 4}, "abc", "def", "ghi")
+		assert.Equal(t, "abcd", string(capture.Bytes()))
+		assert.True(t, capture.Truncated())
+		assert.Equal(t, int64(9), capture.total)
+	})
+
+	t.Run("Tail keeps the last bytes", func(t *testing.T) {
+		capture := write(&bodyCapture{tail: true, limit: 4}, "abc", "def", "ghi", "jkl")
+		assert.Equal(t, "ijkl", string(capture.Bytes()))
+		assert.True(t, capture.Truncated())
+		assert.LessOrEqual(t, len(capture.buf), 2*4+3)
+	})
+
+	t.Run("Zero limit keeps nothing", func(t *testing.T) {
+		capture := write(&bodyCapture{limit: 0}, "abc")
+		assert.Empty(t, capture.Bytes())
+		assert.True(t, capture.Truncated())
+	})
+}
+
+func TestCapturedResponseBody(t *testing.T) {
+	t.Run("Truncated text is not treated as binary", func(t *testing.T) {
+		head := &bodyCapture{limit: 4}
+		head.Write([]byte("abcé and more"))
+		assert.Equal(t, "abc\n[truncated: 4 of 14 bytes logged]", capturedResponseBody(head, ""))
+
+		tail := &bodyCapture{tail: true, limit: 4}
+		tail.Write([]byte("more and éxyz"))
+		assert.Equal(t, "[truncated: 4 of 14 bytes logged]\nxyz", capturedResponseBody(tail, ""))
+	})
+
+	t.Run("Compressed head is decoded as far as it goes", func(t *testing.T) {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		gz.Write([]byte(strings.Repeat("line of text\n", 2000)))
+		gz.Close()
+
+		complete := &bodyCapture{limit: 1 << 20}
+		complete.Write(compressed.Bytes())
+		assert.Equal(t, strings.Repeat("line of text\n", 2000), capturedResponseBody(complete, "gzip"))
+
+		head := &bodyCapture{limit: compressed.Len() - 10}
+		head.Write(compressed.Bytes())
+		body := capturedResponseBody(head, "gzip")
+		assert.True(t, strings.HasPrefix(body, "line of text\nline of text\n"))
+		assert.Contains(t, body, "[truncated:")
+
+		tail := &bodyCapture{tail: true, limit: 10}
+		tail.Write(compressed.Bytes())
+		assert.Contains(t, capturedResponseBody(tail, "gzip"), "tail not decodable")
+	})
+}
@@ -18,10 +18,14 @@ import (
 	"beo-echo/backend/src/database"
 	"beo-echo/backend/src/database/repositories"
 	"beo-echo/backend/src/echo/handler"
+	"beo-echo/backend/src/echo/handler/collection"
 	"beo-echo/backend/src/echo/handler/endpoint"
+	"beo-echo/backend/src/echo/handler/file"
 	"beo-echo/backend/src/echo/handler/project"
+	"beo-echo/backend/src/echo/handler/protofile"
 	"beo-echo/backend/src/echo/handler/proxy"
 	"beo-echo/backend/src/echo/handler/response"
+	"beo-echo/backend/src/echo/handler/scenario"
 	"beo-echo/backend/src/echo/services"
 	"beo-echo/backend/src/health"
 	"beo-echo/backend/src/lib"
@@ -43,6 +47,9 @@ import (
 	workspacesHandler "beo-echo/backend/src/workspaces/handler"
 )
 
+// mockProjectRoute catches the requests to the mock endpoints of every project
+const mockProjectRoute = "/:project/*path"
+
 // SetupRouter creates and configures a new Gin router
 func SetupRouter() *gin.Engine {
 	// Create Gin router without default logger (using custom logger instead)
@@ -75,15 +82,22 @@ func SetupRouter() *gin.Engine {
 		// )
 	})
 
-	// Configure CORS
-	router.Use(cors.New(cors.Config{
+	// Configure CORS for the admin API
+	// Mock endpoints answer with the CORS policy of their project instead, see services.applyCORS
+	adminCORS := cors.New(cors.Config{
 		AllowOrigins:     []string{lib.CORS_ORIGIN},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With", "Accept"},
 		ExposeHeaders:    []string{"Content-Range", "X-Content-Range"},
 		AllowCredentials: false, // No need for credentials since we use localStorage
 		// MaxAge:           12 * time.Hour,
-	}))
+	})
+	router.Use(func(c *gin.Context) {
+		if c.FullPath() == mockProjectRoute {
+			return
+		}
+		adminCORS(c)
+	})
 
 	// Rate limiting middleware - DISABLED for now due to stability issues
 	// TODO: Re-enable once rate limiting middleware is stable
@@ -296,6 +310,32 @@ func SetupRouter() *gin.Engine {
 				projectRoutes.PUT("/proxies/:proxyId", proxy.UpdateProxyTargetHandler)
 				projectRoutes.DELETE("/proxies/:proxyId", proxy.DeleteProxyTargetHandler)
 
+				// Collection management (stateful data for "crud" endpoints)
+				projectRoutes.GET("/collections", collection.ListCollectionsHandler)
+				projectRoutes.POST("/collections", collection.CreateCollectionHandler)
+				projectRoutes.GET("/collections/:collectionId", collection.GetCollectionHandler)
+				projectRoutes.PUT("/collections/:collectionId", collection.UpdateCollectionHandler)
+				projectRoutes.DELETE("/collections/:collectionId", collection.DeleteCollectionHandler)
+				projectRoutes.POST("/collections/:collectionId/reset", collection.ResetCollectionHandler)
+
+				// Uploaded files served by responses with body_type "file"
+				projectRoutes.GET("/files", file.ListFilesHandler)
+				projectRoutes.POST("/files", file.UploadFileHandler)
+				projectRoutes.GET("/files/:fileId", file.GetFileHandler)
+				projectRoutes.GET("/files/:fileId/content", file.DownloadFileHandler)
+				projectRoutes.DELETE("/files/:fileId", file.DeleteFileHandler)
+
+				// Proto files describing the gRPC methods served by grpc endpoints
+				projectRoutes.GET("/protos", protofile.ListProtoFilesHandler)
+				projectRoutes.POST("/protos", protofile.UploadProtoFileHandler)
+				projectRoutes.DELETE("/protos/:protoId", protofile.DeleteProtoFileHandler)
+
+				// Scenario state management
+				projectRoutes.GET("/scenarios", scenario.ListScenariosHandler)
+				projectRoutes.POST("/scenarios/reset", scenario.ResetAllScenariosHandler)
+				projectRoutes.PUT("/scenarios/:name/state", scenario.SetScenarioStateHandler)
+				projectRoutes.POST("/scenarios/:name/reset", scenario.ResetScenarioHandler)
+
 				// Request Logs management
 				projectRoutes.GET("/logs", handlerLogs.GetLogsHandler)
 				projectRoutes.GET("/logs/stream", handlerLogs.StreamLogsHandler)
@@ -355,7 +395,7 @@ func SetupRouter() *gin.Engine {
 	{
 		// This handler will catch any request that doesn't match the above routes
 		// particularly targeting project-specific mock endpoints
-		mockProjectGroup.Any("/:project/*path", handler.MockRequestHandler)
+		mockProjectGroup.Any(mockProjectRoute, handler.MockRequestHandler)
 	}
 
 	return router
@@ -421,6 +461,9 @@ func StartServer() error {
 	// log.Printf("🔍 Health check: http://%s/api/health", serverAddr)
 	log.Printf("=================================================")
 
+	// gRPC clients reach the mock endpoints over HTTP/2 without TLS (h2c)
+	router.UseH2C = true
+
 	// This will block until the server is stopped
 	return router.Run(serverAddr)
 }
@@ -13,11 +13,15 @@ const (
 	FEATURE_OAUTH_AUTO_REGISTER        = "FEATURE_OAUTH_AUTO_REGISTER" // Controls whether new users can register through OAuth
 
 	AUTO_SAVE_LOGS_IN_DB_ENABLED = "AUTO_SAVE_LOGS_IN_DB_ENABLED" // Enable auto-saving of logs
+	LOG_BODY_CAPTURE_MODE        = "LOG_BODY_CAPTURE_MODE"        // Part of large response bodies kept in logs: "head" or "tail"
+	LOG_BODY_CAPTURE_BYTES       = "LOG_BODY_CAPTURE_BYTES"       // Maximum response body bytes kept in logs
+	REQUEST_BODY_MAX_BYTES       = "REQUEST_BODY_MAX_BYTES"       // Maximum request body bytes read and decoded for rules, templates and logs
 
 	// Workspace and Project Limits
 	AUTO_CREATE_WORKSPACE_ON_REGISTER = "AUTO_CREATE_WORKSPACE_ON_REGISTER" // Automatically create a workspace for new users
 	MAX_USER_WORKSPACES               = "MAX_USER_WORKSPACES"               // Maximum number of workspaces a user can create
 	MAX_WORKSPACE_PROJECTS            = "MAX_WORKSPACE_PROJECTS"            // Maximum number of projects allowed in a workspace
+	MAX_UPLOAD_FILE_BYTES             = "MAX_UPLOAD_FILE_BYTES"             // Maximum size of a project file upload request
 
 	JWT_SECRET = "JWT_SECRET" // JWT secret for signing tokens
 
@@ -84,6 +88,24 @@ var DefaultConfigSettings = map[SystemConfigKey]ConfigSetting{
 		Description: "Automatically persist request logs to database (may affect performance)",
 		Category:    "Logging",
 	},
+	LOG_BODY_CAPTURE_MODE: {
+		Type:        TypeString,
+		Value:       "head",
+		Description: "Part of response bodies larger than LOG_BODY_CAPTURE_BYTES kept in logs: \"head\" (first bytes) or \"tail\" (last bytes)",
+		Category:    "Logging",
+	},
+	LOG_BODY_CAPTURE_BYTES: {
+		Type:        TypeNumber,
+		Value:       "1048576",
+		Description: "Maximum number of response body bytes kept in logs, 0 to log no body. Responses are always sent in full",
+		Category:    "Logging",
+	},
+	REQUEST_BODY_MAX_BYTES: {
+		Type:        TypeNumber,
+		Value:       "10485760",
+		Description: "Maximum request body bytes read and decoded for rules, templates and logs. Larger bodies are not parsed or logged but are still forwarded by the proxy",
+		Category:    "Logging",
+	},
 
 	// Workspace and Project Limits
 	AUTO_CREATE_WORKSPACE_ON_REGISTER: {
@@ -104,6 +126,12 @@ var DefaultConfigSettings = map[SystemConfigKey]ConfigSetting{
 		Description: "Maximum number of projects allowed in a workspace",
 		Category:    "Limits",
 	},
+	MAX_UPLOAD_FILE_BYTES: {
+		Type:        TypeNumber,
+		Value:       "52428800",
+		Description: "Maximum size in bytes of a project file upload request (response body files)",
+		Category:    "Limits",
+	},
 	JWT_SECRET: {
 		Type:        TypeString,
 		Value:       "",
@@ -65,6 +65,14 @@ export type RequestLog = {
 	query_params: string;
 	request_headers: string;
 	request_body: string;
+	request_body_parsed?: string; // XML, form or multipart body as JSON
+	graphql_operation?: string; // GraphQL operation, e.g. "query GetUser"
+	proxy_target?: string; // Label of the proxy target that served the request
+	proxy_target_url?: string;
+	websocket_frames?: string; // JSON array of WebSocketFrame for WebSocket sessions
+	grpc_method?: string; // gRPC method, e.g. "/shop.Catalog/GetItem"
+	grpc_status?: string; // gRPC status code name, e.g. "NOT_FOUND"
+	auth?: string; // JSON {type, result, subject, reason} of the simulated authentication decision
 	response_status: number;
 	response_body: string;
 	response_headers: string;
@@ -72,9 +80,20 @@ export type RequestLog = {
 	execution_mode: string;
 	matched: boolean;
 	bookmark?: boolean;
+	source?: string; // "echo", "replay" or "callback"
 	created_at: Date;
 }
 
+export type WebSocketFrame = {
+	direction: 'in' | 'out'; // "in" from the client, "out" to the client
+	type: 'text' | 'binary' | 'close' | 'dropped';
+	data?: string; // Base64 for binary frames
+	code?: number; // Close code
+	size: number;
+	truncated?: boolean;
+	time: string;
+}
+
 export type Endpoint = {
 	id: string;
 	project_id: string;
@@ -91,6 +110,8 @@ export type Endpoint = {
 	updated_at: Date;
 	documentation: string;
 	advance_config?: string; // Advanced configuration (e.g. timeout) as JSON string
+	type?: 'http' | 'graphql' | 'websocket' | 'grpc';
+	graphql_schema?: string; // Optional SDL schema for graphql endpoints
 }
 
 export type Response = {
@@ -102,11 +123,17 @@ export type Response = {
 	priority: number;
 	delay_ms: number;
 	stream: boolean;
+	templated?: boolean; // Render body and headers as templates against the request
 	enabled: boolean;
 	note: string;
 	is_fallback: boolean;
 	rules: Rule[] | null;
 	rules_logic: 'and' | 'or';
+	graphql_errors?: string; // JSON array of GraphQL errors added to the body (graphql endpoints)
+	grpc_status?: string; // JSON {code, message, trailers} status of the call (grpc endpoints)
+	callbacks?: string; // JSON array of callbacks sent after the response is served
+	recorded_request?: string; // Query and body fields of the request this response was recorded from
+	recorded_hash?: string; // Set on responses created by record mode
 	created_at: Date;
 	updated_at: Date;
 }
@@ -116,6 +143,9 @@ export type ProxyTarget = {
 	project_id: string;
 	url: string;
 	label: string;
+	pool?: string; // Pool config as JSON, empty for a single target
+	transport?: string; // Transport config as JSON: TLS, timeouts, HTTP/2 and outbound proxy
+	rewrite?: string; // Rewrite rules as JSON: path, request headers, Host, Location and Set-Cookie
 	created_at: Date;
 	updated_at: Date;
 }
@@ -471,6 +501,7 @@ export const updateResponse = async (projectId: string, endpointId: string, resp
 	priority?: number;
 	delay_ms?: number;
 	stream?: boolean;
+	templated?: boolean;
 	enabled?: boolean;
 	is_fallback?: boolean;
 	rules_logic?: 'and' | 'or';
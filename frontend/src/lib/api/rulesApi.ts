@@ -7,7 +7,7 @@ export type Rule = {
   responseId: string;
   type: string;       // "header", "query", "body"
   key: string;        // The key to match against
-  operator: string;   // "equals", "contains", "regex"
+  operator: string;   // "equals", "not_equals", "contains", "regex", "gt", "between", "in", "exists", ... ("_ci" suffix ignores case)
   value: string;      // The value to match
   isNew?: boolean; // Optional, used for UI state management
 };